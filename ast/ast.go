@@ -53,12 +53,16 @@ func Parse(fullline string) (returnNode Node, err error) {
 		}
 	}
 
-	parts := strings.SplitN(line, " ", 2)
-	nodeName := parts[0]
+	// association of GenericSelectionExpr
+	if isGenericAssociation(line) {
+		return parseGenericAssociation(line), nil
+	}
+
+	nodeName := line
 
 	// skip node name
-	if len(parts) > 1 {
-		line = parts[1]
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		nodeName, line = line[:i], line[i+1:]
 	}
 
 	switch nodeName {
@@ -76,6 +80,10 @@ func Parse(fullline string) (returnNode Node, err error) {
 		return parseArraySubscriptExpr(line), nil
 	case "AsmLabelAttr":
 		return parseAsmLabelAttr(line), nil
+	case "AtomicExpr":
+		return parseAtomicExpr(line), nil
+	case "AtomicType":
+		return parseAtomicType(line), nil
 	case "AttributedType":
 		return parseAttributedType(line), nil
 	case "AvailabilityAttr":
@@ -100,6 +108,8 @@ func Parse(fullline string) (returnNode Node, err error) {
 		return parseCaseStmt(line), nil
 	case "CharacterLiteral":
 		return parseCharacterLiteral(line), nil
+	case "ComplexType":
+		return parseComplexType(line), nil
 	case "CompoundLiteralExpr":
 		return parseCompoundLiteralExpr(line), nil
 	case "CompoundStmt":
@@ -192,6 +202,8 @@ func Parse(fullline string) (returnNode Node, err error) {
 		return parseGotoStmt(line), nil
 	case "IfStmt":
 		return parseIfStmt(line), nil
+	case "ImaginaryLiteral":
+		return parseImaginaryLiteral(line), nil
 	case "ImplicitCastExpr":
 		return parseImplicitCastExpr(line), nil
 	case "ImplicitValueInitExpr":
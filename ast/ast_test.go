@@ -131,10 +131,6 @@ func TestNullStmt(t *testing.T) {
 	}
 }
 
-type Visitor interface {
-	Visit(node Node) (w Visitor)
-}
-
 type Founder struct{}
 
 var nodesFromAst []string
@@ -0,0 +1,54 @@
+package ast
+
+import "strings"
+
+// AtomicExpr is expression of C11 atomic builtin function, like
+// __c11_atomic_fetch_add. Name of builtin function is not shown by all
+// versions of clang.
+type AtomicExpr struct {
+	Addr       Address
+	Pos        Position
+	Type       string
+	Type2      string
+	Name       string
+	ChildNodes []Node
+}
+
+func parseAtomicExpr(line string) *AtomicExpr {
+	groups := groupsFromRegex(
+		`<(?P<position>.*)> '(?P<type1>.*?)'(:'(?P<type2>.*?)')?(?P<name> \w+)?`,
+		line,
+	)
+
+	return &AtomicExpr{
+		Addr:       ParseAddress(groups["address"]),
+		Pos:        NewPositionFromString(groups["position"]),
+		Type:       groups["type1"],
+		Type2:      groups["type2"],
+		Name:       strings.TrimSpace(groups["name"]),
+		ChildNodes: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *AtomicExpr) AddChild(node Node) {
+	n.ChildNodes = append(n.ChildNodes, node)
+}
+
+// Address returns the numeric address of the node. See the documentation for
+// the Address type for more information.
+func (n *AtomicExpr) Address() Address {
+	return n.Addr
+}
+
+// Children returns the child nodes. If this node does not have any children or
+// this node does not support children it will always return an empty slice.
+func (n *AtomicExpr) Children() []Node {
+	return n.ChildNodes
+}
+
+// Position returns the position in the original source code.
+func (n *AtomicExpr) Position() Position {
+	return n.Pos
+}
@@ -0,0 +1,28 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestAtomicExpr(t *testing.T) {
+	nodes := map[string]Node{
+		`0x55d7a1e0c9e8 <line:8:5, col:28> 'int'`: &AtomicExpr{
+			Addr:       0x55d7a1e0c9e8,
+			Pos:        NewPositionFromString("line:8:5, col:28"),
+			Type:       "int",
+			Type2:      "",
+			Name:       "",
+			ChildNodes: []Node{},
+		},
+		`0x1e2c3a0 <col:3, col:38> 'size_t':'unsigned long' __c11_atomic_fetch_add`: &AtomicExpr{
+			Addr:       0x1e2c3a0,
+			Pos:        NewPositionFromString("col:3, col:38"),
+			Type:       "size_t",
+			Type2:      "unsigned long",
+			Name:       "__c11_atomic_fetch_add",
+			ChildNodes: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
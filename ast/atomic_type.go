@@ -0,0 +1,41 @@
+package ast
+
+// AtomicType is C11 atomic type
+type AtomicType struct {
+	Addr       Address
+	Type       string
+	ChildNodes []Node
+}
+
+func parseAtomicType(line string) *AtomicType {
+	groups := groupsFromRegex(`'(?P<type>.*)'`, line)
+
+	return &AtomicType{
+		Addr:       ParseAddress(groups["address"]),
+		Type:       groups["type"],
+		ChildNodes: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *AtomicType) AddChild(node Node) {
+	n.ChildNodes = append(n.ChildNodes, node)
+}
+
+// Address returns the numeric address of the node. See the documentation for
+// the Address type for more information.
+func (n *AtomicType) Address() Address {
+	return n.Addr
+}
+
+// Children returns the child nodes. If this node does not have any children or
+// this node does not support children it will always return an empty slice.
+func (n *AtomicType) Children() []Node {
+	return n.ChildNodes
+}
+
+// Position returns the position in the original source code.
+func (n *AtomicType) Position() Position {
+	return Position{}
+}
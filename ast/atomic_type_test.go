@@ -0,0 +1,17 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestAtomicType(t *testing.T) {
+	nodes := map[string]Node{
+		`0x55d7a1dd0a40 '_Atomic(int)'`: &AtomicType{
+			Addr:       0x55d7a1dd0a40,
+			Type:       "_Atomic(int)",
+			ChildNodes: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
@@ -12,6 +12,12 @@ type BinaryOperator struct {
 }
 
 func parseBinaryOperator(line string) *BinaryOperator {
+	if fastParsers {
+		if n, ok := scanBinaryOperator(line); ok {
+			return n
+		}
+	}
+
 	groups := groupsFromRegex(
 		`<(?P<position>.*)>
 		 '(?P<type1>.*?)'
@@ -0,0 +1,22 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestComplexType(t *testing.T) {
+	nodes := map[string]Node{
+		`0x55d7a1d95fc0 '_Complex double'`: &ComplexType{
+			Addr:       0x55d7a1d95fc0,
+			Type:       "_Complex double",
+			ChildNodes: []Node{},
+		},
+		`0x55d7a1d95fe0 '_Complex float'`: &ComplexType{
+			Addr:       0x55d7a1d95fe0,
+			Type:       "_Complex float",
+			ChildNodes: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
@@ -11,6 +11,12 @@ type CompoundStmt struct {
 }
 
 func parseCompoundStmt(line string) *CompoundStmt {
+	if fastParsers {
+		if n, ok := scanCompoundStmt(line); ok {
+			return n
+		}
+	}
+
 	groups := groupsFromRegex(
 		"<(?P<position>.*)>",
 		line,
@@ -17,6 +17,12 @@ type DeclRefExpr struct {
 }
 
 func parseDeclRefExpr(line string) *DeclRefExpr {
+	if fastParsers {
+		if n, ok := scanDeclRefExpr(line); ok {
+			return n
+		}
+	}
+
 	groups := groupsFromRegex(
 		`<(?P<position>.*)>
 		 '(?P<type>.*?)'(:'(?P<type1>.*?)')?
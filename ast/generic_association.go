@@ -0,0 +1,71 @@
+package ast
+
+import (
+	"strings"
+)
+
+// GenericAssociation is association of generic selection expression.
+// Association have not address and position.
+//
+// Examples:
+//
+//	case 'int'
+//	case 'double' selected
+//	default
+type GenericAssociation struct {
+	Type       string
+	IsDefault  bool
+	IsSelected bool
+	ChildNodes []Node
+}
+
+// isGenericAssociation return true, if line is generic association
+func isGenericAssociation(line string) bool {
+	return strings.HasPrefix(line, "case '") ||
+		line == "default" || strings.HasPrefix(line, "default ")
+}
+
+func parseGenericAssociation(line string) *GenericAssociation {
+	ga := &GenericAssociation{
+		ChildNodes: []Node{},
+	}
+	if strings.HasSuffix(line, " selected") {
+		ga.IsSelected = true
+		line = strings.TrimSuffix(line, " selected")
+	}
+	if strings.HasPrefix(line, "default") {
+		ga.IsDefault = true
+		return ga
+	}
+	line = strings.TrimPrefix(line, "case ")
+	// type with sugar: 'size_t':'unsigned long'
+	if index := strings.Index(line, "':'"); index >= 0 {
+		line = line[:index+1]
+	}
+	ga.Type = strings.Trim(line, "'")
+	return ga
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *GenericAssociation) AddChild(node Node) {
+	n.ChildNodes = append(n.ChildNodes, node)
+}
+
+// Address returns the numeric address of the node. For a GenericAssociation
+// this will always be zero. See the documentation for the Address type for
+// more information.
+func (n *GenericAssociation) Address() Address {
+	return 0
+}
+
+// Children returns the child nodes. If this node does not have any children or
+// this node does not support children it will always return an empty slice.
+func (n *GenericAssociation) Children() []Node {
+	return n.ChildNodes
+}
+
+// Position returns the position in the original source code.
+func (n *GenericAssociation) Position() Position {
+	return Position{}
+}
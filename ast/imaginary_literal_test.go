@@ -0,0 +1,24 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestImaginaryLiteral(t *testing.T) {
+	nodes := map[string]Node{
+		`0x55d7a1e0c6a8 <col:24> '_Complex double'`: &ImaginaryLiteral{
+			Addr:       0x55d7a1e0c6a8,
+			Pos:        NewPositionFromString("col:24"),
+			Type:       "_Complex double",
+			ChildNodes: []Node{},
+		},
+		`0x55d7a1e0c3f0 </usr/include/complex.h:55:34> '_Complex float'`: &ImaginaryLiteral{
+			Addr:       0x55d7a1e0c3f0,
+			Pos:        NewPositionFromString("/usr/include/complex.h:55:34"),
+			Type:       "_Complex float",
+			ChildNodes: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
@@ -15,6 +15,12 @@ type ImplicitCastExpr struct {
 const ImplicitCastExprArrayToPointerDecay = "ArrayToPointerDecay"
 
 func parseImplicitCastExpr(line string) *ImplicitCastExpr {
+	if fastParsers {
+		if n, ok := scanImplicitCastExpr(line); ok {
+			return n
+		}
+	}
+
 	groups := groupsFromRegex(
 		`<(?P<position>.*)>
 		 '(?P<type>.*?)'
@@ -10,6 +10,12 @@ type IntegerLiteral struct {
 }
 
 func parseIntegerLiteral(line string) *IntegerLiteral {
+	if fastParsers {
+		if n, ok := scanIntegerLiteral(line); ok {
+			return n
+		}
+	}
+
 	groups := groupsFromRegex(
 		"<(?P<position>.*)> '(?P<type>.*?)' (?P<value>\\d+)",
 		line,
@@ -0,0 +1,635 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/preprocessor"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// TreeNode is node of AST with depth in the tree. Root node has depth 0.
+type TreeNode struct {
+	Depth int
+	Node  Node
+}
+
+// jsonNode is node of clang AST in JSON format
+// (`clang -Xclang -ast-dump=json`). Only fields, that are used for
+// creating nodes, are decoded.
+type jsonNode struct {
+	ID    string        `json:"id"`
+	Kind  string        `json:"kind"`
+	Loc   *jsonLocation `json:"loc"`
+	Range *struct {
+		Begin *jsonLocation `json:"begin"`
+		End   *jsonLocation `json:"end"`
+	} `json:"range"`
+
+	IsImplicit   bool      `json:"isImplicit"`
+	Implicit     bool      `json:"implicit"`
+	Inherited    bool      `json:"inherited"`
+	IsUsed       bool      `json:"isUsed"`
+	IsReferenced bool      `json:"isReferenced"`
+	PreviousDecl string    `json:"previousDecl"`
+	Name         string    `json:"name"`
+	Type         *jsonType `json:"type"`
+
+	StorageClass       string `json:"storageClass"`
+	Inline             bool   `json:"inline"`
+	Init               string `json:"init"`
+	TLS                string `json:"tls"`
+	Nrvo               bool   `json:"nrvo"`
+	TagUsed            string `json:"tagUsed"`
+	CompleteDefinition bool   `json:"completeDefinition"`
+	IsBitfield         bool   `json:"isBitfield"`
+
+	ValueCategory        string          `json:"valueCategory"`
+	ReferencedDecl       *jsonNode       `json:"referencedDecl"`
+	ReferencedMemberDecl string          `json:"referencedMemberDecl"`
+	IsArrow              bool            `json:"isArrow"`
+	CastKind             string          `json:"castKind"`
+	IsPartOfExplicitCast bool            `json:"isPartOfExplicitCast"`
+	IsPostfix            bool            `json:"isPostfix"`
+	CanOverflow          *bool           `json:"canOverflow"`
+	Opcode               string          `json:"opcode"`
+	ComputeLHSType       *jsonType       `json:"computeLHSType"`
+	ComputeResultType    *jsonType       `json:"computeResultType"`
+	Value                json.RawMessage `json:"value"`
+	ArgType              *jsonType       `json:"argType"`
+	HasElse              bool            `json:"hasElse"`
+	TargetLabelDeclID    string          `json:"targetLabelDeclId"`
+	DeclID               string          `json:"declId"`
+
+	Size       int       `json:"size"`
+	Decl       *jsonNode `json:"decl"`
+	Qualifiers string    `json:"qualifiers"`
+	CC         string    `json:"cc"`
+	Text       string    `json:"text"`
+
+	AssociationKind string `json:"associationKind"`
+	Selected        bool   `json:"selected"`
+
+	ArrayFiller []jsonNode `json:"array_filler"`
+	Inner       []jsonNode `json:"inner"`
+}
+
+// jsonType is type of node with desugared type.
+type jsonType struct {
+	QualType          string `json:"qualType"`
+	DesugaredQualType string `json:"desugaredQualType"`
+}
+
+// jsonLocation is location in source. File and line are omitted, if they
+// are the same as in previous location of dump. Location of macro
+// expansion contains spelling and expansion locations.
+type jsonLocation struct {
+	File         string        `json:"file"`
+	Line         int           `json:"line"`
+	Col          int           `json:"col"`
+	SpellingLoc  *jsonLocation `json:"spellingLoc"`
+	ExpansionLoc *jsonLocation `json:"expansionLoc"`
+}
+
+// lineMarker is preprocessor line marker `# 12 "file.c"`: the next line
+// of preprocessed source is line 12 of file "file.c".
+type lineMarker struct {
+	line int // line of marker in preprocessed source
+	file string
+	next int // line in file after marker
+}
+
+// jsonNodes contains constructors of nodes by kind of clang node.
+var jsonNodes = map[string]func() Node{
+	"AccessSpecDecl":            func() Node { return &AccessSpecDecl{} },
+	"AlignedAttr":               func() Node { return &AlignedAttr{} },
+	"AllocAlignAttr":            func() Node { return &AllocAlignAttr{} },
+	"AllocSizeAttr":             func() Node { return &AllocSizeAttr{} },
+	"AlwaysInlineAttr":          func() Node { return &AlwaysInlineAttr{} },
+	"AnnotateAttr":              func() Node { return &AnnotateAttr{} },
+	"ArraySubscriptExpr":        func() Node { return &ArraySubscriptExpr{} },
+	"AsmLabelAttr":              func() Node { return &AsmLabelAttr{} },
+	"AtomicExpr":                func() Node { return &AtomicExpr{} },
+	"AtomicType":                func() Node { return &AtomicType{} },
+	"AttributedType":            func() Node { return &AttributedType{} },
+	"AvailabilityAttr":          func() Node { return &AvailabilityAttr{} },
+	"BinaryConditionalOperator": func() Node { return &BinaryConditionalOperator{} },
+	"BinaryOperator":            func() Node { return &BinaryOperator{} },
+	"BlockCommandComment":       func() Node { return &BlockCommandComment{} },
+	"BreakStmt":                 func() Node { return &BreakStmt{} },
+	"BuiltinAttr":               func() Node { return &BuiltinAttr{} },
+	"BuiltinType":               func() Node { return &BuiltinType{} },
+	"C11NoReturnAttr":           func() Node { return &C11NoReturnAttr{} },
+	"CStyleCastExpr":            func() Node { return &CStyleCastExpr{} },
+	"CXXConstructExpr":          func() Node { return &CXXConstructExpr{} },
+	"CXXConstructorDecl":        func() Node { return &CXXConstructorDecl{} },
+	"CXXMemberCallExpr":         func() Node { return &CXXMemberCallExpr{CallExpr: &CallExpr{}} },
+	"CXXMethodDecl":             func() Node { return &CXXMethodDecl{} },
+	"CXXRecord":                 func() Node { return &CXXRecord{} },
+	"CXXRecordDecl":             func() Node { return &CXXRecordDecl{RecordDecl: &RecordDecl{}} },
+	"CXXThisExpr":               func() Node { return &CXXThisExpr{} },
+	"CallExpr":                  func() Node { return &CallExpr{} },
+	"CaseStmt":                  func() Node { return &CaseStmt{} },
+	"CharacterLiteral":          func() Node { return &CharacterLiteral{} },
+	"ComplexType":               func() Node { return &ComplexType{} },
+	"CompoundAssignOperator":    func() Node { return &CompoundAssignOperator{} },
+	"CompoundLiteralExpr":       func() Node { return &CompoundLiteralExpr{} },
+	"CompoundStmt":              func() Node { return &CompoundStmt{} },
+	"ConditionalOperator":       func() Node { return &ConditionalOperator{} },
+	"ConstAttr":                 func() Node { return &ConstAttr{} },
+	"ConstantArrayType":         func() Node { return &ConstantArrayType{} },
+	"ConstantExpr":              func() Node { return &ConstantExpr{} },
+	"ContinueStmt":              func() Node { return &ContinueStmt{} },
+	"DecayedType":               func() Node { return &DecayedType{} },
+	"DeclRefExpr":               func() Node { return &DeclRefExpr{} },
+	"DeclStmt":                  func() Node { return &DeclStmt{} },
+	"DefaultStmt":               func() Node { return &DefaultStmt{} },
+	"DeprecatedAttr":            func() Node { return &DeprecatedAttr{} },
+	"DisableTailCallsAttr":      func() Node { return &DisableTailCallsAttr{} },
+	"DoStmt":                    func() Node { return &DoStmt{} },
+	"ElaboratedType":            func() Node { return &ElaboratedType{} },
+	"EmptyDecl":                 func() Node { return &EmptyDecl{} },
+	"EnableIfAttr":              func() Node { return &EnableIfAttr{} },
+	"Enum":                      func() Node { return &Enum{} },
+	"EnumConstantDecl":          func() Node { return &EnumConstantDecl{} },
+	"EnumDecl":                  func() Node { return &EnumDecl{} },
+	"EnumType":                  func() Node { return &EnumType{} },
+	"Field":                     func() Node { return &Field{} },
+	"FieldDecl":                 func() Node { return &FieldDecl{} },
+	"FloatingLiteral":           func() Node { return &FloatingLiteral{} },
+	"ForStmt":                   func() Node { return &ForStmt{} },
+	"FormatArgAttr":             func() Node { return &FormatArgAttr{} },
+	"FormatAttr":                func() Node { return &FormatAttr{} },
+	"FullComment":               func() Node { return &FullComment{} },
+	"FunctionDecl":              func() Node { return &FunctionDecl{} },
+	"FunctionNoProtoType":       func() Node { return &FunctionNoProtoType{} },
+	"FunctionProtoType":         func() Node { return &FunctionProtoType{} },
+	"GCCAsmStmt":                func() Node { return &GCCAsmStmt{} },
+	"GenericSelectionExpr":      func() Node { return &GenericSelectionExpr{} },
+	"GotoStmt":                  func() Node { return &GotoStmt{} },
+	"HTMLEndTagComment":         func() Node { return &HTMLEndTagComment{} },
+	"HTMLStartTagComment":       func() Node { return &HTMLStartTagComment{} },
+	"IfStmt":                    func() Node { return &IfStmt{} },
+	"ImaginaryLiteral":          func() Node { return &ImaginaryLiteral{} },
+	"ImplicitCastExpr":          func() Node { return &ImplicitCastExpr{} },
+	"ImplicitValueInitExpr":     func() Node { return &ImplicitValueInitExpr{} },
+	"IncompleteArrayType":       func() Node { return &IncompleteArrayType{} },
+	"IndirectFieldDecl":         func() Node { return &IndirectFieldDecl{} },
+	"InitListExpr":              func() Node { return &InitListExpr{} },
+	"InlineCommandComment":      func() Node { return &InlineCommandComment{} },
+	"IntegerLiteral":            func() Node { return &IntegerLiteral{} },
+	"LabelStmt":                 func() Node { return &LabelStmt{} },
+	"LinkageSpecDecl":           func() Node { return &LinkageSpecDecl{} },
+	"MallocAttr":                func() Node { return &MallocAttr{} },
+	"MaxFieldAlignmentAttr":     func() Node { return &MaxFieldAlignmentAttr{} },
+	"MemberExpr":                func() Node { return &MemberExpr{} },
+	"ModeAttr":                  func() Node { return &ModeAttr{} },
+	"NoAliasAttr":               func() Node { return &NoAliasAttr{} },
+	"NoInlineAttr":              func() Node { return &NoInlineAttr{} },
+	"NoThrowAttr":               func() Node { return &NoThrowAttr{} },
+	"NonNullAttr":               func() Node { return &NonNullAttr{} },
+	"NotTailCalledAttr":         func() Node { return &NotTailCalledAttr{} },
+	"OffsetOfExpr":              func() Node { return &OffsetOfExpr{} },
+	"OpaqueValueExpr":           func() Node { return &OpaqueValueExpr{} },
+	"OverloadableAttr":          func() Node { return &OverloadableAttr{} },
+	"PackedAttr":                func() Node { return &PackedAttr{} },
+	"ParagraphComment":          func() Node { return &ParagraphComment{} },
+	"ParamCommandComment":       func() Node { return &ParamCommandComment{} },
+	"ParenExpr":                 func() Node { return &ParenExpr{} },
+	"ParenType":                 func() Node { return &ParenType{} },
+	"ParmVarDecl":               func() Node { return &ParmVarDecl{} },
+	"PointerType":               func() Node { return &PointerType{} },
+	"PredefinedExpr":            func() Node { return &PredefinedExpr{} },
+	"PureAttr":                  func() Node { return &PureAttr{} },
+	"QualType":                  func() Node { return &QualType{} },
+	"Record":                    func() Node { return &Record{} },
+	"RecordDecl":                func() Node { return &RecordDecl{} },
+	"RecordType":                func() Node { return &RecordType{} },
+	"RestrictAttr":              func() Node { return &RestrictAttr{} },
+	"ReturnStmt":                func() Node { return &ReturnStmt{} },
+	"ReturnsTwiceAttr":          func() Node { return &ReturnsTwiceAttr{} },
+	"SentinelAttr":              func() Node { return &SentinelAttr{} },
+	"StaticAssertDecl":          func() Node { return &StaticAssertDecl{} },
+	"StmtExpr":                  func() Node { return &StmtExpr{} },
+	"StringLiteral":             func() Node { return &StringLiteral{} },
+	"SwitchStmt":                func() Node { return &SwitchStmt{} },
+	"TextComment":               func() Node { return &TextComment{} },
+	"TranslationUnitDecl":       func() Node { return &TranslationUnitDecl{} },
+	"TransparentUnionAttr":      func() Node { return &TransparentUnionAttr{} },
+	"Typedef":                   func() Node { return &Typedef{} },
+	"TypedefDecl":               func() Node { return &TypedefDecl{} },
+	"TypedefType":               func() Node { return &TypedefType{} },
+	"UnaryExprOrTypeTraitExpr":  func() Node { return &UnaryExprOrTypeTraitExpr{} },
+	"UnaryOperator":             func() Node { return &UnaryOperator{} },
+	"UnusedAttr":                func() Node { return &UnusedAttr{} },
+	"UsedAttr":                  func() Node { return &UsedAttr{} },
+	"VAArgExpr":                 func() Node { return &VAArgExpr{} },
+	"VarDecl":                   func() Node { return &VarDecl{} },
+	"VerbatimBlockComment":      func() Node { return &VerbatimBlockComment{} },
+	"VerbatimBlockLineComment":  func() Node { return &VerbatimBlockLineComment{} },
+	"VerbatimLineComment":       func() Node { return &VerbatimLineComment{} },
+	"VisibilityAttr":            func() Node { return &VisibilityAttr{} },
+	"WarnUnusedResultAttr":      func() Node { return &WarnUnusedResultAttr{} },
+	"WeakAttr":                  func() Node { return &WeakAttr{} },
+	"WhileStmt":                 func() Node { return &WhileStmt{} },
+}
+
+// jsonParser creates nodes from clang JSON AST.
+type jsonParser struct {
+	nodes []TreeNode
+	errs  []error
+
+	// last file and line of location in dump
+	file string
+	line int
+
+	markers   []lineMarker
+	labels    map[string]string // names of labels by address
+	bitfields map[string]bool   // addresses of bitfields
+	gotos     []*GotoStmt
+}
+
+// ParseJSON parses clang AST in JSON format. Preprocessed source is used
+// for positions in original files by line markers. Nodes are returned in
+// the order of text AST dump with depth of nodes in the tree. Unknown nodes
+// are returned as nil nodes with error.
+func ParseJSON(data []byte, filePP preprocessor.FilePP) (nodes []TreeNode, errs []error) {
+	var root jsonNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, []error{fmt.Errorf("cannot parse JSON AST: %v", err)}
+	}
+
+	p := jsonParser{
+		markers:   parseLineMarkers(string(filePP.GetSource())),
+		labels:    map[string]string{},
+		bitfields: map[string]bool{},
+	}
+	p.parse(&root, 0)
+
+	// labels may be defined after goto
+	for _, g := range p.gotos {
+		g.Name = p.labels[g.Position2]
+	}
+	return p.nodes, p.errs
+}
+
+func (p *jsonParser) parse(j *jsonNode, depth int) {
+	n, err := p.node(j)
+	if err != nil {
+		p.errs = append(p.errs, err)
+		// node is ignored, as in text AST
+		n = nil
+	}
+	p.nodes = append(p.nodes, TreeNode{Depth: depth, Node: n})
+
+	// nodes of types are referenced to declarations
+	if j.Decl != nil {
+		switch j.Kind {
+		case "RecordType":
+			p.nodes = append(p.nodes, TreeNode{Depth: depth + 1, Node: &Record{
+				Addr: ParseAddress(j.Decl.ID), Type: j.Decl.Name, ChildNodes: []Node{}}})
+		case "EnumType":
+			p.nodes = append(p.nodes, TreeNode{Depth: depth + 1, Node: &Enum{
+				Addr: ParseAddress(j.Decl.ID), Name: j.Decl.Name, ChildNodes: []Node{}}})
+		case "TypedefType":
+			p.nodes = append(p.nodes, TreeNode{Depth: depth + 1, Node: &Typedef{
+				Addr: ParseAddress(j.Decl.ID), Type: j.Decl.Name, ChildNodes: []Node{}}})
+		}
+	}
+
+	if len(j.ArrayFiller) > 0 {
+		p.nodes = append(p.nodes, TreeNode{Depth: depth + 1,
+			Node: &ArrayFiller{ChildNodes: []Node{}}})
+		for i := range j.ArrayFiller {
+			p.parse(&j.ArrayFiller[i], depth+2)
+		}
+	}
+	for i := range j.Inner {
+		p.parse(&j.Inner[i], depth+1)
+	}
+}
+
+// node creates node without children.
+func (p *jsonParser) node(j *jsonNode) (node Node, err error) {
+	// association of GenericSelectionExpr
+	if j.AssociationKind != "" {
+		ga := &GenericAssociation{
+			IsDefault:  j.AssociationKind == "default",
+			IsSelected: j.Selected,
+			ChildNodes: []Node{},
+		}
+		if j.Type != nil && !ga.IsDefault {
+			ga.Type = j.Type.QualType
+		}
+		return ga, nil
+	}
+
+	// null nodes are omitted parts of statements, for example: for(;;)
+	if j.Kind == "" || j.Kind == "NullStmt" {
+		return nil, nil
+	}
+
+	create, ok := jsonNodes[j.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown node type: `%s` %s", j.Kind, j.ID)
+	}
+	n := create()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot parse JSON node %s %s. %v", j.Kind, j.ID, r)
+			node = nil
+		}
+	}()
+
+	// positions are taken in order of JSON dump
+	var pos, loc Position
+	if j.Loc != nil {
+		loc = p.position(j.Loc, nil)
+	}
+	if j.Range != nil {
+		pos = p.position(j.Range.Begin, j.Range.End)
+	}
+
+	v := reflect.ValueOf(n).Elem()
+	if v.NumField() == 1 && v.Field(0).Kind() == reflect.Ptr {
+		// nodes based on other node, for example: CXXRecordDecl
+		v = v.Field(0).Elem()
+	}
+	set := func(name string, value interface{}) {
+		f := v.FieldByName(name)
+		if !f.IsValid() || !f.CanSet() {
+			return
+		}
+		x := reflect.ValueOf(value)
+		switch {
+		case x.Type().AssignableTo(f.Type()):
+			f.Set(x)
+		case f.Type() == reflect.TypeOf(Address(0)) && x.Kind() == reflect.String:
+			f.Set(reflect.ValueOf(ParseAddress(x.String())))
+		}
+	}
+
+	set("Addr", ParseAddress(j.ID))
+	set("Pos", pos)
+	// Position2 is position of name of declaration
+	set("Position2", loc)
+	if loc.Line > 0 {
+		set("Position2", fmt.Sprintf("line:%d:%d", loc.Line, loc.Column))
+	}
+	set("ChildNodes", []Node{})
+	set("Name", j.Name)
+	if j.Type != nil {
+		if v.FieldByName("Type").IsValid() {
+			set("Type", j.Type.QualType)
+		} else {
+			set("Type1", j.Type.QualType)
+		}
+		set("Type2", j.Type.DesugaredQualType)
+	}
+	set("IsImplicit", j.IsImplicit || j.Implicit)
+	set("IsInherited", j.Inherited)
+	set("Inherited", j.Inherited)
+	set("IsUsed", j.IsUsed)
+	set("IsReferenced", j.IsReferenced)
+	set("IsLvalue", j.ValueCategory == "lvalue")
+	set("Prev", j.PreviousDecl)
+	set("Operator", j.Opcode)
+	set("Opcode", j.Opcode)
+	set("Size", j.Size)
+	set("HasElse", j.HasElse)
+	set("Text", j.Text)
+	set("IsExtern", j.StorageClass == "extern")
+	set("IsStatic", j.StorageClass == "static")
+	set("IsRegister", j.StorageClass == "register")
+	set("IsInline", j.Inline)
+	set("IsTls", j.TLS != "")
+	set("IsNrvo", j.Nrvo)
+	set("IsCInit", j.Init == "c")
+	set("IsCallInit", j.Init == "call")
+	set("IsDefinition", j.CompleteDefinition)
+	set("IsPartExplicitCast", j.IsPartOfExplicitCast)
+	for _, kind := range []string{j.CastKind, j.TagUsed, j.CC, j.Qualifiers} {
+		if kind != "" {
+			set("Kind", kind)
+			break
+		}
+	}
+	if len(j.Value) > 0 {
+		var s string
+		if json.Unmarshal(j.Value, &s) != nil {
+			s = string(j.Value)
+		}
+		set("Value", s)
+	}
+
+	switch n := n.(type) {
+	case *FieldDecl:
+		if j.IsBitfield {
+			p.bitfields[j.ID] = true
+		}
+
+	case *DeclRefExpr:
+		n.Type1 = ""
+		if j.Type != nil {
+			n.Type1 = j.Type.DesugaredQualType
+		}
+		if d := j.ReferencedDecl; d != nil {
+			n.For = strings.TrimSuffix(d.Kind, "Decl")
+			n.Address2 = d.ID
+			n.Name = d.Name
+			if d.Type != nil {
+				n.Type2, n.Type3 = d.Type.QualType, d.Type.DesugaredQualType
+			}
+		}
+
+	case *MemberExpr:
+		n.IsPointer = j.IsArrow
+		n.Address2 = j.ReferencedMemberDecl
+		n.IsBitfield = p.bitfields[j.ReferencedMemberDecl]
+
+	case *UnaryOperator:
+		n.IsPrefix = !j.IsPostfix
+		n.IsCannotOverflow = j.CanOverflow != nil && !*j.CanOverflow
+
+	case *UnaryExprOrTypeTraitExpr:
+		n.Function = j.Name
+		if j.ArgType != nil {
+			n.Type2, n.Type3 = j.ArgType.QualType, j.ArgType.DesugaredQualType
+		} else {
+			n.Type2 = ""
+		}
+
+	case *CompoundAssignOperator:
+		if t := j.ComputeLHSType; t != nil {
+			n.ComputationLHSType, n.ComputationLHSType2 = t.QualType, t.DesugaredQualType
+		}
+		if t := j.ComputeResultType; t != nil {
+			n.ComputationResultType, n.ComputationResultType2 = t.QualType, t.DesugaredQualType
+		}
+
+	case *StringLiteral:
+		value := n.Value
+		for _, prefix := range []string{"L", "u8", "u", "U"} {
+			if strings.HasPrefix(value, prefix+`"`) {
+				n.Runes = prefix == "L"
+				value = value[len(prefix):]
+				break
+			}
+		}
+		n.Value = unquote(value)
+		n.IsLvalue = true
+
+	case *CharacterLiteral:
+		n.Value = util.Atoi(string(j.Value))
+
+	case *FloatingLiteral:
+		var s string
+		if json.Unmarshal(j.Value, &s) != nil {
+			s = string(j.Value)
+		}
+		n.Value = atof(s)
+
+	case *LabelStmt:
+		p.labels[j.DeclID] = j.Name
+
+	case *GotoStmt:
+		n.Position2 = j.TargetLabelDeclID
+		p.gotos = append(p.gotos, n)
+	}
+	return n, nil
+}
+
+// position returns position of range. Last file and line are changed in
+// the same order as in JSON dump.
+func (p *jsonParser) position(begin, end *jsonLocation) (pos Position) {
+	var ok bool
+	pos.File, pos.Line, pos.Column, ok = p.location(begin)
+	if !ok {
+		return Position{}
+	}
+	if end != nil {
+		var file string
+		file, pos.LineEnd, pos.ColumnEnd, _ = p.location(end)
+		if file != pos.File {
+			pos.LineEnd, pos.ColumnEnd = 0, 0
+		}
+	}
+	return
+}
+
+// location returns position of location in original file. For macro
+// expansion the expansion location is used.
+func (p *jsonParser) location(l *jsonLocation) (file string, line, col int, ok bool) {
+	if l == nil {
+		return
+	}
+	if l.SpellingLoc != nil || l.ExpansionLoc != nil {
+		p.location(l.SpellingLoc)
+		return p.location(l.ExpansionLoc)
+	}
+	if l.File != "" {
+		p.file = l.File
+	}
+	if l.Line != 0 {
+		p.line = l.Line
+	}
+	if l.Col == 0 {
+		// invalid location
+		return
+	}
+	file, line = p.presumed(p.file, p.line)
+	return file, line, l.Col, true
+}
+
+// presumed returns file and line in original file by line markers of
+// preprocessed source.
+func (p *jsonParser) presumed(file string, line int) (string, int) {
+	if len(p.markers) == 0 || strings.HasPrefix(file, "<") {
+		return file, line
+	}
+	index := sort.Search(len(p.markers), func(i int) bool {
+		return p.markers[i].line >= line
+	}) - 1
+	if index < 0 {
+		return file, line
+	}
+	m := p.markers[index]
+	return m.file, m.next + line - m.line - 1
+}
+
+// parseLineMarkers returns line markers of preprocessed source.
+func parseLineMarkers(source string) (markers []lineMarker) {
+	re := util.GetRegex(`^#(?:line)? (\d+) (".*?[^\\]")`)
+	for i, line := range strings.Split(source, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		groups := re.FindStringSubmatch(line)
+		if len(groups) == 0 {
+			continue
+		}
+		file, err := strconv.Unquote(groups[2])
+		if err != nil {
+			file = groups[2][1 : len(groups[2])-1]
+		}
+		markers = append(markers, lineMarker{
+			line: i + 1,
+			file: file,
+			next: util.Atoi(groups[1]),
+		})
+	}
+	return
+}
+
+// MarshalTree returns AST tree in JSON format. Each node is object with
+// name of node type in field "NodeType", exported fields of node and
+// children of node in field "Children".
+func MarshalTree(node Node) ([]byte, error) {
+	return json.MarshalIndent(marshalNode(node), "", "\t")
+}
+
+func marshalNode(node Node) interface{} {
+	if node == nil {
+		return nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(node))
+	m := map[string]interface{}{"NodeType": v.Type().Name()}
+	marshalFields(m, v)
+	if len(node.Children()) > 0 {
+		children := make([]interface{}, len(node.Children()))
+		for i, c := range node.Children() {
+			children[i] = marshalNode(c)
+		}
+		m["Children"] = children
+	}
+	return m
+}
+
+func marshalFields(m map[string]interface{}, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f, fv := v.Type().Field(i), v.Field(i)
+		if f.PkgPath != "" || f.Name == "ChildNodes" {
+			continue
+		}
+		if f.Anonymous {
+			// fields of embedded node, for example CallExpr of
+			// CXXMemberCallExpr
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				marshalFields(m, fv)
+				continue
+			}
+		}
+		m[f.Name] = fv.Interface()
+	}
+}
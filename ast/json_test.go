@@ -0,0 +1,163 @@
+package ast
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/preprocessor"
+)
+
+func TestParseJSON(t *testing.T) {
+	data := `{"id":"0x1","kind":"TranslationUnitDecl","loc":{},"range":{"begin":{},"end":{}},"inner":[
+{"id":"0x2","kind":"TypedefDecl","loc":{"file":"a.c","line":1,"col":22},
+ "range":{"begin":{"col":1},"end":{"col":22}},"name":"P","type":{"qualType":"struct p"},"inner":[
+ {"id":"0x3","kind":"ElaboratedType","type":{"qualType":"struct p"},"inner":[
+  {"id":"0x4","kind":"RecordType","type":{"qualType":"struct p"},"decl":{"id":"0x5","kind":"RecordDecl","name":"p"}}]}]},
+{"id":"0x6","kind":"FunctionDecl","loc":{"line":2,"col":13},
+ "range":{"begin":{"col":1},"end":{"line":6,"col":1}},"name":"f","type":{"qualType":"void (void)"},
+ "storageClass":"static","inline":true,"inner":[
+ {"id":"0x7","kind":"CompoundStmt","range":{"begin":{"line":2,"col":22},"end":{"line":6,"col":1}},"inner":[
+  {"id":"0x8","kind":"GotoStmt","range":{"begin":{"line":3,"col":3},"end":{"col":8}},"targetLabelDeclId":"0x9"},
+  {"id":"0xa","kind":"LabelStmt","range":{"begin":{"line":4,"col":1},"end":{"line":5,"col":14}},"declId":"0x9","name":"end","inner":[
+   {"id":"0xb","kind":"CallExpr","range":{"begin":{"spellingLoc":{"file":"b.h","line":1,"col":13},"expansionLoc":{"file":"a.c","line":5,"col":3}},
+    "end":{"spellingLoc":{"file":"b.h","line":1,"col":20},"expansionLoc":{"file":"a.c","line":5,"col":13}}},
+    "type":{"qualType":"int"},"valueCategory":"prvalue","inner":[
+    {"id":"0xc","kind":"StringLiteral","range":{"begin":{"col":5},"end":{"col":5}},
+     "type":{"qualType":"int [3]"},"valueCategory":"lvalue","value":"L\"a\\n\""},
+    {"id":"0xd","kind":"CharacterLiteral","range":{"begin":{"col":9},"end":{"col":9}},
+     "type":{"qualType":"int"},"valueCategory":"prvalue","value":97},
+    {"id":"0xe","kind":"FloatingLiteral","range":{"begin":{"col":11},"end":{"col":11}},
+     "type":{"qualType":"double"},"valueCategory":"prvalue","value":"1.5"},
+    {"id":"0xf","kind":"UnaryExprOrTypeTraitExpr","range":{"begin":{"col":13},"end":{"col":13}},
+     "type":{"qualType":"unsigned long"},"valueCategory":"prvalue","name":"sizeof","argType":{"qualType":"P","desugaredQualType":"struct p"}}]}]}]}]}]}`
+
+	nodes, errs := ParseJSON([]byte(data), preprocessor.FilePP{})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	var types []string
+	depths := map[string]int{}
+	get := map[string]Node{}
+	for _, n := range nodes {
+		name := reflect.TypeOf(n.Node).Elem().Name()
+		types = append(types, name)
+		depths[name] = n.Depth
+		get[name] = n.Node
+	}
+	expected := []string{"TranslationUnitDecl", "TypedefDecl", "ElaboratedType",
+		"RecordType", "Record", "FunctionDecl", "CompoundStmt", "GotoStmt",
+		"LabelStmt", "CallExpr", "StringLiteral", "CharacterLiteral",
+		"FloatingLiteral", "UnaryExprOrTypeTraitExpr"}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("not valid nodes: %v", types)
+	}
+	if depths["Record"] != 4 || depths["StringLiteral"] != 5 {
+		t.Errorf("not valid depths: %v", depths)
+	}
+
+	if r := get["Record"].(*Record); r.Type != "p" || r.Addr != 5 {
+		t.Errorf("not valid record: %#v", r)
+	}
+	f := get["FunctionDecl"].(*FunctionDecl)
+	if f.Name != "f" || !f.IsStatic || !f.IsInline || f.Type != "void (void)" ||
+		f.Pos.File != "a.c" || f.Pos.Line != 2 || f.Pos.LineEnd != 6 {
+		t.Errorf("not valid function: %#v", f)
+	}
+	if g := get["GotoStmt"].(*GotoStmt); g.Name != "end" || g.Pos.Line != 3 {
+		t.Errorf("not valid goto: %#v", g)
+	}
+	// position of macro expansion
+	if c := get["CallExpr"].(*CallExpr); c.Pos.File != "a.c" || c.Pos.Line != 5 ||
+		c.Pos.Column != 3 || c.Pos.ColumnEnd != 13 {
+		t.Errorf("not valid position of macro: %#v", c.Pos)
+	}
+	if s := get["StringLiteral"].(*StringLiteral); s.Value != "a\n" || !s.Runes {
+		t.Errorf("not valid string literal: %#v", s)
+	}
+	if c := get["CharacterLiteral"].(*CharacterLiteral); c.Value != 97 {
+		t.Errorf("not valid character literal: %#v", c)
+	}
+	if fl := get["FloatingLiteral"].(*FloatingLiteral); fl.Value != 1.5 {
+		t.Errorf("not valid floating literal: %#v", fl)
+	}
+	if u := get["UnaryExprOrTypeTraitExpr"].(*UnaryExprOrTypeTraitExpr); u.Function != "sizeof" ||
+		u.Type1 != "unsigned long" || u.Type2 != "P" || u.Type3 != "struct p" {
+		t.Errorf("not valid sizeof: %#v", u)
+	}
+
+	if _, errs = ParseJSON([]byte("{"), preprocessor.FilePP{}); len(errs) != 1 {
+		t.Errorf("not valid JSON is parsed")
+	}
+}
+
+func TestJSONLineMarkers(t *testing.T) {
+	p := jsonParser{markers: parseLineMarkers(`# 1 "main.c"
+int a;
+# 1 "/usr/include/head.h" 1 3 4
+int b;
+
+int c;
+# 3 "main.c" 2
+int d;`)}
+	tcs := []struct {
+		line    int
+		file    string
+		presume int
+	}{
+		{2, "main.c", 1},
+		{4, "/usr/include/head.h", 1},
+		{6, "/usr/include/head.h", 3},
+		{8, "main.c", 3},
+	}
+	for _, tc := range tcs {
+		file, line := p.presumed("pp.c", tc.line)
+		if file != tc.file || line != tc.presume {
+			t.Errorf("line %d: %s:%d", tc.line, file, line)
+		}
+	}
+	if file, line := p.presumed("<scratch space>", 2); file != "<scratch space>" || line != 2 {
+		t.Errorf("not valid scratch space: %s:%d", file, line)
+	}
+}
+
+func TestMarshalTree(t *testing.T) {
+	tree := walkTree(t)
+	data, err := MarshalTree(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+	if root["NodeType"] != "CompoundStmt" || root["Addr"] != float64(0x1) {
+		t.Errorf("not valid root: %v", root)
+	}
+	if _, ok := root["ChildNodes"]; ok {
+		t.Errorf("child nodes are marshaled as fields")
+	}
+	children := root["Children"].([]interface{})
+	if len(children) != 3 {
+		t.Fatalf("not valid amount of children: %d", len(children))
+	}
+	call := children[1].(map[string]interface{})
+	if call["NodeType"] != "CallExpr" || call["Type"] != "void" {
+		t.Errorf("not valid call: %v", call)
+	}
+	// absent else branch of IfStmt
+	ifs := children[2].(map[string]interface{})["Children"].([]interface{})
+	if ifs[len(ifs)-1] != nil {
+		t.Errorf("not valid nil child: %v", ifs)
+	}
+
+	member := &CXXMemberCallExpr{CallExpr: &CallExpr{Type: "int"}}
+	data, err = MarshalTree(member)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"Type": "int"`) {
+		t.Errorf("fields of embedded node are not marshaled: %s", data)
+	}
+}
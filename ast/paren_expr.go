@@ -12,6 +12,12 @@ type ParenExpr struct {
 }
 
 func parseParenExpr(line string) *ParenExpr {
+	if fastParsers {
+		if n, ok := scanParenExpr(line); ok {
+			return n
+		}
+	}
+
 	groups := groupsFromRegex(
 		`<(?P<position>.*)> '(?P<type1>.*?)'(:'(?P<type2>.*)')?
 		(?P<lvalue> lvalue)?
@@ -3,6 +3,7 @@ package ast
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/Konstantin8105/c4go/util"
@@ -39,6 +40,12 @@ func NewPositionFromString(s string) Position {
 		return Position{}
 	}
 
+	if fastParsers {
+		if p, ok := parseSimplePosition(s); ok {
+			return p
+		}
+	}
+
 	re := util.GetRegex(`^col:(\d+)$`)
 	if groups := re.FindStringSubmatch(s); len(groups) > 0 {
 		return Position{
@@ -181,6 +188,94 @@ func NewPositionFromString(s string) Position {
 	panic("unable to understand position '" + s + "'")
 }
 
+// parseSimplePosition parses the most frequent positions without file
+// names, for example `col:3`, `line:2:5, col:14` or `col:3, line:4:1`,
+// without regular expressions. The result is the same as result of
+// NewPositionFromString.
+func parseSimplePosition(s string) (p Position, ok bool) {
+	begin, end := s, ""
+	if i := strings.Index(s, ", "); i >= 0 {
+		begin, end = s[:i], s[i+2:]
+	}
+	b, ok := parseSimpleLocation(begin)
+	if !ok {
+		return
+	}
+	var e simpleLocation
+	if end != "" {
+		if e, ok = parseSimpleLocation(end); !ok {
+			return
+		}
+	}
+	p.StringValue = s
+	switch {
+	case b.column && end == "":
+		p.Column = b.values[0]
+	case b.column && e.column:
+		p.Column, p.ColumnEnd = b.values[0], e.values[0]
+	case b.column && e.amount == 1:
+		p.Column, p.Line = b.values[0], e.values[0]
+	case b.column && e.amount == 2:
+		p.Column, p.LineEnd, p.ColumnEnd = b.values[0], e.values[0], e.values[1]
+	case b.column:
+		return p, false
+	case b.amount == 1 && !e.column && e.amount == 1:
+		p.Line, p.LineEnd = b.values[0], e.values[0]
+	case b.amount == 2 && end == "":
+		p.Line, p.Column = b.values[0], b.values[1]
+	case b.amount == 2 && e.column:
+		p.Line, p.Column, p.ColumnEnd = b.values[0], b.values[1], e.values[0]
+	case b.amount == 2 && e.amount == 2:
+		p.Line, p.Column = b.values[0], b.values[1]
+		p.LineEnd, p.ColumnEnd = e.values[0], e.values[1]
+	default:
+		return Position{}, false
+	}
+	return p, true
+}
+
+// simpleLocation is location `col:C`, `line:L` or `line:L:C`.
+type simpleLocation struct {
+	column bool
+	amount int // amount of values
+	values [2]int
+}
+
+func parseSimpleLocation(s string) (l simpleLocation, ok bool) {
+	switch {
+	case strings.HasPrefix(s, "col:"):
+		l.column = true
+		s = s[len("col:"):]
+	case strings.HasPrefix(s, "line:"):
+		s = s[len("line:"):]
+	default:
+		return
+	}
+	for {
+		i := 0
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		if i == 0 || l.amount == len(l.values) {
+			return l, false
+		}
+		v, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return l, false
+		}
+		l.values[l.amount] = v
+		l.amount++
+		if s = s[i:]; s == "" {
+			break
+		}
+		if s[0] != ':' || l.column {
+			return l, false
+		}
+		s = s[1:]
+	}
+	return l, true
+}
+
 func mergePositions(p1, p2 Position) Position {
 	if p2.File != "" {
 		p1.File = p2.File
@@ -246,6 +341,8 @@ func setPosition(node Node, position Position) {
 		n.Pos = position
 	case *AsmLabelAttr:
 		n.Pos = position
+	case *AtomicExpr:
+		n.Pos = position
 	case *AvailabilityAttr:
 		n.Pos = position
 	case *BuiltinAttr:
@@ -340,6 +437,8 @@ func setPosition(node Node, position Position) {
 		n.Pos = position
 	case *IfStmt:
 		n.Pos = position
+	case *ImaginaryLiteral:
+		n.Pos = position
 	case *ImplicitCastExpr:
 		n.Pos = position
 	case *ImplicitValueInitExpr:
@@ -450,7 +549,8 @@ func setPosition(node Node, position Position) {
 		*QualType, *PointerType, *ParenType, *IncompleteArrayType,
 		*FunctionProtoType, *FunctionNoProtoType, *EnumType, *Enum, *ElaboratedType,
 		*ConstantArrayType, *BuiltinType, *ArrayFiller, *Field,
-		*DecayedType, *CXXRecord, *AttributedType:
+		*DecayedType, *CXXRecord, *AttributedType, *AtomicType, *ComplexType,
+		*GenericAssociation:
 		// These do not have positions so they can be ignored.
 	default:
 		panic(fmt.Sprintf("unknown node type: %+#v", node))
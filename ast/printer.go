@@ -0,0 +1,1140 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PrintC writes C source code of AST tree into w. Tree is usually
+// TranslationUnitDecl, but any declaration, statement or expression may be
+// printed. Implicit declarations, attributes and comments are not printed.
+//
+// Nodes, that cannot be printed as C code, are printed as comments and
+// error with names of these nodes is returned after writing of all code.
+func PrintC(w io.Writer, node Node) error {
+	p := &cPrinter{buf: new(bytes.Buffer)}
+	switch n := node.(type) {
+	case *TranslationUnitDecl:
+		p.declarations(n.Children())
+	case *CompoundStmt, *DeclStmt, *IfStmt, *ForStmt, *WhileStmt, *DoStmt,
+		*SwitchStmt, *CaseStmt, *DefaultStmt, *LabelStmt, *GotoStmt,
+		*ReturnStmt, *BreakStmt, *ContinueStmt:
+		p.stmt(n)
+	default:
+		if isDeclaration(n) {
+			p.declarations([]Node{n})
+		} else {
+			p.line(p.exprString(n))
+		}
+	}
+	if _, err := w.Write(p.buf.Bytes()); err != nil {
+		return err
+	}
+	if len(p.unsupported) > 0 {
+		return fmt.Errorf("cannot print nodes as C code: %s",
+			strings.Join(p.unsupported, ", "))
+	}
+	return nil
+}
+
+// cPrinter is printer of C code.
+type cPrinter struct {
+	buf    *bytes.Buffer
+	indent int
+	join   bool // next line continues the last line
+
+	// anonymous records of declarations, that are not printed yet
+	anonymous [][]*anonymousRecord
+
+	unsupported []string
+}
+
+// anonymousRecord is definition of struct, union or enum without name.
+// Definition is printed instead of type of next declaration.
+type anonymousRecord struct {
+	kind string // struct, union or enum
+	key  string // position of record: file:line:column
+	body string // definition of record
+}
+
+// anonymousType is type of record without name, for example:
+// `struct (anonymous struct at /tmp/a.c:1:9)`,
+// `union (unnamed union at a.c:3:5)`.
+var anonymousType = regexp.MustCompile(
+	`\b(struct|union|enum) \((?:anonymous|unnamed)[^()]*?(?: at (.*?):(\d+):(\d+))?\)`)
+
+// Precedence of C operators
+const (
+	precComma = iota + 1
+	precAssign
+	precConditional
+	precOr
+	precAnd
+	precBitOr
+	precBitXor
+	precBitAnd
+	precEquality
+	precRelational
+	precShift
+	precAdditive
+	precMultiplicative
+	precCast
+	precUnary
+	precPostfix
+)
+
+var binaryPrecedence = map[string]int{
+	",":  precComma,
+	"=":  precAssign,
+	"||": precOr,
+	"&&": precAnd,
+	"|":  precBitOr,
+	"^":  precBitXor,
+	"&":  precBitAnd,
+	"==": precEquality, "!=": precEquality,
+	"<": precRelational, "<=": precRelational,
+	">": precRelational, ">=": precRelational,
+	"<<": precShift, ">>": precShift,
+	"+": precAdditive, "-": precAdditive,
+	"*": precMultiplicative, "/": precMultiplicative, "%": precMultiplicative,
+}
+
+func (p *cPrinter) line(s string) {
+	if p.join {
+		p.join = false
+		p.buf.Truncate(p.buf.Len() - 1)
+		p.buf.WriteString(" ")
+	} else {
+		p.buf.WriteString(strings.Repeat("\t", p.indent))
+	}
+	p.buf.WriteString(s)
+	p.buf.WriteString("\n")
+}
+
+// capture returns code printed by function f with zero indent.
+func (p *cPrinter) capture(f func()) string {
+	buf, indent := p.buf, p.indent
+	p.buf, p.indent = new(bytes.Buffer), 0
+	f()
+	s := strings.TrimSuffix(p.buf.String(), "\n")
+	p.buf, p.indent = buf, indent
+	return s
+}
+
+// unsupportedNode returns comment instead of node.
+func (p *cPrinter) unsupportedNode(node Node) string {
+	name := "nil"
+	if node != nil {
+		name = reflect.TypeOf(node).Elem().Name()
+	}
+	p.unsupported = append(p.unsupported, name)
+	return fmt.Sprintf("/* %s */", name)
+}
+
+// isMeta returns true for attributes and comments.
+func isMeta(node Node) bool {
+	if node == nil {
+		return false
+	}
+	name := reflect.TypeOf(node).Elem().Name()
+	return strings.HasSuffix(name, "Attr") || strings.HasSuffix(name, "Comment")
+}
+
+// children returns children of node without attributes and comments.
+func children(node Node) (cs []Node) {
+	for _, c := range node.Children() {
+		if !isMeta(c) {
+			cs = append(cs, c)
+		}
+	}
+	return
+}
+
+func isDeclaration(node Node) bool {
+	switch node.(type) {
+	case *VarDecl, *FunctionDecl, *FieldDecl, *RecordDecl, *CXXRecordDecl,
+		*EnumDecl, *TypedefDecl, *StaticAssertDecl:
+		return true
+	}
+	return false
+}
+
+// declarations prints declarations in the same scope.
+func (p *cPrinter) declarations(nodes []Node) {
+	p.anonymous = append(p.anonymous, nil)
+	defer func() {
+		p.anonymous = p.anonymous[:len(p.anonymous)-1]
+	}()
+
+	// anonymous record of previous declaration
+	var prev *anonymousRecord
+
+	for i, node := range nodes {
+		if isMeta(node) {
+			continue
+		}
+		last := prev
+		prev = nil
+
+		switch n := node.(type) {
+		case nil, *EmptyDecl, *IndirectFieldDecl:
+			// ignored
+
+		case *RecordDecl:
+			prev = p.record(n, nodes[i+1:])
+
+		case *CXXRecordDecl:
+			prev = p.record(n.RecordDecl, nodes[i+1:])
+
+		case *EnumDecl:
+			prev = p.enum(n, nodes[i+1:])
+
+		case *TypedefDecl:
+			if n.IsImplicit {
+				continue
+			}
+			t := n.Type
+			if a := last; a != nil {
+				// clang names anonymous record by name of typedef
+				if named := a.kind + " " + n.Name; t == named ||
+					strings.HasPrefix(t, named+" ") {
+					t = a.kind + " (anonymous)" + t[len(named):]
+				}
+			}
+			p.line("typedef " + p.declarator(t, n.Name) + ";")
+
+		case *VarDecl:
+			p.line(p.varDecl(n) + ";")
+
+		case *FunctionDecl:
+			if !n.IsImplicit {
+				p.function(n)
+			}
+
+		case *FieldDecl:
+			name := n.Name
+			if n.IsImplicit {
+				// anonymous struct or union
+				name = ""
+			}
+			s := p.declarator(n.Type, name)
+			if cs := children(n); len(cs) > 0 {
+				s += " : " + p.exprString(cs[0])
+			}
+			p.line(s + ";")
+
+		case *StaticAssertDecl:
+			var args []string
+			for _, c := range children(n) {
+				args = append(args, p.operand(c, precAssign))
+			}
+			p.line("_Static_assert(" + strings.Join(args, ", ") + ");")
+
+		default:
+			p.line(p.unsupportedNode(n))
+		}
+	}
+}
+
+// isAnonymousUsed returns true, if next declaration has type of anonymous
+// record with kind.
+func isAnonymousUsed(kind string, next []Node) bool {
+	var t, name string
+loop:
+	for _, n := range next {
+		switch n := n.(type) {
+		case *VarDecl:
+			t = n.Type
+		case *FieldDecl:
+			t = n.Type
+		case *TypedefDecl:
+			t, name = n.Type, n.Name
+		default:
+			if n == nil || isMeta(n) {
+				continue
+			}
+		}
+		break loop
+	}
+	if t == "" {
+		return false
+	}
+	for _, groups := range anonymousType.FindAllStringSubmatch(t, -1) {
+		if groups[1] == kind {
+			return true
+		}
+	}
+	named := kind + " " + name
+	return name != "" && (t == named || strings.HasPrefix(t, named+" "))
+}
+
+// addAnonymous adds anonymous record, if next declaration uses it.
+// Otherwise record is printed.
+func (p *cPrinter) addAnonymous(kind string, pos Position, body string,
+	next []Node) *anonymousRecord {
+	if !isAnonymousUsed(kind, next) {
+		p.line(body + ";")
+		return nil
+	}
+	a := &anonymousRecord{
+		kind: kind,
+		key:  fmt.Sprintf("%s:%d:%d", filepath.Base(pos.File), pos.Line, pos.Column),
+		body: body,
+	}
+	last := len(p.anonymous) - 1
+	p.anonymous[last] = append(p.anonymous[last], a)
+	return a
+}
+
+// findAnonymous returns definition of anonymous record by type.
+func (p *cPrinter) findAnonymous(groups []string) string {
+	var key string
+	if groups[2] != "" {
+		key = filepath.Base(groups[2]) + ":" + groups[3] + ":" + groups[4]
+	}
+	for i := len(p.anonymous) - 1; i >= 0; i-- {
+		for _, a := range p.anonymous[i] {
+			if a.kind == groups[1] && a.key == key {
+				return a.body
+			}
+		}
+	}
+	// position of record is not same
+	for i := len(p.anonymous) - 1; i >= 0; i-- {
+		for j := len(p.anonymous[i]) - 1; j >= 0; j-- {
+			if a := p.anonymous[i][j]; a.kind == groups[1] {
+				return a.body
+			}
+		}
+	}
+	return ""
+}
+
+func (p *cPrinter) record(n *RecordDecl, next []Node) *anonymousRecord {
+	if n.IsImplicit {
+		return nil
+	}
+	kind := n.Kind
+	if kind == "" {
+		kind = "struct"
+	}
+	name := kind
+	if n.Name != "" {
+		name += " " + n.Name
+	}
+	if !n.IsDefinition {
+		if n.Name != "" {
+			p.line(name + ";")
+		}
+		return nil
+	}
+	body := p.capture(func() {
+		p.line(name + " {")
+		p.indent++
+		p.declarations(n.Children())
+		p.indent--
+		p.line("}")
+	})
+	if n.Name == "" {
+		return p.addAnonymous(kind, n.Pos, body, next)
+	}
+	p.line(body + ";")
+	return nil
+}
+
+func (p *cPrinter) enum(n *EnumDecl, next []Node) *anonymousRecord {
+	name := "enum"
+	if n.Name != "" {
+		name += " " + n.Name
+	}
+	var constants []*EnumConstantDecl
+	for _, c := range n.Children() {
+		if c, ok := c.(*EnumConstantDecl); ok {
+			constants = append(constants, c)
+		}
+	}
+	if len(constants) == 0 {
+		if n.Name != "" {
+			p.line(name + ";")
+		}
+		return nil
+	}
+	body := p.capture(func() {
+		p.line(name + " {")
+		p.indent++
+		for _, c := range constants {
+			s := c.Name
+			if cs := children(c); len(cs) > 0 {
+				s += " = " + p.operand(cs[0], precAssign)
+			}
+			p.line(s + ",")
+		}
+		p.indent--
+		p.line("}")
+	})
+	if n.Name == "" {
+		return p.addAnonymous("enum", n.Pos, body, next)
+	}
+	p.line(body + ";")
+	return nil
+}
+
+// varDecl returns declaration of variable without semicolon.
+func (p *cPrinter) varDecl(n *VarDecl) string {
+	var s string
+	switch {
+	case n.IsExtern:
+		s += "extern "
+	case n.IsStatic:
+		s += "static "
+	case n.IsRegister:
+		s += "register "
+	}
+	if n.IsTls {
+		s += "_Thread_local "
+	}
+	s += p.declarator(n.Type, n.Name)
+	if cs := children(n); len(cs) > 0 && cs[len(cs)-1] != nil {
+		s += " = " + p.operand(cs[len(cs)-1], precAssign)
+	}
+	return s
+}
+
+func (p *cPrinter) function(n *FunctionDecl) {
+	var (
+		params []string
+		body   *CompoundStmt
+	)
+	for _, c := range n.Children() {
+		switch c := c.(type) {
+		case *ParmVarDecl:
+			params = append(params, p.declarator(c.Type, c.Name))
+		case *CompoundStmt:
+			body = c
+		}
+	}
+
+	var s string
+	switch {
+	case n.IsStatic:
+		s += "static "
+	case n.IsExtern:
+		s += "extern "
+	}
+	if n.IsInline {
+		s += "inline "
+	}
+	s += p.functionDeclarator(n.Type, n.Name, params)
+	if body == nil {
+		p.line(s + ";")
+		return
+	}
+	p.line("")
+	p.line(s + " {")
+	p.indent++
+	for _, c := range body.Children() {
+		p.stmt(c)
+	}
+	p.indent--
+	p.line("}")
+	p.line("")
+}
+
+// functionDeclarator returns declarator of function with names of
+// parameters.
+func (p *cPrinter) functionDeclarator(t, name string, params []string) string {
+	d := p.declarator(t, name)
+	pos := strings.Index(d, name+"(")
+	if pos < 0 || len(params) == 0 {
+		return d
+	}
+	begin := pos + len(name)
+	end := matchParen(d, begin)
+	if end < 0 {
+		return d
+	}
+	list := strings.Join(params, ", ")
+	if strings.HasSuffix(d[begin+1:end], "...") {
+		list += ", ..."
+	}
+	return d[:begin+1] + list + d[end:]
+}
+
+// matchParen returns index of parenthesis closing parenthesis at index
+// begin.
+func matchParen(s string, begin int) int {
+	level := 0
+	for i := begin; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			level++
+		case ')':
+			level--
+			if level == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// declarator returns declaration of name with type. Definitions of
+// anonymous records are printed instead of type of anonymous records.
+func (p *cPrinter) declarator(t, name string) string {
+	var bodies []string
+	t = anonymousType.ReplaceAllStringFunc(t, func(s string) string {
+		body := p.findAnonymous(anonymousType.FindStringSubmatch(s))
+		if body == "" {
+			p.unsupported = append(p.unsupported, "type "+s)
+			return s
+		}
+		bodies = append(bodies, body)
+		return fmt.Sprintf("c4go_anonymous_%d", len(bodies)-1)
+	})
+	d := Declarator(t, name)
+	for i, body := range bodies {
+		body = strings.Replace(body, "\n", "\n"+strings.Repeat("\t", p.indent), -1)
+		d = strings.Replace(d, fmt.Sprintf("c4go_anonymous_%d", i), body, 1)
+	}
+	return d
+}
+
+// Declarator returns C declaration of name with clang type, for example:
+//
+//	Declarator("int *", "p")          // int *p
+//	Declarator("int [10]", "a")       // int a[10]
+//	Declarator("int (*)(int)", "f")   // int (*f)(int)
+//	Declarator("char *(*[3])(void)", "fs") // char *(*fs[3])(void)
+//
+// If name is empty, then type is returned.
+func Declarator(t, name string) string {
+	t = strings.TrimSpace(t)
+	if name == "" {
+		return t
+	}
+	pos := declaratorPosition(t)
+	before := strings.TrimRight(t[:pos], " ")
+	if before != "" && !strings.HasSuffix(before, "*") && !strings.HasSuffix(before, "(") {
+		before += " "
+	}
+	return before + name + t[pos:]
+}
+
+// declaratorPosition returns position of name in type.
+func declaratorPosition(t string) int {
+	// pointer to function or array: `int (*)(int)`, `int (*[3])[4]`
+	for i := 0; i < len(t); i++ {
+		if t[i] != '(' {
+			continue
+		}
+		j := i + 1
+		if j >= len(t) || (t[j] != '*' && t[j] != '^') {
+			continue
+		}
+		for j < len(t) {
+			rest := t[j:]
+			switch {
+			case rest[0] == '*' || rest[0] == '^' || rest[0] == ' ':
+				j++
+				continue
+			}
+			found := false
+			for _, q := range []string{"const", "volatile", "restrict", "__restrict"} {
+				if strings.HasPrefix(rest, q) {
+					j += len(q)
+					found = true
+					break
+				}
+			}
+			if !found {
+				break
+			}
+		}
+		if j >= len(t) {
+			continue
+		}
+		switch t[j] {
+		case ')', '[':
+			return j
+		case '(':
+			// parameters of function returning pointer
+			if k := j + 1; k < len(t) && t[k] != '*' && t[k] != '^' {
+				return j
+			}
+		}
+	}
+	// function or array: `int (int)`, `int *[3]`
+	if i := strings.IndexAny(t, "(["); i >= 0 {
+		return i
+	}
+	return len(t)
+}
+
+// stmt prints statement.
+func (p *cPrinter) stmt(node Node) {
+	switch n := node.(type) {
+	case nil:
+		p.line(";")
+
+	case *CompoundStmt:
+		p.line("{")
+		p.indent++
+		for _, c := range n.Children() {
+			p.stmt(c)
+		}
+		p.indent--
+		p.line("}")
+
+	case *DeclStmt:
+		p.declarations(n.Children())
+
+	case *IfStmt:
+		p.ifStmt(n, "")
+
+	case *ForStmt:
+		cs := n.Children()
+		if len(cs) != 5 {
+			p.line(p.unsupportedNode(n))
+			return
+		}
+		header := "for (" + p.forInit(cs[0]) + ";"
+		if cs[2] != nil {
+			header += " " + p.exprString(cs[2])
+		}
+		header += ";"
+		if cs[3] != nil {
+			header += " " + p.exprString(cs[3])
+		}
+		p.body(header+")", cs[4], "")
+
+	case *WhileStmt:
+		cs := n.Children()
+		if len(cs) < 2 {
+			p.line(p.unsupportedNode(n))
+			return
+		}
+		p.body("while ("+p.exprString(cs[len(cs)-2])+")", cs[len(cs)-1], "")
+
+	case *DoStmt:
+		cs := n.Children()
+		if len(cs) != 2 {
+			p.line(p.unsupportedNode(n))
+			return
+		}
+		p.body("do", cs[0], " while ("+p.exprString(cs[1])+");")
+
+	case *SwitchStmt:
+		cs := n.Children()
+		if len(cs) < 2 {
+			p.line(p.unsupportedNode(n))
+			return
+		}
+		p.body("switch ("+p.exprString(cs[len(cs)-2])+")", cs[len(cs)-1], "")
+
+	case *CaseStmt:
+		cs := n.Children()
+		if len(cs) < 2 {
+			p.line(p.unsupportedNode(n))
+			return
+		}
+		label := "case " + p.exprString(cs[0])
+		if len(cs) == 3 && cs[1] != nil {
+			// GNU extension: case 1 ... 5:
+			label += " ... " + p.exprString(cs[1])
+		}
+		p.line(label + ":")
+		p.stmt(cs[len(cs)-1])
+
+	case *DefaultStmt:
+		p.line("default:")
+		if cs := n.Children(); len(cs) > 0 {
+			p.stmt(cs[len(cs)-1])
+		} else {
+			p.line(";")
+		}
+
+	case *LabelStmt:
+		p.line(n.Name + ":")
+		if cs := n.Children(); len(cs) > 0 {
+			p.stmt(cs[len(cs)-1])
+		} else {
+			p.line(";")
+		}
+
+	case *GotoStmt:
+		p.line("goto " + n.Name + ";")
+
+	case *BreakStmt:
+		p.line("break;")
+
+	case *ContinueStmt:
+		p.line("continue;")
+
+	case *ReturnStmt:
+		if cs := children(n); len(cs) > 0 && cs[0] != nil {
+			p.line("return " + p.exprString(cs[0]) + ";")
+		} else {
+			p.line("return;")
+		}
+
+	case *GCCAsmStmt:
+		p.line(p.unsupportedNode(n))
+
+	default:
+		if isMeta(n) {
+			return
+		}
+		p.line(p.exprString(n) + ";")
+	}
+}
+
+// body prints control statement with header and body. Body is always
+// printed in braces for avoid problem of dangling else.
+func (p *cPrinter) body(header string, body Node, footer string) {
+	p.line(header + " {")
+	p.indent++
+	if c, ok := body.(*CompoundStmt); ok {
+		for _, s := range c.Children() {
+			p.stmt(s)
+		}
+	} else if body != nil {
+		p.stmt(body)
+	}
+	p.indent--
+	p.line("}" + footer)
+}
+
+func (p *cPrinter) ifStmt(n *IfStmt, prefix string) {
+	// children of IfStmt are different in versions of clang:
+	// [<<<NULL>>>,] [<<<NULL>>>,] condition, body [, else body]
+	cs := n.Children()
+	for len(cs) > 0 && cs[0] == nil {
+		cs = cs[1:]
+	}
+	if len(cs) < 2 {
+		p.line(p.unsupportedNode(n))
+		return
+	}
+	p.body(prefix+"if ("+p.exprString(cs[0])+")", cs[1], "")
+	if len(cs) < 3 || cs[2] == nil {
+		return
+	}
+	p.join = true
+	if elseIf, ok := cs[2].(*IfStmt); ok {
+		p.ifStmt(elseIf, "else ")
+		return
+	}
+	p.body("else", cs[2], "")
+}
+
+// forInit returns initialization of for statement.
+func (p *cPrinter) forInit(node Node) string {
+	decl, ok := node.(*DeclStmt)
+	if !ok {
+		if node == nil {
+			return ""
+		}
+		return p.exprString(node)
+	}
+	var (
+		s    string
+		base string
+	)
+	for _, c := range decl.Children() {
+		v, ok := c.(*VarDecl)
+		if !ok {
+			return p.unsupportedNode(c)
+		}
+		d := p.varDecl(v)
+		if s == "" {
+			s = d
+			t := strings.TrimSpace(v.Type)
+			base = strings.TrimRight(t[:declaratorPosition(t)], " *(")
+			continue
+		}
+		s += ", " + strings.TrimPrefix(d, base+" ")
+	}
+	return s
+}
+
+// exprString returns expression without parentheses.
+func (p *cPrinter) exprString(node Node) string {
+	s, _ := p.expr(node)
+	return s
+}
+
+// operand returns expression in parentheses, if precedence of expression
+// is less than prec.
+func (p *cPrinter) operand(node Node, prec int) string {
+	s, pr := p.expr(node)
+	if pr < prec {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// expr returns expression and precedence of expression.
+func (p *cPrinter) expr(node Node) (string, int) {
+	switch n := node.(type) {
+	case *ImplicitCastExpr, *ConstantExpr, *OpaqueValueExpr:
+		if cs := children(n); len(cs) > 0 {
+			return p.expr(cs[0])
+		}
+
+	case *ParenExpr:
+		if cs := children(n); len(cs) > 0 {
+			return "(" + p.exprString(cs[0]) + ")", precPostfix
+		}
+
+	case *DeclRefExpr:
+		return n.Name, precPostfix
+
+	case *PredefinedExpr:
+		return n.Name, precPostfix
+
+	case *IntegerLiteral:
+		return n.Value + integerSuffix(n.Type), precPostfix
+
+	case *FloatingLiteral:
+		return floatingLiteral(n), precPostfix
+
+	case *CharacterLiteral:
+		return characterLiteral(n), precPostfix
+
+	case *StringLiteral:
+		return stringLiteral(n), precPostfix
+
+	case *ImaginaryLiteral:
+		if cs := children(n); len(cs) > 0 {
+			return p.operand(cs[0], precPostfix) + "i", precPostfix
+		}
+
+	case *UnaryOperator:
+		cs := children(n)
+		if len(cs) == 0 {
+			break
+		}
+		if !n.IsPrefix {
+			return p.operand(cs[0], precPostfix) + n.Operator, precPostfix
+		}
+		switch op := n.Operator; op {
+		case "__extension__", "__real", "__imag", "real", "imag":
+			if !strings.HasPrefix(op, "__") {
+				op = "__" + op
+			}
+			return op + " " + p.operand(cs[0], precUnary), precUnary
+		default:
+			x := p.operand(cs[0], precUnary)
+			if (op == "-" || op == "+" || op == "&") && strings.HasPrefix(x, op[:1]) {
+				x = " " + x
+			}
+			return op + x, precUnary
+		}
+
+	case *BinaryOperator:
+		if cs := children(n); len(cs) == 2 {
+			return p.binary(n.Operator, cs[0], cs[1])
+		}
+
+	case *CompoundAssignOperator:
+		if cs := children(n); len(cs) == 2 {
+			return p.binary(n.Opcode, cs[0], cs[1])
+		}
+
+	case *ConditionalOperator:
+		if cs := children(n); len(cs) == 3 {
+			return p.operand(cs[0], precOr) + " ? " + p.exprString(cs[1]) +
+				" : " + p.operand(cs[2], precConditional), precConditional
+		}
+
+	case *BinaryConditionalOperator:
+		// children: common, opaque value, condition, true, false
+		if cs := children(n); len(cs) > 1 {
+			return p.operand(cs[0], precOr) + " ?: " +
+				p.operand(cs[len(cs)-1], precConditional), precConditional
+		}
+
+	case *CStyleCastExpr:
+		if cs := children(n); len(cs) > 0 {
+			return "(" + p.declarator(n.Type, "") + ")" + p.operand(cs[0], precCast), precCast
+		}
+
+	case *CallExpr:
+		cs := children(n)
+		if len(cs) == 0 {
+			break
+		}
+		var args []string
+		for _, a := range cs[1:] {
+			args = append(args, p.operand(a, precAssign))
+		}
+		return p.operand(cs[0], precPostfix) + "(" + strings.Join(args, ", ") + ")", precPostfix
+
+	case *ArraySubscriptExpr:
+		if cs := children(n); len(cs) == 2 {
+			return p.operand(cs[0], precPostfix) + "[" + p.exprString(cs[1]) + "]", precPostfix
+		}
+
+	case *MemberExpr:
+		return p.member(n)
+
+	case *UnaryExprOrTypeTraitExpr:
+		f := n.Function
+		if f == "alignof" {
+			f = "_Alignof"
+		}
+		if n.Type2 != "" {
+			return f + "(" + p.declarator(n.Type2, "") + ")", precUnary
+		}
+		if cs := children(n); len(cs) > 0 {
+			return f + "(" + p.exprString(cs[0]) + ")", precUnary
+		}
+
+	case *InitListExpr:
+		var elements []string
+		for _, c := range children(n) {
+			if _, ok := c.(*ArrayFiller); ok {
+				continue
+			}
+			elements = append(elements, p.operand(c, precAssign))
+		}
+		if len(elements) == 0 {
+			return "{0}", precPostfix
+		}
+		return "{" + strings.Join(elements, ", ") + "}", precPostfix
+
+	case *ImplicitValueInitExpr:
+		t := n.Type1
+		if n.Type2 != "" {
+			t = n.Type2
+		}
+		if strings.HasPrefix(t, "struct ") || strings.HasPrefix(t, "union ") ||
+			strings.HasSuffix(t, "]") {
+			return "{0}", precPostfix
+		}
+		return "0", precPostfix
+
+	case *CompoundLiteralExpr:
+		if cs := children(n); len(cs) > 0 {
+			return "(" + p.declarator(n.Type1, "") + ")" + p.exprString(cs[0]), precPostfix
+		}
+
+	case *StmtExpr:
+		if cs := children(n); len(cs) > 0 {
+			indent := p.indent
+			block := p.capture(func() {
+				p.stmt(cs[0])
+			})
+			block = strings.Replace(block, "\n", "\n"+strings.Repeat("\t", indent), -1)
+			return "(" + block + ")", precPostfix
+		}
+
+	case *VAArgExpr:
+		if cs := children(n); len(cs) > 0 {
+			return "__builtin_va_arg(" + p.exprString(cs[0]) + ", " +
+				p.declarator(n.Type, "") + ")", precPostfix
+		}
+
+	case *AtomicExpr:
+		var args []string
+		for _, a := range children(n) {
+			args = append(args, p.operand(a, precAssign))
+		}
+		return n.Name + "(" + strings.Join(args, ", ") + ")", precPostfix
+
+	case *GenericSelectionExpr:
+		var args []string
+		for _, c := range children(n) {
+			a, ok := c.(*GenericAssociation)
+			if !ok {
+				if len(args) == 0 {
+					args = append(args, p.operand(c, precAssign))
+				}
+				continue
+			}
+			cs := children(a)
+			if len(cs) == 0 {
+				return p.unsupportedNode(a), precPostfix
+			}
+			t := "default"
+			if !a.IsDefault {
+				t = p.declarator(a.Type, "")
+			}
+			args = append(args, t+": "+p.operand(cs[len(cs)-1], precAssign))
+		}
+		return "_Generic(" + strings.Join(args, ", ") + ")", precPostfix
+	}
+	return p.unsupportedNode(node), precPostfix
+}
+
+func (p *cPrinter) binary(op string, left, right Node) (string, int) {
+	prec, ok := binaryPrecedence[op]
+	if !ok && strings.HasSuffix(op, "=") {
+		// compound assignment
+		prec = precAssign
+	}
+	var l, r string
+	if prec == precAssign {
+		// right-to-left associativity
+		l, r = p.operand(left, prec+1), p.operand(right, prec)
+	} else {
+		l, r = p.operand(left, prec), p.operand(right, prec+1)
+	}
+	if op == "," {
+		return l + ", " + r, prec
+	}
+	return l + " " + op + " " + r, prec
+}
+
+func (p *cPrinter) member(n *MemberExpr) (string, int) {
+	cs := children(n)
+	if len(cs) == 0 {
+		return p.unsupportedNode(n), precPostfix
+	}
+	op := "."
+	if n.IsPointer {
+		op = "->"
+	}
+	base := cs[0]
+	for {
+		// member of anonymous struct or union is accessed directly
+		m, ok := base.(*MemberExpr)
+		for !ok {
+			c, cast := base.(*ImplicitCastExpr)
+			if !cast || len(children(c)) == 0 {
+				break
+			}
+			base = children(c)[0]
+			m, ok = base.(*MemberExpr)
+		}
+		if !ok || m.Name != "" || len(children(m)) == 0 {
+			break
+		}
+		if m.IsPointer {
+			op = "->"
+		}
+		base = children(m)[0]
+	}
+	return p.operand(base, precPostfix) + op + n.Name, precPostfix
+}
+
+func integerSuffix(t string) string {
+	switch t {
+	case "unsigned int":
+		return "U"
+	case "long":
+		return "L"
+	case "unsigned long":
+		return "UL"
+	case "long long":
+		return "LL"
+	case "unsigned long long":
+		return "ULL"
+	}
+	return ""
+}
+
+func floatingLiteral(n *FloatingLiteral) string {
+	switch {
+	case math.IsInf(n.Value, 1):
+		return "__builtin_inf()"
+	case math.IsNaN(n.Value):
+		return "__builtin_nan(\"\")"
+	}
+	s := strconv.FormatFloat(n.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	switch n.Type {
+	case "float":
+		s += "F"
+	case "long double":
+		s += "L"
+	}
+	return s
+}
+
+// escapeC returns C escape sequence of character or empty string, if
+// character has no special escape sequence.
+func escapeC(r rune, quote rune) string {
+	switch r {
+	case '\n':
+		return `\n`
+	case '\t':
+		return `\t`
+	case '\r':
+		return `\r`
+	case '\a':
+		return `\a`
+	case '\b':
+		return `\b`
+	case '\f':
+		return `\f`
+	case '\v':
+		return `\v`
+	case '\\':
+		return `\\`
+	case quote:
+		return `\` + string(quote)
+	}
+	if 0x20 <= r && r < 0x7f {
+		return string(r)
+	}
+	if r < 0x100 {
+		return fmt.Sprintf("\\%03o", r)
+	}
+	if r <= 0xffff {
+		return fmt.Sprintf("\\u%04x", r)
+	}
+	return fmt.Sprintf("\\U%08x", r)
+}
+
+func characterLiteral(n *CharacterLiteral) string {
+	var prefix string
+	switch n.Type {
+	case "wchar_t":
+		prefix = "L"
+	case "unsigned short", "char16_t":
+		prefix = "u"
+	case "unsigned int", "char32_t":
+		prefix = "U"
+	case "int":
+		if n.Value > 0xff {
+			prefix = "L"
+		}
+	}
+	return prefix + "'" + escapeC(rune(n.Value), '\'') + "'"
+}
+
+func stringLiteral(n *StringLiteral) string {
+	var buf bytes.Buffer
+	if n.Runes {
+		buf.WriteString("L")
+	}
+	buf.WriteString(`"`)
+	var last rune
+	for i := 0; i < len(n.Value); {
+		r, size := rune(n.Value[i]), 1
+		if n.Runes {
+			r, size = utf8.DecodeRuneInString(n.Value[i:])
+		}
+		i += size
+		if r == '?' && last == '?' {
+			// avoid trigraphs
+			buf.WriteString(`\?`)
+		} else {
+			buf.WriteString(escapeC(r, '"'))
+		}
+		last = r
+	}
+	buf.WriteString(`"`)
+	return buf.String()
+}
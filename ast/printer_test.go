@@ -0,0 +1,308 @@
+package ast
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// treeFromDump returns tree of clang AST dump.
+func treeFromDump(t *testing.T, dump string) Node {
+	var stack []Node
+	for _, line := range strings.Split(strings.TrimSpace(dump), "\n") {
+		index := strings.IndexFunc(line, func(r rune) bool {
+			return !strings.ContainsRune(" |`-", r)
+		})
+		depth := index / 2
+		var node Node
+		if !strings.HasPrefix(line[index:], "<<<NULL>>>") {
+			var err error
+			if node, err = Parse(line[index:]); err != nil {
+				t.Fatalf("cannot parse line `%s`: %v", line, err)
+			}
+		}
+		stack = append(stack[:depth], node)
+		if depth > 0 {
+			stack[depth-1].AddChild(node)
+		}
+	}
+	return stack[0]
+}
+
+func TestPrintC(t *testing.T) {
+	// clang AST of code:
+	//
+	//	typedef struct { int x; union { int i; float f; }; } P;
+	//	enum color { RED, GREEN = 5 };
+	//	static int (*handler)(int);
+	//	int sum(int *a, int n);
+	//	int main(void) {
+	//		P p = {1, {2}};
+	//		int a[3] = {1, 2};
+	//		char *s = "a\"b\n";
+	//		int r = 0;
+	//		for (int i = 0, *j = &r; i < 3; i++) r += a[i] * (*j + 1);
+	//		while (r > 100) r /= 2;
+	//		do { r--; } while (r > 50);
+	//		switch (r) { case 1: r = 2; break; default: ; }
+	//		if (r == 0) goto end; else if (r < 0) r = -r; else r = r > 10 ? 10 : (int)sizeof(P);
+	//		p.i = 'c';
+	//	end:
+	//		return sum(a, 3) + p.x + GREEN;
+	//	}
+	dump := `
+TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>
+|-TypedefDecl 0x2 <<invalid sloc>> <invalid sloc> implicit __int128_t '__int128'
+|-RecordDecl 0x10 <a.c:1:9, col:53> col:9 struct definition
+| |-FieldDecl 0x11 <col:18, col:22> col:22 referenced x 'int'
+| |-RecordDecl 0x12 <col:25, col:49> col:25 union definition
+| | |-FieldDecl 0x13 <col:33, col:37> col:37 referenced i 'int'
+| | ` + "`" + `-FieldDecl 0x14 <col:40, col:46> col:46 f 'float'
+| |-FieldDecl 0x15 <col:25> col:25 implicit referenced 'union (anonymous union at a.c:1:25)':'union (anonymous union at a.c:1:25)'
+| |-IndirectFieldDecl 0x16 <col:37> col:37 implicit i 'int'
+| ` + "`" + `-IndirectFieldDecl 0x17 <col:46> col:46 implicit f 'float'
+|-TypedefDecl 0x18 <col:1, col:55> col:55 referenced P 'struct P':'P'
+|-EnumDecl 0x20 <line:2:1, col:29> col:6 color
+| |-EnumConstantDecl 0x21 <col:14> col:14 RED 'int'
+| ` + "`" + `-EnumConstantDecl 0x22 <col:19, col:27> col:19 referenced GREEN 'int'
+|   ` + "`" + `-IntegerLiteral 0x23 <col:27> 'int' 5
+|-VarDecl 0x30 <line:3:1, col:26> col:14 handler 'int (*)(int)' static
+|-FunctionDecl 0x40 <line:4:1, col:22> col:5 used sum 'int (int *, int)'
+| |-ParmVarDecl 0x41 <col:9, col:14> col:14 a 'int *'
+| ` + "`" + `-ParmVarDecl 0x42 <col:17, col:21> col:21 n 'int'
+` + "`" + `-FunctionDecl 0x50 <line:5:1, line:18:1> line:5:5 main 'int (void)'
+  ` + "`" + `-CompoundStmt 0x51 <col:16, line:18:1>
+    |-DeclStmt 0x52 <line:6:2, col:16>
+    | ` + "`" + `-VarDecl 0x53 <col:2, col:15> col:4 used p 'P':'P' cinit
+    |   ` + "`" + `-InitListExpr 0x54 <col:8, col:15> 'P':'P'
+    |     |-IntegerLiteral 0x55 <col:9> 'int' 1
+    |     ` + "`" + `-InitListExpr 0x56 <col:12, col:14> 'union (anonymous union at a.c:1:25)':'union (anonymous union at a.c:1:25)'
+    |       ` + "`" + `-IntegerLiteral 0x57 <col:13> 'int' 2
+    |-DeclStmt 0x58 <line:7:2, col:19>
+    | ` + "`" + `-VarDecl 0x59 <col:2, col:18> col:6 used a 'int [3]' cinit
+    |   ` + "`" + `-InitListExpr 0x5a <col:13, col:18> 'int [3]'
+    |     |-array_filler: ImplicitValueInitExpr 0x5b <<invalid sloc>> 'int'
+    |     |-IntegerLiteral 0x5c <col:14> 'int' 1
+    |     ` + "`" + `-IntegerLiteral 0x5d <col:17> 'int' 2
+    |-DeclStmt 0x60 <line:8:2, col:19>
+    | ` + "`" + `-VarDecl 0x61 <col:2, col:12> col:8 s 'char *' cinit
+    |   ` + "`" + `-ImplicitCastExpr 0x62 <col:12> 'char *' <ArrayToPointerDecay>
+    |     ` + "`" + `-StringLiteral 0x63 <col:12> 'char [5]' lvalue "a\"b\n"
+    |-DeclStmt 0x64 <line:9:2, col:11>
+    | ` + "`" + `-VarDecl 0x65 <col:2, col:10> col:6 used r 'int' cinit
+    |   ` + "`" + `-IntegerLiteral 0x66 <col:10> 'int' 0
+    |-ForStmt 0x70 <line:10:2, col:58>
+    | |-DeclStmt 0x71 <col:7, col:26>
+    | | |-VarDecl 0x72 <col:7, col:15> col:11 used i 'int' cinit
+    | | | ` + "`" + `-IntegerLiteral 0x73 <col:15> 'int' 0
+    | | ` + "`" + `-VarDecl 0x74 <col:7, col:24> col:19 used j 'int *' cinit
+    | |   ` + "`" + `-UnaryOperator 0x75 <col:23, col:24> 'int *' prefix '&' cannot overflow
+    | |     ` + "`" + `-DeclRefExpr 0x76 <col:24> 'int' lvalue Var 0x65 'r' 'int'
+    | |-<<<NULL>>>
+    | |-BinaryOperator 0x77 <col:27, col:31> 'int' '<'
+    | | |-ImplicitCastExpr 0x78 <col:27> 'int' <LValueToRValue>
+    | | | ` + "`" + `-DeclRefExpr 0x79 <col:27> 'int' lvalue Var 0x72 'i' 'int'
+    | | ` + "`" + `-IntegerLiteral 0x7a <col:31> 'int' 3
+    | |-UnaryOperator 0x7b <col:34, col:35> 'int' postfix '++'
+    | | ` + "`" + `-DeclRefExpr 0x7c <col:34> 'int' lvalue Var 0x72 'i' 'int'
+    | ` + "`" + `-CompoundAssignOperator 0x7d <col:39, col:58> 'int' '+=' ComputeLHSTy='int' ComputeResultTy='int'
+    |   |-DeclRefExpr 0x7e <col:39> 'int' lvalue Var 0x65 'r' 'int'
+    |   ` + "`" + `-BinaryOperator 0x7f <col:44, col:58> 'int' '*'
+    |     |-ImplicitCastExpr 0x80 <col:44, col:47> 'int' <LValueToRValue>
+    |     | ` + "`" + `-ArraySubscriptExpr 0x81 <col:44, col:47> 'int' lvalue
+    |     |   |-ImplicitCastExpr 0x82 <col:44> 'int *' <ArrayToPointerDecay>
+    |     |   | ` + "`" + `-DeclRefExpr 0x83 <col:44> 'int [3]' lvalue Var 0x59 'a' 'int [3]'
+    |     |   ` + "`" + `-ImplicitCastExpr 0x84 <col:46> 'int' <LValueToRValue>
+    |     |     ` + "`" + `-DeclRefExpr 0x85 <col:46> 'int' lvalue Var 0x72 'i' 'int'
+    |     ` + "`" + `-BinaryOperator 0x86 <col:52, col:57> 'int' '+'
+    |       |-ImplicitCastExpr 0x87 <col:52, col:53> 'int' <LValueToRValue>
+    |       | ` + "`" + `-UnaryOperator 0x88 <col:52, col:53> 'int' lvalue prefix '*' cannot overflow
+    |       |   ` + "`" + `-ImplicitCastExpr 0x89 <col:53> 'int *' <LValueToRValue>
+    |       |     ` + "`" + `-DeclRefExpr 0x8a <col:53> 'int *' lvalue Var 0x74 'j' 'int *'
+    |       ` + "`" + `-IntegerLiteral 0x8b <col:57> 'int' 1
+    |-WhileStmt 0x90 <line:11:2, col:25>
+    | |-<<<NULL>>>
+    | |-BinaryOperator 0x91 <col:9, col:13> 'int' '>'
+    | | |-ImplicitCastExpr 0x92 <col:9> 'int' <LValueToRValue>
+    | | | ` + "`" + `-DeclRefExpr 0x93 <col:9> 'int' lvalue Var 0x65 'r' 'int'
+    | | ` + "`" + `-IntegerLiteral 0x94 <col:13> 'int' 100
+    | ` + "`" + `-CompoundAssignOperator 0x95 <col:18, col:24> 'int' '/=' ComputeLHSTy='int' ComputeResultTy='int'
+    |   |-DeclRefExpr 0x96 <col:18> 'int' lvalue Var 0x65 'r' 'int'
+    |   ` + "`" + `-IntegerLiteral 0x97 <col:24> 'int' 2
+    |-DoStmt 0xa0 <line:12:2, col:27>
+    | |-CompoundStmt 0xa1 <col:5, col:12>
+    | | ` + "`" + `-UnaryOperator 0xa2 <col:7, col:8> 'int' postfix '--'
+    | |   ` + "`" + `-DeclRefExpr 0xa3 <col:7> 'int' lvalue Var 0x65 'r' 'int'
+    | ` + "`" + `-BinaryOperator 0xa4 <col:21, col:25> 'int' '>'
+    |   |-ImplicitCastExpr 0xa5 <col:21> 'int' <LValueToRValue>
+    |   | ` + "`" + `-DeclRefExpr 0xa6 <col:21> 'int' lvalue Var 0x65 'r' 'int'
+    |   ` + "`" + `-IntegerLiteral 0xa7 <col:25> 'int' 50
+    |-SwitchStmt 0xb0 <line:13:2, col:47>
+    | |-<<<NULL>>>
+    | |-ImplicitCastExpr 0xb1 <col:10> 'int' <LValueToRValue>
+    | | ` + "`" + `-DeclRefExpr 0xb2 <col:10> 'int' lvalue Var 0x65 'r' 'int'
+    | ` + "`" + `-CompoundStmt 0xb3 <col:13, col:47>
+    |   |-CaseStmt 0xb4 <col:15, col:27>
+    |   | |-IntegerLiteral 0xb5 <col:20> 'int' 1
+    |   | |-<<<NULL>>>
+    |   | ` + "`" + `-BinaryOperator 0xb6 <col:23, col:27> 'int' '='
+    |   |   |-DeclRefExpr 0xb7 <col:23> 'int' lvalue Var 0x65 'r' 'int'
+    |   |   ` + "`" + `-IntegerLiteral 0xb8 <col:27> 'int' 2
+    |   |-BreakStmt 0xb9 <col:30>
+    |   ` + "`" + `-DefaultStmt 0xba <col:37, col:46>
+    |     ` + "`" + `-NullStmt 0xbb <col:46>
+    |-IfStmt 0xc0 <line:14:2, col:83> has_else
+    | |-BinaryOperator 0xc1 <col:6, col:11> 'int' '=='
+    | | |-ImplicitCastExpr 0xc2 <col:6> 'int' <LValueToRValue>
+    | | | ` + "`" + `-DeclRefExpr 0xc3 <col:6> 'int' lvalue Var 0x65 'r' 'int'
+    | | ` + "`" + `-IntegerLiteral 0xc4 <col:11> 'int' 0
+    | |-GotoStmt 0xc5 <col:14, col:19> 'end' 0xff
+    | ` + "`" + `-IfStmt 0xc6 <col:27, col:83> has_else
+    |   |-BinaryOperator 0xc7 <col:31, col:35> 'int' '<'
+    |   | |-ImplicitCastExpr 0xc8 <col:31> 'int' <LValueToRValue>
+    |   | | ` + "`" + `-DeclRefExpr 0xc9 <col:31> 'int' lvalue Var 0x65 'r' 'int'
+    |   | ` + "`" + `-IntegerLiteral 0xca <col:35> 'int' 0
+    |   |-BinaryOperator 0xcb <col:38, col:43> 'int' '='
+    |   | |-DeclRefExpr 0xcc <col:38> 'int' lvalue Var 0x65 'r' 'int'
+    |   | ` + "`" + `-UnaryOperator 0xcd <col:42, col:43> 'int' prefix '-'
+    |   |   ` + "`" + `-ImplicitCastExpr 0xce <col:43> 'int' <LValueToRValue>
+    |   |     ` + "`" + `-DeclRefExpr 0xcf <col:43> 'int' lvalue Var 0x65 'r' 'int'
+    |   ` + "`" + `-BinaryOperator 0xd0 <col:51, col:82> 'int' '='
+    |     |-DeclRefExpr 0xd1 <col:51> 'int' lvalue Var 0x65 'r' 'int'
+    |     ` + "`" + `-ConditionalOperator 0xd2 <col:55, col:82> 'int'
+    |       |-BinaryOperator 0xd3 <col:55, col:59> 'int' '>'
+    |       | |-ImplicitCastExpr 0xd4 <col:55> 'int' <LValueToRValue>
+    |       | | ` + "`" + `-DeclRefExpr 0xd5 <col:55> 'int' lvalue Var 0x65 'r' 'int'
+    |       | ` + "`" + `-IntegerLiteral 0xd6 <col:59> 'int' 10
+    |       |-IntegerLiteral 0xd7 <col:64> 'int' 10
+    |       ` + "`" + `-CStyleCastExpr 0xd8 <col:69, col:82> 'int' <IntegralCast>
+    |         ` + "`" + `-UnaryExprOrTypeTraitExpr 0xd9 <col:74, col:82> 'unsigned long' sizeof 'P':'P'
+    |-BinaryOperator 0xe0 <line:15:2, col:8> 'int' '='
+    | |-MemberExpr 0xe1 <col:2, col:4> 'int' lvalue .i 0x13
+    | | ` + "`" + `-MemberExpr 0xe2 <col:2, col:4> 'union (anonymous union at a.c:1:25)':'union (anonymous union at a.c:1:25)' lvalue . 0x15
+    | |   ` + "`" + `-DeclRefExpr 0xe3 <col:2> 'P':'P' lvalue Var 0x53 'p' 'P':'P'
+    | ` + "`" + `-CharacterLiteral 0xe4 <col:8> 'int' 99
+    ` + "`" + `-LabelStmt 0xff <line:16:1, line:17:32> 'end'
+      ` + "`" + `-ReturnStmt 0xf0 <line:17:2, col:32>
+        ` + "`" + `-BinaryOperator 0xf1 <col:9, col:32> 'int' '+'
+          |-BinaryOperator 0xf2 <col:9, col:24> 'int' '+'
+          | |-CallExpr 0xf3 <col:9, col:17> 'int'
+          | | |-ImplicitCastExpr 0xf4 <col:9> 'int (*)(int *, int)' <FunctionToPointerDecay>
+          | | | ` + "`" + `-DeclRefExpr 0xf5 <col:9> 'int (int *, int)' Function 0x40 'sum' 'int (int *, int)'
+          | | |-ImplicitCastExpr 0xf6 <col:13> 'int *' <ArrayToPointerDecay>
+          | | | ` + "`" + `-DeclRefExpr 0xf7 <col:13> 'int [3]' lvalue Var 0x59 'a' 'int [3]'
+          | | ` + "`" + `-IntegerLiteral 0xf8 <col:16> 'int' 3
+          | ` + "`" + `-ImplicitCastExpr 0xf9 <col:21, col:24> 'int' <LValueToRValue>
+          |   ` + "`" + `-MemberExpr 0xfa <col:21, col:24> 'int' lvalue .x 0x11
+          |     ` + "`" + `-DeclRefExpr 0xfb <col:21> 'P':'P' lvalue Var 0x53 'p' 'P':'P'
+          ` + "`" + `-DeclRefExpr 0xfc <col:28> 'int' EnumConstant 0x22 'GREEN' 'int'
+`
+	tree := treeFromDump(t, dump)
+
+	var buf bytes.Buffer
+	if err := PrintC(&buf, tree); err != nil {
+		t.Fatal(err)
+	}
+	expected := `typedef struct {
+	int x;
+	union {
+		int i;
+		float f;
+	};
+} P;
+enum color {
+	RED,
+	GREEN = 5,
+};
+static int (*handler)(int);
+int sum(int *a, int n);
+
+int main(void) {
+	P p = {1, {2}};
+	int a[3] = {1, 2};
+	char *s = "a\"b\n";
+	int r = 0;
+	for (int i = 0, *j = &r; i < 3; i++) {
+		r += a[i] * (*j + 1);
+	}
+	while (r > 100) {
+		r /= 2;
+	}
+	do {
+		r--;
+	} while (r > 50);
+	switch (r) {
+		case 1:
+		r = 2;
+		break;
+		default:
+		;
+	}
+	if (r == 0) {
+		goto end;
+	} else if (r < 0) {
+		r = -r;
+	} else {
+		r = r > 10 ? 10 : (int)sizeof(P);
+	}
+	p.i = 'c';
+	end:
+	return sum(a, 3) + p.x + GREEN;
+}
+
+`
+	if buf.String() != expected {
+		t.Errorf("not valid C code:\n%s", buf.String())
+	}
+
+	// printed code is valid C code
+	if _, err := exec.LookPath("gcc"); err == nil {
+		cmd := exec.Command("gcc", "-fsyntax-only", "-Werror", "-x", "c", "-")
+		cmd.Stdin = &buf
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("cannot compile C code: %v\n%s", err, out)
+		}
+	}
+}
+
+func TestPrintCUnsupported(t *testing.T) {
+	tree := treeFromDump(t, `
+CompoundStmt 0x1 <a.c:1:1, col:10>
+`+"`"+`-GCCAsmStmt 0x2 <col:3, col:8>
+`)
+	var buf bytes.Buffer
+	if err := PrintC(&buf, tree); err == nil || !strings.Contains(err.Error(), "GCCAsmStmt") {
+		t.Errorf("not valid error: %v", err)
+	}
+	if buf.String() != "{\n\t/* GCCAsmStmt */\n}\n" {
+		t.Errorf("not valid C code:\n%s", buf.String())
+	}
+}
+
+func TestDeclarator(t *testing.T) {
+	tcs := []struct {
+		t, name, d string
+	}{
+		{"int", "a", "int a"},
+		{"char *", "s", "char *s"},
+		{"const char *const", "s", "const char *const s"},
+		{"int [10]", "a", "int a[10]"},
+		{"int *[3]", "a", "int *a[3]"},
+		{"int (*)[3]", "a", "int (*a)[3]"},
+		{"int (int, char *)", "f", "int f(int, char *)"},
+		{"int *(void)", "f", "int *f(void)"},
+		{"int (*)(int)", "f", "int (*f)(int)"},
+		{"int (**)(int)", "f", "int (**f)(int)"},
+		{"int (*const)(int)", "f", "int (*const f)(int)"},
+		{"int (*[3])(int)", "fs", "int (*fs[3])(int)"},
+		{"void (*(*)(int))(int)", "f", "void (*(*f)(int))(int)"},
+		{"int (*(int))(double)", "f", "int (*f(int))(double)"},
+		{"int", "", "int"},
+	}
+	for _, tc := range tcs {
+		if d := Declarator(tc.t, tc.name); d != tc.d {
+			t.Errorf("not valid declarator of `%s`: `%s` != `%s`", tc.t, d, tc.d)
+		}
+	}
+}
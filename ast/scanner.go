@@ -0,0 +1,285 @@
+package ast
+
+import (
+	"strings"
+)
+
+// lineScanner is tokenizer of line of clang AST. It is used by fast
+// parsers of the most frequent nodes instead of regular expressions.
+//
+// Fast parsers choose the same alternatives as regular expressions of
+// nodes in the same order, but without backtracking. Any unexpected part of
+// line marks scanner as failed and then the line is parsed by regular
+// expression, so results of both parsers are always the same.
+type lineScanner struct {
+	line   string
+	failed bool
+}
+
+// fastParsers switches on fast parsers of nodes. It is switched off only in
+// tests and benchmarks for comparing with regular expressions.
+var fastParsers = true
+
+// whitespaces is the same as `\s` of regular expressions.
+const whitespaces = "\t\n\f\r "
+
+func (s *lineScanner) fail() {
+	s.failed = true
+	s.line = ""
+}
+
+// address returns address of node `[0-9a-fx]+` with the next space.
+func (s *lineScanner) address() Address {
+	addr := s.hex()
+	s.expect(" ")
+	return ParseAddress(addr)
+}
+
+// position returns position of node between `<` and the last `> '`,
+// because position of node is greedy in regular expressions.
+func (s *lineScanner) position() string {
+	i := strings.LastIndex(s.line, "> '")
+	if s.failed || i < 1 || s.line[0] != '<' {
+		s.fail()
+		return ""
+	}
+	pos := s.line[1:i]
+	s.line = s.line[i+1:]
+	return pos
+}
+
+// prefix removes prefix p, if line starts with it.
+func (s *lineScanner) prefix(p string) bool {
+	if !strings.HasPrefix(s.line, p) {
+		return false
+	}
+	s.line = s.line[len(p):]
+	return true
+}
+
+// expect removes prefix p or marks scanner as failed.
+func (s *lineScanner) expect(p string) {
+	if !s.prefix(p) {
+		s.fail()
+	}
+}
+
+// quoted returns the shortest string in quotes `'.*?'`.
+func (s *lineScanner) quoted() string {
+	s.expect("'")
+	i := strings.IndexByte(s.line, '\'')
+	if i < 0 {
+		s.fail()
+		return ""
+	}
+	str := s.line[:i]
+	s.line = s.line[i+1:]
+	return str
+}
+
+// types returns types `'type'(:'type2')?`.
+func (s *lineScanner) types() (t, t2 string) {
+	t = s.quoted()
+	if strings.HasPrefix(s.line, ":'") {
+		s.line = s.line[1:]
+		t2 = s.quoted()
+	}
+	return
+}
+
+// span returns the longest not empty string of bytes, for which function
+// f returns true.
+func (s *lineScanner) span(f func(c byte) bool) string {
+	i := 0
+	for i < len(s.line) && f(s.line[i]) {
+		i++
+	}
+	if i == 0 {
+		s.fail()
+		return ""
+	}
+	str := s.line[:i]
+	s.line = s.line[i:]
+	return str
+}
+
+// hex returns address `[0-9a-fx]+`.
+func (s *lineScanner) hex() string {
+	return s.span(func(c byte) bool {
+		return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || c == 'x'
+	})
+}
+
+// word returns word `\w+`.
+func (s *lineScanner) word() string {
+	return s.span(func(c byte) bool {
+		return '0' <= c && c <= '9' || 'a' <= c && c <= 'z' ||
+			'A' <= c && c <= 'Z' || c == '_'
+	})
+}
+
+// digits returns number `\d+`.
+func (s *lineScanner) digits() string {
+	return s.span(func(c byte) bool {
+		return '0' <= c && c <= '9'
+	})
+}
+
+// rest returns the rest of line without whitespaces at the end.
+func (s *lineScanner) rest() string {
+	rest := strings.TrimRight(s.line, whitespaces)
+	s.line = ""
+	return rest
+}
+
+// end checks that only whitespaces are at the end of line.
+func (s *lineScanner) end() {
+	if s.rest() != "" {
+		s.fail()
+	}
+}
+
+func scanImplicitCastExpr(line string) (*ImplicitCastExpr, bool) {
+	s := lineScanner{line: line}
+	n := &ImplicitCastExpr{
+		Addr:       s.address(),
+		ChildNodes: []Node{},
+	}
+	pos := s.position()
+	s.expect(" ")
+	n.Type, n.Type2 = s.types()
+	s.expect(" <")
+	switch kind := s.rest(); {
+	case strings.HasSuffix(kind, ">"):
+		n.Kind = kind[:len(kind)-1]
+	case strings.HasSuffix(kind, "> part_of_explicit_cast"):
+		n.Kind = kind[:len(kind)-len("> part_of_explicit_cast")]
+		n.IsPartExplicitCast = true
+	default:
+		s.fail()
+	}
+	if s.failed {
+		return nil, false
+	}
+	n.Pos = NewPositionFromString(pos)
+	return n, true
+}
+
+func scanDeclRefExpr(line string) (*DeclRefExpr, bool) {
+	s := lineScanner{line: line}
+	n := &DeclRefExpr{
+		Addr:       s.address(),
+		ChildNodes: []Node{},
+	}
+	pos := s.position()
+	s.expect(" ")
+	n.Type, n.Type1 = s.types()
+	n.IsLvalue = s.prefix(" lvalue ")
+	if !n.IsLvalue {
+		s.expect(" ")
+	}
+	n.For = s.word()
+	s.expect(" ")
+	n.Address2 = s.hex()
+	s.expect(" ")
+	n.Name = s.quoted()
+	s.expect(" ")
+	n.Type2, n.Type3 = s.types()
+	if strings.HasPrefix(s.line, " ") {
+		// the shortest other part with whitespaces after it
+		if n.Other = s.rest(); n.Other == "" {
+			n.Other = " "
+		}
+	}
+	s.end()
+	if s.failed {
+		return nil, false
+	}
+	n.Pos = NewPositionFromString(pos)
+	return n, true
+}
+
+func scanIntegerLiteral(line string) (*IntegerLiteral, bool) {
+	s := lineScanner{line: line}
+	n := &IntegerLiteral{
+		Addr:       s.address(),
+		ChildNodes: []Node{},
+	}
+	pos := s.position()
+	s.expect(" ")
+	n.Type = s.quoted()
+	s.expect(" ")
+	n.Value = s.digits()
+	s.end()
+	if s.failed {
+		return nil, false
+	}
+	n.Pos = NewPositionFromString(pos)
+	return n, true
+}
+
+func scanBinaryOperator(line string) (*BinaryOperator, bool) {
+	s := lineScanner{line: line}
+	n := &BinaryOperator{
+		Addr:       s.address(),
+		ChildNodes: []Node{},
+	}
+	pos := s.position()
+	s.expect(" ")
+	n.Type, n.Type2 = s.types()
+	n.IsLvalue = s.prefix(" lvalue")
+	s.expect(" ")
+	// operator is followed by whitespaces only
+	if op := s.rest(); len(op) >= 2 && op[0] == '\'' && op[len(op)-1] == '\'' {
+		n.Operator = op[1 : len(op)-1]
+	} else {
+		s.fail()
+	}
+	if s.failed {
+		return nil, false
+	}
+	n.Pos = NewPositionFromString(pos)
+	return n, true
+}
+
+func scanCompoundStmt(line string) (*CompoundStmt, bool) {
+	s := lineScanner{line: line}
+	n := &CompoundStmt{
+		Addr:       s.address(),
+		ChildNodes: []Node{},
+	}
+	pos := s.rest()
+	if s.failed || len(pos) < 2 || pos[0] != '<' || pos[len(pos)-1] != '>' {
+		return nil, false
+	}
+	n.Pos = NewPositionFromString(pos[1 : len(pos)-1])
+	return n, true
+}
+
+func scanParenExpr(line string) (*ParenExpr, bool) {
+	s := lineScanner{line: line}
+	n := &ParenExpr{
+		Addr:       s.address(),
+		ChildNodes: []Node{},
+	}
+	pos := s.position()
+	s.expect(" ")
+	n.Type = s.quoted()
+	if s.prefix(":'") {
+		// the second type is greedy in regular expression
+		i := strings.LastIndexByte(s.line, '\'')
+		if i < 0 {
+			s.fail()
+		} else {
+			n.Type2, s.line = s.line[:i], s.line[i+1:]
+		}
+	}
+	n.IsLvalue = s.prefix(" lvalue")
+	n.IsBitfield = s.prefix(" bitfield")
+	s.end()
+	if s.failed {
+		return nil, false
+	}
+	n.Pos = NewPositionFromString(pos)
+	return n, true
+}
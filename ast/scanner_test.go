@@ -0,0 +1,158 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// scannerLines are lines of the most frequent nodes. Lines with fast is
+// false must be parsed by regular expressions.
+var scannerLines = []struct {
+	line string
+	fast bool
+}{
+	{"ImplicitCastExpr 0x7f9f5b0a1288 <col:8> 'FILE *' <LValueToRValue>", true},
+	{"ImplicitCastExpr 0x21267c8 <col:8> 'enum week1':'enum week2' <IntegralCast>", true},
+	{"ImplicitCastExpr 0x55d0624a21e8 <col:32> 'void (*)(int)' <FunctionToPointerDecay> part_of_explicit_cast", true},
+	{"ImplicitCastExpr 0x1 <<invalid sloc>> 'int' <IntegralCast>", true},
+	{"ImplicitCastExpr 0x2 </usr/include/stdio.h:10:3, line:12:1> 'int' <NoOp>  ", true},
+	{"ImplicitCastExpr 0x3 <col:8> 'int' <LValueToRValue> extra", false},
+	{"DeclRefExpr 0x7fc972064460 <col:8> 'FILE *' lvalue Var 0x7fc97204ba08 'stderr' 'FILE *'", true},
+	{"DeclRefExpr 0x1 <line:2:3, col:9> 'int (*)(int)':'int (*)(int)' Function 0x2 'f' 'int (int)'", true},
+	{"DeclRefExpr 0x2 <col:3> 'int' EnumConstant 0x3 'A' 'int'", true},
+	{"DeclRefExpr 0x3 <col:3> 'int' lvalue ParmVar 0x4 'a' 'int' non_odr_use_unevaluated", true},
+	{"DeclRefExpr 0x4 <col:3> 'S':'struct S' lvalue Var 0x5 's' 'S':'struct S' ", true},
+	{"DeclRefExpr 0x5 <col:3> 'int' lvalue refers_to_enclosing Var 0x6 'a' 'int'", false},
+	{"DeclRefExpr 0x6 <col:3> 'int' lvalue Var 0x7 'a' 'int'\tx", false},
+	{"IntegerLiteral 0x21267c8 <col:8> 'int' 42", true},
+	{"IntegerLiteral 0x1 <line:4:9, col:11> 'unsigned long' 18446744073709551615 ", true},
+	{"BinaryOperator 0x7fc972064530 <col:9, col:14> 'int' '='", true},
+	{"BinaryOperator 0x1 <col:3, col:7> 'int' '>'", true},
+	{"BinaryOperator 0x2 <line:3:3, col:17> 'unsigned int':'unsigned int' lvalue '>>='", true},
+	{"BinaryOperator 0x3 <col:3, col:7> 'int' '+' extra", false},
+	{"CompoundStmt 0x7fc972064618 <col:12, line:7:1>", true},
+	{"CompoundStmt 0x1 <a.c:1:12, line:3:1> ", true},
+	{"CompoundStmt 0x2 <<invalid sloc>>", true},
+	{"ParenExpr 0x7fc972064518 <col:9, col:16> 'int'", true},
+	{"ParenExpr 0x1 <col:9, col:16> 'struct S':'struct S' lvalue", true},
+	{"ParenExpr 0x2 <col:9, col:16> 'unsigned int' lvalue bitfield", true},
+	{"ParenExpr 0x3 <col:9, col:16> 'int' xvalue", false},
+}
+
+func parseWithoutFastParsers(line string) (Node, error) {
+	fastParsers = false
+	defer func() {
+		fastParsers = true
+	}()
+	return Parse(line)
+}
+
+func TestScanner(t *testing.T) {
+	for i, tc := range scannerLines {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			expected, expectedErr := parseWithoutFastParsers(tc.line)
+			actual, err := Parse(tc.line)
+			if !reflect.DeepEqual(expected, actual) {
+				t.Errorf("%s", util.ShowDiff(formatMultiLine(expected),
+					formatMultiLine(actual)))
+			}
+			if fmt.Sprint(err) != fmt.Sprint(expectedErr) {
+				t.Errorf("not same errors: `%v` and `%v`", err, expectedErr)
+			}
+
+			var ok bool
+			parts := strings.SplitN(tc.line, " ", 2)
+			switch parts[0] {
+			case "ImplicitCastExpr":
+				_, ok = scanImplicitCastExpr(parts[1])
+			case "DeclRefExpr":
+				_, ok = scanDeclRefExpr(parts[1])
+			case "IntegerLiteral":
+				_, ok = scanIntegerLiteral(parts[1])
+			case "BinaryOperator":
+				_, ok = scanBinaryOperator(parts[1])
+			case "CompoundStmt":
+				_, ok = scanCompoundStmt(parts[1])
+			case "ParenExpr":
+				_, ok = scanParenExpr(parts[1])
+			}
+			if ok != tc.fast {
+				t.Errorf("not valid choice of parser: %v", ok)
+			}
+		})
+	}
+}
+
+func TestParseSimplePosition(t *testing.T) {
+	positions := []string{
+		"col:3",
+		"col:3, col:14",
+		"line:2, line:5",
+		"col:3, line:4",
+		"line:2:5, line:4:1",
+		"col:3, line:4:1",
+		"line:2:5, col:14",
+		"line:2:5",
+		// positions for regular expressions
+		"a.c:2:5, col:14",
+		"line:2:5, a.c:3:1",
+		"col:3, a.c:3:1",
+	}
+	for _, s := range positions {
+		fastParsers = false
+		expected := NewPositionFromString(s)
+		fastParsers = true
+		p, ok := parseSimplePosition(s)
+		if ok && p != expected {
+			t.Errorf("not same positions for `%s`: %#v", s, p)
+		}
+		if ok == strings.Contains(s, "a.c") {
+			t.Errorf("not valid choice of parser for `%s`: %v", s, ok)
+		}
+	}
+	for _, s := range []string{"line:2", "line:2, col:3", "col:3:4", "col:", "line:1:2:3"} {
+		if _, ok := parseSimplePosition(s); ok {
+			t.Errorf("position `%s` is parsed", s)
+		}
+	}
+}
+
+// Example of run benchmark:
+//
+// go test -run=Benchmark -bench=Parse -benchmem ./ast
+func BenchmarkParse(b *testing.B) {
+	var lines []string
+	for _, tc := range scannerLines {
+		if tc.fast {
+			lines = append(lines, tc.line)
+		}
+	}
+	b.Run("Regexp", func(b *testing.B) {
+		fastParsers = false
+		defer func() {
+			fastParsers = true
+		}()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, line := range lines {
+				if _, err := Parse(line); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("Fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, line := range lines {
+				if _, err := Parse(line); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
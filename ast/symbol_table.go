@@ -0,0 +1,253 @@
+package ast
+
+// SymbolTable is index of declarations of AST by address. Table links
+// references (DeclRefExpr, MemberExpr) with declarations (VarDecl,
+// FunctionDecl, FieldDecl, ...) and contains lexical scopes of
+// declarations.
+//
+// Table is not updated after modification of AST, so table must be created
+// again after adding or removing nodes.
+type SymbolTable struct {
+	decls      map[Address]Node    // declaration by address
+	canonical  map[Address]Address // first declaration of redeclarations
+	redecls    map[Address][]Node  // redeclarations by first declaration
+	references map[Address][]Node  // references by first declaration
+	scopes     map[Node]*Scope     // innermost scope of each node
+	order      map[Node]int        // position of node in source order
+	root       *Scope
+}
+
+// Scope is lexical scope of C code. Scopes are created for translation
+// unit, function, compound statement, selection and iteration statements
+// and fields of record.
+type Scope struct {
+	// Node is node of scope, for example: *TranslationUnitDecl,
+	// *FunctionDecl, *CompoundStmt, *ForStmt, *IfStmt, *RecordDecl.
+	Node Node
+
+	// Parent is enclosing scope. Parent is nil for root scope.
+	Parent *Scope
+
+	// Children are nested scopes in order of source.
+	Children []*Scope
+
+	// Decls are declarations of scope in order of source.
+	Decls []Node
+
+	order map[Node]int // position of node in source order
+}
+
+// Lookup returns declaration of name visible at node: the last declaration
+// before node in scope or in enclosing scopes. Declaration is visible in
+// own initializer, as in C. If node is nil, all declarations of scopes are
+// used. Names of struct, union and enum tags are not ordinary identifiers
+// and are ignored. If declaration is not found, nil is returned.
+func (s *Scope) Lookup(name string, at Node) Node {
+	pos, ok := s.order[at]
+	if !ok {
+		// after all declarations
+		pos = len(s.order)
+	}
+	for ; s != nil; s = s.Parent {
+		for i := len(s.Decls) - 1; i >= 0; i-- {
+			switch s.Decls[i].(type) {
+			case *RecordDecl, *CXXRecordDecl, *EnumDecl:
+				continue
+			}
+			if s.order[s.Decls[i]] > pos {
+				continue
+			}
+			if n, ok := DeclarationName(s.Decls[i]); ok && n == name {
+				return s.Decls[i]
+			}
+		}
+	}
+	return nil
+}
+
+// NewSymbolTable returns symbol table of all nodes of tree with root node.
+func NewSymbolTable(root Node) *SymbolTable {
+	t := &SymbolTable{
+		decls:      map[Address]Node{},
+		canonical:  map[Address]Address{},
+		redecls:    map[Address][]Node{},
+		references: map[Address][]Node{},
+		scopes:     map[Node]*Scope{},
+		order:      map[Node]int{},
+	}
+	t.root = &Scope{Node: root, order: t.order}
+
+	// declarations are indexed before references, because function may be
+	// called before definition
+	t.addDeclarations(root, t.root)
+	t.addReferences(root)
+	return t
+}
+
+func (t *SymbolTable) addDeclarations(node Node, scope *Scope) {
+	if node == nil {
+		return
+	}
+	t.scopes[node] = scope
+	t.order[node] = len(t.order)
+
+	if _, ok := DeclarationName(node); ok {
+		scope.Decls = append(scope.Decls, node)
+		if addr := node.Address(); addr != 0 {
+			t.decls[addr] = node
+			first := addr
+			if prev := previousDeclaration(node); prev != 0 {
+				if c, ok := t.canonical[prev]; ok {
+					first = c
+				}
+			}
+			t.canonical[addr] = first
+			t.redecls[first] = append(t.redecls[first], node)
+		}
+	}
+
+	if node != scope.Node && isScopeNode(node) {
+		s := &Scope{Node: node, Parent: scope, order: t.order}
+		scope.Children = append(scope.Children, s)
+		scope = s
+	}
+	for _, c := range node.Children() {
+		t.addDeclarations(c, scope)
+	}
+}
+
+func (t *SymbolTable) addReferences(node Node) {
+	if node == nil {
+		return
+	}
+	if addr, ok := referencedAddress(node); ok {
+		if first, ok := t.canonical[addr]; ok {
+			t.references[first] = append(t.references[first], node)
+		}
+	}
+	for _, c := range node.Children() {
+		t.addReferences(c)
+	}
+}
+
+// Root returns scope of root node.
+func (t *SymbolTable) Root() *Scope {
+	return t.root
+}
+
+// Lookup returns declaration with address. If declaration is not found,
+// nil is returned.
+func (t *SymbolTable) Lookup(addr Address) Node {
+	return t.decls[addr]
+}
+
+// Declaration returns declaration of reference. Reference is *DeclRefExpr
+// or *MemberExpr. If declaration is not found, nil is returned.
+func (t *SymbolTable) Declaration(ref Node) Node {
+	addr, ok := referencedAddress(ref)
+	if !ok {
+		return nil
+	}
+	return t.decls[addr]
+}
+
+// References returns all references to declaration and to other
+// declarations of the same entity, for example: to prototype and
+// definition of function. References are in order of source.
+func (t *SymbolTable) References(decl Node) []Node {
+	if decl == nil {
+		return nil
+	}
+	first, ok := t.canonical[decl.Address()]
+	if !ok {
+		return nil
+	}
+	return t.references[first]
+}
+
+// Redeclarations returns all declarations of the same entity as
+// declaration in order of source, including declaration itself.
+func (t *SymbolTable) Redeclarations(decl Node) []Node {
+	if decl == nil {
+		return nil
+	}
+	first, ok := t.canonical[decl.Address()]
+	if !ok {
+		return nil
+	}
+	return t.redecls[first]
+}
+
+// Scope returns innermost scope of node. For node of scope, for example
+// *CompoundStmt, enclosing scope is returned. If node is not in tree of
+// table, nil is returned.
+func (t *SymbolTable) Scope(node Node) *Scope {
+	return t.scopes[node]
+}
+
+// DeclarationName returns name of declaration. If node is not declaration,
+// then false is returned.
+func DeclarationName(node Node) (name string, ok bool) {
+	switch n := node.(type) {
+	case *VarDecl:
+		return n.Name, true
+	case *ParmVarDecl:
+		return n.Name, true
+	case *FunctionDecl:
+		return n.Name, true
+	case *FieldDecl:
+		return n.Name, true
+	case *IndirectFieldDecl:
+		return n.Name, true
+	case *RecordDecl:
+		return n.Name, true
+	case *CXXRecordDecl:
+		return n.Name, true
+	case *EnumDecl:
+		return n.Name, true
+	case *EnumConstantDecl:
+		return n.Name, true
+	case *TypedefDecl:
+		return n.Name, true
+	}
+	return "", false
+}
+
+// previousDeclaration returns address of previous declaration of the same
+// entity.
+func previousDeclaration(node Node) Address {
+	switch n := node.(type) {
+	case *VarDecl:
+		return n.Prev
+	case *FunctionDecl:
+		return ParseAddress(n.Prev)
+	case *RecordDecl:
+		return ParseAddress(n.Prev)
+	case *CXXRecordDecl:
+		return ParseAddress(n.Prev)
+	case *EnumDecl:
+		return ParseAddress(n.Prev)
+	}
+	return 0
+}
+
+// referencedAddress returns address of declaration of reference.
+func referencedAddress(node Node) (addr Address, ok bool) {
+	switch n := node.(type) {
+	case *DeclRefExpr:
+		addr = ParseAddress(n.Address2)
+	case *MemberExpr:
+		addr = ParseAddress(n.Address2)
+	}
+	return addr, addr != 0
+}
+
+// isScopeNode return true, if node creates lexical scope.
+func isScopeNode(node Node) bool {
+	switch node.(type) {
+	case *TranslationUnitDecl, *FunctionDecl, *CompoundStmt, *ForStmt,
+		*IfStmt, *WhileStmt, *DoStmt, *SwitchStmt, *RecordDecl, *CXXRecordDecl:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestSymbolTable(t *testing.T) {
+	// struct point { int x; };
+	// int f(int);
+	// int f(int a) {
+	//     struct point p;
+	//     for (int a = 0; a < 1; a++) { p.x = a; }
+	//     return f(a);
+	// }
+	lines := []struct {
+		depth int
+		line  string
+	}{
+		{0, "TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>"},
+		{1, "RecordDecl 0x10 <a.c:1:1, col:23> col:8 struct point definition"},
+		{2, "FieldDecl 0x11 <col:15, col:19> col:19 referenced x 'int'"},
+		{1, "FunctionDecl 0x20 <line:2:1, col:10> col:5 used f 'int (int)'"},
+		{2, "ParmVarDecl 0x21 <col:7> col:10 'int'"},
+		{1, "FunctionDecl 0x30 prev 0x20 <line:3:1, line:7:1> line:3:5 used f 'int (int)'"},
+		{2, "ParmVarDecl 0x31 <col:7, col:11> col:11 used a 'int'"},
+		{2, "CompoundStmt 0x32 <col:14, line:7:1>"},
+		{3, "DeclStmt 0x33 <line:4:5, col:20>"},
+		{4, "VarDecl 0x34 <col:5, col:18> col:18 used p 'struct point':'struct point'"},
+		{3, "ForStmt 0x40 <line:5:5, col:44>"},
+		{4, "DeclStmt 0x41 <col:10, col:19>"},
+		{5, "VarDecl 0x42 <col:10, col:18> col:14 used a 'int' cinit"},
+		{4, "CompoundStmt 0x43 <col:33, col:44>"},
+		{5, "BinaryOperator 0x44 <col:35, col:41> 'int' '='"},
+		{6, "MemberExpr 0x45 <col:35, col:37> 'int' lvalue .x 0x11"},
+		{7, "DeclRefExpr 0x46 <col:35> 'struct point':'struct point' lvalue Var 0x34 'p' 'struct point':'struct point'"},
+		{6, "DeclRefExpr 0x47 <col:41> 'int' lvalue Var 0x42 'a' 'int'"},
+		{3, "ReturnStmt 0x50 <line:6:5, col:15>"},
+		{4, "CallExpr 0x51 <col:12, col:15> 'int'"},
+		{5, "DeclRefExpr 0x52 <col:12> 'int (int)' Function 0x30 'f' 'int (int)'"},
+		{5, "DeclRefExpr 0x53 <col:14> 'int' lvalue ParmVar 0x31 'a' 'int'"},
+	}
+	var stack []Node
+	nodes := map[Address]Node{}
+	for _, l := range lines {
+		n, err := Parse(l.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stack = append(stack[:l.depth], n)
+		if l.depth > 0 {
+			stack[l.depth-1].AddChild(n)
+		}
+		nodes[n.Address()] = n
+	}
+	table := NewSymbolTable(stack[0])
+
+	t.Run("declaration", func(t *testing.T) {
+		refs := map[Address]Address{0x45: 0x11, 0x46: 0x34, 0x47: 0x42, 0x52: 0x30, 0x53: 0x31}
+		for ref, decl := range refs {
+			if d := table.Declaration(nodes[ref]); d != nodes[decl] {
+				t.Errorf("not valid declaration of %#x: %v", ref, d)
+			}
+		}
+		if d := table.Declaration(nodes[0x51]); d != nil {
+			t.Errorf("declaration of not reference: %v", d)
+		}
+		if d := table.Lookup(0x21); d != nodes[0x21] {
+			t.Errorf("not valid lookup: %v", d)
+		}
+	})
+
+	t.Run("references", func(t *testing.T) {
+		tcs := []struct {
+			decl Address
+			refs []Address
+		}{
+			{0x11, []Address{0x45}},
+			{0x20, []Address{0x52}},
+			{0x30, []Address{0x52}},
+			{0x31, []Address{0x53}},
+			{0x42, []Address{0x47}},
+			{0x21, nil},
+		}
+		for _, tc := range tcs {
+			refs := table.References(nodes[tc.decl])
+			if len(refs) != len(tc.refs) {
+				t.Errorf("not valid references of %#x: %v", tc.decl, refs)
+				continue
+			}
+			for i := range refs {
+				if refs[i] != nodes[tc.refs[i]] {
+					t.Errorf("not valid reference of %#x: %v", tc.decl, refs[i])
+				}
+			}
+		}
+		if rs := table.Redeclarations(nodes[0x30]); len(rs) != 2 ||
+			rs[0] != nodes[0x20] || rs[1] != nodes[0x30] {
+			t.Errorf("not valid redeclarations: %v", rs)
+		}
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		if s := table.Root(); s.Node != nodes[0x1] || len(s.Decls) != 3 {
+			t.Errorf("not valid root scope: %#v", s)
+		}
+		s := table.Scope(nodes[0x47])
+		if s == nil || s.Node != nodes[0x43] {
+			t.Fatalf("not valid scope of reference: %#v", s)
+		}
+		if d := s.Lookup("a", nodes[0x47]); d != nodes[0x42] {
+			t.Errorf("not valid lookup of variable in for: %v", d)
+		}
+		if d := s.Lookup("p", nodes[0x47]); d != nodes[0x34] {
+			t.Errorf("not valid lookup of variable in function: %v", d)
+		}
+		if d := s.Lookup("point", nodes[0x47]); d != nil {
+			t.Errorf("tag is found as identifier: %v", d)
+		}
+		if d := table.Scope(nodes[0x53]).Lookup("a", nodes[0x53]); d != nodes[0x31] {
+			t.Errorf("not valid lookup of parameter: %v", d)
+		}
+		if s := table.Scope(nodes[0x11]); s.Node != nodes[0x10] || s.Parent != table.Root() {
+			t.Errorf("not valid scope of field: %#v", s)
+		}
+		if s := table.Scope(nodes[0x40]); s.Node != nodes[0x32] ||
+			len(s.Children) != 1 || s.Children[0].Children[0].Node != nodes[0x43] {
+			t.Errorf("not valid scope of for statement: %#v", s)
+		}
+	})
+}
+
+func TestSymbolTableLookup(t *testing.T) {
+	// int x;
+	// void g() {
+	//     x = 1;
+	//     int x;
+	//     if (x) { int y; }
+	// }
+	lines := []struct {
+		depth int
+		line  string
+	}{
+		{0, "TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>"},
+		{1, "VarDecl 0x2 <a.c:1:1, col:5> col:5 used x 'int'"},
+		{1, "FunctionDecl 0x3 <line:2:1, line:6:1> line:2:6 g 'void ()'"},
+		{2, "CompoundStmt 0x4 <col:10, line:6:1>"},
+		{3, "BinaryOperator 0x5 <line:3:5, col:9> 'int' '='"},
+		{4, "DeclRefExpr 0x6 <col:5> 'int' lvalue Var 0x2 'x' 'int'"},
+		{4, "IntegerLiteral 0x7 <col:9> 'int' 1"},
+		{3, "DeclStmt 0x8 <line:4:5, col:10>"},
+		{4, "VarDecl 0x9 <col:5, col:9> col:9 used x 'int'"},
+		{3, "IfStmt 0xa <line:5:5, col:21>"},
+		{4, "ImplicitCastExpr 0xb <col:9> 'int' <LValueToRValue>"},
+		{5, "DeclRefExpr 0xc <col:9> 'int' lvalue Var 0x9 'x' 'int'"},
+		{4, "CompoundStmt 0xd <col:12, col:21>"},
+		{5, "DeclStmt 0xe <col:14, col:19>"},
+		{6, "VarDecl 0xf <col:14, col:18> col:18 y 'int'"},
+	}
+	var stack []Node
+	nodes := map[Address]Node{}
+	for _, l := range lines {
+		n, err := Parse(l.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stack = append(stack[:l.depth], n)
+		if l.depth > 0 {
+			stack[l.depth-1].AddChild(n)
+		}
+		nodes[n.Address()] = n
+	}
+	table := NewSymbolTable(stack[0])
+
+	s := table.Scope(nodes[0x6])
+	if d := s.Lookup("x", nodes[0x6]); d != nodes[0x2] {
+		t.Errorf("declaration after reference is found: %v", d)
+	}
+	if d := s.Lookup("x", nil); d != nodes[0x9] {
+		t.Errorf("not valid lookup at the end of scope: %v", d)
+	}
+	if d := table.Scope(nodes[0x9]).Lookup("x", nodes[0x9]); d != nodes[0x9] {
+		t.Errorf("declaration is not visible in own initializer: %v", d)
+	}
+
+	s = table.Scope(nodes[0xc])
+	if s.Node != nodes[0xa] || s.Parent.Node != nodes[0x4] {
+		t.Fatalf("not valid scope of if statement: %#v", s)
+	}
+	if d := s.Lookup("x", nodes[0xc]); d != nodes[0x9] {
+		t.Errorf("not valid lookup in condition: %v", d)
+	}
+	if d := s.Lookup("y", nodes[0xc]); d != nil {
+		t.Errorf("declaration of nested scope is found: %v", d)
+	}
+}
@@ -0,0 +1,216 @@
+package ast
+
+import (
+	"reflect"
+)
+
+// Visitor is visitor of nodes for Walk. Visit is invoked for each not nil
+// node. If the result visitor w is not nil, Walk visits each of the
+// children of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order. Nil children (for example,
+// <<<NULL>>> nodes of IfStmt) are not visited.
+func Walk(v Visitor, node Node) {
+	if node == nil {
+		return
+	}
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	for _, c := range node.Children() {
+		Walk(v, c)
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if node != nil && f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order. If f returns true,
+// Inspect visits each of the children of node.
+//
+// Example of renaming all variables `i`:
+//
+//	ast.Inspect(tree, func(node ast.Node) bool {
+//		if v, ok := node.(*ast.VarDecl); ok && v.Name == "i" {
+//			v.Name = "index"
+//		}
+//		return true
+//	})
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// ApplyFunc is function of Apply for each node. Returned value is
+// described in Apply.
+type ApplyFunc func(c *Cursor) bool
+
+// Cursor describes node encountered during Apply.
+type Cursor struct {
+	node   Node
+	index  int
+	stack  []Node
+	result Node
+}
+
+// Node returns the current node.
+func (c *Cursor) Node() Node {
+	return c.node
+}
+
+// Parent returns the parent of the current node. For root node, nil is
+// returned.
+func (c *Cursor) Parent() Node {
+	if len(c.stack) == 0 {
+		return nil
+	}
+	return c.stack[len(c.stack)-1]
+}
+
+// Stack returns all parents of the current node from root node to parent.
+// Returned slice must not be modified.
+func (c *Cursor) Stack() []Node {
+	return c.stack
+}
+
+// Index returns index of the current node in children of parent. For root
+// node, -1 is returned.
+func (c *Cursor) Index() int {
+	return c.index
+}
+
+// Replace replaces the current node by node n in children of parent.
+// Children of new node are traversed instead of children of replaced node.
+func (c *Cursor) Replace(n Node) {
+	if p := c.Parent(); p != nil {
+		p.Children()[c.index] = n
+	} else {
+		c.result = n
+	}
+	c.node = n
+}
+
+// Apply traverses an AST in depth-first order and calls pre and post
+// function for each not nil node. Pre and post functions may be nil.
+//
+// If pre returns false, children of node and post are not called. If post
+// returns false, traversal is stopped and Apply returns immediately.
+//
+// Apply returns root node, that is possibly replaced.
+//
+// Example of replacing `sizeof` expressions by 1:
+//
+//	tree = ast.Apply(tree, func(c *ast.Cursor) bool {
+//		if u, ok := c.Node().(*ast.UnaryExprOrTypeTraitExpr); ok && u.Function == "sizeof" {
+//			c.Replace(&ast.IntegerLiteral{Type: "int", Value: "1"})
+//			return false
+//		}
+//		return true
+//	}, nil)
+func Apply(root Node, pre, post ApplyFunc) (result Node) {
+	c := &Cursor{node: root, index: -1, result: root}
+	if root != nil {
+		c.apply(pre, post)
+	}
+	return c.result
+}
+
+func (c *Cursor) apply(pre, post ApplyFunc) bool {
+	if pre != nil && !pre(c) {
+		return true
+	}
+	node, index := c.node, c.index
+	if node != nil {
+		c.stack = append(c.stack, node)
+		children := node.Children()
+		for i := range children {
+			if children[i] == nil {
+				continue
+			}
+			c.node, c.index = children[i], i
+			if !c.apply(pre, post) {
+				return false
+			}
+		}
+		c.stack = c.stack[:len(c.stack)-1]
+		c.node, c.index = node, index
+	}
+	if post != nil && !post(c) {
+		return false
+	}
+	return true
+}
+
+// Find returns all nodes of tree in depth-first order, for which function
+// match returns true.
+//
+// Example of finding all calls of function `free`:
+//
+//	calls := ast.Find(tree, ast.CallTo("free"))
+func Find(root Node, match func(Node) bool) (nodes []Node) {
+	Inspect(root, func(node Node) bool {
+		if match(node) {
+			nodes = append(nodes, node)
+		}
+		return true
+	})
+	return
+}
+
+// OfType returns filter of nodes with the same type as one of examples.
+//
+// Example of finding all loops:
+//
+//	loops := ast.Find(tree, ast.OfType(&ast.ForStmt{}, &ast.WhileStmt{}, &ast.DoStmt{}))
+func OfType(examples ...Node) func(Node) bool {
+	types := map[reflect.Type]bool{}
+	for _, e := range examples {
+		types[reflect.TypeOf(e)] = true
+	}
+	return func(node Node) bool {
+		return types[reflect.TypeOf(node)]
+	}
+}
+
+// CallTo returns filter of calls (CallExpr) of function with name.
+func CallTo(name string) func(Node) bool {
+	return func(node Node) bool {
+		call, ok := node.(*CallExpr)
+		if !ok {
+			return false
+		}
+		callee, ok := CalleeName(call)
+		return ok && callee == name
+	}
+}
+
+// CalleeName returns name of called function. If function is not called
+// by name (for example, it is called by pointer), then false is returned.
+func CalleeName(call *CallExpr) (name string, ok bool) {
+	if len(call.Children()) == 0 {
+		return "", false
+	}
+	node := call.Children()[0]
+	for {
+		switch n := node.(type) {
+		case *DeclRefExpr:
+			return n.Name, n.For == FunctionDeclRefExpr
+		case *ImplicitCastExpr, *ParenExpr:
+			if len(n.Children()) == 0 {
+				return "", false
+			}
+			node = n.Children()[0]
+		default:
+			return "", false
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package ast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// walkTree returns tree of code:
+//
+//	p = malloc(sizeof(int));
+//	free(p);
+//	if (p) {}
+func walkTree(t *testing.T) Node {
+	lines := []struct {
+		depth int
+		line  string
+	}{
+		{0, "CompoundStmt 0x1 <a.c:1:1, line:5:1>"},
+		{1, "BinaryOperator 0x2 <line:2:3, col:24> 'int *' '='"},
+		{2, "DeclRefExpr 0x3 <col:3> 'int *' lvalue Var 0x100 'p' 'int *'"},
+		{2, "CallExpr 0x4 <col:7, col:24> 'void *'"},
+		{3, "ImplicitCastExpr 0x5 <col:7> 'void *(*)(unsigned long)' <FunctionToPointerDecay>"},
+		{4, "DeclRefExpr 0x6 <col:7> 'void *(unsigned long)' Function 0x200 'malloc' 'void *(unsigned long)'"},
+		{3, "UnaryExprOrTypeTraitExpr 0x7 <col:14, col:24> 'unsigned long' sizeof 'int'"},
+		{1, "CallExpr 0x8 <line:3:3, col:9> 'void'"},
+		{2, "ImplicitCastExpr 0x9 <col:3> 'void (*)(void *)' <FunctionToPointerDecay>"},
+		{3, "DeclRefExpr 0xa <col:3> 'void (void *)' Function 0x300 'free' 'void (void *)'"},
+		{2, "ImplicitCastExpr 0xb <col:8> 'void *' <BitCast>"},
+		{3, "DeclRefExpr 0xc <col:8> 'int *' lvalue Var 0x100 'p' 'int *'"},
+		{1, "IfStmt 0xd <line:4:3, col:11>"},
+		{2, "ImplicitCastExpr 0xe <col:7> 'int *' <LValueToRValue>"},
+		{3, "DeclRefExpr 0xf <col:7> 'int *' lvalue Var 0x100 'p' 'int *'"},
+		{2, "CompoundStmt 0x10 <col:10, col:11>"},
+	}
+	var stack []Node
+	for _, l := range lines {
+		n, err := Parse(l.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stack = append(stack[:l.depth], n)
+		if l.depth > 0 {
+			stack[l.depth-1].AddChild(n)
+		}
+	}
+	// IfStmt has nil children for absent parts
+	ifStmt := stack[0].Children()[2]
+	ifStmt.AddChild(nil)
+	return stack[0]
+}
+
+func addresses(nodes []Node) (addrs []Address) {
+	for _, n := range nodes {
+		addrs = append(addrs, n.Address())
+	}
+	return
+}
+
+type depthVisitor struct {
+	depth  int
+	result *[]string
+}
+
+func (v depthVisitor) Visit(node Node) Visitor {
+	if node == nil {
+		*v.result = append(*v.result, "end")
+		return nil
+	}
+	name := reflect.TypeOf(node).Elem().Name()
+	*v.result = append(*v.result, strings.Repeat(" ", v.depth)+name)
+	if _, ok := node.(*CallExpr); ok {
+		return nil
+	}
+	return depthVisitor{depth: v.depth + 1, result: v.result}
+}
+
+func TestWalk(t *testing.T) {
+	tree := walkTree(t)
+
+	var result []string
+	Walk(depthVisitor{result: &result}, tree)
+	expected := []string{
+		"CompoundStmt",
+		" BinaryOperator",
+		"  DeclRefExpr",
+		"end",
+		"  CallExpr",
+		"end",
+		" CallExpr",
+		" IfStmt",
+		"  ImplicitCastExpr",
+		"   DeclRefExpr",
+		"end",
+		"end",
+		"  CompoundStmt",
+		"end",
+		"end",
+		"end",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("not valid walking:\n%s", strings.Join(result, "\n"))
+	}
+
+	var nodes []Node
+	Inspect(tree, func(node Node) bool {
+		nodes = append(nodes, node)
+		_, ok := node.(*CallExpr)
+		return !ok
+	})
+	if addrs := addresses(nodes); !reflect.DeepEqual(addrs,
+		[]Address{0x1, 0x2, 0x3, 0x4, 0x8, 0xd, 0xe, 0xf, 0x10}) {
+		t.Errorf("not valid inspecting: %v", addrs)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tree := walkTree(t)
+
+	t.Run("parents", func(t *testing.T) {
+		var post []Address
+		Apply(tree, func(c *Cursor) bool {
+			if c.Node().Address() == 0xc {
+				if addrs := addresses(c.Stack()); !reflect.DeepEqual(addrs,
+					[]Address{0x1, 0x8, 0xb}) {
+					t.Errorf("not valid stack: %v", addrs)
+				}
+				if c.Parent() != tree.Children()[1].Children()[1] || c.Index() != 0 {
+					t.Errorf("not valid parent: %v %d", c.Parent(), c.Index())
+				}
+			}
+			if c.Node() == tree && (c.Parent() != nil || c.Index() != -1) {
+				t.Errorf("not valid root: %v %d", c.Parent(), c.Index())
+			}
+			_, ok := c.Node().(*IfStmt)
+			return !ok
+		}, func(c *Cursor) bool {
+			post = append(post, c.Node().Address())
+			return c.Node().Address() != 0x8
+		})
+		if !reflect.DeepEqual(post, []Address{0x3, 0x6, 0x5, 0x7, 0x4, 0x2, 0xa, 0x9, 0xc, 0xb, 0x8}) {
+			t.Errorf("not valid post order: %v", post)
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		one := &IntegerLiteral{Type: "int", Value: "1"}
+		result := Apply(tree, func(c *Cursor) bool {
+			if u, ok := c.Node().(*UnaryExprOrTypeTraitExpr); ok && u.Function == "sizeof" {
+				c.Replace(one)
+			}
+			return true
+		}, nil)
+		if result != tree || tree.Children()[0].Children()[1].Children()[1] != one {
+			t.Errorf("node is not replaced")
+		}
+
+		root := &CompoundStmt{}
+		result = Apply(tree, func(c *Cursor) bool {
+			c.Replace(root)
+			return true
+		}, nil)
+		if result != root {
+			t.Errorf("root is not replaced: %v", result)
+		}
+	})
+}
+
+func TestFind(t *testing.T) {
+	tree := walkTree(t)
+
+	tcs := []struct {
+		match func(Node) bool
+		addrs []Address
+	}{
+		{CallTo("free"), []Address{0x8}},
+		{CallTo("malloc"), []Address{0x4}},
+		{CallTo("p"), nil},
+		{OfType(&CallExpr{}, &IfStmt{}), []Address{0x4, 0x8, 0xd}},
+		{OfType(&ForStmt{}), nil},
+	}
+	for i, tc := range tcs {
+		if addrs := addresses(Find(tree, tc.match)); !reflect.DeepEqual(addrs, tc.addrs) {
+			t.Errorf("%d: not valid nodes: %v", i, addrs)
+		}
+	}
+
+	name, ok := CalleeName(tree.Children()[1].(*CallExpr))
+	if !ok || name != "free" {
+		t.Errorf("not valid callee: %v %v", name, ok)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/preprocessor"
+)
+
+func TestAstFormat(t *testing.T) {
+	// a.c : int main() {
+	//           int a = 1;
+	//           return a;
+	//       }
+	lines := strings.Split(`TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>
+`+"`"+`-FunctionDecl 0x2 <a.c:1:1, line:4:1> line:1:5 main 'int ()'
+  `+"`"+`-CompoundStmt 0x3 <col:12, line:4:1>
+    |-DeclStmt 0x4 <line:2:5, col:14>
+    | `+"`"+`-VarDecl 0x5 <col:5, col:13> col:9 used a 'int' cinit
+    |   `+"`"+`-IntegerLiteral 0x6 <col:13> 'int' 1
+    `+"`"+`-ReturnStmt 0x7 <line:3:5, col:12>
+      `+"`"+`-ImplicitCastExpr 0x8 <col:12> 'int' <LValueToRValue>
+        `+"`"+`-DeclRefExpr 0x9 <col:12> 'int' lvalue Var 0x5 'a' 'int'`, "\n")
+
+	write := func(format string) (string, error) {
+		var buf bytes.Buffer
+		args := DefaultProgramArgs()
+		args.astOutput = format
+		err := writeAst(&buf, args, lines, preprocessor.FilePP{})
+		return buf.String(), err
+	}
+
+	t.Run("tree", func(t *testing.T) {
+		out, err := write("tree")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != strings.Join(lines, "\n")+"\n\n" {
+			t.Errorf("not valid tree:\n%s", out)
+		}
+	})
+
+	t.Run("c", func(t *testing.T) {
+		out, err := write("c")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "int main()") ||
+			!strings.Contains(out, "\tint a = 1;\n\treturn a;\n") {
+			t.Errorf("not valid C code:\n%s", out)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := write("json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var root map[string]interface{}
+		if err := json.Unmarshal([]byte(out), &root); err != nil {
+			t.Fatal(err)
+		}
+		if root["NodeType"] != "TranslationUnitDecl" {
+			t.Errorf("not valid root node: %v", root["NodeType"])
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := write("xml"); err == nil {
+			t.Errorf("error is not found for unknown format")
+		}
+	})
+}
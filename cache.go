@@ -0,0 +1,270 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/preprocessor"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/version"
+)
+
+// cache is directory with results of transpiling of translation units.
+// Results are stored in files with name by hash of sources, clang flags
+// and versions of clang and c4go:
+//
+//	<hash>.pp   - results of preprocessor and hashes of included files
+//	<hash>.ast  - lines of clang AST
+//	<hash>.decl - top-level declarations of translation unit
+//	<hash>.go   - generated Go source and names of declarations of unit
+//
+// Results of preprocessor are found by input files before preprocessing,
+// all other results are found by preprocessed source.
+//
+// Empty directory means that cache is not used.
+type cache struct {
+	dir string
+}
+
+// cachedUnit is generated Go source of translation unit.
+type cachedUnit struct {
+	Source      string
+	Names       []string             // names of declarations from C source file of unit
+	Diagnostics []program.Diagnostic // warnings of transpiling
+}
+
+// hash returns key for cache by parts of data.
+func (c cache) hash(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:%s;", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// inputKey returns key of results of preprocessor for cache. Key is
+// changed, if input files, working directory, flags of clang, clang or
+// c4go are changed. Changes of included files are checked by loadFilePP.
+func (c cache) inputKey(args ProgramArgs) string {
+	wd, _ := os.Getwd()
+	parts := []string{cacheVersion(), compilerVersion(args.cppCode), wd,
+		fmt.Sprintf("%v %v", args.cppCode, args.getClangFlags())}
+	for _, in := range args.inputFiles {
+		b, _ := ioutil.ReadFile(in)
+		parts = append(parts, in, string(b))
+	}
+	return c.hash(parts...)
+}
+
+// unitKey returns key of translation unit for cache. Key is changed, if
+// preprocessed source, user source files, flags of clang, clang or c4go
+// are changed.
+func (c cache) unitKey(args ProgramArgs, filePP preprocessor.FilePP) string {
+	parts := []string{cacheVersion(), compilerVersion(args.cppCode),
+		string(filePP.GetSource()),
+		fmt.Sprintf("%v %v %s", args.cppCode, args.getClangFlags(), args.astFormat)}
+
+	// comments and macros are taken from user sources
+	var files []string
+	for _, inc := range filePP.GetIncludeFiles() {
+		if inc.IsUserSource {
+			files = append(files, inc.HeaderName)
+		}
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		b, _ := ioutil.ReadFile(file)
+		parts = append(parts, file, string(b))
+	}
+	return c.hash(parts...)
+}
+
+// goKey returns key of generated Go source of translation unit. Context is
+// information about other translation units of Go package.
+func (c cache) goKey(args ProgramArgs, unitKey, context string) string {
+	return c.hash(unitKey, context, fmt.Sprintf("%s %v %v %v %v %v",
+		args.packageName, args.outsideStructs, args.macroFunctions,
+		args.cLayout, args.library, args.lineDirectives || args.sourceMapFile != ""))
+}
+
+// cacheVersion returns version of c4go. For development build the
+// executable file is used.
+func cacheVersion() string {
+	v := version.Version()
+	if exe, err := os.Executable(); err == nil {
+		if info, err := os.Stat(exe); err == nil {
+			v += fmt.Sprintf("%s %d %d", exe, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return v
+}
+
+// compilerVersions is output of `clang --version` for each compiler.
+var compilerVersions = map[string]string{}
+
+// compilerVersion returns version of clang, because preprocessed source
+// and AST depend on it.
+func compilerVersion(cppCode bool) string {
+	compiler, _ := preprocessor.Compiler(cppCode)
+	v, ok := compilerVersions[compiler]
+	if !ok {
+		out, _ := exec.Command(compiler, "--version").Output()
+		v = string(out)
+		compilerVersions[compiler] = v
+	}
+	return v
+}
+
+func (c cache) filename(key, kind string) string {
+	return filepath.Join(c.dir, key+"."+kind)
+}
+
+// load returns data from cache.
+func (c cache) load(key, kind string) ([]byte, bool) {
+	if c.dir == "" {
+		return nil, false
+	}
+	b, err := ioutil.ReadFile(c.filename(key, kind))
+	return b, err == nil
+}
+
+// store saves data in cache. Data is written into temporary file and
+// renamed, so parallel runs of c4go see only complete files. Errors are
+// ignored, because cache does not change the result of transpiling.
+func (c cache) store(key, kind string, data []byte) {
+	if c.dir == "" {
+		return
+	}
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return
+	}
+	tmp, err := ioutil.TempFile(c.dir, key+".tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.filename(key, kind))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// cachedPP is results of preprocessor with hashes of included files.
+type cachedPP struct {
+	Files  map[string]string // hash of content by name of included file
+	FilePP preprocessor.FilePP
+}
+
+// fileHash returns hash of content of file or empty string, if file
+// cannot be read.
+func (c cache) fileHash(name string) string {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return ""
+	}
+	return c.hash(string(b))
+}
+
+// loadFilePP returns results of preprocessor from cache, if included
+// files are not changed.
+func (c cache) loadFilePP(key string) (f preprocessor.FilePP, ok bool) {
+	b, ok := c.load(key, "pp")
+	if !ok {
+		return
+	}
+	var u cachedPP
+	if err := json.Unmarshal(b, &u); err != nil {
+		return f, false
+	}
+	for name, hash := range u.Files {
+		if c.fileHash(name) != hash {
+			return f, false
+		}
+	}
+	return u.FilePP, true
+}
+
+// storeFilePP saves results of preprocessor in cache.
+func (c cache) storeFilePP(key string, f preprocessor.FilePP) {
+	if c.dir == "" {
+		return
+	}
+	u := cachedPP{Files: map[string]string{}, FilePP: f}
+	for _, inc := range f.GetIncludeFiles() {
+		u.Files[inc.HeaderName] = c.fileHash(inc.HeaderName)
+	}
+	b, err := json.Marshal(u)
+	if err != nil {
+		return
+	}
+	c.store(key, "pp", b)
+}
+
+// loadAst returns lines of clang AST from cache.
+func (c cache) loadAst(key string) (lines []string, ok bool) {
+	b, ok := c.load(key, "ast")
+	if !ok {
+		return
+	}
+	return strings.Split(string(b), "\n"), true
+}
+
+// storeAst saves lines of clang AST in cache.
+func (c cache) storeAst(key string, lines []string) {
+	c.store(key, "ast", []byte(strings.Join(lines, "\n")))
+}
+
+// loadUnit returns generated Go source of translation unit from cache.
+func (c cache) loadUnit(key string) (u cachedUnit, ok bool) {
+	b, ok := c.load(key, "go")
+	if !ok {
+		return
+	}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return u, false
+	}
+	return u, true
+}
+
+// storeUnit saves generated Go source of translation unit in cache.
+func (c cache) storeUnit(key string, u cachedUnit) {
+	b, err := json.Marshal(u)
+	if err != nil {
+		return
+	}
+	c.store(key, "go", b)
+}
+
+// loadDecls returns top-level declarations of translation unit from cache.
+func (c cache) loadDecls(key string) (decls []unitDecl, ok bool) {
+	b, ok := c.load(key, "decl")
+	if !ok {
+		return
+	}
+	if err := json.Unmarshal(b, &decls); err != nil {
+		return nil, false
+	}
+	return decls, true
+}
+
+// storeDecls saves top-level declarations of translation unit in cache.
+func (c cache) storeDecls(key string, decls []unitDecl) {
+	b, err := json.Marshal(decls)
+	if err != nil {
+		return
+	}
+	c.store(key, "decl", b)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/preprocessor"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c4go-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	args := DefaultProgramArgs()
+	c := cache{dir: dir}
+	key := c.unitKey(args, preprocessor.FilePP{})
+
+	args2 := args
+	args2.clangFlags = []string{"-DN=1"}
+	if key == c.unitKey(args2, preprocessor.FilePP{}) {
+		t.Errorf("key is not changed by clang flags")
+	}
+
+	if _, ok := c.loadAst(key); ok {
+		t.Fatalf("empty cache")
+	}
+	lines := []string{"TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>", ""}
+	c.storeAst(key, lines)
+	if l, ok := c.loadAst(key); !ok || !reflect.DeepEqual(l, lines) {
+		t.Errorf("not valid AST lines: %q", l)
+	}
+
+	goKey := c.goKey(args, key, "")
+	if goKey == c.goKey(args, key, "other.c") {
+		t.Errorf("key is not changed by context")
+	}
+	args2 = args
+	args2.cLayout = true
+	if goKey == c.goKey(args2, key, "") {
+		t.Errorf("key is not changed by options")
+	}
+	u := cachedUnit{Source: "package main\n", Names: []string{"a", "b"}}
+	c.storeUnit(goKey, u)
+	if v, ok := c.loadUnit(goKey); !ok || !reflect.DeepEqual(u, v) {
+		t.Errorf("not valid unit: %#v", v)
+	}
+
+	decls := []unitDecl{{Name: "get", Type: "int (void)", IsFunction: true, HasBody: true}}
+	c.storeDecls(key, decls)
+	if d, ok := c.loadDecls(key); !ok || !reflect.DeepEqual(d, decls) {
+		t.Errorf("not valid declarations: %#v", d)
+	}
+
+	// results of preprocessor are found by input files
+	input := filepath.Join(dir, "a.c")
+	if err := ioutil.WriteFile(input, []byte("int a;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args.inputFiles = []string{input}
+	ppKey := c.inputKey(args)
+	if _, ok := c.loadFilePP(ppKey); ok {
+		t.Fatalf("empty cache of preprocessor")
+	}
+	c.storeFilePP(ppKey, preprocessor.FilePP{})
+	if _, ok := c.loadFilePP(ppKey); !ok {
+		t.Errorf("results of preprocessor are not found")
+	}
+	if err := ioutil.WriteFile(input, []byte("int b;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ppKey == c.inputKey(args) {
+		t.Errorf("key is not changed by input file")
+	}
+
+	// results of preprocessor are not used after change of included file
+	header := filepath.Join(dir, "a.h")
+	if err := ioutil.WriteFile(header, []byte("int h;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var filePP preprocessor.FilePP
+	includes, _ := json.Marshal(map[string]interface{}{
+		"Includes": []preprocessor.IncludeHeader{{HeaderName: header}},
+	})
+	if err := json.Unmarshal(includes, &filePP); err != nil {
+		t.Fatal(err)
+	}
+	c.storeFilePP(ppKey, filePP)
+	if _, ok := c.loadFilePP(ppKey); !ok {
+		t.Errorf("results of preprocessor with header are not found")
+	}
+	if err := ioutil.WriteFile(header, []byte("long h;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.loadFilePP(ppKey); ok {
+		t.Errorf("results of preprocessor are used after change of header")
+	}
+
+	// cache is not used without directory
+	none := cache{}
+	none.storeUnit(goKey, u)
+	if _, ok := none.loadUnit(goKey); ok {
+		t.Errorf("cache without directory")
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompilationDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c4go-compdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "compile_commands.json")
+	err = ioutil.WriteFile(filename, []byte(`[
+{"directory": "`+dir+`", "command": "cc -DA=1 -Iinc -c a.c", "file": "a.c"},
+{"directory": "`+dir+`", "command": "cc -DB=1 -Iinc -c b.c", "file": "b.c"}
+]`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inc := "-I" + filepath.Join(dir, "inc")
+
+	// all files of database
+	args := DefaultProgramArgs()
+	args.clangFlags = []string{"-DC=1"}
+	if err = args.applyCompilationDatabase(filename); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{filepath.Join(dir, "a.c"), filepath.Join(dir, "b.c")}
+	if !reflect.DeepEqual(args.inputFiles, files) {
+		t.Errorf("not valid input files: %q", args.inputFiles)
+	}
+	flags := []string{"-DC=1", "-DA=1", inc, "-DB=1"}
+	if f := args.getClangFlags(); !reflect.DeepEqual(f, flags) {
+		t.Errorf("not valid flags: %q", f)
+	}
+	// files with different flags are not preprocessed together
+	for _, f := range files {
+		if err = ioutil.WriteFile(f, []byte("int main() { return 0; }"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = args.checkFileFlags(); err == nil {
+		t.Errorf("files with different flags are accepted")
+	}
+	if _, _, err = generateAstLines(args); err == nil ||
+		!strings.Contains(err.Error(), "-outdir") {
+		t.Errorf("not valid error for files with different flags: %v", err)
+	}
+
+	// only flags of input file
+	args = DefaultProgramArgs()
+	args.inputFiles = []string{files[1]}
+	if err = args.applyCompilationDatabase(filename); err != nil {
+		t.Fatal(err)
+	}
+	flags = []string{"-DB=1", inc}
+	if f := args.getClangFlags(); !reflect.DeepEqual(f, flags) {
+		t.Errorf("not valid flags: %q", f)
+	}
+	if err = args.checkFileFlags(); err != nil {
+		t.Error(err)
+	}
+
+	// file outside of database
+	args = DefaultProgramArgs()
+	args.inputFiles = []string{filepath.Join(dir, "c.c")}
+	if err = args.applyCompilationDatabase(filename); err == nil {
+		t.Errorf("file outside of database is accepted")
+	}
+}
@@ -191,7 +191,7 @@ func generateDebugCCode(args ProgramArgs, lines []string, filePP preprocessor.Fi
 	}
 
 	// convert lines to tree ast
-	tree, errs := fromLinesToTree(args.verbose, lines, filePP)
+	tree, errs := fromLinesToTree(args.verbose, args.cLayout, lines, filePP)
 	for i := range errs {
 		fmt.Fprintf(os.Stderr, "AST error #%d:\n%v\n",
 			i, errs[i].Error())
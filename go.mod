@@ -11,4 +11,4 @@ require (
 	golang.org/x/sys v0.1.0
 )
 
-go 1.13
+go 1.19
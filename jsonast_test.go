@@ -0,0 +1,186 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/preprocessor"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/transpiler"
+)
+
+func TestJSONAst(t *testing.T) {
+	// a.c : struct point { int x; };
+	//       int sum(struct point *p, int n) {
+	//           int s = 0;
+	//           for (int i = 0; i < n; i++) {
+	//               s += p[i].x;
+	//           }
+	//           return s;
+	//       }
+	text := `
+TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>
+|-RecordDecl 0x10 <a.c:1:1, col:23> col:8 struct point definition
+| ` + "`" + `-FieldDecl 0x11 <col:16, col:20> col:20 referenced x 'int'
+` + "`" + `-FunctionDecl 0x20 <line:2:1, line:8:1> line:2:5 sum 'int (struct point *, int)'
+  |-ParmVarDecl 0x21 <col:9, col:23> col:23 used p 'struct point *'
+  |-ParmVarDecl 0x22 <col:26, col:30> col:30 used n 'int'
+  ` + "`" + `-CompoundStmt 0x23 <col:33, line:8:1>
+    |-DeclStmt 0x24 <line:3:5, col:14>
+    | ` + "`" + `-VarDecl 0x25 <col:5, col:13> col:9 used s 'int' cinit
+    |   ` + "`" + `-IntegerLiteral 0x26 <col:13> 'int' 0
+    |-ForStmt 0x27 <line:4:5, line:6:5>
+    | |-DeclStmt 0x28 <line:4:10, col:19>
+    | | ` + "`" + `-VarDecl 0x29 <col:10, col:18> col:14 used i 'int' cinit
+    | |   ` + "`" + `-IntegerLiteral 0x2a <col:18> 'int' 0
+    | |-<<<NULL>>>
+    | |-BinaryOperator 0x2b <col:21, col:25> 'int' '<'
+    | | |-ImplicitCastExpr 0x2c <col:21> 'int' <LValueToRValue>
+    | | | ` + "`" + `-DeclRefExpr 0x2d <col:21> 'int' lvalue Var 0x29 'i' 'int'
+    | | ` + "`" + `-ImplicitCastExpr 0x2e <col:25> 'int' <LValueToRValue>
+    | |   ` + "`" + `-DeclRefExpr 0x2f <col:25> 'int' lvalue ParmVar 0x22 'n' 'int'
+    | |-UnaryOperator 0x30 <col:28, col:29> 'int' postfix '++'
+    | | ` + "`" + `-DeclRefExpr 0x31 <col:28> 'int' lvalue Var 0x29 'i' 'int'
+    | ` + "`" + `-CompoundStmt 0x32 <col:33, line:6:5>
+    |   ` + "`" + `-CompoundAssignOperator 0x33 <line:5:9, col:21> 'int' '+=' ComputeLHSTy='int' ComputeResultTy='int'
+    |     |-DeclRefExpr 0x34 <col:9> 'int' lvalue Var 0x25 's' 'int'
+    |     ` + "`" + `-ImplicitCastExpr 0x35 <col:14, col:21> 'int' <LValueToRValue>
+    |       ` + "`" + `-MemberExpr 0x36 <col:14, col:21> 'int' lvalue .x 0x11
+    |         ` + "`" + `-ArraySubscriptExpr 0x37 <col:14, col:17> 'struct point' lvalue
+    |           |-ImplicitCastExpr 0x38 <col:14> 'struct point *' <LValueToRValue>
+    |           | ` + "`" + `-DeclRefExpr 0x39 <col:14> 'struct point *' lvalue ParmVar 0x21 'p' 'struct point *'
+    |           ` + "`" + `-ImplicitCastExpr 0x3a <col:16> 'int' <LValueToRValue>
+    |             ` + "`" + `-DeclRefExpr 0x3b <col:16> 'int' lvalue Var 0x29 'i' 'int'
+    ` + "`" + `-ReturnStmt 0x3c <line:7:5, col:12>
+      ` + "`" + `-ImplicitCastExpr 0x3d <col:12> 'int' <LValueToRValue>
+        ` + "`" + `-DeclRefExpr 0x3e <col:12> 'int' lvalue Var 0x25 's' 'int'
+`
+	json := `
+{"id":"0x1","kind":"TranslationUnitDecl","loc":{},"range":{"begin":{},"end":{}},"inner":[
+{"id":"0x10","kind":"RecordDecl","loc":{"offset":7,"file":"a.c","line":1,"col":8,"tokLen":5},
+ "range":{"begin":{"offset":0,"col":1,"tokLen":6},"end":{"offset":22,"col":23,"tokLen":1}},
+ "name":"point","tagUsed":"struct","completeDefinition":true,"inner":[
+ {"id":"0x11","kind":"FieldDecl","loc":{"offset":19,"col":20,"tokLen":1},
+  "range":{"begin":{"offset":15,"col":16,"tokLen":3},"end":{"offset":19,"col":20,"tokLen":1}},
+  "isReferenced":true,"name":"x","type":{"qualType":"int"}}]},
+{"id":"0x20","kind":"FunctionDecl","loc":{"offset":30,"line":2,"col":5,"tokLen":3},
+ "range":{"begin":{"offset":26,"col":1,"tokLen":3},"end":{"offset":120,"line":8,"col":1,"tokLen":1}},
+ "name":"sum","type":{"qualType":"int (struct point *, int)"},"inner":[
+ {"id":"0x21","kind":"ParmVarDecl","loc":{"offset":48,"line":2,"col":23,"tokLen":1},
+  "range":{"begin":{"offset":34,"col":9,"tokLen":6},"end":{"offset":48,"col":23,"tokLen":1}},
+  "isUsed":true,"name":"p","type":{"qualType":"struct point *"}},
+ {"id":"0x22","kind":"ParmVarDecl","loc":{"offset":55,"col":30,"tokLen":1},
+  "range":{"begin":{"offset":51,"col":26,"tokLen":3},"end":{"offset":55,"col":30,"tokLen":1}},
+  "isUsed":true,"name":"n","type":{"qualType":"int"}},
+ {"id":"0x23","kind":"CompoundStmt","range":{"begin":{"offset":58,"col":33,"tokLen":1},"end":{"offset":120,"line":8,"col":1,"tokLen":1}},"inner":[
+  {"id":"0x24","kind":"DeclStmt","range":{"begin":{"offset":64,"line":3,"col":5,"tokLen":3},"end":{"offset":73,"col":14,"tokLen":1}},"inner":[
+   {"id":"0x25","kind":"VarDecl","loc":{"offset":68,"col":9,"tokLen":1},
+    "range":{"begin":{"offset":64,"col":5,"tokLen":3},"end":{"offset":72,"col":13,"tokLen":1}},
+    "isUsed":true,"name":"s","type":{"qualType":"int"},"init":"c","inner":[
+    {"id":"0x26","kind":"IntegerLiteral","range":{"begin":{"offset":72,"col":13,"tokLen":1},"end":{"offset":72,"col":13,"tokLen":1}},
+     "type":{"qualType":"int"},"valueCategory":"prvalue","value":"0"}]}]},
+  {"id":"0x27","kind":"ForStmt","range":{"begin":{"offset":79,"line":4,"col":5,"tokLen":3},"end":{"offset":110,"line":6,"col":5,"tokLen":1}},"inner":[
+   {"id":"0x28","kind":"DeclStmt","range":{"begin":{"offset":84,"line":4,"col":10,"tokLen":3},"end":{"offset":93,"col":19,"tokLen":1}},"inner":[
+    {"id":"0x29","kind":"VarDecl","loc":{"offset":88,"col":14,"tokLen":1},
+     "range":{"begin":{"offset":84,"col":10,"tokLen":3},"end":{"offset":92,"col":18,"tokLen":1}},
+     "isUsed":true,"name":"i","type":{"qualType":"int"},"init":"c","inner":[
+     {"id":"0x2a","kind":"IntegerLiteral","range":{"begin":{"offset":92,"col":18,"tokLen":1},"end":{"offset":92,"col":18,"tokLen":1}},
+      "type":{"qualType":"int"},"valueCategory":"prvalue","value":"0"}]}]},
+   {},
+   {"id":"0x2b","kind":"BinaryOperator","range":{"begin":{"offset":95,"col":21,"tokLen":1},"end":{"offset":99,"col":25,"tokLen":1}},
+    "type":{"qualType":"int"},"valueCategory":"prvalue","opcode":"<","inner":[
+    {"id":"0x2c","kind":"ImplicitCastExpr","range":{"begin":{"offset":95,"col":21,"tokLen":1},"end":{"offset":95,"col":21,"tokLen":1}},
+     "type":{"qualType":"int"},"valueCategory":"prvalue","castKind":"LValueToRValue","inner":[
+     {"id":"0x2d","kind":"DeclRefExpr","range":{"begin":{"offset":95,"col":21,"tokLen":1},"end":{"offset":95,"col":21,"tokLen":1}},
+      "type":{"qualType":"int"},"valueCategory":"lvalue","referencedDecl":{"id":"0x29","kind":"VarDecl","name":"i","type":{"qualType":"int"}}}]},
+    {"id":"0x2e","kind":"ImplicitCastExpr","range":{"begin":{"offset":99,"col":25,"tokLen":1},"end":{"offset":99,"col":25,"tokLen":1}},
+     "type":{"qualType":"int"},"valueCategory":"prvalue","castKind":"LValueToRValue","inner":[
+     {"id":"0x2f","kind":"DeclRefExpr","range":{"begin":{"offset":99,"col":25,"tokLen":1},"end":{"offset":99,"col":25,"tokLen":1}},
+      "type":{"qualType":"int"},"valueCategory":"lvalue","referencedDecl":{"id":"0x22","kind":"ParmVarDecl","name":"n","type":{"qualType":"int"}}}]}]},
+   {"id":"0x30","kind":"UnaryOperator","range":{"begin":{"offset":102,"col":28,"tokLen":1},"end":{"offset":103,"col":29,"tokLen":2}},
+    "type":{"qualType":"int"},"valueCategory":"prvalue","isPostfix":true,"opcode":"++","inner":[
+    {"id":"0x31","kind":"DeclRefExpr","range":{"begin":{"offset":102,"col":28,"tokLen":1},"end":{"offset":102,"col":28,"tokLen":1}},
+     "type":{"qualType":"int"},"valueCategory":"lvalue","referencedDecl":{"id":"0x29","kind":"VarDecl","name":"i","type":{"qualType":"int"}}}]},
+   {"id":"0x32","kind":"CompoundStmt","range":{"begin":{"offset":107,"col":33,"tokLen":1},"end":{"offset":110,"line":6,"col":5,"tokLen":1}},"inner":[
+    {"id":"0x33","kind":"CompoundAssignOperator","range":{"begin":{"offset":117,"line":5,"col":9,"tokLen":1},"end":{"offset":129,"col":21,"tokLen":1}},
+     "type":{"qualType":"int"},"valueCategory":"prvalue","opcode":"+=","computeLHSType":{"qualType":"int"},"computeResultType":{"qualType":"int"},"inner":[
+     {"id":"0x34","kind":"DeclRefExpr","range":{"begin":{"offset":117,"col":9,"tokLen":1},"end":{"offset":117,"col":9,"tokLen":1}},
+      "type":{"qualType":"int"},"valueCategory":"lvalue","referencedDecl":{"id":"0x25","kind":"VarDecl","name":"s","type":{"qualType":"int"}}},
+     {"id":"0x35","kind":"ImplicitCastExpr","range":{"begin":{"offset":122,"col":14,"tokLen":1},"end":{"offset":129,"col":21,"tokLen":1}},
+      "type":{"qualType":"int"},"valueCategory":"prvalue","castKind":"LValueToRValue","inner":[
+      {"id":"0x36","kind":"MemberExpr","range":{"begin":{"offset":122,"col":14,"tokLen":1},"end":{"offset":129,"col":21,"tokLen":1}},
+       "type":{"qualType":"int"},"valueCategory":"lvalue","name":"x","isArrow":false,"referencedMemberDecl":"0x11","inner":[
+       {"id":"0x37","kind":"ArraySubscriptExpr","range":{"begin":{"offset":122,"col":14,"tokLen":1},"end":{"offset":125,"col":17,"tokLen":1}},
+        "type":{"qualType":"struct point"},"valueCategory":"lvalue","inner":[
+        {"id":"0x38","kind":"ImplicitCastExpr","range":{"begin":{"offset":122,"col":14,"tokLen":1},"end":{"offset":122,"col":14,"tokLen":1}},
+         "type":{"qualType":"struct point *"},"valueCategory":"prvalue","castKind":"LValueToRValue","inner":[
+         {"id":"0x39","kind":"DeclRefExpr","range":{"begin":{"offset":122,"col":14,"tokLen":1},"end":{"offset":122,"col":14,"tokLen":1}},
+          "type":{"qualType":"struct point *"},"valueCategory":"lvalue","referencedDecl":{"id":"0x21","kind":"ParmVarDecl","name":"p","type":{"qualType":"struct point *"}}}]},
+        {"id":"0x3a","kind":"ImplicitCastExpr","range":{"begin":{"offset":124,"col":16,"tokLen":1},"end":{"offset":124,"col":16,"tokLen":1}},
+         "type":{"qualType":"int"},"valueCategory":"prvalue","castKind":"LValueToRValue","inner":[
+         {"id":"0x3b","kind":"DeclRefExpr","range":{"begin":{"offset":124,"col":16,"tokLen":1},"end":{"offset":124,"col":16,"tokLen":1}},
+          "type":{"qualType":"int"},"valueCategory":"lvalue","referencedDecl":{"id":"0x29","kind":"VarDecl","name":"i","type":{"qualType":"int"}}}]}]}]}]}]}]}]},
+  {"id":"0x3c","kind":"ReturnStmt","range":{"begin":{"offset":140,"line":7,"col":5,"tokLen":6},"end":{"offset":147,"col":12,"tokLen":1}},"inner":[
+   {"id":"0x3d","kind":"ImplicitCastExpr","range":{"begin":{"offset":147,"col":12,"tokLen":1},"end":{"offset":147,"col":12,"tokLen":1}},
+    "type":{"qualType":"int"},"valueCategory":"prvalue","castKind":"LValueToRValue","inner":[
+    {"id":"0x3e","kind":"DeclRefExpr","range":{"begin":{"offset":147,"col":12,"tokLen":1},"end":{"offset":147,"col":12,"tokLen":1}},
+     "type":{"qualType":"int"},"valueCategory":"lvalue","referencedDecl":{"id":"0x25","kind":"VarDecl","name":"s","type":{"qualType":"int"}}}]}]}]}]}]}
+`
+
+	sources := map[string]string{}
+	for name, ast := range map[string]string{"text": text, "json": json} {
+		lines := strings.Split(ast, "\n")
+		if isJSONAst(lines) != (name == "json") {
+			t.Errorf("not valid format of %s AST", name)
+		}
+		tree, errs := fromLinesToTree(false, false, lines, preprocessor.FilePP{})
+		if len(errs) > 0 {
+			t.Fatalf("%s: %v", name, errs)
+		}
+		p := program.NewProgram()
+		source, err := transpiler.TranspileAST("a.go", "main", true, p, tree[0], nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		sources[name] = source
+	}
+	if sources["text"] != sources["json"] {
+		t.Errorf("Go code is different:\n%s\n%s", sources["text"], sources["json"])
+	}
+	if !strings.Contains(sources["json"], "s += p[i].x") {
+		t.Errorf("not valid Go code:\n%s", sources["json"])
+	}
+
+	// unknown nodes are errors
+	_, errs := fromLinesToTree(false, false, []string{
+		`{"id":"0x1","kind":"TranslationUnitDecl","inner":[{"id":"0x2","kind":"NewDecl"}]}`,
+	}, preprocessor.FilePP{})
+	if len(errs) != 1 {
+		t.Errorf("not valid errors of unknown node: %v", errs)
+	}
+
+	// not valid JSON
+	tree, errs := fromLinesToTree(false, false, []string{`{"id":`}, preprocessor.FilePP{})
+	if tree != nil || len(errs) != 1 {
+		t.Errorf("not valid JSON is parsed: %v", errs)
+	}
+}
+
+func TestClangJSONSupported(t *testing.T) {
+	tcs := []struct {
+		version   string
+		supported bool
+	}{
+		{"clang version 6.0.0-1ubuntu2 (tags/RELEASE_600/final)", false},
+		{"clang version 10.0.0-4ubuntu1\nTarget: x86_64-pc-linux-gnu", true},
+		{"Ubuntu clang version 14.0.0-1ubuntu1", true},
+		{"Apple clang version 11.0.0 (clang-1100.0.33.8)", false},
+		{"Apple clang version 13.1.6 (clang-1316.0.21.2.5)", true},
+		{"gcc (GCC) 9.3.0", false},
+	}
+	for _, tc := range tcs {
+		if clangJSONSupported(tc.version) != tc.supported {
+			t.Errorf("not valid support of JSON for `%s`", tc.version)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"runtime"
 	"runtime/pprof"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/Konstantin8105/c4go/preprocessor"
 	"github.com/Konstantin8105/c4go/program"
 	"github.com/Konstantin8105/c4go/transpiler"
+	"github.com/Konstantin8105/c4go/util"
 	"github.com/Konstantin8105/c4go/version"
 )
 
@@ -48,10 +50,21 @@ type ProgramArgs struct {
 	verbose        bool
 	inputFiles     []string
 	clangFlags     []string
+	fileFlags      map[string][]string // flags of clang for each input file
 	outputFile     string
+	outputDir      string
+	cacheDir       string
+	reportFile     string
+	sourceMapFile  string
+	lineDirectives bool
+	astFormat      string // format of clang AST: text, json or auto
+	astOutput      string // output format of ast command: tree, c or json
 	packageName    string
 	cppCode        bool
 	outsideStructs bool
+	macroFunctions bool
+	cLayout        bool
+	library        bool
 
 	// for debugging
 	debugPrefix string
@@ -66,6 +79,7 @@ const (
 	StateTranspile
 	StateDebug
 	StateBinding
+	StateTranspilePackage
 )
 
 // DefaultProgramArgs default value of ProgramArgs
@@ -76,9 +90,78 @@ func DefaultProgramArgs() ProgramArgs {
 		packageName: "main",
 		debugPrefix: "debug.",
 		clangFlags:  []string{},
+		astFormat:   "text",
 	}
 }
 
+// getClangFlags returns flags of clang for input files: flags from command
+// line and flags of input files from compilation database without
+// duplicates.
+func (args ProgramArgs) getClangFlags() (flags []string) {
+	flags = append(flags, args.clangFlags...)
+	found := map[string]bool{}
+	for _, in := range args.inputFiles {
+		for _, flag := range args.fileFlags[in] {
+			if !found[flag] {
+				found[flag] = true
+				flags = append(flags, flag)
+			}
+		}
+	}
+	return
+}
+
+// checkFileFlags returns error, if input files have different flags in
+// compilation database. Input files are preprocessed together with the same
+// flags, so files with own flags must be transpiled as separate translation
+// units of Go package (option -outdir).
+func (args ProgramArgs) checkFileFlags() error {
+	for i := 1; i < len(args.inputFiles); i++ {
+		first, in := args.inputFiles[0], args.inputFiles[i]
+		if strings.Join(args.fileFlags[first], "\x00") != strings.Join(args.fileFlags[in], "\x00") {
+			return fmt.Errorf("files `%s` and `%s` have different flags in compilation database. "+
+				"Please use option -outdir for transpiling each file with own flags", first, in)
+		}
+	}
+	return nil
+}
+
+// applyCompilationDatabase reads flags of clang for input files from
+// compilation database. If input files are not defined, then all source
+// files of database are used.
+func (args *ProgramArgs) applyCompilationDatabase(filename string) error {
+	cs, err := preprocessor.ReadCompilationDatabase(filename)
+	if err != nil {
+		return err
+	}
+	flags := map[string][]string{}
+	var files []string
+	for _, c := range cs {
+		file := c.SourceFile()
+		if _, ok := flags[file]; !ok {
+			files = append(files, file)
+		}
+		flags[file] = c.Flags()
+	}
+
+	if len(args.inputFiles) == 0 {
+		args.inputFiles = files
+	}
+	args.fileFlags = map[string][]string{}
+	for _, in := range args.inputFiles {
+		abs, err := filepath.Abs(in)
+		if err != nil {
+			return err
+		}
+		fs, ok := flags[abs]
+		if !ok {
+			return fmt.Errorf("file `%s` is not found in compilation database %s", in, filename)
+		}
+		args.fileFlags[in] = fs
+	}
+	return nil
+}
+
 type treeNode struct {
 	indent int
 	node   ast.Node
@@ -120,6 +203,27 @@ func convertLinesToNodes(lines []string) (nodes []treeNode, errs []error) {
 	return
 }
 
+// isJSONAst return true, if lines are clang AST in JSON format.
+func isJSONAst(lines []string) bool {
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			return strings.HasPrefix(line, "{")
+		}
+	}
+	return false
+}
+
+// convertJSONToNodes converts clang AST in JSON format to nodes.
+func convertJSONToNodes(lines []string, filePP preprocessor.FilePP) (
+	nodes []treeNode, errs []error) {
+	tns, errs := ast.ParseJSON([]byte(strings.Join(lines, "\n")), filePP)
+	nodes = make([]treeNode, len(tns))
+	for i := range tns {
+		nodes[i] = treeNode{indent: tns[i].Depth, node: tns[i].Node}
+	}
+	return
+}
+
 func convertLinesToNodesParallel(lines []string) (_ []treeNode, errs []error) {
 	// function f separate full list on 2 parts and
 	// then each part can recursive run function f
@@ -174,7 +278,9 @@ func convertLinesToNodesParallel(lines []string) (_ []treeNode, errs []error) {
 }
 
 // buildTree converts an array of nodes, each prefixed with a depth into a tree.
-func buildTree(nodes []treeNode, depth int) []ast.Node {
+// Attributes of memory layout are added into structs and fields only, if
+// layoutAttrs is true.
+func buildTree(nodes []treeNode, depth int, layoutAttrs bool) []ast.Node {
 	if len(nodes) == 0 {
 		return []ast.Node{}
 	}
@@ -199,7 +305,7 @@ func buildTree(nodes []treeNode, depth int) []ast.Node {
 			}
 		}
 
-		children := buildTree(slice, depth+1)
+		children := buildTree(slice, depth+1, layoutAttrs)
 		switch section[0].node.(type) {
 		case *ast.C4goErrorNode:
 			continue
@@ -211,9 +317,7 @@ func buildTree(nodes []treeNode, depth int) []ast.Node {
 			*ast.InlineCommandComment, *ast.ParagraphComment,
 			*ast.ParamCommandComment, *ast.TextComment,
 			*ast.VerbatimLineComment, *ast.VerbatimBlockComment,
-			*ast.MaxFieldAlignmentAttr,
-			*ast.AlignedAttr,
-			*ast.AnnotateAttr, *ast.PackedAttr, *ast.DeprecatedAttr,
+			*ast.AnnotateAttr, *ast.DeprecatedAttr,
 			*ast.VerbatimBlockLineComment:
 			continue
 
@@ -222,6 +326,12 @@ func buildTree(nodes []treeNode, depth int) []ast.Node {
 				if section[0].node == nil {
 					break
 				}
+				if isLayoutAttr(child) &&
+					(!layoutAttrs || !isRecordOrField(section[0].node)) {
+					// attributes of memory layout are used only
+					// for structs and fields with memory layout of C
+					continue
+				}
 				section[0].node.AddChild(child)
 			}
 			results = append(results, section[0].node)
@@ -231,6 +341,22 @@ func buildTree(nodes []treeNode, depth int) []ast.Node {
 	return results
 }
 
+func isLayoutAttr(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.MaxFieldAlignmentAttr, *ast.AlignedAttr, *ast.PackedAttr:
+		return true
+	}
+	return false
+}
+
+func isRecordOrField(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.RecordDecl, *ast.FieldDecl:
+		return true
+	}
+	return false
+}
+
 // Avoid Go keywords
 var goKeywords = [...]string{
 	// keywords
@@ -272,79 +398,72 @@ func isLetter(b byte) bool {
 }
 
 func avoidGoKeywords(tree []ast.Node) {
-	if tree == nil {
-		return
-	}
 	for i := range tree {
-		if tree[i] == nil {
-			continue
-		}
-
-		if _, ok := tree[i].(*ast.StringLiteral); ok {
-			continue
-		}
-
-		// going depper
-		avoidGoKeywords(tree[i].Children())
-
-		// modify ast node : tree[i]
-		s := reflect.ValueOf(tree[i]).Elem()
-		typeOfT := s.Type()
-		for p := 0; p < s.NumField(); p++ {
-			f := s.Field(p)
-			name := typeOfT.Field(p).Name
-			if strings.Contains(name, "Value") {
-				continue
-			}
-			_, ok := f.Interface().(string)
-			if !ok {
-				continue
+		ast.Inspect(tree[i], func(node ast.Node) bool {
+			if _, ok := node.(*ast.StringLiteral); ok {
+				return false
 			}
-			str := f.Addr().Interface().(*string)
 
-			// avoid problem with GOPATH and `go` keyword
-			if gopath := os.Getenv("GOPATH"); gopath != "" {
-				*str = strings.Replace(*str, gopath, "GOPATH", -1)
-			}
-
-			for _, gk := range goKeywords {
-				// example *st :
-				// from:
-				// "bool (int, bool)"
-				// to:
-				// "bool_ (int, bool_)"
-				// but for:
-				// "abool" - no changes
-				if !strings.Contains(*str, gk) {
+			// modify ast node
+			s := reflect.ValueOf(node).Elem()
+			typeOfT := s.Type()
+			for p := 0; p < s.NumField(); p++ {
+				f := s.Field(p)
+				name := typeOfT.Field(p).Name
+				if strings.Contains(name, "Value") {
 					continue
 				}
-				// possible changes
-				index := 0
-				iter := 0 // limit of iteration
-				for ; iter < 100; iter++ {
-					indexs := strings.Index((*str)[index:], gk)
-					if indexs < 0 {
-						break
-					}
-					index += indexs
-					// change string
-					change := true
-					if pos := index - 1; pos >= 0 && isLetter((*str)[pos]) {
-						change = false
-					}
-					if pos := index + len(gk); pos < len(*str) && isLetter((*str)[pos]) {
-						change = false
+				_, ok := f.Interface().(string)
+				if !ok {
+					continue
+				}
+				str := f.Addr().Interface().(*string)
+
+				// avoid problem with GOPATH and `go` keyword
+				if gopath := os.Getenv("GOPATH"); gopath != "" {
+					*str = strings.Replace(*str, gopath, "GOPATH", -1)
+				}
+
+				for _, gk := range goKeywords {
+					// example *st :
+					// from:
+					// "bool (int, bool)"
+					// to:
+					// "bool_ (int, bool_)"
+					// but for:
+					// "abool" - no changes
+					if !strings.Contains(*str, gk) {
+						continue
 					}
-					if change {
-						y := index + len(gk)
-						st := (*str)[:y]
-						fi := (*str)[y:]
-						*str = st + "_" + fi
+					// possible changes
+					index := 0
+					iter := 0 // limit of iteration
+					for ; iter < 100; iter++ {
+						indexs := strings.Index((*str)[index:], gk)
+						if indexs < 0 {
+							break
+						}
+						index += indexs
+						// change string
+						change := true
+						if pos := index - 1; pos >= 0 && isLetter((*str)[pos]) {
+							change = false
+						}
+						if pos := index + len(gk); pos < len(*str) && isLetter((*str)[pos]) {
+							change = false
+						}
+						if change {
+							y := index + len(gk)
+							st := (*str)[:y]
+							fi := (*str)[y:]
+							*str = st + "_" + fi
+						}
+						index += len(gk)
 					}
-					index += len(gk)
 				}
 			}
-		}
+			return true
+		})
 	}
 }
 
@@ -360,6 +479,11 @@ func Start(args ProgramArgs) (err error) {
 		fmt.Fprintln(os.Stdout, "Reading clang AST tree...")
 	}
 
+	if args.state == StateTranspilePackage {
+		// each C source file is translation unit with own AST tree
+		return generateGoPackage(args)
+	}
+
 	lines, filePP, err := generateAstLines(args)
 	if err != nil {
 		return
@@ -367,10 +491,7 @@ func Start(args ProgramArgs) (err error) {
 
 	switch args.state {
 	case StateAst:
-		for _, l := range lines {
-			fmt.Fprintln(astout, l)
-		}
-		fmt.Fprintln(astout)
+		err = writeAst(astout, args, lines, filePP)
 
 	case StateTranspile:
 		p := program.NewProgram()
@@ -391,6 +512,45 @@ func Start(args ProgramArgs) (err error) {
 	return err
 }
 
+// writeAst writes AST in output format of ast command. Format "tree" is
+// AST lines of clang, format "c" is C code generated from AST tree and
+// format "json" is AST tree in JSON format.
+func writeAst(w io.Writer, args ProgramArgs, lines []string, filePP preprocessor.FilePP) error {
+	if args.astOutput == "" || args.astOutput == "tree" {
+		for _, l := range lines {
+			fmt.Fprintln(w, l)
+		}
+		fmt.Fprintln(w)
+		return nil
+	}
+	if args.astOutput != "c" && args.astOutput != "json" {
+		return fmt.Errorf("output format of AST `%s` is not valid", args.astOutput)
+	}
+
+	tree, errs := fromLinesToTree(args.verbose, args.cLayout, lines, filePP)
+	for i := range errs {
+		fmt.Fprintln(stderr, errs[i].Error())
+	}
+	if tree == nil {
+		return fmt.Errorf("cannot create tree: tree is nil. Please try another version of clang")
+	}
+
+	if args.astOutput == "json" {
+		data, err := ast.MarshalTree(tree[0])
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	// not supported nodes are written as comments
+	if err := ast.PrintC(w, tree[0]); err != nil {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	}
+	return nil
+}
+
 func generateAstLines(args ProgramArgs) (lines []string, filePP preprocessor.FilePP, err error) {
 	if args.verbose {
 		fmt.Fprintln(os.Stdout, "Start tanspiling ...")
@@ -410,14 +570,41 @@ func generateAstLines(args ProgramArgs) (lines []string, filePP preprocessor.Fil
 		fmt.Fprintln(os.Stdout, "Running clang preprocessor...")
 	}
 
-	filePP, err = preprocessor.NewFilePP(
-		args.inputFiles,
-		args.clangFlags,
-		args.cppCode)
-	if err != nil {
+	if err = args.checkFileFlags(); err != nil {
 		return
 	}
 
+	c := cache{dir: args.cacheDir}
+	var key string
+	if c.dir != "" {
+		key = c.inputKey(args)
+	}
+	filePP, ok := c.loadFilePP(key)
+	if ok {
+		if args.verbose {
+			fmt.Fprintln(os.Stdout, "Reading preprocessor results from cache...")
+		}
+	} else {
+		filePP, err = preprocessor.NewFilePP(
+			args.inputFiles,
+			args.getClangFlags(),
+			args.cppCode)
+		if err != nil {
+			return
+		}
+		c.storeFilePP(key, filePP)
+	}
+
+	if c.dir != "" {
+		key = c.unitKey(args, filePP)
+		if lines, ok = c.loadAst(key); ok {
+			if args.verbose {
+				fmt.Fprintln(os.Stdout, "Reading clang AST tree from cache...")
+			}
+			return
+		}
+	}
+
 	if args.verbose {
 		fmt.Fprintln(os.Stdout, "Writing preprocessor ...")
 	}
@@ -440,7 +627,17 @@ func generateAstLines(args ProgramArgs) (lines []string, filePP preprocessor.Fil
 		fmt.Fprintln(os.Stdout, "Running clang for AST tree...")
 	}
 	compiler, compilerFlag := preprocessor.Compiler(args.cppCode)
-	astPP, err := exec.Command(compiler, append(compilerFlag, "-Xclang", "-ast-dump",
+	for _, flag := range args.getClangFlags() {
+		// version of C standard is needed for parsing of source
+		if strings.HasPrefix(flag, "-std=") {
+			compilerFlag = append(compilerFlag, flag)
+		}
+	}
+	dump := "-ast-dump"
+	if useJSONAst(args.astFormat, compiler) {
+		dump = "-ast-dump=json"
+	}
+	astPP, err := exec.Command(compiler, append(compilerFlag, "-Xclang", dump,
 		"-fsyntax-only", "-fno-color-diagnostics", ppFilePath)...).Output()
 	if err != nil {
 		// If clang fails it still prints out the AST, so we have to run it
@@ -451,16 +648,57 @@ func generateAstLines(args ProgramArgs) (lines []string, filePP preprocessor.Fil
 		panic(compiler + " failed: " + err.Error() + ":\n\n" + string(errBody))
 	}
 	lines = strings.Split(string(astPP), "\n")
+	c.storeAst(key, lines)
 
 	return
 }
 
-func fromLinesToTree(verbose bool, lines []string, filePP preprocessor.FilePP) (tree []ast.Node, errs []error) {
+// useJSONAst return true, if clang AST in JSON format is used. JSON format
+// is supported since clang 9. In auto format JSON is used, if version of
+// clang is known.
+func useJSONAst(format, compiler string) bool {
+	switch format {
+	case "json":
+		return true
+	case "text":
+		return false
+	}
+	out, err := exec.Command(compiler, "--version").Output()
+	if err != nil {
+		return false
+	}
+	return clangJSONSupported(string(out))
+}
+
+// clangJSONSupported return true, if clang with version output supports
+// AST in JSON format.
+func clangJSONSupported(version string) bool {
+	groups := util.GetRegex(`(Apple )?clang version (\d+)`).FindStringSubmatch(version)
+	if len(groups) == 0 {
+		return false
+	}
+	major, _ := strconv.Atoi(groups[2])
+	if groups[1] != "" {
+		// Apple clang 11 is based on LLVM 8
+		return major >= 12
+	}
+	return major >= 9
+}
+
+func fromLinesToTree(verbose, cLayout bool, lines []string, filePP preprocessor.FilePP) (tree []ast.Node, errs []error) {
 	// Converting to nodes
 	if verbose {
 		fmt.Fprintln(os.Stdout, "Converting to nodes...")
 	}
-	nodes, astErrors := convertLinesToNodesParallel(lines)
+	var (
+		nodes     []treeNode
+		astErrors []error
+	)
+	if isJSONAst(lines) {
+		nodes, astErrors = convertJSONToNodes(lines, filePP)
+	} else {
+		nodes, astErrors = convertLinesToNodesParallel(lines)
+	}
 	for i := range astErrors {
 		errs = append(errs, fmt.Errorf(
 			"/"+"* AST Error :\n%v\n*"+"/",
@@ -471,7 +709,10 @@ func fromLinesToTree(verbose bool, lines []string, filePP preprocessor.FilePP) (
 	if verbose {
 		fmt.Fprintln(os.Stdout, "Building tree...")
 	}
-	tree = buildTree(nodes, 0)
+	tree = buildTree(nodes, 0, cLayout)
+	if len(tree) == 0 || tree[0] == nil {
+		return nil, errs
+	}
 	ast.FixPositions(tree)
 
 	// Repair the floating literals. See RepairFloatingLiteralsFromSource for
@@ -489,27 +730,6 @@ func fromLinesToTree(verbose bool, lines []string, filePP preprocessor.FilePP) (
 func generateGoCode(p *program.Program, args ProgramArgs, lines []string, filePP preprocessor.FilePP) (
 	err error) {
 
-	// p := program.NewProgram()
-	p.Verbose = args.verbose
-	p.PreprocessorFile = filePP
-
-	// convert lines to tree ast
-	tree, errs := fromLinesToTree(args.verbose, lines, filePP)
-	for i := range errs {
-		fmt.Fprintf(os.Stderr, "AST error #%d:\n%v\n",
-			i, errs[i].Error())
-		p.AddMessage(errs[i].Error())
-	}
-	if tree == nil {
-		return fmt.Errorf("cannot create tree: tree is nil. Please try another version of clang")
-	}
-
-	// avoid Go keywords
-	if args.verbose {
-		fmt.Fprintln(os.Stdout, "Modify nodes for avoid Go keywords...")
-	}
-	avoidGoKeywords(tree)
-
 	outputFilePath := args.outputFile
 
 	if outputFilePath == "" {
@@ -523,16 +743,28 @@ func generateGoCode(p *program.Program, args ProgramArgs, lines []string, filePP
 			".go"
 	}
 
-	// transpile ast tree
-	if args.verbose {
-		fmt.Fprintln(os.Stdout, "Transpiling tree...")
+	c := cache{dir: args.cacheDir}
+	var key string
+	if c.dir != "" {
+		key = c.goKey(args, c.unitKey(args, filePP),
+			fmt.Sprintf("binding %v", p.Binding))
 	}
-
-	var source string
-	source, err = transpiler.TranspileAST(args.outputFile, args.packageName, args.outsideStructs,
-		p, tree[0].(ast.Node), args.clangFlags)
-	if err != nil {
-		return fmt.Errorf("cannot transpile AST : %v", err)
+	var (
+		source      string
+		diagnostics []program.Diagnostic
+	)
+	if u, ok := c.loadUnit(key); ok {
+		if args.verbose {
+			fmt.Fprintln(os.Stdout, "Reading Go code from cache...")
+		}
+		source, diagnostics = u.Source, u.Diagnostics
+	} else {
+		source, err = transpileTree(p, args, lines, filePP)
+		if err != nil {
+			return
+		}
+		diagnostics = p.GetDiagnostics()
+		c.storeUnit(key, cachedUnit{Source: source, Diagnostics: diagnostics})
 	}
 
 	// write the output Go code
@@ -548,9 +780,84 @@ func generateGoCode(p *program.Program, args ProgramArgs, lines []string, filePP
 	// error ignored, because it is not change the workflow
 	_, _ = exec.Command("gofmt", "-s", "-w", outputFilePath).Output()
 
+	if args.lineDirectives || args.sourceMapFile != "" {
+		mappings, err := applyLineMarkers(outputFilePath, args.lineDirectives)
+		if err != nil {
+			return err
+		}
+		if args.sourceMapFile != "" {
+			if err = writeSourceMap(args.sourceMapFile, mappings); err != nil {
+				return err
+			}
+		}
+	}
+
+	if args.reportFile != "" {
+		files, err := readGoFiles("", []string{outputFilePath})
+		if err != nil {
+			return err
+		}
+		l := newCommentLocator(files)
+		for i := range diagnostics {
+			l.locate(&diagnostics[i])
+		}
+		return writeReport(args.reportFile, diagnostics, files)
+	}
+
 	return nil
 }
 
+// transpileTree converts lines of clang AST to tree and transpiles it.
+func transpileTree(p *program.Program, args ProgramArgs, lines []string, filePP preprocessor.FilePP) (
+	source string, err error) {
+
+	// p := program.NewProgram()
+	prepareProgram(p, args, filePP)
+
+	// convert lines to tree ast
+	tree, errs := fromLinesToTree(args.verbose, args.cLayout, lines, filePP)
+	var file string
+	if len(args.inputFiles) == 1 {
+		file = args.inputFiles[0]
+	}
+	for i := range errs {
+		fmt.Fprintf(os.Stderr, "AST error #%d:\n%v\n",
+			i, errs[i].Error())
+		p.AddAstError(errs[i], file)
+	}
+	if tree == nil {
+		return "", fmt.Errorf("cannot create tree: tree is nil. Please try another version of clang")
+	}
+
+	// avoid Go keywords
+	if args.verbose {
+		fmt.Fprintln(os.Stdout, "Modify nodes for avoid Go keywords...")
+	}
+	avoidGoKeywords(tree)
+
+	// transpile ast tree
+	if args.verbose {
+		fmt.Fprintln(os.Stdout, "Transpiling tree...")
+	}
+
+	source, err = transpiler.TranspileAST(args.outputFile, args.packageName, args.outsideStructs,
+		p, tree[0].(ast.Node), args.getClangFlags())
+	if err != nil {
+		return "", fmt.Errorf("cannot transpile AST : %v", err)
+	}
+	return
+}
+
+// prepareProgram sets options of transpiling from arguments.
+func prepareProgram(p *program.Program, args ProgramArgs, filePP preprocessor.FilePP) {
+	p.Verbose = args.verbose
+	p.PreprocessorFile = filePP
+	p.MacroFunctionMode = args.macroFunctions
+	p.CLayout = args.cLayout
+	p.LibraryMode = args.library
+	p.SourceLines = args.lineDirectives || args.sourceMapFile != ""
+}
+
 type inputDataFlags []string
 
 func (i *inputDataFlags) String() (s string) {
@@ -583,12 +890,32 @@ func runCommand() int {
 			"V", false, "print progress as comments")
 		outputFlag = transpileCommand.String(
 			"o", "", "output Go generated code to the specified file")
+		outputDirFlag = transpileCommand.String(
+			"outdir", "", "output Go package to the specified directory: one file per C source file and shared file")
+		cacheFlag = transpileCommand.String(
+			"cache", "", "directory for cache of clang AST and Go code of unchanged C sources")
+		reportFlag = transpileCommand.String(
+			"report", "", "output JSON report with warnings of transpiling to the specified file")
+		sourceMapFlag = transpileCommand.String(
+			"sourcemap", "", "output JSON source map from lines of Go code to C source to the specified file")
+		lineFlag = transpileCommand.Bool(
+			"line", false, "add //line directives with position of C source")
+		clangAstFlag = transpileCommand.String(
+			"clang-ast", "text", "format of clang AST: text, json or auto by version of clang")
+		compdbFlag = transpileCommand.String(
+			"compdb", "", "compilation database compile_commands.json with flags of clang for each source file")
 		packageFlag = transpileCommand.String(
 			"p", "main", "set the name of the generated package")
 		transpileHelpFlag = transpileCommand.Bool(
 			"h", false, "print help information")
 		withOutsideStructs = transpileCommand.Bool(
 			"s", false, "transpile with structs(types, unions...) from all source headers")
+		macroFunctionsFlag = transpileCommand.Bool(
+			"macro-func", false, "transpile function-like macros from user sources to Go functions")
+		layoutFlag = transpileCommand.String(
+			"layout", "go", "memory layout of structs: go or c (C-compatible with explicit padding fields)")
+		libraryFlag = transpileCommand.Bool(
+			"lib", false, "transpile C library without main: exported Go functions for public functions from user headers")
 		cpuprofile = transpileCommand.String(
 			"cpuprofile", "", "write cpu profile to this file") // debugging
 
@@ -596,6 +923,12 @@ func runCommand() int {
 			"ast", flag.ContinueOnError)
 		astCppFlag = astCommand.Bool(
 			"cpp", false, "transpile CPP code")
+		astFormatFlag = astCommand.String(
+			"clang-ast", "text", "format of clang AST: text, json or auto by version of clang")
+		astOutputFlag = astCommand.String(
+			"format", "tree", "output format of AST: tree, c or json")
+		astCompdbFlag = astCommand.String(
+			"compdb", "", "compilation database compile_commands.json with flags of clang for each source file")
 		astHelpFlag = astCommand.Bool(
 			"h", false, "print help information")
 
@@ -676,8 +1009,8 @@ func runCommand() int {
 			return 2
 		}
 
-		if *astHelpFlag || astCommand.NArg() == 0 {
-			fmt.Fprintf(stderr, "Usage: %s ast [-cpp] [-clang-flag values] file.c\n", os.Args[0])
+		if *astHelpFlag || (astCommand.NArg() == 0 && *astCompdbFlag == "") {
+			fmt.Fprintf(stderr, "Usage: %s ast [-cpp] [-clang-ast text|json|auto] [-format tree|c|json] [-compdb compile_commands.json] [-clang-flag values] file.c\n", os.Args[0])
 			astCommand.PrintDefaults()
 			return 3
 		}
@@ -686,6 +1019,15 @@ func runCommand() int {
 		args.inputFiles = astCommand.Args()
 		args.clangFlags = clangFlags
 		args.cppCode = *astCppFlag
+		args.astFormat = *astFormatFlag
+		args.astOutput = *astOutputFlag
+
+		if *astCompdbFlag != "" {
+			if err := args.applyCompilationDatabase(*astCompdbFlag); err != nil {
+				fmt.Fprintf(os.Stdout, "ast command: %v", err)
+				return 2
+			}
+		}
 
 	case "transpile":
 		err := transpileCommand.Parse(os.Args[2:])
@@ -694,9 +1036,9 @@ func runCommand() int {
 			return 4
 		}
 
-		if *transpileHelpFlag || transpileCommand.NArg() == 0 {
+		if *transpileHelpFlag || (transpileCommand.NArg() == 0 && *compdbFlag == "") {
 			fmt.Fprintf(stderr,
-				"Usage: %s transpile [-V] [-o file.go] [-cpp] [-p package] [-clang-flag values] [-cpuprofile cpu.out] file1.c ...\n",
+				"Usage: %s transpile [-V] [-o file.go] [-outdir dir] [-cache dir] [-report out.json] [-sourcemap map.json] [-line] [-clang-ast text|json|auto] [-compdb compile_commands.json] [-cpp] [-p package] [-macro-func] [-layout go|c] [-lib] [-clang-flag values] [-cpuprofile cpu.out] file1.c ...\n",
 				os.Args[0])
 			transpileCommand.PrintDefaults()
 			return 5
@@ -705,11 +1047,43 @@ func runCommand() int {
 		args.state = StateTranspile
 		args.inputFiles = transpileCommand.Args()
 		args.outputFile = *outputFlag
+		args.outputDir = *outputDirFlag
+		args.cacheDir = *cacheFlag
+		args.reportFile = *reportFlag
+		args.sourceMapFile = *sourceMapFlag
+		args.lineDirectives = *lineFlag
+		args.astFormat = *clangAstFlag
 		args.packageName = *packageFlag
+		if args.outputDir != "" {
+			args.state = StateTranspilePackage
+		}
 		args.verbose = *verboseFlag
 		args.clangFlags = clangFlags
 		args.cppCode = *cppFlag
 		args.outsideStructs = *withOutsideStructs
+		args.macroFunctions = *macroFunctionsFlag
+		args.library = *libraryFlag
+
+		if *compdbFlag != "" {
+			if err := args.applyCompilationDatabase(*compdbFlag); err != nil {
+				fmt.Fprintf(os.Stdout, "transpile command: %v", err)
+				return 4
+			}
+		}
+
+		if args.library && args.packageName == "main" {
+			fmt.Fprintf(os.Stdout, "transpile command: library mode needs package name, use flag -p")
+			return 4
+		}
+
+		switch *layoutFlag {
+		case "go":
+		case "c":
+			args.cLayout = true
+		default:
+			fmt.Fprintf(os.Stdout, "transpile command: not valid layout `%s`", *layoutFlag)
+			return 4
+		}
 
 		// debugging
 		if *cpuprofile != "" {
@@ -90,6 +90,9 @@ func TestIntegrationScripts(t *testing.T) {
 			// only for test "assert.c"
 			if strings.Contains(file, "assert.c") {
 				progs = progs[1:]
+			} else if strings.HasSuffix(file, ".c") {
+				// C code printed from AST must have the same output
+				progs = append(progs, runCRoundTrip)
 			}
 
 			results := make([]string, len(progs))
@@ -270,7 +273,7 @@ func runC(file, subFolder, stdin string, clangFlags, args []string) (string, err
 	// Compile C.
 	var seq []string
 	seq = append(seq, compilerFlag...)
-	seq = append(seq, "-lm")
+	seq = append(seq, "-lm", "-pthread")
 	seq = append(seq, "-o", cPath)
 	seq = append(seq, clangFlags...)
 	seq = append(seq, file)
@@ -306,6 +309,35 @@ func runC(file, subFolder, stdin string, clangFlags, args []string) (string, err
 	return cProgram.stdout.String() + cProgram.stderr.String(), nil
 }
 
+// print C code from AST of C file, compile and run it
+func runCRoundTrip(file, subFolder, stdin string, clangFlags, args []string) (string, error) {
+	pArgs := DefaultProgramArgs()
+	pArgs.inputFiles = []string{file}
+	pArgs.clangFlags = clangFlags
+	pArgs.state = StateAst
+	pArgs.astOutput = "c"
+
+	lines, filePP, err := generateAstLines(pArgs)
+	if err != nil {
+		return "", fmt.Errorf("Cannot get AST : %v", err)
+	}
+	var buf bytes.Buffer
+	if err = writeAst(&buf, pArgs, lines, filePP); err != nil {
+		return "", fmt.Errorf("Cannot print C code : %v", err)
+	}
+
+	// separate folder for executable, because C programs run in parallel
+	subFolder += "roundtrip" + separator
+	if err = os.MkdirAll(subFolder, os.ModePerm); err != nil {
+		return "", err
+	}
+	cFile := subFolder + "main.c"
+	if err = ioutil.WriteFile(cFile, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("Cannot write C code : %v", err)
+	}
+	return runC(cFile, subFolder, stdin, clangFlags, args)
+}
+
 func (pr ProgramArgs) runGoTest(stdin string, args []string) (_ string, err error) {
 
 	// Example : programArgs.outputFile = subFolder + "main.go"
@@ -443,6 +475,12 @@ func runGo(file, subFolder, stdin string, clangFlags, args []string) (string, er
 	if strings.HasSuffix(file, "cpp") {
 		programArgs.cppCode = true
 	}
+	if strings.Contains(file, "layout.c") {
+		programArgs.cLayout = true
+	}
+	if strings.Contains(file, "macro.c") {
+		programArgs.macroFunctions = true
+	}
 
 	// Compile Go
 	err := Start(programArgs)
@@ -943,7 +981,7 @@ func TestBuildTree(t *testing.T) {
 		if len(errs) > 0 {
 			amountError++
 		}
-		_ = buildTree(nodes, 0)
+		_ = buildTree(nodes, 0, false)
 	}
 	if amountError < len(lines)/2 {
 		t.Errorf("AST error test is not enought: %v", amountError)
@@ -0,0 +1,559 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	goast "go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/preprocessor"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/transpiler"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// sharedFileName is name of Go file with declarations used by all files of
+// multi-file package: types, functions from headers and runtime helpers.
+const sharedFileName = "c4go_shared.go"
+
+// translationUnit is C source file of multi-file package. AST tree is
+// created only for transpiling of unit, declarations of unit are enough
+// for other units.
+type translationUnit struct {
+	file   string // C source file
+	lines  []string
+	tree   ast.Node
+	filePP preprocessor.FilePP
+	errs   []error         // errors of AST
+	decls  []unitDecl      // top-level declarations
+	rename map[string]bool // static names renamed in tree
+}
+
+// unitDecl is top-level declaration of C source file of translation unit.
+type unitDecl struct {
+	Name       string
+	Type       string
+	IsFunction bool
+	IsStatic   bool
+	IsExtern   bool // only for variables
+	HasBody    bool // only for functions
+}
+
+// goUnit is Go source of transpiled translation unit.
+type goUnit struct {
+	file        string            // C source file
+	source      string            // Go source
+	isUnit      func(string) bool // declaration is transpiled from C source file
+	diagnostics []program.Diagnostic
+}
+
+// generateGoPackage transpiles each C source file as separate translation
+// unit and writes the Go package into output directory: one Go file per
+// C source file and one shared file.
+func generateGoPackage(args ProgramArgs) (err error) {
+	c := cache{dir: args.cacheDir}
+
+	units := make([]translationUnit, len(args.inputFiles))
+	keys := make([]string, len(args.inputFiles))
+	for i, in := range args.inputFiles {
+		a := args
+		a.inputFiles = []string{in}
+		units[i].file = in
+		units[i].lines, units[i].filePP, err = generateAstLines(a)
+		if err != nil {
+			return
+		}
+		if c.dir != "" {
+			keys[i] = c.unitKey(args, units[i].filePP)
+		}
+		var ok bool
+		if units[i].decls, ok = c.loadDecls(keys[i]); ok {
+			continue
+		}
+		if err = units[i].parse(args); err != nil {
+			return
+		}
+		units[i].decls = topLevelDecls(units[i])
+		c.storeDecls(keys[i], units[i].decls)
+	}
+
+	// names of other translation units, that change generated Go code
+	contexts := make([]string, len(units))
+	for i := range units {
+		contexts[i] = unitContext(units, i)
+	}
+
+	renameStaticCollisions(units)
+
+	gus := make([]goUnit, len(units))
+	for i := range units {
+		if args.verbose {
+			fmt.Fprintf(os.Stdout, "Transpiling %s...\n", units[i].file)
+		}
+		var key string
+		if c.dir != "" {
+			key = c.goKey(args, keys[i], contexts[i])
+		}
+		if u, ok := c.loadUnit(key); ok {
+			names := map[string]bool{}
+			for _, name := range u.Names {
+				names[name] = true
+			}
+			gus[i] = goUnit{file: units[i].file, source: u.Source,
+				isUnit:      func(name string) bool { return names[name] },
+				diagnostics: u.Diagnostics}
+			continue
+		}
+		if units[i].tree == nil {
+			if err = units[i].parse(args); err != nil {
+				return
+			}
+		}
+
+		p := program.NewProgram()
+		prepareProgram(p, args, units[i].filePP)
+		p.UnitFile = units[i].file
+		for _, e := range units[i].errs {
+			p.AddAstError(e, units[i].file)
+		}
+		defineUnitFunctions(p, units, i)
+
+		var source string
+		source, err = transpiler.TranspileAST(units[i].file, args.packageName,
+			args.outsideStructs, p, units[i].tree, args.getClangFlags())
+		if err != nil {
+			return fmt.Errorf("cannot transpile AST of %s: %v", units[i].file, err)
+		}
+		gus[i] = goUnit{file: units[i].file, source: source, isUnit: p.IsUnitName,
+			diagnostics: p.GetDiagnostics()}
+		c.storeUnit(key, cachedUnit{Source: source, Names: p.GetUnitNames(),
+			Diagnostics: p.GetDiagnostics()})
+	}
+
+	files, unitFiles, err := splitPackage(args.packageName, gus)
+	if err != nil {
+		return
+	}
+
+	if args.verbose {
+		fmt.Fprintln(os.Stdout, "Writing the output Go package...")
+	}
+	if err = os.MkdirAll(args.outputDir, os.ModePerm); err != nil {
+		return fmt.Errorf("cannot create output directory: %v", err)
+	}
+	var names []string
+	for name, source := range files {
+		names = append(names, name)
+		filename := filepath.Join(args.outputDir, name)
+		if err = ioutil.WriteFile(filename, []byte(source), 0644); err != nil {
+			return fmt.Errorf("writing Go output file failed: %v", err)
+		}
+		// simplify Go code by `gofmt`
+		// error ignored, because it is not change the workflow
+		_, _ = exec.Command("gofmt", "-s", "-w", filename).Output()
+	}
+
+	if args.lineDirectives || args.sourceMapFile != "" {
+		sort.Strings(names)
+		var mappings []sourceMapping
+		for _, name := range names {
+			ms, err := applyLineMarkers(filepath.Join(args.outputDir, name), args.lineDirectives)
+			if err != nil {
+				return err
+			}
+			mappings = append(mappings, ms...)
+		}
+		if args.sourceMapFile != "" {
+			if err = writeSourceMap(args.sourceMapFile, mappings); err != nil {
+				return err
+			}
+		}
+	}
+
+	if args.reportFile != "" {
+		return writePackageReport(args, gus, names, unitFiles)
+	}
+	return nil
+}
+
+// writePackageReport writes report with diagnostics of all translation
+// units. Diagnostics are searched in Go file of translation unit first.
+// Names of Go files of translation units are in slice unitFiles.
+func writePackageReport(args ProgramArgs, gus []goUnit, names []string,
+	unitFiles []string) error {
+	files, err := readGoFiles(args.outputDir, names)
+	if err != nil {
+		return err
+	}
+	l := newCommentLocator(files)
+	var diagnostics []program.Diagnostic
+	for i, u := range gus {
+		name := filepath.Join(args.outputDir, unitFiles[i])
+		for _, d := range u.diagnostics {
+			l.locate(&d, name)
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return writeReport(args.reportFile, diagnostics, files)
+}
+
+// parse creates AST tree of translation unit. Static names are renamed, if
+// collisions are already found.
+func (u *translationUnit) parse(args ProgramArgs) error {
+	tree, errs := fromLinesToTree(args.verbose, args.cLayout, u.lines, u.filePP)
+	for j := range errs {
+		fmt.Fprintf(os.Stderr, "AST error #%d in %s:\n%v\n",
+			j, u.file, errs[j].Error())
+	}
+	if tree == nil {
+		return fmt.Errorf("cannot create tree for %s: tree is nil. Please try another version of clang", u.file)
+	}
+	avoidGoKeywords(tree)
+	u.tree, u.errs = tree[0], errs
+	u.renameStatics()
+	return nil
+}
+
+// topLevelDecls returns functions and variables of C source file of
+// translation unit.
+func topLevelDecls(u translationUnit) (decls []unitDecl) {
+	for _, c := range u.tree.Children() {
+		if !u.isTopLevel(c) {
+			continue
+		}
+		switch n := c.(type) {
+		case *ast.FunctionDecl:
+			decls = append(decls, unitDecl{Name: n.Name, Type: n.Type,
+				IsFunction: true, IsStatic: n.IsStatic, HasBody: hasBody(n)})
+		case *ast.VarDecl:
+			decls = append(decls, unitDecl{Name: n.Name, Type: n.Type,
+				IsStatic: n.IsStatic, IsExtern: n.IsExtern})
+		}
+	}
+	return
+}
+
+// isTopLevel return true, if node is declaration in C source file of
+// translation unit.
+func (u translationUnit) isTopLevel(n ast.Node) bool {
+	return filepath.Clean(n.Position().File) == filepath.Clean(u.file)
+}
+
+// unitContext returns top-level declarations of other translation units.
+// These declarations change names of static functions and binding of
+// functions in translation unit.
+func unitContext(units []translationUnit, current int) string {
+	var buf bytes.Buffer
+	for i, u := range units {
+		if i == current {
+			continue
+		}
+		fmt.Fprintf(&buf, "%s\n", u.file)
+		for _, d := range u.decls {
+			if d.IsFunction {
+				fmt.Fprintf(&buf, "func %s %s %v %v\n", d.Name, d.Type, d.IsStatic, d.HasBody)
+			} else {
+				fmt.Fprintf(&buf, "var %s %s %v %v\n", d.Name, d.Type, d.IsStatic, d.IsExtern)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// renameStaticCollisions renames static functions and variables, if the
+// same name is defined in other translation unit. In Go all files of
+// package have one scope, so name is changed by adding name of C source
+// file, for example: `helper` in "list.c" is renamed to `helper_list`.
+// Names are renamed in created trees and in trees created later.
+func renameStaticCollisions(units []translationUnit) {
+	// amount of translation units with definition of name
+	defined := map[string]int{}
+	for _, u := range units {
+		names := map[string]bool{}
+		for _, d := range u.decls {
+			if d.IsFunction || !d.IsExtern {
+				names[d.Name] = true
+			}
+		}
+		for name := range names {
+			defined[name]++
+		}
+	}
+
+	for i := range units {
+		rename := map[string]bool{}
+		for _, d := range units[i].decls {
+			if d.IsStatic && defined[d.Name] > 1 {
+				rename[d.Name] = true
+			}
+		}
+		if len(rename) == 0 {
+			continue
+		}
+		units[i].rename = rename
+		if units[i].tree != nil {
+			units[i].renameStatics()
+		}
+	}
+}
+
+// renameStatics renames static names of translation unit in AST tree.
+func (u *translationUnit) renameStatics() {
+	if len(u.rename) == 0 {
+		return
+	}
+	rename := u.rename
+	suffix := "_" + strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') ||
+			('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.TrimSuffix(filepath.Base(u.file), filepath.Ext(u.file)))
+
+	// all declarations of static name in translation unit
+	var decls []ast.Node
+	for _, c := range u.tree.Children() {
+		switch n := c.(type) {
+		case *ast.FunctionDecl:
+			if rename[n.Name] {
+				decls = append(decls, n)
+			}
+		case *ast.VarDecl:
+			if rename[n.Name] {
+				decls = append(decls, n)
+			}
+		}
+	}
+
+	// references of all redeclarations are the same
+	table := ast.NewSymbolTable(u.tree)
+	renamed := map[ast.Node]bool{}
+	for _, decl := range decls {
+		for _, r := range table.References(decl) {
+			if ref, ok := r.(*ast.DeclRefExpr); ok && !renamed[ref] {
+				renamed[ref] = true
+				ref.Name += suffix
+			}
+		}
+	}
+	for _, decl := range decls {
+		switch n := decl.(type) {
+		case *ast.FunctionDecl:
+			n.Name += suffix
+		case *ast.VarDecl:
+			n.Name += suffix
+		}
+	}
+}
+
+// hasBody return true, if function declaration is function definition.
+func hasBody(n *ast.FunctionDecl) bool {
+	for _, c := range n.Children() {
+		if _, ok := c.(*ast.CompoundStmt); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// defineUnitFunctions adds definitions of not static functions from other
+// translation units. Calls of these functions are calls of Go functions
+// in the same package, so binding is not needed.
+func defineUnitFunctions(p *program.Program, units []translationUnit, current int) {
+	for i, u := range units {
+		if i == current {
+			continue
+		}
+		for _, d := range u.decls {
+			if !d.IsFunction || d.IsStatic || !d.HasBody {
+				continue
+			}
+			_, _, f, r, err := util.ParseFunction(d.Type)
+			if err != nil || len(r) == 0 {
+				continue
+			}
+			p.AddFunctionDefinition(program.DefinitionFunction{
+				Name:          util.ConvertFunctionNameFromCtoGo(d.Name),
+				ReturnType:    r[0],
+				ArgumentTypes: f,
+				HaveBody:      true,
+			})
+		}
+	}
+}
+
+// splitPackage splits Go sources of translation units into files of Go
+// package. Declarations transpiled from C source file are located in
+// the file of translation unit, all other declarations are located in
+// the shared file without duplicates. Imports of each file are
+// filtered by usage. Names of Go files of translation units are returned
+// in order of units.
+func splitPackage(packageName string, units []goUnit) (
+	files map[string]string, unitFiles []string, err error) {
+
+	type importDecl struct {
+		source string
+		names  []string // package names
+	}
+	type file struct {
+		header string
+		decls  []string
+		used   map[string]bool // used package names
+	}
+	var (
+		imports  []importDecl
+		shared   = file{used: map[string]bool{}}
+		outputs  = make([]file, len(units))
+		known    = map[string]string{} // source of shared declarations
+		warnings []string
+	)
+
+	for i, u := range units {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, u.file, u.source, parser.ParseComments)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot parse Go source of %s: %v", u.file, err)
+		}
+		outputs[i] = file{
+			header: u.source[:fset.Position(f.Package).Offset],
+			used:   map[string]bool{},
+		}
+		if i == 0 {
+			// the first lines of header is comment about c4go
+			shared.header = outputs[i].header
+			if index := strings.Index(shared.header, "\n\n"); index > 0 {
+				shared.header = shared.header[:index+2]
+			}
+		}
+
+		for _, decl := range f.Decls {
+			var buf bytes.Buffer
+			if err := format.Node(&buf, fset, &printer.CommentedNode{
+				Node:     decl,
+				Comments: f.Comments,
+			}); err != nil {
+				return nil, nil, fmt.Errorf("cannot print declaration from %s: %v", u.file, err)
+			}
+			src := buf.String()
+
+			if g, ok := decl.(*goast.GenDecl); ok && g.Tok == token.IMPORT {
+				found := false
+				for _, im := range imports {
+					found = found || im.source == src
+				}
+				if found {
+					continue
+				}
+				im := importDecl{source: src}
+				for _, spec := range g.Specs {
+					is := spec.(*goast.ImportSpec)
+					importPath, _ := strconv.Unquote(is.Path.Value)
+					name := path.Base(importPath)
+					if is.Name != nil {
+						name = is.Name.Name
+					}
+					im.names = append(im.names, name)
+				}
+				imports = append(imports, im)
+				continue
+			}
+
+			used := map[string]bool{}
+			goast.Inspect(decl, func(node goast.Node) bool {
+				if sel, ok := node.(*goast.SelectorExpr); ok {
+					if id, ok := sel.X.(*goast.Ident); ok {
+						used[id.Name] = true
+					}
+				}
+				return true
+			})
+
+			names := util.GetDeclNames(decl)
+			isUnit := len(names) > 0
+			for _, name := range names {
+				isUnit = isUnit && u.isUnit(name)
+			}
+			if isUnit {
+				outputs[i].decls = append(outputs[i].decls, src)
+				for name := range used {
+					outputs[i].used[name] = true
+				}
+				continue
+			}
+
+			key := strings.Join(names, ",")
+			if key != "" && key != "_" && key != "init" {
+				if s, ok := known[key]; ok {
+					if s != src {
+						warnings = append(warnings, fmt.Sprintf(
+							"// Warning: declaration `%s` from %s is not same in other files",
+							key, u.file))
+					}
+					continue
+				}
+				known[key] = src
+			}
+			shared.decls = append(shared.decls, src)
+			for name := range used {
+				shared.used[name] = true
+			}
+		}
+	}
+	if len(warnings) > 0 {
+		shared.header += strings.Join(warnings, "\n") + "\n\n"
+	}
+
+	files = map[string]string{}
+	generate := func(name string, f file) error {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "%spackage %s\n\n", f.header, packageName)
+		for _, im := range imports {
+			for _, pkg := range im.names {
+				if f.used[pkg] || pkg == "_" || pkg == "." {
+					fmt.Fprintf(&buf, "%s\n", im.source)
+					break
+				}
+			}
+		}
+		for _, decl := range f.decls {
+			fmt.Fprintf(&buf, "\n%s\n", decl)
+		}
+		source, err := format.Source(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("cannot format Go file %s: %v", name, err)
+		}
+		files[name] = string(source)
+		return nil
+	}
+
+	used := map[string]bool{sharedFileName: true}
+	for i, u := range units {
+		base := filepath.Base(u.file)
+		name := strings.TrimSuffix(base, filepath.Ext(base)) + ".go"
+		for index := 1; used[name]; index++ {
+			name = fmt.Sprintf("%s_%d.go", strings.TrimSuffix(base, filepath.Ext(base)), index)
+		}
+		used[name] = true
+		unitFiles = append(unitFiles, name)
+		if err = generate(name, outputs[i]); err != nil {
+			return
+		}
+	}
+	if len(shared.decls) > 0 {
+		err = generate(sharedFileName, shared)
+	}
+	return
+}
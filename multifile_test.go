@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/preprocessor"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/transpiler"
+)
+
+func TestGoPackage(t *testing.T) {
+	// point.h : struct point { int x; };
+	// a.c     : static int helper(void) { return 1; }
+	//           int get(void) { return helper(); }
+	// b.c     : static int helper(void) { return 2; }
+	//           int sum(void) { return get() + helper(); }
+	record := `|-RecordDecl 0x10 <point.h:1:1, line:1:22> line:1:8 struct point definition
+| ` + "`" + `-FieldDecl 0x11 <col:17, col:21> col:21 x 'int'`
+	sources := map[string]string{
+		"a.c": `TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>
+` + record + `
+|-FunctionDecl 0x2 <a.c:1:1, col:41> col:12 used helper 'int (void)' static
+| ` + "`" + `-CompoundStmt 0x3 <col:30, col:41>
+|   ` + "`" + `-ReturnStmt 0x4 <col:32, col:39>
+|     ` + "`" + `-IntegerLiteral 0x5 <col:39> 'int' 1
+` + "`" + `-FunctionDecl 0x6 <line:2:1, col:35> col:5 get 'int (void)'
+  ` + "`" + `-CompoundStmt 0x7 <col:15, col:35>
+    ` + "`" + `-ReturnStmt 0x8 <col:17, col:32>
+      ` + "`" + `-CallExpr 0x9 <col:24, col:32> 'int'
+        ` + "`" + `-ImplicitCastExpr 0xa <col:24> 'int (*)(void)' <FunctionToPointerDecay>
+          ` + "`" + `-DeclRefExpr 0xb <col:24> 'int (void)' Function 0x2 'helper' 'int (void)'`,
+		"b.c": `TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>
+` + record + `
+|-FunctionDecl 0x20 <b.c:1:1, col:41> col:12 used helper 'int (void)' static
+| ` + "`" + `-CompoundStmt 0x21 <col:30, col:41>
+|   ` + "`" + `-ReturnStmt 0x22 <col:32, col:39>
+|     ` + "`" + `-IntegerLiteral 0x23 <col:39> 'int' 2
+|-FunctionDecl 0x24 <line:2:1, col:15> col:5 used get 'int (void)'
+` + "`" + `-FunctionDecl 0x25 <line:3:1, col:45> col:5 sum 'int (void)'
+  ` + "`" + `-CompoundStmt 0x26 <col:15, col:45>
+    ` + "`" + `-ReturnStmt 0x27 <col:17, col:42>
+      ` + "`" + `-BinaryOperator 0x28 <col:24, col:42> 'int' '+'
+        |-CallExpr 0x29 <col:24, col:28> 'int'
+        | ` + "`" + `-ImplicitCastExpr 0x2a <col:24> 'int (*)(void)' <FunctionToPointerDecay>
+        |   ` + "`" + `-DeclRefExpr 0x2b <col:24> 'int (void)' Function 0x24 'get' 'int (void)'
+        ` + "`" + `-CallExpr 0x2c <col:33, col:42> 'int'
+          ` + "`" + `-ImplicitCastExpr 0x2d <col:33> 'int (*)(void)' <FunctionToPointerDecay>
+            ` + "`" + `-DeclRefExpr 0x2e <col:33> 'int (void)' Function 0x20 'helper' 'int (void)'`,
+	}
+
+	var units []translationUnit
+	for _, file := range []string{"a.c", "b.c"} {
+		lines := strings.Split(sources[file], "\n")
+		tree, errs := fromLinesToTree(false, false, lines, preprocessor.FilePP{})
+		if len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		u := translationUnit{file: file, tree: tree[0]}
+		u.decls = topLevelDecls(u)
+		units = append(units, u)
+	}
+	renameStaticCollisions(units)
+
+	var gus []goUnit
+	for i, u := range units {
+		p := program.NewProgram()
+		p.UnitFile = u.file
+		defineUnitFunctions(p, units, i)
+		source, err := transpiler.TranspileAST(u.file, "pkg", true, p, u.tree, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gus = append(gus, goUnit{file: u.file, source: source, isUnit: p.IsUnitName})
+	}
+
+	files, unitFiles, err := splitPackage("pkg", gus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("not valid amount of files: %d", len(files))
+	}
+	if !reflect.DeepEqual(unitFiles, []string{"a.go", "b.go"}) {
+		t.Errorf("not valid files of units: %v", unitFiles)
+	}
+	tcs := []struct {
+		file     string
+		contains []string
+		absent   []string
+	}{
+		{"a.go", []string{"func helper_a() int32", "return helper_a()"}, []string{"type point"}},
+		{"b.go", []string{"func helper_b() int32", "get() + helper_b()"}, []string{"type point", "C."}},
+		{sharedFileName, []string{"type point struct"}, []string{"func helper"}},
+	}
+	for _, tc := range tcs {
+		source, ok := files[tc.file]
+		if !ok {
+			t.Errorf("file %s is not found", tc.file)
+			continue
+		}
+		for _, s := range tc.contains {
+			if !strings.Contains(source, s) {
+				t.Errorf("file %s does not contain `%s`:\n%s", tc.file, s, source)
+			}
+		}
+		for _, s := range tc.absent {
+			if strings.Contains(source, s) {
+				t.Errorf("file %s contains `%s`:\n%s", tc.file, s, source)
+			}
+		}
+		if strings.Count(source, "package pkg") != 1 {
+			t.Errorf("not valid package in file %s", tc.file)
+		}
+	}
+
+	t.Run("same base names", func(t *testing.T) {
+		// a/util.c and b/util.c
+		same := []goUnit{gus[0], gus[1]}
+		same[0].file = filepath.Join("a", "util.c")
+		same[1].file = filepath.Join("b", "util.c")
+		comment := "// Warning (*ast.CallExpr):  b/util.c:2:24 :same warning"
+		same[1].diagnostics = []program.Diagnostic{{Message: "same warning", Comment: comment}}
+
+		files, unitFiles, err := splitPackage("pkg", same)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(unitFiles, []string{"util.go", "util_1.go"}) {
+			t.Fatalf("not valid files of units: %v", unitFiles)
+		}
+		if !strings.Contains(files["util_1.go"], "func helper_b() int32") {
+			t.Errorf("not valid file of unit:\n%s", files["util_1.go"])
+		}
+
+		// the same comment in both files
+		dir := t.TempDir()
+		var names []string
+		for _, name := range unitFiles {
+			source := files[name] + comment + "\n"
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, name)
+		}
+		args := ProgramArgs{outputDir: dir, reportFile: filepath.Join(dir, "report.json")}
+		if err := writePackageReport(args, same, names, unitFiles); err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadFile(args.reportFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r report
+		if err := json.Unmarshal(b, &r); err != nil {
+			t.Fatal(err)
+		}
+		if len(r.Diagnostics) != 1 ||
+			r.Diagnostics[0].GoFile != filepath.Join(dir, "util_1.go") {
+			t.Errorf("not valid Go file of diagnostic: %#v", r.Diagnostics)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package noarch
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Creal - real part of complex number.
+func Creal(z complex128) float64 {
+	return real(z)
+}
+
+// Cimag - imaginary part of complex number.
+func Cimag(z complex128) float64 {
+	return imag(z)
+}
+
+// Cproj - projection of complex number onto the Riemann sphere.
+func Cproj(z complex128) complex128 {
+	if !cmplx.IsInf(z) {
+		return z
+	}
+	return complex(math.Inf(1), math.Copysign(0, imag(z)))
+}
+
+// Crealf - real part of complex number.
+func Crealf(z complex64) float32 {
+	return real(z)
+}
+
+// Cimagf - imaginary part of complex number.
+func Cimagf(z complex64) float32 {
+	return imag(z)
+}
+
+// Cabsf - absolute value of complex number.
+func Cabsf(z complex64) float32 {
+	return float32(cmplx.Abs(complex128(z)))
+}
+
+// Cargf - argument (phase angle) of complex number.
+func Cargf(z complex64) float32 {
+	return float32(cmplx.Phase(complex128(z)))
+}
+
+// Conjf - complex conjugate.
+func Conjf(z complex64) complex64 {
+	return complex(real(z), -imag(z))
+}
+
+// Cprojf - projection of complex number onto the Riemann sphere.
+func Cprojf(z complex64) complex64 {
+	return complex64(Cproj(complex128(z)))
+}
+
+// Cpowf - complex power function.
+func Cpowf(x, y complex64) complex64 {
+	return complex64(cmplx.Pow(complex128(x), complex128(y)))
+}
+
+// Cexpf - complex base-e exponential.
+func Cexpf(z complex64) complex64 {
+	return complex64(cmplx.Exp(complex128(z)))
+}
+
+// Clogf - complex natural logarithm.
+func Clogf(z complex64) complex64 {
+	return complex64(cmplx.Log(complex128(z)))
+}
+
+// Csqrtf - complex square root.
+func Csqrtf(z complex64) complex64 {
+	return complex64(cmplx.Sqrt(complex128(z)))
+}
+
+// Csinf - complex sine.
+func Csinf(z complex64) complex64 {
+	return complex64(cmplx.Sin(complex128(z)))
+}
+
+// Ccosf - complex cosine.
+func Ccosf(z complex64) complex64 {
+	return complex64(cmplx.Cos(complex128(z)))
+}
+
+// Ctanf - complex tangent.
+func Ctanf(z complex64) complex64 {
+	return complex64(cmplx.Tan(complex128(z)))
+}
+
+// Casinf - complex arc sine.
+func Casinf(z complex64) complex64 {
+	return complex64(cmplx.Asin(complex128(z)))
+}
+
+// Cacosf - complex arc cosine.
+func Cacosf(z complex64) complex64 {
+	return complex64(cmplx.Acos(complex128(z)))
+}
+
+// Catanf - complex arc tangent.
+func Catanf(z complex64) complex64 {
+	return complex64(cmplx.Atan(complex128(z)))
+}
+
+// Csinhf - complex hyperbolic sine.
+func Csinhf(z complex64) complex64 {
+	return complex64(cmplx.Sinh(complex128(z)))
+}
+
+// Ccoshf - complex hyperbolic cosine.
+func Ccoshf(z complex64) complex64 {
+	return complex64(cmplx.Cosh(complex128(z)))
+}
+
+// Ctanhf - complex hyperbolic tangent.
+func Ctanhf(z complex64) complex64 {
+	return complex64(cmplx.Tanh(complex128(z)))
+}
+
+// Casinhf - complex arc hyperbolic sine.
+func Casinhf(z complex64) complex64 {
+	return complex64(cmplx.Asinh(complex128(z)))
+}
+
+// Cacoshf - complex arc hyperbolic cosine.
+func Cacoshf(z complex64) complex64 {
+	return complex64(cmplx.Acosh(complex128(z)))
+}
+
+// Catanhf - complex arc hyperbolic tangent.
+func Catanhf(z complex64) complex64 {
+	return complex64(cmplx.Atanh(complex128(z)))
+}
@@ -0,0 +1,29 @@
+package noarch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComplex(t *testing.T) {
+	z := complex64(complex(3, 4))
+	if Cabsf(z) != 5 {
+		t.Errorf("not valid Cabsf: %v", Cabsf(z))
+	}
+	if Conjf(z) != complex(3, -4) {
+		t.Errorf("not valid Conjf: %v", Conjf(z))
+	}
+	if Crealf(z) != 3 || Cimagf(z) != 4 {
+		t.Errorf("not valid parts: %v %v", Crealf(z), Cimagf(z))
+	}
+	if s := Csqrtf(-4); s != complex(0, 2) {
+		t.Errorf("not valid Csqrtf: %v", s)
+	}
+	if p := Cproj(complex(3, 4)); p != complex(3, 4) {
+		t.Errorf("not valid Cproj of finite value: %v", p)
+	}
+	p := Cproj(complex(math.Inf(-1), -2))
+	if !math.IsInf(real(p), 1) || imag(p) != 0 || !math.Signbit(imag(p)) {
+		t.Errorf("not valid Cproj of infinite value: %v", p)
+	}
+}
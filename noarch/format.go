@@ -0,0 +1,932 @@
+package noarch
+
+import (
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// formatSpec is conversion specification of C format string:
+//
+//	%[flags][width][.precision][length]conversion
+type formatSpec struct {
+	minus, plus, space, hash, zero bool
+
+	width     int // -1, if not defined
+	precision int // -1, if not defined
+	length    string
+	verb      byte
+}
+
+// formatArgs is list of arguments of printf functions.
+type formatArgs struct {
+	args []interface{}
+	pos  int
+}
+
+func (a *formatArgs) next() interface{} {
+	if a.pos >= len(a.args) {
+		return nil
+	}
+	arg := a.args[a.pos]
+	a.pos++
+	return arg
+}
+
+// cFormat returns result of C format string with arguments as printf
+// functions in C99.
+func cFormat(format []byte, args []interface{}) (out []byte) {
+	list := formatArgs{args: args}
+	for i := 0; i < len(format) && format[i] != 0; i++ {
+		if format[i] != '%' {
+			out = append(out, format[i])
+			continue
+		}
+		start := i
+		var spec formatSpec
+		spec, i = parseFormatSpec(format, i+1, &list)
+		if i >= len(format) || format[i] == 0 {
+			out = append(out, format[start:i]...)
+			break
+		}
+
+		switch spec.verb {
+		case '%':
+			out = append(out, '%')
+
+		case 'd', 'i':
+			v := formatSignedArg(list.next(), spec.length)
+			neg := v < 0
+			mag := uint64(v)
+			if neg {
+				mag = -mag
+			}
+			out = append(out, formatInteger(spec, neg, mag, 10)...)
+
+		case 'u', 'o', 'x', 'X':
+			v := formatUnsignedArg(list.next(), spec.length)
+			base := 10
+			switch spec.verb {
+			case 'o':
+				base = 8
+			case 'x', 'X':
+				base = 16
+			}
+			out = append(out, formatInteger(spec, false, v, base)...)
+
+		case 'f', 'F', 'e', 'E', 'g', 'G', 'a', 'A':
+			out = append(out, formatFloat(spec, formatFloatArg(list.next()))...)
+
+		case 'c':
+			v := formatUnsignedArg(list.next(), "")
+			var b []byte
+			if spec.length == "l" {
+				b = []byte(string(rune(v)))
+			} else {
+				b = []byte{byte(v)}
+			}
+			out = append(out, formatPad(spec, b)...)
+
+		case 's':
+			out = append(out, formatPad(spec, formatStringArg(list.next(), spec))...)
+
+		case 'p':
+			spec.zero = false
+			out = append(out, formatPad(spec, formatPointerArg(list.next()))...)
+
+		case 'n':
+			setValue(list.next(), int64(len(out)))
+
+		default:
+			// not valid conversion specification
+			out = append(out, format[start:i+1]...)
+		}
+	}
+	return
+}
+
+// parseFormatSpec parse conversion specification after symbol `%`.
+// Returns position of conversion symbol.
+func parseFormatSpec(format []byte, i int, list *formatArgs) (spec formatSpec, _ int) {
+	spec.width = -1
+	spec.precision = -1
+
+	// flags
+flags:
+	for ; i < len(format); i++ {
+		switch format[i] {
+		case '-':
+			spec.minus = true
+		case '+':
+			spec.plus = true
+		case ' ':
+			spec.space = true
+		case '#':
+			spec.hash = true
+		case '0':
+			spec.zero = true
+		default:
+			break flags
+		}
+	}
+
+	// width
+	if i < len(format) && format[i] == '*' {
+		spec.width = int(formatSignedArg(list.next(), ""))
+		if spec.width < 0 {
+			spec.minus = true
+			spec.width = -spec.width
+		}
+		i++
+	} else {
+		for ; i < len(format) && '0' <= format[i] && format[i] <= '9'; i++ {
+			if spec.width < 0 {
+				spec.width = 0
+			}
+			spec.width = spec.width*10 + int(format[i]-'0')
+		}
+	}
+
+	// precision
+	if i < len(format) && format[i] == '.' {
+		i++
+		spec.precision = 0
+		if i < len(format) && format[i] == '*' {
+			spec.precision = int(formatSignedArg(list.next(), ""))
+			if spec.precision < 0 {
+				spec.precision = -1
+			}
+			i++
+		} else {
+			for ; i < len(format) && '0' <= format[i] && format[i] <= '9'; i++ {
+				spec.precision = spec.precision*10 + int(format[i]-'0')
+			}
+		}
+	}
+
+	spec.length, i = parseLengthModifier(format, i)
+
+	if i < len(format) {
+		spec.verb = format[i]
+	}
+	return spec, i
+}
+
+// parseLengthModifier parse length modifier of conversion specification.
+// Returns position after modifier.
+func parseLengthModifier(format []byte, i int) (string, int) {
+	for _, l := range []string{"hh", "h", "ll", "l", "j", "z", "t", "L", "q"} {
+		if strings.HasPrefix(string(format[i:]), l) {
+			return l, i + len(l)
+		}
+	}
+	return "", i
+}
+
+// formatInteger returns integer in C format.
+func formatInteger(spec formatSpec, neg bool, mag uint64, base int) []byte {
+	digits := strconv.FormatUint(mag, base)
+	if spec.verb == 'X' {
+		digits = strings.ToUpper(digits)
+	}
+	if spec.precision == 0 && mag == 0 {
+		digits = ""
+	}
+	if len(digits) < spec.precision {
+		digits = strings.Repeat("0", spec.precision-len(digits)) + digits
+	}
+	if spec.hash && spec.verb == 'o' && (digits == "" || digits[0] != '0') {
+		digits = "0" + digits
+	}
+
+	var prefix string
+	switch {
+	case neg:
+		prefix = "-"
+	case spec.plus && (spec.verb == 'd' || spec.verb == 'i'):
+		prefix = "+"
+	case spec.space && (spec.verb == 'd' || spec.verb == 'i'):
+		prefix = " "
+	}
+	if spec.hash && mag != 0 {
+		switch spec.verb {
+		case 'x':
+			prefix += "0x"
+		case 'X':
+			prefix += "0X"
+		}
+	}
+
+	if spec.zero && !spec.minus && spec.precision < 0 {
+		if size := len(prefix) + len(digits); size < spec.width {
+			digits = strings.Repeat("0", spec.width-size) + digits
+		}
+	}
+	return formatPad(spec, []byte(prefix+digits))
+}
+
+// formatFloat returns floating point value in C format.
+func formatFloat(spec formatSpec, v float64) []byte {
+	verb := spec.verb | 0x20 // lower case
+	upper := spec.verb != verb
+
+	var prefix string
+	switch {
+	case math.Signbit(v):
+		prefix = "-"
+		v = -v
+	case spec.plus:
+		prefix = "+"
+	case spec.space:
+		prefix = " "
+	}
+
+	var body string
+	finite := !math.IsNaN(v) && !math.IsInf(v, 0)
+	switch {
+	case math.IsNaN(v):
+		body = "nan"
+	case math.IsInf(v, 0):
+		body = "inf"
+	default:
+		prec := spec.precision
+		if prec < 0 && verb != 'a' {
+			prec = 6
+		}
+		switch verb {
+		case 'f':
+			body = strconv.FormatFloat(v, 'f', prec, 64)
+		case 'e':
+			body = strconv.FormatFloat(v, 'e', prec, 64)
+		case 'g':
+			body = formatFloatG(v, prec, spec.hash)
+		case 'a':
+			body = strconv.FormatFloat(v, 'x', prec, 64)
+			// exponent without leading zeros
+			p := strings.IndexByte(body, 'p')
+			exp := strings.TrimLeft(body[p+2:], "0")
+			if exp == "" {
+				exp = "0"
+			}
+			body = body[:p+2] + exp
+			// hexadecimal prefix is before zero padding
+			prefix += body[:2]
+			body = body[2:]
+		}
+		if spec.hash && !strings.Contains(body, ".") {
+			if e := strings.IndexAny(body, "ep"); e >= 0 {
+				body = body[:e] + "." + body[e:]
+			} else {
+				body += "."
+			}
+		}
+	}
+	if upper {
+		prefix = strings.ToUpper(prefix)
+		body = strings.ToUpper(body)
+	}
+
+	if finite && spec.zero && !spec.minus {
+		if size := len(prefix) + len(body); size < spec.width {
+			body = strings.Repeat("0", spec.width-size) + body
+		}
+	}
+	return formatPad(spec, []byte(prefix+body))
+}
+
+// formatFloatG returns floating point value for conversion `%g`.
+func formatFloatG(v float64, prec int, hash bool) (body string) {
+	if prec == 0 {
+		prec = 1
+	}
+	e := strconv.FormatFloat(v, 'e', prec-1, 64)
+	var exp int
+	if v != 0 {
+		exp, _ = strconv.Atoi(e[strings.IndexByte(e, 'e')+1:])
+	}
+	if exp < prec && -4 <= exp {
+		body = strconv.FormatFloat(v, 'f', prec-1-exp, 64)
+	} else {
+		body = e
+	}
+	if hash {
+		return
+	}
+	// remove trailing zeros of fractional part
+	mantissa, exponent := body, ""
+	if e := strings.IndexByte(body, 'e'); e >= 0 {
+		mantissa, exponent = body[:e], body[e:]
+	}
+	if strings.Contains(mantissa, ".") {
+		mantissa = strings.TrimRight(mantissa, "0")
+		mantissa = strings.TrimSuffix(mantissa, ".")
+	}
+	return mantissa + exponent
+}
+
+// formatPad returns value with spaces up to width of conversion.
+func formatPad(spec formatSpec, b []byte) []byte {
+	if len(b) >= spec.width {
+		return b
+	}
+	padding := []byte(strings.Repeat(" ", spec.width-len(b)))
+	if spec.minus {
+		return append(b, padding...)
+	}
+	return append(padding, b...)
+}
+
+// formatSignedArg returns value of signed integer argument with length
+// modifier.
+func formatSignedArg(arg interface{}, length string) int64 {
+	v := integerValue(arg)
+	switch length {
+	case "hh":
+		return int64(int8(v))
+	case "h":
+		return int64(int16(v))
+	case "":
+		return int64(int32(v))
+	}
+	return v
+}
+
+// formatUnsignedArg returns value of unsigned integer argument with length
+// modifier.
+func formatUnsignedArg(arg interface{}, length string) uint64 {
+	v := uint64(integerValue(arg))
+	switch length {
+	case "hh":
+		return uint64(uint8(v))
+	case "h":
+		return uint64(uint16(v))
+	case "":
+		return uint64(uint32(v))
+	}
+	return v
+}
+
+// integerValue returns value of any integer argument.
+func integerValue(arg interface{}) int64 {
+	if arg == nil {
+		return 0
+	}
+	v := reflect.ValueOf(arg)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return int64(v.Float())
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+	case reflect.Slice, reflect.Ptr, reflect.UnsafePointer, reflect.Func:
+		return int64(v.Pointer())
+	}
+	return 0
+}
+
+// formatFloatArg returns value of floating point argument.
+func formatFloatArg(arg interface{}) float64 {
+	if arg == nil {
+		return 0
+	}
+	v := reflect.ValueOf(arg)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return float64(integerValue(arg))
+}
+
+// formatStringArg returns C string argument limited by precision.
+func formatStringArg(arg interface{}, spec formatSpec) (b []byte) {
+	switch v := arg.(type) {
+	case nil:
+		b = []byte("(null)")
+		if 0 <= spec.precision && spec.precision < len(b) {
+			b = nil
+		}
+		return
+	case []byte:
+		if v == nil {
+			return formatStringArg(nil, spec)
+		}
+		for _, c := range v {
+			if c == 0 {
+				break
+			}
+			b = append(b, c)
+		}
+	case string:
+		b = []byte(CStringToString([]byte(v)))
+	case []rune:
+		for _, r := range v {
+			if r == 0 {
+				break
+			}
+			b = append(b, string(r)...)
+		}
+	default:
+		b = []byte(CStringToString([]byte(reflectString(arg))))
+	}
+	if 0 <= spec.precision && spec.precision < len(b) {
+		b = b[:spec.precision]
+		if spec.length == "l" {
+			// do not cut multibyte characters
+			for len(b) > 0 && !utf8.Valid(b) {
+				b = b[:len(b)-1]
+			}
+		}
+	}
+	return
+}
+
+func reflectString(arg interface{}) string {
+	v := reflect.ValueOf(arg)
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return string(v.Bytes())
+	}
+	return ""
+}
+
+// formatPointerArg returns pointer argument as in glibc.
+func formatPointerArg(arg interface{}) []byte {
+	if arg == nil {
+		return []byte("(nil)")
+	}
+	v := reflect.ValueOf(arg)
+	var address uint64
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return []byte("(nil)")
+		}
+		if v.Len() > 0 {
+			address = uint64(v.Index(0).Addr().Pointer())
+		} else {
+			address = uint64(v.Pointer())
+		}
+	default:
+		address = uint64(integerValue(arg))
+	}
+	if address == 0 {
+		return []byte("(nil)")
+	}
+	return []byte("0x" + strconv.FormatUint(address, 16))
+}
+
+// setValue set integer or floating value to C pointer: slice or Go pointer.
+func setValue(ptr interface{}, value interface{}) bool {
+	if ptr == nil {
+		return false
+	}
+	v := reflect.ValueOf(ptr)
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return false
+		}
+		v = v.Index(0)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	default:
+		return false
+	}
+
+	switch x := value.(type) {
+	case int64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v.SetInt(x)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Uintptr:
+			v.SetUint(uint64(x))
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(x))
+		default:
+			return false
+		}
+	case float64:
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(x)
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// scanState is state of reading input of scanf functions.
+type scanState struct {
+	r     io.ByteScanner
+	count int  // amount of read bytes
+	eof   bool // input failure
+}
+
+func (s *scanState) read() int {
+	b, err := s.r.ReadByte()
+	if err != nil {
+		s.eof = true
+		return -1
+	}
+	s.count++
+	return int(b)
+}
+
+func (s *scanState) unread() {
+	if s.r.UnreadByte() == nil {
+		s.count--
+	}
+}
+
+// peek returns next byte of input without reading.
+func (s *scanState) peek() int {
+	b := s.read()
+	if b >= 0 {
+		s.unread()
+	}
+	return b
+}
+
+func (s *scanState) skipSpaces() {
+	for {
+		b := s.read()
+		if b < 0 {
+			return
+		}
+		if !isCSpace(b) {
+			s.unread()
+			return
+		}
+	}
+}
+
+func isCSpace(b int) bool {
+	switch b {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
+}
+
+// cScan reads input as scanf functions in C99 and stores values to
+// arguments. Returns amount of assigned arguments or EOF (-1) if input
+// failure occurs before the first conversion.
+func cScan(r io.ByteScanner, format []byte, args []interface{}) int32 {
+	s := scanState{r: r}
+	list := formatArgs{args: args}
+	var assigned int32
+	var converted bool
+
+	failure := func() int32 {
+		if s.eof && !converted {
+			return -1
+		}
+		return assigned
+	}
+
+	for i := 0; i < len(format) && format[i] != 0; i++ {
+		c := format[i]
+		if isCSpace(int(c)) {
+			s.skipSpaces()
+			continue
+		}
+		if c != '%' {
+			b := s.read()
+			if b < 0 {
+				return failure()
+			}
+			if b != int(c) {
+				s.unread()
+				return assigned
+			}
+			continue
+		}
+
+		// conversion specification
+		i++
+		suppress := false
+		if i < len(format) && format[i] == '*' {
+			suppress = true
+			i++
+		}
+		width := 0
+		for ; i < len(format) && '0' <= format[i] && format[i] <= '9'; i++ {
+			width = width*10 + int(format[i]-'0')
+		}
+		// type of value is defined by argument
+		_, i = parseLengthModifier(format, i)
+		if i >= len(format) {
+			return assigned
+		}
+		verb := format[i]
+
+		var set [256]bool
+		if verb == '[' {
+			i = parseScanSet(format, i+1, &set)
+		}
+
+		if verb != '[' && verb != 'c' && verb != 'n' {
+			s.skipSpaces()
+		}
+
+		var value interface{}
+		switch verb {
+		case '%':
+			b := s.read()
+			if b < 0 {
+				return failure()
+			}
+			if b != '%' {
+				s.unread()
+				return assigned
+			}
+			continue
+
+		case 'n':
+			if !suppress {
+				setValue(list.next(), int64(s.count))
+			}
+			continue
+
+		case 'd', 'i', 'u', 'o', 'x', 'X', 'p':
+			base := 10
+			switch verb {
+			case 'i':
+				base = 0
+			case 'o':
+				base = 8
+			case 'x', 'X', 'p':
+				base = 16
+			}
+			v, ok := scanInteger(&s, width, base)
+			if !ok {
+				return failure()
+			}
+			value = v
+
+		case 'a', 'A', 'e', 'E', 'f', 'F', 'g', 'G':
+			v, ok := scanFloat(&s, width)
+			if !ok {
+				return failure()
+			}
+			value = v
+
+		case 's', 'c', '[':
+			if width == 0 {
+				width = -1
+				if verb == 'c' {
+					width = 1
+				}
+			}
+			var b []byte
+			for width != 0 {
+				ch := s.read()
+				if ch < 0 {
+					break
+				}
+				if (verb == 's' && isCSpace(ch)) || (verb == '[' && !set[ch]) {
+					s.unread()
+					break
+				}
+				b = append(b, byte(ch))
+				width--
+			}
+			if len(b) == 0 || (verb == 'c' && width > 0) {
+				return failure()
+			}
+			if verb != 'c' {
+				b = append(b, 0)
+			}
+			value = b
+
+		default:
+			return assigned
+		}
+
+		converted = true
+		if suppress {
+			continue
+		}
+		arg := list.next()
+		var ok bool
+		if b, isBytes := value.([]byte); isBytes {
+			if dst, isBytes := arg.([]byte); isBytes {
+				copy(dst, b)
+				ok = true
+			}
+		} else {
+			ok = setValue(arg, value)
+		}
+		if ok {
+			assigned++
+		}
+	}
+	return assigned
+}
+
+// parseScanSet parse scan set of conversion `%[`. Returns position of
+// symbol `]`.
+func parseScanSet(format []byte, i int, set *[256]bool) int {
+	negate := false
+	if i < len(format) && format[i] == '^' {
+		negate = true
+		i++
+	}
+	start := i
+	for ; i < len(format) && format[i] != 0; i++ {
+		c := format[i]
+		if c == ']' && i != start {
+			break
+		}
+		if c == '-' && i != start && i+1 < len(format) && format[i+1] != ']' {
+			for x := int(format[i-1]); x <= int(format[i+1]); x++ {
+				set[x] = true
+			}
+			i++
+			continue
+		}
+		set[c] = true
+	}
+	if negate {
+		for x := range set {
+			set[x] = !set[x]
+		}
+	}
+	return i
+}
+
+// scanInteger reads integer as strtol with base. Width 0 is unlimited
+// width.
+func scanInteger(s *scanState, width, base int) (value int64, ok bool) {
+	if width == 0 {
+		width = -1
+	}
+	read := func() int {
+		if width == 0 {
+			return -1
+		}
+		b := s.read()
+		if b >= 0 {
+			width--
+		}
+		return b
+	}
+	unread := func(b int) {
+		if b >= 0 {
+			s.unread()
+			width++
+		}
+	}
+
+	neg := false
+	b := read()
+	if b == '-' || b == '+' {
+		neg = b == '-'
+		b = read()
+	}
+
+	var digits []byte
+	if b == '0' && (base == 0 || base == 16) {
+		digits = append(digits, '0')
+		b = read()
+		if b == 'x' || b == 'X' {
+			base = 16
+			b = read()
+		} else if base == 0 {
+			base = 8
+		}
+	}
+	if base == 0 {
+		base = 10
+	}
+	for b >= 0 && digitValue(b) < base {
+		digits = append(digits, byte(b))
+		b = read()
+	}
+	unread(b)
+	if len(digits) == 0 {
+		return 0, false
+	}
+
+	mag, err := strconv.ParseUint(string(digits), base, 64)
+	if err != nil {
+		mag = math.MaxUint64
+	}
+	value = int64(mag)
+	if neg {
+		value = -value
+	}
+	return value, true
+}
+
+func digitValue(b int) int {
+	switch {
+	case '0' <= b && b <= '9':
+		return b - '0'
+	case 'a' <= b && b <= 'z':
+		return b - 'a' + 10
+	case 'A' <= b && b <= 'Z':
+		return b - 'A' + 10
+	}
+	return 36
+}
+
+// scanFloat reads floating point value as strtod. Width 0 is unlimited
+// width.
+func scanFloat(s *scanState, width int) (value float64, ok bool) {
+	if width == 0 {
+		width = -1
+	}
+	var token []byte
+	read := func() int {
+		if width == 0 {
+			return -1
+		}
+		b := s.read()
+		if b >= 0 {
+			width--
+		}
+		return b
+	}
+	accept := func(check func(b int) bool) bool {
+		if width == 0 {
+			return false
+		}
+		b := s.peek()
+		if b < 0 || !check(b) {
+			return false
+		}
+		token = append(token, byte(read()))
+		return true
+	}
+	is := func(chars string) func(b int) bool {
+		return func(b int) bool {
+			return strings.IndexByte(chars, byte(b)) >= 0
+		}
+	}
+	word := func(w string) bool {
+		for i := range w {
+			if !accept(is(w[i:i+1] + strings.ToUpper(w[i:i+1]))) {
+				return false
+			}
+		}
+		return true
+	}
+
+	accept(is("+-"))
+	switch {
+	case accept(is("iI")):
+		if !word("nf") {
+			return 0, false
+		}
+		word("inity")
+	case accept(is("nN")):
+		if !word("an") {
+			return 0, false
+		}
+	default:
+		digits := is("0123456789")
+		exponent := "eE"
+		if accept(is("0")) && accept(is("xX")) {
+			digits = func(b int) bool { return digitValue(b) < 16 }
+			exponent = "pP"
+		}
+		for accept(digits) {
+		}
+		if accept(is(".")) {
+			for accept(digits) {
+			}
+		}
+		if accept(is(exponent)) {
+			accept(is("+-"))
+			for accept(is("0123456789")) {
+			}
+		}
+	}
+
+	str := string(token)
+	if lower := strings.ToLower(str); strings.Contains(lower, "0x") &&
+		!strings.Contains(lower, "p") {
+		str += "p0"
+	}
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		if ne, isNumErr := err.(*strconv.NumError); !isNumErr || ne.Err != strconv.ErrRange {
+			return 0, false
+		}
+	}
+	return value, true
+}
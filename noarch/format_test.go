@@ -0,0 +1,146 @@
+package noarch
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestCFormat(t *testing.T) {
+	n := make([]int32, 1)
+	tcs := []struct {
+		format string
+		args   []interface{}
+		out    string
+	}{
+		{"%i|%d", []interface{}{int32(-42), int32(7)}, "-42|7"},
+		{"%*d|%-*d|", []interface{}{int32(5), int32(42), int32(4), int32(1)}, "   42|1   |"},
+		{"%.*f", []interface{}{int32(2), 3.14159}, "3.14"},
+		{"%hhu", []interface{}{int32(257)}, "1"},
+		{"%hd", []interface{}{int32(65537)}, "1"},
+		{"%u", []interface{}{int32(-1)}, "4294967295"},
+		{"%lu", []interface{}{int64(-1)}, "18446744073709551615"},
+		{"%Lf", []interface{}{1.5}, "1.500000"},
+		{"%lf", []interface{}{float32(0.25)}, "0.250000"},
+		{"%a", []interface{}{1.0}, "0x1p+0"},
+		{"%A", []interface{}{0.5}, "0X1P-1"},
+		{"%g", []interface{}{100000.0}, "100000"},
+		{"%g", []interface{}{1000000.0}, "1e+06"},
+		{"%g", []interface{}{0.0001}, "0.0001"},
+		{"%g", []interface{}{0.00001}, "1e-05"},
+		{"%g", []interface{}{2.5}, "2.5"},
+		{"%#g", []interface{}{2.0}, "2.00000"},
+		{"%.3g", []interface{}{3.14159}, "3.14"},
+		{"%#x|%#o|%X", []interface{}{int32(255), int32(8), int32(255)}, "0xff|010|FF"},
+		{"%#x", []interface{}{int32(0)}, "0"},
+		{"%+.3e", []interface{}{12345.678}, "+1.235e+04"},
+		{"%e", []interface{}{0.0}, "0.000000e+00"},
+		{"%-5s|%5s|%.2s", []interface{}{[]byte("ab\x00"), []byte("cd\x00"), []byte("xyz\x00")}, "ab   |   cd|xy"},
+		{"%05d|% d|%+d", []interface{}{int32(-42), int32(1), int32(1)}, "-0042| 1|+1"},
+		{"%.0f|%.0f", []interface{}{2.5, 3.5}, "2|4"},
+		{"%f|%F|%f", []interface{}{math.Inf(1), math.Inf(-1), math.NaN()}, "inf|-INF|nan"},
+		{"%5.1f|%-6.2f|", []interface{}{3.14159, 2.0}, "  3.1|2.00  |"},
+		{"%c%c", []interface{}{int32('o'), byte('k')}, "ok"},
+		{"%.3d", []interface{}{int32(7)}, "007"},
+		{"%.0d", []interface{}{int32(0)}, ""},
+		{"100%%", nil, "100%"},
+		{"%s", []interface{}{nil}, "(null)"},
+		{"abc%n", []interface{}{n}, "abc"},
+		{"%y", nil, "%y"},
+	}
+	for _, tc := range tcs {
+		out := string(cFormat([]byte(tc.format), tc.args))
+		if out != tc.out {
+			t.Errorf("format %q: %q != %q", tc.format, out, tc.out)
+		}
+	}
+	if n[0] != 3 {
+		t.Errorf("not valid value of %%n: %d", n[0])
+	}
+}
+
+func TestCScan(t *testing.T) {
+	{
+		name := make([]byte, 10)
+		rest := make([]byte, 10)
+		if r := cScan(bytes.NewReader([]byte("abc,def")), []byte("%[^,],%s"),
+			[]interface{}{name, rest}); r != 2 {
+			t.Fatalf("not valid result: %d", r)
+		}
+		if s := CStringToString(name); s != "abc" {
+			t.Errorf("not valid scan set: %q", s)
+		}
+		if s := CStringToString(rest); s != "def" {
+			t.Errorf("not valid string: %q", s)
+		}
+	}
+	{
+		a, b, c := make([]int32, 1), make([]int32, 1), make([]int32, 1)
+		n := make([]int32, 1)
+		if r := cScan(bytes.NewReader([]byte(" 1 2 0x1f 017")), []byte("%*d %d %i %i%n"),
+			[]interface{}{a, b, c, n}); r != 3 {
+			t.Fatalf("not valid result: %d", r)
+		}
+		if a[0] != 2 || b[0] != 31 || c[0] != 15 || n[0] != 13 {
+			t.Errorf("not valid values: %d %d %d %d", a[0], b[0], c[0], n[0])
+		}
+	}
+	{
+		ch := make([]byte, 2)
+		f := make([]float64, 1)
+		if r := cScan(bytes.NewReader([]byte("xy 2.5e1")), []byte("%2c%lf"),
+			[]interface{}{ch, f}); r != 2 {
+			t.Fatalf("not valid result: %d", r)
+		}
+		if string(ch) != "xy" || f[0] != 25 {
+			t.Errorf("not valid values: %q %v", ch, f[0])
+		}
+	}
+	{
+		a := make([]int32, 1)
+		if r := cScan(bytes.NewReader([]byte("abc")), []byte("%d"),
+			[]interface{}{a}); r != 0 {
+			t.Errorf("matching failure must return 0: %d", r)
+		}
+		if r := cScan(bytes.NewReader(nil), []byte("%d"),
+			[]interface{}{a}); r != -1 {
+			t.Errorf("input failure must return EOF: %d", r)
+		}
+	}
+}
+
+func TestSnprintf(t *testing.T) {
+	buf := []byte("xxxxxxxx")
+	if n := Snprintf(buf, 4, []byte("%d-%s\x00"), int32(12), []byte("ab\x00")); n != 5 {
+		t.Errorf("not valid length: %d", n)
+	}
+	if string(buf) != "12-\x00xxxx" {
+		t.Errorf("not valid buffer: %q", buf)
+	}
+}
+
+func TestFscanfUnread(t *testing.T) {
+	tmp, err := ioutil.TempFile("", "c4go-fscanf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString("42;rest"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmp.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer tmp.Close()
+
+	f := NewFile(tmp)
+	a := make([]int32, 1)
+	if r := Fscanf(f, []byte("%d\x00"), a); r != 1 || a[0] != 42 {
+		t.Fatalf("not valid result: %d %d", r, a[0])
+	}
+	if c := Fgetc(f); c != ';' {
+		t.Errorf("byte after number is lost: %q", c)
+	}
+}
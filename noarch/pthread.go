@@ -0,0 +1,311 @@
+package noarch
+
+import (
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// PthreadT is identifier of thread. Each thread is goroutine.
+type PthreadT uint64
+
+// PthreadAttrT is attributes of thread.
+type PthreadAttrT struct {
+	detachState int32
+}
+
+// PthreadMutexT is mutex based on sync.Mutex. Zero value is unlocked mutex,
+// so PTHREAD_MUTEX_INITIALIZER is zero value.
+type PthreadMutexT struct {
+	mu sync.Mutex
+}
+
+// PthreadMutexattrT is attributes of mutex. Attributes are ignored.
+type PthreadMutexattrT struct{}
+
+// PthreadCondT is condition variable based on sync.Cond. Zero value is
+// ready for use, so PTHREAD_COND_INITIALIZER is zero value.
+type PthreadCondT struct {
+	once sync.Once
+	mu   sync.Mutex
+	cond *sync.Cond
+}
+
+// PthreadCondattrT is attributes of condition variable. Attributes are
+// ignored.
+type PthreadCondattrT struct{}
+
+// PthreadOnceT is control of once initialization.
+type PthreadOnceT int32
+
+// Values of detach state of thread attributes from pthread.h
+const (
+	PthreadCreateJoinable int32 = 0
+	PthreadCreateDetached int32 = 1
+)
+
+type thread struct {
+	done  chan struct{}
+	value interface{}
+}
+
+// pthreadExit is value of panic created by PthreadExit.
+type pthreadExit struct {
+	value interface{}
+}
+
+// threads is joinable threads. As in C, thread is removed by join or
+// detach, detached threads are not added.
+var (
+	threadsMutex sync.Mutex
+	threads      = map[PthreadT]*thread{}
+	threadLast   PthreadT
+)
+
+// PthreadCreate - create a new thread.
+func PthreadCreate(tid []PthreadT, attr []PthreadAttrT,
+	start func(interface{}) interface{}, arg interface{}) int32 {
+	if start == nil {
+		return int32(syscall.EINVAL)
+	}
+	t := &thread{done: make(chan struct{})}
+
+	threadsMutex.Lock()
+	threadLast++
+	id := threadLast
+	if len(attr) == 0 || attr[0].detachState != PthreadCreateDetached {
+		threads[id] = t
+	}
+	threadsMutex.Unlock()
+
+	if len(tid) > 0 {
+		tid[0] = id
+	}
+
+	go func() {
+		defer close(t.done)
+		defer func() {
+			if r := recover(); r != nil {
+				e, ok := r.(pthreadExit)
+				if !ok {
+					panic(r)
+				}
+				t.value = e.value
+			}
+		}()
+		t.value = start(arg)
+	}()
+	return 0
+}
+
+// PthreadJoin - join with a terminated thread.
+func PthreadJoin(tid PthreadT, value []interface{}) int32 {
+	threadsMutex.Lock()
+	t, ok := threads[tid]
+	delete(threads, tid)
+	threadsMutex.Unlock()
+	if !ok {
+		return int32(syscall.ESRCH)
+	}
+
+	<-t.done
+	if len(value) > 0 {
+		value[0] = t.value
+	}
+	return 0
+}
+
+// PthreadDetach - detach a thread.
+func PthreadDetach(tid PthreadT) int32 {
+	threadsMutex.Lock()
+	defer threadsMutex.Unlock()
+	if _, ok := threads[tid]; !ok {
+		return int32(syscall.ESRCH)
+	}
+	delete(threads, tid)
+	return 0
+}
+
+// PthreadExit - terminate calling thread. Function cannot be called in
+// main thread.
+func PthreadExit(value interface{}) {
+	panic(pthreadExit{value: value})
+}
+
+// PthreadEqual - compare thread IDs.
+func PthreadEqual(t1, t2 PthreadT) int32 {
+	if t1 == t2 {
+		return 1
+	}
+	return 0
+}
+
+// PthreadAttrInit - initialize thread attributes object.
+func PthreadAttrInit(attr []PthreadAttrT) int32 {
+	attr[0] = PthreadAttrT{}
+	return 0
+}
+
+// PthreadAttrDestroy - destroy thread attributes object.
+func PthreadAttrDestroy(attr []PthreadAttrT) int32 {
+	return 0
+}
+
+// PthreadAttrSetdetachstate - set detach state attribute in thread
+// attributes object.
+func PthreadAttrSetdetachstate(attr []PthreadAttrT, state int32) int32 {
+	if state != PthreadCreateJoinable && state != PthreadCreateDetached {
+		return int32(syscall.EINVAL)
+	}
+	attr[0].detachState = state
+	return 0
+}
+
+// PthreadAttrGetdetachstate - get detach state attribute in thread
+// attributes object.
+func PthreadAttrGetdetachstate(attr []PthreadAttrT, state []int32) int32 {
+	state[0] = attr[0].detachState
+	return 0
+}
+
+// PthreadMutexInit - initialize a mutex.
+func PthreadMutexInit(mutex []PthreadMutexT, attr []PthreadMutexattrT) int32 {
+	mutex[0] = PthreadMutexT{}
+	return 0
+}
+
+// PthreadMutexDestroy - destroy a mutex.
+func PthreadMutexDestroy(mutex []PthreadMutexT) int32 {
+	return 0
+}
+
+// PthreadMutexLock - lock a mutex.
+func PthreadMutexLock(mutex []PthreadMutexT) int32 {
+	mutex[0].mu.Lock()
+	return 0
+}
+
+// PthreadMutexTrylock - try lock a mutex without blocking.
+func PthreadMutexTrylock(mutex []PthreadMutexT) int32 {
+	if !mutex[0].mu.TryLock() {
+		return int32(syscall.EBUSY)
+	}
+	return 0
+}
+
+// PthreadMutexUnlock - unlock a mutex.
+func PthreadMutexUnlock(mutex []PthreadMutexT) int32 {
+	mutex[0].mu.Unlock()
+	return 0
+}
+
+// PthreadMutexattrInit - initialize the mutex attributes object.
+func PthreadMutexattrInit(attr []PthreadMutexattrT) int32 {
+	return 0
+}
+
+// PthreadMutexattrDestroy - destroy the mutex attributes object.
+func PthreadMutexattrDestroy(attr []PthreadMutexattrT) int32 {
+	return 0
+}
+
+func (c *PthreadCondT) init() {
+	c.once.Do(func() {
+		c.cond = sync.NewCond(&c.mu)
+	})
+}
+
+// PthreadCondInit - initialize condition variable.
+func PthreadCondInit(cond []PthreadCondT, attr []PthreadCondattrT) int32 {
+	cond[0] = PthreadCondT{}
+	return 0
+}
+
+// PthreadCondDestroy - destroy condition variable.
+func PthreadCondDestroy(cond []PthreadCondT) int32 {
+	return 0
+}
+
+// PthreadCondWait - wait on a condition. Mutex must be locked by caller.
+func PthreadCondWait(cond []PthreadCondT, mutex []PthreadMutexT) int32 {
+	c := &cond[0]
+	c.init()
+	// internal mutex of condition is locked before unlock of mutex,
+	// so signal between unlock and wait is not lost
+	c.mu.Lock()
+	mutex[0].mu.Unlock()
+	c.cond.Wait()
+	c.mu.Unlock()
+	mutex[0].mu.Lock()
+	return 0
+}
+
+// PthreadCondSignal - unblock at least one of the threads that are blocked
+// on the condition variable.
+func PthreadCondSignal(cond []PthreadCondT) int32 {
+	c := &cond[0]
+	c.init()
+	c.mu.Lock()
+	c.cond.Signal()
+	c.mu.Unlock()
+	return 0
+}
+
+// PthreadCondBroadcast - unblock all threads currently blocked on the
+// condition variable.
+func PthreadCondBroadcast(cond []PthreadCondT) int32 {
+	c := &cond[0]
+	c.init()
+	c.mu.Lock()
+	c.cond.Broadcast()
+	c.mu.Unlock()
+	return 0
+}
+
+// PthreadCondattrInit - initialize the condition variable attributes object.
+func PthreadCondattrInit(attr []PthreadCondattrT) int32 {
+	return 0
+}
+
+// PthreadCondattrDestroy - destroy the condition variable attributes object.
+func PthreadCondattrDestroy(attr []PthreadCondattrT) int32 {
+	return 0
+}
+
+// onces is once controls, because PthreadOnceT is integer type for support
+// of PTHREAD_ONCE_INIT. Control is removed after initialization, then
+// value of PthreadOnceT is used.
+var (
+	oncesMutex sync.Mutex
+	onces      = map[*PthreadOnceT]*sync.Once{}
+)
+
+// PthreadOnce - dynamic package initialization.
+func PthreadOnce(control []PthreadOnceT, routine func()) int32 {
+	c := (*int32)(&control[0])
+	if atomic.LoadInt32(c) != 0 {
+		return 0
+	}
+	oncesMutex.Lock()
+	if atomic.LoadInt32(c) != 0 {
+		// initialization is finished and control is removed
+		oncesMutex.Unlock()
+		return 0
+	}
+	once, ok := onces[&control[0]]
+	if !ok {
+		once = new(sync.Once)
+		onces[&control[0]] = once
+	}
+	oncesMutex.Unlock()
+
+	once.Do(func() {
+		routine()
+		atomic.StoreInt32(c, 1)
+	})
+
+	oncesMutex.Lock()
+	delete(onces, &control[0])
+	oncesMutex.Unlock()
+	return 0
+}
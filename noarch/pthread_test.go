@@ -0,0 +1,80 @@
+package noarch
+
+import (
+	"testing"
+)
+
+func TestPthread(t *testing.T) {
+	var (
+		mutex   [1]PthreadMutexT
+		cond    [1]PthreadCondT
+		once    [1]PthreadOnceT
+		counter int32
+		ready   int32
+		inits   int32
+	)
+	worker := func(arg interface{}) interface{} {
+		PthreadOnce(once[:], func() { inits++ })
+		PthreadMutexLock(mutex[:])
+		for ready == 0 {
+			PthreadCondWait(cond[:], mutex[:])
+		}
+		counter += arg.(int32)
+		PthreadMutexUnlock(mutex[:])
+		if arg.(int32) == 2 {
+			PthreadExit(int32(-2))
+		}
+		return arg
+	}
+
+	tids := make([]PthreadT, 4)
+	for i := range tids {
+		if r := PthreadCreate(tids[i:], nil, worker, int32(i)); r != 0 {
+			t.Fatalf("PthreadCreate returns %d", r)
+		}
+	}
+
+	PthreadMutexLock(mutex[:])
+	ready = 1
+	PthreadCondBroadcast(cond[:])
+	PthreadMutexUnlock(mutex[:])
+
+	for i := range tids {
+		value := make([]interface{}, 1)
+		if r := PthreadJoin(tids[i], value); r != 0 {
+			t.Fatalf("PthreadJoin returns %d", r)
+		}
+		want := int32(i)
+		if i == 2 {
+			want = -2
+		}
+		if value[0] != want {
+			t.Errorf("thread %d returns %v", i, value[0])
+		}
+	}
+	if counter != 6 || inits != 1 {
+		t.Errorf("not valid counter %d or inits %d", counter, inits)
+	}
+	if r := PthreadJoin(tids[0], nil); r == 0 {
+		t.Errorf("second join of thread must be fail")
+	}
+	threadsMutex.Lock()
+	oncesMutex.Lock()
+	if len(threads) != 0 || len(onces) != 0 {
+		t.Errorf("threads %d or once controls %d are not removed", len(threads), len(onces))
+	}
+	oncesMutex.Unlock()
+	threadsMutex.Unlock()
+	PthreadOnce(once[:], func() { inits++ })
+	if inits != 1 {
+		t.Errorf("initialization is repeated")
+	}
+
+	if r := PthreadMutexTrylock(mutex[:]); r != 0 {
+		t.Errorf("PthreadMutexTrylock returns %d", r)
+	}
+	if r := PthreadMutexTrylock(mutex[:]); r == 0 {
+		t.Errorf("PthreadMutexTrylock of locked mutex must be fail")
+	}
+	PthreadMutexUnlock(mutex[:])
+}
@@ -0,0 +1,64 @@
+package noarch
+
+// JmpBuf is the representation of "jmp_buf" and "sigjmp_buf". Identity of
+// buffer is the address of variable, so buffer cannot be copied.
+type JmpBuf struct {
+	// Count of active calls of Setjmp for that buffer.
+	active int
+}
+
+// jmpPanic is value of panic created by Longjmp.
+type jmpPanic struct {
+	buf   *JmpBuf
+	value int32
+}
+
+// Setjmp is analog of C function setjmp.
+//
+// In C code the result of setjmp is 0 for first call and value of longjmp
+// after each jump. In Go code all code after setjmp in same block is body of
+// that function. Body is run with value 0 and run again with value of
+// Longjmp after each jump to the same buffer.
+//
+// Example:
+//
+//	C  : if (setjmp(buf) == 0) { body(); } else { handler(); }
+//	Go : noarch.Setjmp(&buf, func(c4go_setjmp int32) {
+//		if c4go_setjmp == 0 { body() } else { handler() }
+//	})
+func Setjmp(buf *JmpBuf, body func(value int32)) {
+	var value int32
+	for runSetjmp(buf, body, &value) {
+	}
+}
+
+// runSetjmp run body and return true, if Longjmp for buffer is called.
+func runSetjmp(buf *JmpBuf, body func(int32), value *int32) (jumped bool) {
+	buf.active++
+	defer func() {
+		buf.active--
+		if r := recover(); r != nil {
+			if j, ok := r.(jmpPanic); ok && j.buf == buf {
+				*value = j.value
+				jumped = true
+				return
+			}
+			panic(r)
+		}
+	}()
+	body(*value)
+	return false
+}
+
+// Longjmp is analog of C function longjmp. Execution is continued in body of
+// last active Setjmp for the same buffer. If value is 0, then Setjmp run
+// body with value 1.
+func Longjmp(buf *JmpBuf, value int32) {
+	if buf.active == 0 {
+		panic("longjmp: jmp_buf is not initialized by setjmp")
+	}
+	if value == 0 {
+		value = 1
+	}
+	panic(jmpPanic{buf: buf, value: value})
+}
@@ -0,0 +1,37 @@
+package noarch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetjmp(t *testing.T) {
+	var outer, inner JmpBuf
+	var values []int32
+	Setjmp(&outer, func(value int32) {
+		values = append(values, value)
+		if value >= 3 {
+			return
+		}
+		Setjmp(&inner, func(value int32) {
+			if value == 0 {
+				Longjmp(&inner, 0)
+			}
+			// jump through inner setjmp
+			Longjmp(&outer, value+2)
+		})
+	})
+	if want := []int32{0, 3}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Setjmp values = %v, want %v", values, want)
+	}
+	if outer.active != 0 || inner.active != 0 {
+		t.Errorf("buffers are active: %v %v", outer.active, inner.active)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Longjmp without Setjmp must panic")
+		}
+	}()
+	Longjmp(&outer, 1)
+}
@@ -0,0 +1,39 @@
+package noarch
+
+import "io"
+
+// Stdio is standard streams of transpiled program. Generated code reads and
+// writes standard streams through the package variable C4goStdio, so input
+// and output of each transpiled program may be redirected separately. For
+// example:
+//
+//	var out bytes.Buffer
+//	program.C4goStdio = noarch.NewStdio(strings.NewReader("input"), &out, &out)
+type Stdio struct {
+	Stdin  *File
+	Stdout *File
+	Stderr *File
+}
+
+// DefaultStdio is standard streams of process.
+var DefaultStdio = newStdio(Stdin, Stdout, Stderr)
+
+func newStdio(stdin, stdout, stderr *File) *Stdio {
+	stdin.tie = stdout
+	return &Stdio{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	}
+}
+
+// NewStdio creates standard streams based on Go reader and writers. Stdin
+// is fully buffered, stdout and stderr are unbuffered. Reading or writing
+// fails for nil argument.
+func NewStdio(stdin io.Reader, stdout, stderr io.Writer) *Stdio {
+	return newStdio(
+		newStream(stdin, nil, ioFullyBuffered),
+		newStream(nil, stdout, ioUnbuffered),
+		newStream(nil, stderr, ioUnbuffered),
+	)
+}
@@ -0,0 +1,160 @@
+package noarch
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// fence is used for memory fences. All operations of package "sync/atomic"
+// are sequentially consistent.
+var fence int32
+
+// AtomicThreadFence - generic memory order-dependent fence synchronization
+// primitive. Fence is sequentially consistent for any memory order.
+func AtomicThreadFence(order int32) {
+	atomic.AddInt32(&fence, 0)
+}
+
+// AtomicSignalFence - fence between a thread and a signal handler executed
+// in the same thread.
+func AtomicSignalFence(order int32) {
+	atomic.AddInt32(&fence, 0)
+}
+
+// AtomicIsLockFree - indicates whether the atomic object of that size is
+// lock-free.
+func AtomicIsLockFree(size uint32) int32 {
+	if size <= 8 {
+		return 1
+	}
+	return 0
+}
+
+// atomicSmall - atomic value of C characters and shorts. Package
+// "sync/atomic" has no 8-bit and 16-bit types, so value is widened to
+// atomic int32. Stored value is always masked by size of type.
+type atomicSmall[T int8 | uint8 | int16 | uint16] struct {
+	v atomic.Int32
+}
+
+// Types of C11 atomic characters and shorts.
+type (
+	AtomicInt8   = atomicSmall[int8]
+	AtomicUint8  = atomicSmall[uint8]
+	AtomicInt16  = atomicSmall[int16]
+	AtomicUint16 = atomicSmall[uint16]
+)
+
+// Load atomically loads and returns the value.
+func (a *atomicSmall[T]) Load() T {
+	return T(a.v.Load())
+}
+
+// Store atomically stores value.
+func (a *atomicSmall[T]) Store(value T) {
+	a.v.Store(int32(value))
+}
+
+// Swap atomically stores new value and returns the previous value.
+func (a *atomicSmall[T]) Swap(value T) (old T) {
+	return T(a.v.Swap(int32(value)))
+}
+
+// CompareAndSwap executes the compare-and-swap operation for value.
+func (a *atomicSmall[T]) CompareAndSwap(old, value T) (swapped bool) {
+	return a.v.CompareAndSwap(int32(old), int32(value))
+}
+
+// Add atomically adds delta to value with overflow of type and returns
+// the new value.
+func (a *atomicSmall[T]) Add(delta T) (value T) {
+	for {
+		old := a.v.Load()
+		value = T(old) + delta
+		if a.v.CompareAndSwap(old, int32(value)) {
+			return
+		}
+	}
+}
+
+// AtomicPointer - atomic value of C pointer. Type T is Go type of pointer,
+// like slice, pointer or interface{}. Pointers are compared by address,
+// because slices cannot be compared in Go.
+//
+// Example:
+//
+//	C  : _Atomic(struct node *) head;
+//	Go : var head noarch.AtomicPointer[[]node]
+type AtomicPointer[T any] struct {
+	v atomic.Pointer[T]
+}
+
+// Load atomically loads and returns the value.
+func (a *AtomicPointer[T]) Load() (value T) {
+	if v := a.v.Load(); v != nil {
+		value = *v
+	}
+	return
+}
+
+// Store atomically stores value.
+func (a *AtomicPointer[T]) Store(value T) {
+	a.v.Store(&value)
+}
+
+// Swap atomically stores new value and returns the previous value.
+func (a *AtomicPointer[T]) Swap(value T) (old T) {
+	if v := a.v.Swap(&value); v != nil {
+		old = *v
+	}
+	return
+}
+
+// CompareAndSwap executes the compare-and-swap operation for value.
+func (a *AtomicPointer[T]) CompareAndSwap(old, value T) (swapped bool) {
+	expected := []T{old}
+	return a.CompareExchange(expected, value)
+}
+
+// CompareExchange is analog of C function atomic_compare_exchange_strong.
+// If value is equal to expected[0], then new value is stored. Otherwise
+// current value is stored in expected[0].
+func (a *AtomicPointer[T]) CompareExchange(expected []T, value T) bool {
+	for {
+		v := a.v.Load()
+		var current T
+		if v != nil {
+			current = *v
+		}
+		if !samePointer(current, expected[0]) {
+			expected[0] = current
+			return false
+		}
+		if a.v.CompareAndSwap(v, &value) {
+			return true
+		}
+	}
+}
+
+// samePointer return true, if C pointers have the same address.
+func samePointer(a, b interface{}) bool {
+	pa, okA := pointerAddress(reflect.ValueOf(a))
+	pb, okB := pointerAddress(reflect.ValueOf(b))
+	if okA || okB {
+		return okA && okB && pa == pb
+	}
+	return reflect.TypeOf(a).Comparable() && a == b
+}
+
+// pointerAddress return address of value, if value is nil or has kind
+// of pointer.
+func pointerAddress(v reflect.Value) (address uintptr, ok bool) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return 0, true
+	case reflect.Slice, reflect.Ptr, reflect.UnsafePointer,
+		reflect.Func, reflect.Map, reflect.Chan:
+		return v.Pointer(), true
+	}
+	return 0, false
+}
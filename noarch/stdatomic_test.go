@@ -0,0 +1,101 @@
+package noarch
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAtomicSmall(t *testing.T) {
+	var c AtomicInt8
+	c.Store(127)
+	if v := c.Add(1); v != -128 {
+		t.Errorf("overflow of int8: %d", v)
+	}
+	if c.CompareAndSwap(127, 0) || !c.CompareAndSwap(-128, 5) {
+		t.Errorf("not valid compare and swap: %d", c.Load())
+	}
+	if v := c.Swap(-1); v != 5 || c.Load() != -1 {
+		t.Errorf("not valid swap: %d %d", v, c.Load())
+	}
+
+	var u AtomicUint16
+	u.Store(65535)
+	if v := u.Add(2); v != 1 || u.v.Load() != 1 {
+		t.Errorf("overflow of uint16: %d %d", v, u.v.Load())
+	}
+
+	var wg sync.WaitGroup
+	var b AtomicUint8
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				b.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if v := b.Load(); v != 4000%256 {
+		t.Errorf("not valid sum: %d", v)
+	}
+}
+
+func TestAtomicPointer(t *testing.T) {
+	type node struct {
+		value int
+		next  []node
+	}
+
+	// lock-free stack
+	var head AtomicPointer[[]node]
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				n := []node{{value: i*100 + j}}
+				expected := [][]node{head.Load()}
+				for {
+					n[0].next = expected[0]
+					if head.CompareExchange(expected, n) {
+						break
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	count := 0
+	for n := head.Load(); n != nil; n = n[0].next {
+		count++
+	}
+	if count != 400 {
+		t.Errorf("not valid amount of nodes: %d", count)
+	}
+
+	// pointers are compared by address
+	arr := []int32{1, 2}
+	var p AtomicPointer[[]int32]
+	if p.Load() != nil || !p.CompareAndSwap(nil, arr) {
+		t.Errorf("not valid nil pointer")
+	}
+	if p.CompareAndSwap([]int32{1, 2}, nil) || !p.CompareAndSwap(arr[:1], arr[1:]) {
+		t.Errorf("pointers must be compared by address")
+	}
+	expected := [][]int32{arr}
+	if p.CompareExchange(expected, nil) || &expected[0][0] != &arr[1] {
+		t.Errorf("expected value is not changed")
+	}
+	if old := p.Swap(nil); &old[0] != &arr[1] || p.Load() != nil {
+		t.Errorf("not valid swap")
+	}
+
+	var v AtomicPointer[interface{}]
+	if !v.CompareAndSwap(nil, arr) || !v.CompareAndSwap(arr, 5) ||
+		v.CompareAndSwap(6, nil) || !v.CompareAndSwap(5, nil) {
+		t.Errorf("not valid pointer of interface")
+	}
+}
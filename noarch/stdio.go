@@ -8,15 +8,22 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"sync"
+	"unsafe"
 )
 
-// Programs generated by c4go will reference noarch.Stdin instead of os.Stdin
-// directly so that under test these can be replaced. This is required because
-// "go test" does not redirect the stdin to the executable it is testing.
+// Programs generated by c4go will reference streams of DefaultStdio instead
+// of os.Stdin directly so that under test these can be replaced. This is
+// required because "go test" does not redirect the stdin to the executable it
+// is testing.
+//
+// Stdin is fully buffered. Stdout and Stderr are unbuffered, because
+// generated code may write into os.Stdout directly. Buffering of streams
+// can be changed by setvbuf().
 var (
-	Stdin  = NewFile(os.Stdin)
-	Stdout = NewFile(os.Stdout)
-	Stderr = NewFile(os.Stderr)
+	Stdin  = newStream(os.Stdin, nil, ioFullyBuffered)
+	Stdout = newStream(nil, os.Stdout, ioUnbuffered)
+	Stderr = newStream(nil, os.Stderr, ioUnbuffered)
 )
 
 // File represents the definition has been translated from the original
@@ -24,9 +31,32 @@ var (
 // have been translated. They should be turned on as needed.
 type File struct {
 	// This is not part of the original struct but it is needed for internal
-	// calls in Go.
+	// calls in Go. OsFile is nil for streams, that are not based on files.
 	OsFile *os.File
 
+	// mu locks stream during each operation of C library, so streams may
+	// be used by several threads. Unexported methods of File expect, that
+	// stream is locked.
+	mu sync.Mutex
+
+	tie    *File // output stream flushed before reading
+	reader io.Reader
+	writer io.Writer
+	seeker io.Seeker
+	closer io.Closer
+	sync   func() // called after flushing of stream
+
+	mode     int32  // buffering mode: _IOFBF, _IOLBF or _IONBF
+	size     int    // size of buffer
+	input    []byte // input buffer, unread data is input[pos:]
+	pos      int
+	output   []byte // unwritten data of output buffer
+	pushback []byte // characters pushed back by ungetc, last is read first
+
+	eof       bool  // end-of-file indicator
+	err       error // error indicator
+	temporary bool  // file is removed after closing
+
 	// unsigned char *_p;
 	// int _r;
 	// int _w;
@@ -62,35 +92,36 @@ type File struct {
 // or freopen(). All opened files are automatically closed on normal program
 // termination.
 func Fopen(filePath, mode []byte) *File {
-	var file *os.File
-	var err error
+	file, err := openFile(CStringToString(filePath), CStringToString(mode))
+	if err != nil {
+		return nil
+	}
 
-	sFilePath := CStringToString(filePath)
+	return NewFile(file)
+}
+
+// openFile opens file in according to mode of fopen().
+func openFile(filePath, mode string) (*os.File, error) {
+	// Binary mode is same as text mode on POSIX systems.
+	mode = strings.Replace(mode, "b", "", -1)
 
 	// TODO: Only some modes are supported by fopen()
 	// https://github.com/Konstantin8105/c4go/issues/89
-	switch CStringToString(mode) {
+	switch mode {
 	case "r":
-		file, err = os.OpenFile(sFilePath, os.O_RDONLY, 0655)
+		return os.OpenFile(filePath, os.O_RDONLY, 0655)
 	case "r+":
-		file, err = os.OpenFile(sFilePath, os.O_RDWR, 0655)
+		return os.OpenFile(filePath, os.O_RDWR, 0655)
 	case "a":
-		file, err = os.OpenFile(sFilePath, os.O_WRONLY|os.O_APPEND, 0655)
+		return os.OpenFile(filePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0655)
 	case "a+":
-		file, err = os.OpenFile(sFilePath, os.O_RDWR|os.O_APPEND, 0655)
+		return os.OpenFile(filePath, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0655)
 	case "w":
-		file, err = os.OpenFile(sFilePath, os.O_RDWR|os.O_CREATE, 0655)
+		return os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0655)
 	case "w+":
-		file, err = os.OpenFile(sFilePath, os.O_RDWR|os.O_CREATE, 0655)
-	default:
-		panic(fmt.Sprintf("unsupported file mode: %s", mode))
+		return os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0655)
 	}
-
-	if err != nil {
-		return nil
-	}
-
-	return NewFile(file)
+	panic(fmt.Sprintf("unsupported file mode: %s", mode))
 }
 
 // Fclose handles fclose().
@@ -104,7 +135,9 @@ func Fopen(filePath, mode []byte) *File {
 // Even if the call fails, the stream passed as parameter will no longer be
 // associated with the file nor its buffers.
 func Fclose(f *File) int32 {
-	err := f.OsFile.Close()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.close()
 	if err != nil {
 		// Is this the correct error code?
 		return 1
@@ -173,9 +206,11 @@ func Fputs(str []byte, stream *File) int32 {
 
 	s := CStringToString(str)
 
-	n, err := stream.OsFile.WriteString(s)
-	if err != nil {
-		panic(err)
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	n := stream.write([]byte(s))
+	if n < len(s) {
+		return -1
 	}
 
 	return int32(n)
@@ -197,7 +232,9 @@ func Tmpfile() *File {
 		return nil
 	}
 
-	return NewFile(f)
+	stream := NewFile(f)
+	stream.temporary = true
+	return stream
 }
 
 // Fgets handles fgets().
@@ -217,40 +254,58 @@ func Tmpfile() *File {
 // includes in the string any ending newline character.
 func Fgets(str []byte, num32 int32, stream *File) []byte {
 	num := int(num32)
-	buf := make([]byte, num)
-	n, err := stream.OsFile.Read(buf)
-
-	// FIXME: Is this the right thing to do in this case?
-	if err != nil {
-		return []byte{}
+	if num <= 0 {
+		return nil
 	}
 
-	// TODO: Allow arguments to be passed by reference.
-	// https://github.com/Konstantin8105/c4go/issues/90
-	// This appears in multiple locations.
-
-	// Be careful to crop the buffer to the real number of bytes read.
-	//
-	// We do not trim off the NULL characters because we do not know if the file
-	// we are reading is in binary mode.
-	if n == num {
-		// If it is the case that we have read the entire buffer with this read
-		// we need to make sure we leave room for what would be the NULL
-		// character at the end of the string in C.
-		return buf[:n-1]
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	var n int
+	for n < num-1 {
+		c := stream.readByte()
+		if c < 0 {
+			break
+		}
+		str[n] = byte(c)
+		n++
+		if c == '\n' {
+			break
+		}
 	}
+	if n == 0 || stream.err != nil {
+		return nil
+	}
+	str[n] = 0
 
-	return buf[:n]
+	return str
 }
 
-// Gets read bytes from stdin
+// Gets read bytes from stdin until newline or end of file. Newline is not
+// stored in str.
 func Gets(str []byte) []byte {
-	_, err := Stdin.OsFile.Read(str)
+	return DefaultStdio.Gets(str)
+}
 
-	// FIXME: Is this the right thing to do in this case?
-	if err != nil {
-		return []byte{}
+// Gets read bytes from stdin of program until newline or end of file.
+func (s *Stdio) Gets(str []byte) []byte {
+	s.Stdin.mu.Lock()
+	defer s.Stdin.mu.Unlock()
+	var n int
+	for {
+		c := s.Stdin.readByte()
+		if c < 0 {
+			if n == 0 || s.Stdin.err != nil {
+				return nil
+			}
+			break
+		}
+		if c == '\n' {
+			break
+		}
+		str[n] = byte(c)
+		n++
 	}
+	str[n] = 0
 
 	return str
 }
@@ -267,7 +322,10 @@ func Gets(str []byte) []byte {
 // On streams open for update (read+write), a call to rewind allows to switch
 // between reading and writing.
 func Rewind(stream *File) {
-	stream.OsFile.Seek(0, 0)
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	stream.seek(0, io.SeekStart)
+	stream.err = nil
 }
 
 // Feof handles feof().
@@ -286,27 +344,18 @@ func Rewind(stream *File) {
 // freopen. Although if the position indicator is not repositioned by such a
 // call, the next i/o operation is likely to set the indicator again.
 func Feof(stream *File) int32 {
-	// FIXME: This is a really bad way of doing this. Basically try and peek
-	// ahead to test for EOF.
-	buf := make([]byte, 1)
-	_, err := stream.OsFile.Read(buf)
-
-	result := 0
-	if err == io.EOF {
-		result = 1
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	if stream.eof {
+		return 1
 	}
 
-	// Undo cursor before returning.
-	stream.OsFile.Seek(-1, 1)
-
-	return int32(result)
+	return 0
 }
 
 // NewFile creates a File pointer from a Go file pointer.
 func NewFile(f *os.File) *File {
-	return &File{
-		OsFile: f,
-	}
+	return newStream(f, f, ioFullyBuffered)
 }
 
 // Tmpnam handles tmpnam().
@@ -351,8 +400,7 @@ func Tmpnam(str []byte) []byte {
 // the last i/o operation was an output operation) any unwritten data in its
 // output buffer is written to the file.
 //
-// If stream is a null pointer, all such streams should be flushed, but this is
-// currently not supported.
+// If stream is a null pointer, all such streams are flushed.
 //
 // The stream remains open after this call.
 //
@@ -360,9 +408,16 @@ func Tmpnam(str []byte) []byte {
 // program terminates, all the buffers associated with it are automatically
 // flushed.
 func Fflush(stream *File) int32 {
-	err := stream.OsFile.Sync()
-	if err != nil {
-		return 1
+	if stream == nil {
+		if FlushAll() != nil {
+			return -1
+		}
+		return 0
+	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	if !stream.flush() {
+		return -1
 	}
 
 	return 0
@@ -378,24 +433,14 @@ func Fflush(stream *File) int32 {
 // After the format parameter, the function expects at least as many additional
 // arguments as specified by format.
 func Fprintf(f *File, format []byte, args ...interface{}) int32 {
-	realArgs := []interface{}{}
-
-	// Convert any C strings into Go strings.
-	typeOfByteSlice := reflect.TypeOf([]byte(nil))
-	for _, arg := range args {
-		if reflect.TypeOf(arg) == typeOfByteSlice {
-			realArgs = append(realArgs, CStringToString(arg.([]byte)))
-		} else {
-			realArgs = append(realArgs, arg)
-		}
-	}
-
-	n, err := fmt.Fprintf(f.OsFile, CStringToString(format), realArgs...)
-	if err != nil {
+	out := cFormat(format, args)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if n := f.write(out); n < len(out) {
 		return -1
 	}
 
-	return int32(n)
+	return int32(len(out))
 }
 
 // Fscanf handles fscanf().
@@ -407,60 +452,35 @@ func Fprintf(f *File, format []byte, args ...interface{}) int32 {
 // type specified by their corresponding format specifier within the format
 // string.
 func Fscanf(f *File, format []byte, args ...interface{}) int32 {
-	realArgs := prepareArgsForScanf(args)
-	goFormat := CStringToString(format)
-	iter := 0
-again:
-
-	n, err := fmt.Fscanf(f.OsFile, goFormat, realArgs...)
-	if err != nil {
-		if strings.Contains(err.Error(), "unexpected newline") && iter < 100 {
-			iter++
-			goto again
-		}
-		return -1
-	}
-
-	finalizeArgsForScanf(realArgs, args)
-
-	return int32(n)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return cScan(&fileReader{f: f, last: -1}, format, args)
 }
 
-func finalizeArgsForScanf(realArgs []interface{}, args []interface{}) {
-	typeOfStringRef := reflect.TypeOf(new(string))
-	for i, arg := range realArgs {
-		if reflect.TypeOf(arg) == typeOfStringRef {
-			s := *arg.(*string)
-			copy(args[i].([]byte), []byte(s+"\x00"))
-		} else {
-			GoPointerToCPointer(arg, args[i])
-		}
-	}
+// fileReader is reader of stream by bytes with possibility to return
+// the last read byte back to the stream.
+type fileReader struct {
+	f    *File
+	last int32
 }
 
-func prepareArgsForScanf(args []interface{}) []interface{} {
-	realArgs := []interface{}{}
-
-	typeOfByteSlice := reflect.TypeOf([]byte(nil))
-	for _, arg := range args {
-		if reflect.TypeOf(arg) == typeOfByteSlice {
-			realArgs = append(realArgs, new(string))
-		} else {
-			realArgs = append(realArgs, CPointerToGoPointer(arg))
-		}
+// ReadByte reads next byte of stream.
+func (r *fileReader) ReadByte() (byte, error) {
+	r.last = r.f.readByte()
+	if r.last < 0 {
+		return 0, io.EOF
 	}
-
-	return realArgs
+	return byte(r.last), nil
 }
 
-func getc(f *os.File) int32 {
-	buffer := make([]byte, 1)
-	_, err := f.Read(buffer)
-	if err != nil {
-		return -1
+// UnreadByte returns the last read byte back to the stream.
+func (r *fileReader) UnreadByte() error {
+	if r.last < 0 {
+		return fmt.Errorf("cannot unread byte")
 	}
-
-	return int32(buffer[0])
+	r.f.unget(byte(r.last))
+	r.last = -1
+	return nil
 }
 
 // Fgetc handles fgetc().
@@ -478,7 +498,9 @@ func getc(f *os.File) int32 {
 // fgetc and getc are equivalent, except that getc may be implemented as a macro
 // in some libraries.
 func Fgetc(stream *File) int32 {
-	return int32(getc(stream.OsFile))
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	return stream.readByte()
 }
 
 // Fputc handles fputc().
@@ -491,12 +513,13 @@ func Fputc(c int32, f *File) int32 {
 	if f == nil {
 		return -1
 	}
-	n, err := f.OsFile.Write([]byte{byte(c)})
-	if err != nil {
-		return 0
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.write([]byte{byte(c)}) != 1 {
+		return -1
 	}
 
-	return int32(n)
+	return int32(byte(c))
 }
 
 // Getchar handles getchar().
@@ -505,7 +528,14 @@ func Fputc(c int32, f *File) int32 {
 //
 // It is equivalent to calling getc with stdin as argument.
 func Getchar() int32 {
-	return int32(getc(Stdin.OsFile))
+	return DefaultStdio.Getchar()
+}
+
+// Getchar returns the next character from stdin of program.
+func (s *Stdio) Getchar() int32 {
+	s.Stdin.mu.Lock()
+	defer s.Stdin.mu.Unlock()
+	return s.Stdin.readByte()
 }
 
 // Fseek handles fseek().
@@ -529,12 +559,14 @@ func Getchar() int32 {
 // On streams open for update (read+write), a call to fseek allows to switch
 // between reading and writing.
 func Fseek(f *File, offset int32, origin int32) int32 {
-	n, err := f.OsFile.Seek(int64(offset), int(origin))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err := f.seek(int64(offset), int(origin))
 	if err != nil {
 		return -1
 	}
 
-	return int32(n)
+	return 0
 }
 
 // Ftell handles ftell().
@@ -549,7 +581,9 @@ func Fseek(f *File, offset int32, origin int32) int32 {
 // are characters put back using ungetc still pending of being read, the
 // behavior is undefined).
 func Ftell(f *File) int32 {
-	return int32(Fseek(f, 0, 1))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return int32(f.tell())
 }
 
 // Fread handles fread().
@@ -562,23 +596,18 @@ func Ftell(f *File) int32 {
 //
 // The total amount of bytes read if successful is (size*count).
 func Fread(ptr *[]byte, size1, size2 int32, f *File) int32 {
-	// Create a new buffer so that we can ensure we read up to the correct
-	// number of bytes from the file.
-	newBuffer := make([]byte, size1*size2)
-	n, err := f.OsFile.Read(newBuffer)
-
-	// Despite any error we need to make sure the bytes read are copied to the
-	// destination buffer.
-	for i, b := range newBuffer {
-		(*ptr)[i] = b
+	if size1 <= 0 || size2 <= 0 {
+		return 0
 	}
-
-	// Now we can handle the success or failure.
-	if err != nil {
-		return -1
+	size := int(size1 * size2)
+	if size > len(*ptr) {
+		size = len(*ptr)
 	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := f.read((*ptr)[:size])
 
-	return int32(n)
+	return int32(n) / size1
 }
 
 // Fwrite handles fwrite().
@@ -593,12 +622,141 @@ func Fread(ptr *[]byte, size1, size2 int32, f *File) int32 {
 // array of (size*count) elements of type unsigned char, and writes them
 // sequentially to stream as if fputc was called for each byte.
 func Fwrite(str []byte, size1, size2 int32, stream *File) int32 {
-	n, err := stream.OsFile.Write(str[:int(size1*size2)])
-	if err != nil {
-		return -1
+	if size1 <= 0 || size2 <= 0 {
+		return 0
 	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	n := stream.write(str[:int(size1*size2)])
 
-	return int32(n)
+	return int32(n) / size1
+}
+
+// FreadValue handles fread() for slice of values with fixed size, for
+// example structs with memory layout of C. Fields of values are read from
+// the stream sequentially without alignment of Go, so padding of C struct
+// must be defined by padding fields. Returns amount of read elements with
+// size of size1.
+func FreadValue(ptr interface{}, size1, size2 int32, f *File) int32 {
+	v := reflect.ValueOf(ptr)
+	values, elemSize := valueLayout(v, size1, size2)
+	if values == 0 {
+		return 0
+	}
+
+	buffer := make([]byte, int(size1*size2))
+	f.mu.Lock()
+	n := f.read(buffer)
+	f.mu.Unlock()
+
+	if values > n/elemSize {
+		values = n / elemSize
+	}
+	var pos int
+	for i := 0; i < values; i++ {
+		for _, mem := range valueMemory(v.Index(i), nil) {
+			pos += copy(mem, buffer[pos:])
+		}
+	}
+
+	return int32(n) / size1
+}
+
+// FwriteValue handles fwrite() for slice of values with fixed size, for
+// example structs with memory layout of C. Fields of values are written to
+// the stream sequentially without alignment of Go. Returns amount of
+// written elements with size of size1.
+func FwriteValue(ptr interface{}, size1, size2 int32, stream *File) int32 {
+	v := reflect.ValueOf(ptr)
+	values, _ := valueLayout(v, size1, size2)
+	if values == 0 {
+		return 0
+	}
+
+	var buffer []byte
+	for i := 0; i < values; i++ {
+		for _, mem := range valueMemory(v.Index(i), nil) {
+			buffer = append(buffer, mem...)
+		}
+	}
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	n := stream.write(buffer)
+	return int32(n) / size1
+}
+
+// valueLayout return amount of slice elements in size1*size2 bytes and
+// size of element in bytes.
+func valueLayout(v reflect.Value, size1, size2 int32) (values, elemSize int) {
+	if v.Kind() != reflect.Slice || size1 <= 0 || size2 <= 0 {
+		return 0, 0
+	}
+	elemSize = valueSize(v.Type().Elem())
+	if elemSize <= 0 {
+		return 0, 0
+	}
+	values = int(size1*size2) / elemSize
+	if values > v.Len() {
+		values = v.Len()
+	}
+	return
+}
+
+// valueSize return size of all fields of type without alignment of Go.
+// Returns -1 for type without fixed size.
+func valueSize(t reflect.Type) (size int) {
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			s := valueSize(t.Field(i).Type)
+			if s < 0 {
+				return -1
+			}
+			size += s
+		}
+		return size
+
+	case reflect.Array:
+		s := valueSize(t.Elem())
+		if s < 0 {
+			return -1
+		}
+		return s * t.Len()
+
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Float32, reflect.Float64,
+		reflect.Complex64, reflect.Complex128:
+		return int(t.Size())
+	}
+	return -1
+}
+
+// valueMemory return memory of all fields of addressable value in order
+// of definition.
+func valueMemory(v reflect.Value, mem [][]byte) [][]byte {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			mem = valueMemory(v.Field(i), mem)
+		}
+		return mem
+
+	case reflect.Array:
+		if k := v.Type().Elem().Kind(); k == reflect.Struct || k == reflect.Array {
+			for i := 0; i < v.Len(); i++ {
+				mem = valueMemory(v.Index(i), mem)
+			}
+			return mem
+		}
+	}
+	size := int(v.Type().Size())
+	if size == 0 {
+		return mem
+	}
+	return append(mem, (*[1 << 30]byte)(unsafe.Pointer(v.UnsafeAddr()))[:size:size])
 }
 
 // Fgetpos handles fgetpos().
@@ -613,12 +771,17 @@ func Fwrite(str []byte, size1, size2 int32, stream *File) int32 {
 // The ftell function can be used to retrieve the current position in the stream
 // as an integer value.
 func Fgetpos(f *File, pos []int32) int32 {
-	absolutePos := int32(Fseek(f, 0, 1))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	absolutePos := f.tell()
+	if absolutePos < 0 {
+		return -1
+	}
 	if pos != nil {
-		pos[0] = absolutePos
+		pos[0] = int32(absolutePos)
 	}
 
-	return absolutePos
+	return 0
 }
 
 // Fsetpos handles fsetpos().
@@ -649,21 +812,12 @@ func Fsetpos(stream *File, pos []int32) int32 {
 // additional arguments following format are formatted and inserted in the
 // resulting string replacing their respective specifiers.
 func Printf(format []byte, args ...interface{}) int32 {
-	realArgs := []interface{}{}
-
-	// Convert any C strings into Go strings.
-	typeOfByteSlice := reflect.TypeOf([]byte(nil))
-	for _, arg := range args {
-		if reflect.TypeOf(arg) == typeOfByteSlice {
-			realArgs = append(realArgs, CStringToString(arg.([]byte)))
-		} else {
-			realArgs = append(realArgs, arg)
-		}
-	}
-
-	n, _ := fmt.Fprintf(os.Stdout, CStringToString(format), realArgs...)
+	return DefaultStdio.Printf(format, args...)
+}
 
-	return int32(n)
+// Printf writes formatted data to stdout of program.
+func (s *Stdio) Printf(format []byte, args ...interface{}) int32 {
+	return Fprintf(s.Stdout, format, args...)
 }
 
 // Puts handles puts().
@@ -679,9 +833,19 @@ func Printf(format []byte, args ...interface{}) int32 {
 // destination, but it also appends a newline character at the end automatically
 // (which fputs does not).
 func Puts(str []byte) int32 {
-	n, _ := fmt.Fprintln(os.Stdout, CStringToString(str))
+	return DefaultStdio.Puts(str)
+}
 
-	return int32(n)
+// Puts writes the C string and newline to stdout of program.
+func (s *Stdio) Puts(str []byte) int32 {
+	line := CStringToString(str) + "\n"
+	s.Stdout.mu.Lock()
+	defer s.Stdout.mu.Unlock()
+	if n := s.Stdout.write([]byte(line)); n < len(line) {
+		return -1
+	}
+
+	return int32(len(line))
 }
 
 // Scanf handles scanf().
@@ -693,22 +857,23 @@ func Puts(str []byte) int32 {
 // type specified by their corresponding format specifier within the format
 // string.
 func Scanf(format []byte, args ...interface{}) int32 {
-	realArgs := prepareArgsForScanf(args)
+	return DefaultStdio.Scanf(format, args...)
+}
 
-	// We cannot use fmt Scanf() here because that would use the real stdin
+// Scanf reads formatted data from stdin of program.
+func (s *Stdio) Scanf(format []byte, args ...interface{}) int32 {
+	// We cannot use os.Stdin here because that would use the real stdin
 	// which does not work under test. See docs for noarch.Stdin.
-	n, _ := fmt.Fscanf(Stdin.OsFile, CStringToString(format), realArgs...)
-	finalizeArgsForScanf(realArgs, args)
-
-	return int32(n)
+	return Fscanf(s.Stdin, format, args...)
 }
 
+// Sscanf handles sscanf().
+//
+// Reads data from str and stores them according to parameter format into the
+// locations given by the additional arguments, as if scanf was used, but
+// reading from str instead of the standard input (stdin).
 func Sscanf(str []byte, format []byte, args ...interface{}) int32 {
-	wws := bytes.Replace(str, []byte("\n"), []byte{' '}, -1) // without newline
-	realArgs := prepareArgsForScanf(args)
-	n, _ := fmt.Sscanf(CStringToString(wws), CStringToString(format), realArgs...)
-	finalizeArgsForScanf(realArgs, args)
-	return int32(n)
+	return cScan(bytes.NewReader([]byte(CStringToString(str))), format, args)
 }
 
 // Putchar handles putchar().
@@ -717,7 +882,14 @@ func Sscanf(str []byte, format []byte, args ...interface{}) int32 {
 //
 // It is equivalent to calling putc with stdout as second argument.
 func Putchar(character int32) {
-	fmt.Fprintf(os.Stdout, "%c", int(character))
+	DefaultStdio.Putchar(character)
+}
+
+// Putchar writes a character to stdout of program.
+func (s *Stdio) Putchar(character int32) {
+	s.Stdout.mu.Lock()
+	defer s.Stdout.mu.Unlock()
+	s.Stdout.write([]byte{byte(character)})
 }
 
 // Sprintf handles sprintf().
@@ -727,18 +899,11 @@ func Putchar(character int32) {
 // additional arguments following format are formatted and inserted in the
 // resulting string replacing their respective specifiers.
 func Sprintf(buffer, format []byte, args ...interface{}) int32 {
-	realArgs := []interface{}{}
-
-	realArgs = append(realArgs, convert(args)...)
-
-	result := fmt.Sprintf(CStringToString(format), realArgs...)
-	for i := range []byte(result) {
-		buffer[i] = result[i]
-	}
+	result := cFormat(format, args)
+	copy(buffer, result)
 	buffer[len(result)] = '\x00'
 
-	n := len(result)
-	return int32(n)
+	return int32(len(result))
 }
 
 // Vsprintf handles vsprintf().
@@ -748,33 +913,23 @@ func Sprintf(buffer, format []byte, args ...interface{}) int32 {
 // additional arguments following format are formatted and inserted in the
 // resulting string replacing their respective specifiers.
 func Vsprintf(buffer, format []byte, varList ...interface{}) int32 {
-	realArgs := []interface{}{}
-	realArgs = append(realArgs, convert(varList)...)
-
-	result := fmt.Sprintf(CStringToString(format), realArgs...)
-	for i := range []byte(result) {
-		buffer[i] = result[i]
-	}
-	buffer[len(result)] = '\x00'
-
-	n := int32(len(result))
-	return n
+	return Sprintf(buffer, format, vaListArgs(varList)...)
 }
 
+// Vprintf handles vprintf().
 func Vprintf(format []byte, varList ...interface{}) int32 {
-	buffer := make([]byte, 1000)
-	n := Vsprintf(buffer, format, varList...)
-	buf := CStringToString(buffer)
-	fmt.Fprintf(os.Stdout, "%s", buf)
-	return n
+	return DefaultStdio.Vprintf(format, varList...)
+}
+
+// Vprintf writes formatted data from variable argument list to stdout of
+// program.
+func (s *Stdio) Vprintf(format []byte, varList ...interface{}) int32 {
+	return s.Printf(format, vaListArgs(varList)...)
 }
 
+// Vfprintf handles vfprintf().
 func Vfprintf(f *File, format []byte, varList ...interface{}) int32 {
-	buffer := make([]byte, 1000)
-	n := Vsprintf(buffer, format, varList...)
-	buf := CStringToString(buffer)
-	fmt.Fprintf(f.OsFile, "%s", buf)
-	return n
+	return Fprintf(f, format, vaListArgs(varList)...)
 }
 
 // Snprintf handles snprintf().
@@ -784,55 +939,27 @@ func Vfprintf(f *File, format []byte, varList ...interface{}) int32 {
 // additional arguments following format are formatted and inserted in the
 // resulting string replacing their respective specifiers.
 func Snprintf(buffer []byte, n int32, format []byte, args ...interface{}) int32 {
-	return Vsnprintf(buffer, n, format, args...)
-}
-
-// convert - convert va_list
-func convert(args []interface{}) (result []interface{}) {
-	for i := range args {
-		arg := args[i]
-
-		typeOfByteSlice := reflect.TypeOf([]byte(nil))
-		if reflect.TypeOf(arg) == typeOfByteSlice {
-			result = append(result, CStringToString(arg.([]byte)))
-			continue
-		}
+	result := cFormat(format, args)
+	if n > 0 {
+		size := copy(buffer[:n-1], result)
+		buffer[size] = '\x00'
+	}
 
-		switch v := arg.(type) {
-		case int32:
-			result = append(result, v)
-			continue
-		case string:
-			result = append(result, v)
-			continue
-		case float64:
-			result = append(result, v)
-			continue
-		}
+	return int32(len(result))
+}
 
+// vaListArgs returns arguments of va_list.
+func vaListArgs(args []interface{}) (result []interface{}) {
+	for _, arg := range args {
 		// here come &main.va_list{position:0, slice:[]interface {}{2}}
 		if reflect.TypeOf(arg).Kind() == reflect.Ptr {
-			val := reflect.ValueOf(arg)
-			v := reflect.Indirect(val)
-			if v.NumField() == 2 {
-				if v.Field(1).Type().String() == "[]interface {}" {
-					field := v.Field(1)
-					slice := field.Interface().([]interface{})
-					for i := range slice {
-						switch v := slice[i].(type) {
-						case []byte:
-							result = append(result, CStringToString(v))
-
-						default:
-							result = append(result, slice[i])
-						}
-					}
-				}
+			v := reflect.Indirect(reflect.ValueOf(arg))
+			if v.Kind() == reflect.Struct && v.NumField() == 2 &&
+				v.Field(1).Type().String() == "[]interface {}" {
+				result = append(result, v.Field(1).Interface().([]interface{})...)
+				continue
 			}
-
-			continue
 		}
-
 		result = append(result, arg)
 	}
 	return
@@ -845,50 +972,33 @@ func convert(args []interface{}) (result []interface{}) {
 // additional arguments following format are formatted and inserted in the
 // resulting string replacing their respective specifiers.
 func Vsnprintf(buffer []byte, n int32, format []byte, varList ...interface{}) int32 {
-	realArgs := []interface{}{}
-	realArgs = append(realArgs, convert(varList)...)
-
-	var result string
-	if len(realArgs) > 0 {
-		result = fmt.Sprintf(CStringToString(format), realArgs...)
-	} else {
-		result = fmt.Sprintf(CStringToString(format))
-	}
-	if int32(len(result)) > n {
-		result = result[:n]
-	}
-	for i := range []byte(result) {
-		buffer[i] = result[i]
-	}
-	if len(buffer) <= len(result) {
-		buffer = append(buffer, '\x00')
-	} else {
-		buffer[len(result)] = '\x00'
-	}
-
-	n = int32(len(result))
-	return n
+	return Snprintf(buffer, n, format, vaListArgs(varList)...)
 }
 
 func Perror(msg []byte) {
+	DefaultStdio.Perror(msg)
+}
+
+// Perror writes error message to stderr of program.
+func (s *Stdio) Perror(msg []byte) {
 	m := CStringToString(msg)
-	fmt.Fprintf(os.Stderr, "%s: No such file or directory\n", m)
+	s.Stderr.mu.Lock()
+	defer s.Stderr.mu.Unlock()
+	s.Stderr.write([]byte(m + ": No such file or directory\n"))
 }
 
 func Getline(line [][]byte, len []uint32, f *File) SsizeT {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	counter := 0
 	for {
-		buf := make([]byte, 1)
-		n, err := f.OsFile.Read(buf)
-		buf = buf[:n]
-		if err == io.EOF || err != nil {
+		c := f.readByte()
+		if c < 0 {
 			break
 		}
-		for i := range buf {
-			line[0] = append(line[0], []byte{buf[i]}...)
-		}
-		counter += n
-		if buf[n-1] == '\n' {
+		line[0] = append(line[0], byte(c))
+		counter++
+		if c == '\n' {
 			break
 		}
 	}
@@ -898,3 +1008,215 @@ func Getline(line [][]byte, len []uint32, f *File) SsizeT {
 	line[0] = append(line[0], '\x00')
 	return SsizeT(counter)
 }
+
+// Ungetc handles ungetc().
+//
+// A character is virtually put back into an input stream, decreasing its
+// internal file position as if a previous getc operation was undone.
+//
+// This character may or may not be the one read from the stream in the
+// preceding input operation. In any case, the next character retrieved from
+// stream is the character passed to this function, independently of the
+// original one.
+//
+// A successful call to this function clears the end-of-file indicator of
+// the stream. If the value of character is EOF, the operation fails and the
+// input stream remains unchanged.
+func Ungetc(character int32, stream *File) int32 {
+	if character < 0 || stream == nil {
+		return -1
+	}
+	stream.mu.Lock()
+	stream.unget(byte(character))
+	stream.mu.Unlock()
+
+	return int32(byte(character))
+}
+
+// Setvbuf handles setvbuf().
+//
+// Changes the buffer to be used for I/O operations with the specified
+// stream. The mode is one of _IOFBF (full buffering), _IOLBF (line
+// buffering) or _IONBF (no buffering), size is the size of buffer in bytes.
+//
+// Memory of buffer is allocated by stream, so the buffer argument is not
+// used.
+func Setvbuf(stream *File, buffer []byte, mode int32, size int32) int32 {
+	if stream == nil {
+		return -1
+	}
+	switch mode {
+	case ioFullyBuffered, ioLineBuffered, ioUnbuffered:
+	default:
+		return -1
+	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	if !stream.flush() {
+		return -1
+	}
+	stream.mode = mode
+	if size > 0 {
+		stream.size = int(size)
+	}
+
+	return 0
+}
+
+// Setbuf handles setbuf().
+//
+// Specifies the buffer to be used by the stream for I/O operations, which
+// becomes a fully buffered stream. Or, alternatively, if buffer is a null
+// pointer, buffering is disabled for the stream, which becomes an unbuffered
+// stream.
+func Setbuf(stream *File, buffer []byte) {
+	if buffer == nil {
+		Setvbuf(stream, nil, ioUnbuffered, 0)
+		return
+	}
+	Setvbuf(stream, buffer, ioFullyBuffered, bufferSize)
+}
+
+// Ferror handles ferror().
+//
+// Checks if the error indicator associated with stream is set, returning a
+// value different from zero if it is.
+//
+// This indicator is generally set by a previous operation on the stream that
+// failed, and is cleared by a call to clearerr, rewind or freopen.
+func Ferror(stream *File) int32 {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	if stream.err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+// Clearerr handles clearerr().
+//
+// Resets both the error and the eof indicators of the stream.
+func Clearerr(stream *File) {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	stream.err = nil
+	stream.eof = false
+}
+
+// Freopen handles freopen().
+//
+// Reuses stream to either open the file specified by filePath or to change
+// its access mode.
+//
+// If a new filePath is specified, the function first attempts to close any
+// file already associated with stream and disassociates it. Then,
+// independently of whether that stream was successfully closed or not,
+// freopen opens the file specified by filePath and associates it with the
+// stream just as fopen would do using the specified mode.
+//
+// If filePath is a null pointer, the same file is reopened with new mode.
+func Freopen(filePath, mode []byte, stream *File) *File {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	var name string
+	if filePath != nil {
+		name = CStringToString(filePath)
+	} else if stream.OsFile != nil {
+		name = stream.OsFile.Name()
+	} else {
+		return nil
+	}
+	if stream == Stdin || stream == Stdout || stream == Stderr {
+		// standard files of process are kept open
+		stream.flush()
+		stream.dropInput()
+		streams.Lock()
+		delete(streams.list, stream)
+		streams.Unlock()
+	} else {
+		stream.close()
+	}
+
+	file, err := openFile(name, CStringToString(mode))
+	if err != nil {
+		return nil
+	}
+	bufferMode, size := stream.mode, stream.size
+	stream.open(file, file, bufferMode)
+	stream.size = size
+
+	return stream
+}
+
+// Fmemopen handles fmemopen().
+//
+// Opens a stream that permits the access specified by mode. The stream
+// allows I/O to be performed on the memory buffer pointed to by buffer with
+// size bytes. If buffer is a null pointer, then memory is allocated by
+// stream.
+//
+// When a stream that has been opened for writing is flushed or closed, a
+// null byte is written at the end of written data, if there is space.
+func Fmemopen(buffer []byte, size uint32, mode []byte) *File {
+	if size == 0 {
+		return nil
+	}
+	if buffer == nil {
+		buffer = make([]byte, size)
+	}
+	if int(size) < len(buffer) {
+		buffer = buffer[:size]
+	}
+	m := &memoryFile{buf: buffer, end: len(buffer), fixed: true}
+
+	s := strings.Replace(CStringToString(mode), "b", "", -1)
+	switch s {
+	case "r", "r+":
+	case "w", "w+":
+		m.end = 0
+		buffer[0] = 0
+	case "a", "a+":
+		if i := bytes.IndexByte(buffer, 0); i >= 0 {
+			m.end = i
+		}
+		m.pos = m.end
+	default:
+		return nil
+	}
+
+	switch {
+	case strings.HasSuffix(s, "+"):
+		return newStream(m, m, ioFullyBuffered)
+	case s == "r":
+		return newStream(m, nil, ioFullyBuffered)
+	}
+	return newStream(nil, m, ioFullyBuffered)
+}
+
+// OpenMemstream handles open_memstream().
+//
+// Opens a stream for writing to a dynamically allocated buffer. The
+// locations pointed by ptr and sizeloc are updated each time the stream is
+// flushed and when the stream is closed. Values are the address of buffer
+// with null-terminated data and the smaller of the size of data and the
+// current position of stream.
+func OpenMemstream(ptr [][]byte, sizeloc []uint32) *File {
+	if ptr == nil || sizeloc == nil {
+		return nil
+	}
+	m := &memoryFile{}
+	stream := newStream(nil, m, ioFullyBuffered)
+	stream.sync = func() {
+		data := make([]byte, m.end+1)
+		copy(data, m.buf[:m.end])
+		ptr[0] = data
+		size := m.end
+		if m.pos < size {
+			size = m.pos
+		}
+		sizeloc[0] = uint32(size)
+	}
+	stream.sync()
+	return stream
+}
@@ -0,0 +1,342 @@
+package noarch
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFreadValue(t *testing.T) {
+	// struct rec { char c; int i; double d; };
+	type rec struct {
+		c byte
+		_ [3]byte
+		i int32
+		d float64
+	}
+
+	tmp, err := ioutil.TempFile("", "c4go-fread")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+
+	out := []rec{{c: 'a', i: 1, d: 1.5}, {c: 'b', i: 258, d: 2.5}}
+	stream := NewFile(tmp)
+	if n := FwriteValue(out, 16, 2, stream); n != 2 {
+		t.Fatalf("FwriteValue returns %d", n)
+	}
+	Fclose(stream)
+
+	b, err := ioutil.ReadFile(tmp.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 32 || b[0] != 'a' || b[4] != 1 || b[16] != 'b' || b[20] != 2 || b[21] != 1 {
+		t.Fatalf("not valid C layout of values: %v", b)
+	}
+
+	f, err := os.Open(tmp.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	in := make([]rec, 3)
+	if n := FreadValue(in, 16, 3, NewFile(f)); n != 2 {
+		t.Fatalf("FreadValue returns %d", n)
+	}
+	if in[0].c != out[0].c || in[0].i != out[0].i || in[1].d != out[1].d {
+		t.Errorf("not same values: %v != %v", in, out)
+	}
+
+	// struct __attribute__((packed)) pk { char c; int i; short s; };
+	type pk struct {
+		c byte
+		i int32
+		s int16
+	}
+	packed, err := ioutil.TempFile("", "c4go-fread")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(packed.Name())
+	stream = NewFile(packed)
+	if n := FwriteValue([]pk{{c: 'p', i: 258, s: 7}}, 7, 1, stream); n != 1 {
+		t.Fatalf("FwriteValue returns %d", n)
+	}
+	Fclose(stream)
+	if b, err := ioutil.ReadFile(packed.Name()); err != nil ||
+		string(b) != "p\x02\x01\x00\x00\x07\x00" {
+		t.Errorf("not valid packed layout: %q. %v", b, err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	tmp, err := ioutil.TempFile("", "c4go-stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := tmp.Name()
+	tmp.Close()
+	defer os.Remove(name)
+
+	f := Fopen([]byte(name+"\x00"), []byte("w+\x00"))
+	if f == nil {
+		t.Fatal("cannot open file")
+	}
+
+	// data is kept in buffer until flushing
+	Fputs([]byte("ab\ncd\x00"), f)
+	if b, _ := ioutil.ReadFile(name); len(b) != 0 {
+		t.Errorf("data is not buffered: %q", b)
+	}
+	if pos := Ftell(f); pos != 5 {
+		t.Errorf("not valid position of buffered stream: %d", pos)
+	}
+	if Fflush(f) != 0 {
+		t.Fatal("cannot flush")
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "ab\ncd" {
+		t.Errorf("not valid flushed data: %q", b)
+	}
+
+	// line buffering
+	Setvbuf(f, nil, 1, 0)
+	Fputc('e', f)
+	if b, _ := ioutil.ReadFile(name); string(b) != "ab\ncd" {
+		t.Errorf("line is not buffered: %q", b)
+	}
+	Fputc('\n', f)
+	if b, _ := ioutil.ReadFile(name); string(b) != "ab\ncde\n" {
+		t.Errorf("line is not flushed: %q", b)
+	}
+
+	// reading with pushback
+	Rewind(f)
+	if c := Fgetc(f); c != 'a' {
+		t.Errorf("not valid character: %q", c)
+	}
+	if Ungetc('x', f) != 'x' {
+		t.Errorf("cannot push back character")
+	}
+	if pos := Ftell(f); pos != 0 {
+		t.Errorf("not valid position after ungetc: %d", pos)
+	}
+	line := make([]byte, 10)
+	if Fgets(line, 10, f) == nil || CStringToString(line) != "xb\n" {
+		t.Errorf("not valid line: %q", line)
+	}
+
+	// end-of-file indicator is set only after reading
+	Fseek(f, 0, 2)
+	if Feof(f) != 0 {
+		t.Errorf("end-of-file before reading")
+	}
+	if c := Fgetc(f); c != -1 || Feof(f) == 0 {
+		t.Errorf("end-of-file is not found: %d", c)
+	}
+	Clearerr(f)
+	if Feof(f) != 0 || Ferror(f) != 0 {
+		t.Errorf("indicators are not cleared")
+	}
+
+	// reopen for reading
+	f = Freopen(nil, []byte("r\x00"), f)
+	if f == nil {
+		t.Fatal("cannot reopen file")
+	}
+	Fputc('z', f)
+	if Fflush(f) != -1 || Ferror(f) == 0 {
+		t.Errorf("error of writing into read-only stream is not found")
+	}
+	Fclose(f)
+}
+
+func TestMemoryStream(t *testing.T) {
+	// fmemopen
+	buf := make([]byte, 8)
+	f := Fmemopen(buf, 8, []byte("w+\x00"))
+	Fprintf(f, []byte("%d-%s\x00"), int32(42), []byte("ab\x00"))
+	Fflush(f)
+	if s := CStringToString(buf); s != "42-ab" {
+		t.Errorf("not valid memory: %q", s)
+	}
+	Rewind(f)
+	a := make([]int32, 1)
+	if Fscanf(f, []byte("%d\x00"), a) != 1 || a[0] != 42 {
+		t.Errorf("not valid reading from memory: %d", a[0])
+	}
+	Fseek(f, 0, 2)
+	Fputs([]byte("overflow\x00"), f)
+	if Fflush(f) == 0 || Ferror(f) == 0 {
+		t.Errorf("overflow of memory is not found")
+	}
+	Fclose(f)
+
+	f = Fmemopen([]byte("x y\x00"), 3, []byte("r\x00"))
+	if Fgetc(f) != 'x' || Fgetc(f) != ' ' || Fgetc(f) != 'y' || Fgetc(f) != -1 {
+		t.Errorf("not valid reading")
+	}
+	Fclose(f)
+
+	// open_memstream
+	ptr := make([][]byte, 1)
+	size := make([]uint32, 1)
+	f = OpenMemstream(ptr, size)
+	Fputs([]byte("hello\x00"), f)
+	if size[0] != 0 {
+		t.Errorf("size is changed before flushing: %d", size[0])
+	}
+	Fflush(f)
+	if CStringToString(ptr[0]) != "hello" || size[0] != 5 {
+		t.Errorf("not valid memory: %q %d", ptr[0], size[0])
+	}
+	Fprintf(f, []byte(", %s\x00"), []byte("world\x00"))
+	Fclose(f)
+	if CStringToString(ptr[0]) != "hello, world" || size[0] != 12 {
+		t.Errorf("not valid memory: %q %d", ptr[0], size[0])
+	}
+}
+
+func TestGoStream(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriteFile(&out)
+	Fprintf(w, []byte("%5.2f\n\x00"), 3.14159)
+	if out.Len() != 0 {
+		t.Errorf("stream is not buffered")
+	}
+	if err := w.Flush(); err != nil || out.String() != " 3.14\n" {
+		t.Errorf("not valid output: %q %v", out.String(), err)
+	}
+	if Fgetc(w) != -1 || Ferror(w) == 0 {
+		t.Errorf("reading from write-only stream")
+	}
+	if Fseek(w, 0, 0) != -1 {
+		t.Errorf("seeking of not seekable stream")
+	}
+
+	r := NewReadFile(strings.NewReader("12 abc"))
+	a := make([]int32, 1)
+	if Fscanf(r, []byte("%d\x00"), a) != 1 || a[0] != 12 {
+		t.Errorf("not valid reading: %d", a[0])
+	}
+	Ungetc('_', r)
+	rest, err := ioutil.ReadAll(r)
+	if err != nil || string(rest) != "_ abc" {
+		t.Errorf("not valid rest of stream: %q %v", rest, err)
+	}
+	if Fputc('x', r) != -1 || Ferror(r) == 0 {
+		t.Errorf("writing into read-only stream")
+	}
+
+	var buf bytes.Buffer
+	w = NewWriteFile(&buf)
+	if _, err := io.Copy(w, NewReadFile(strings.NewReader("copy"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil || buf.String() != "copy" {
+		t.Errorf("not valid copy: %q %v", buf.String(), err)
+	}
+}
+
+func TestFlushAll(t *testing.T) {
+	name := t.TempDir() + "/flush.txt"
+	f := Fopen([]byte(name+"\x00"), []byte("w\x00"))
+	if f == nil {
+		t.Fatalf("cannot open file")
+	}
+	defer Fclose(f)
+	var out bytes.Buffer
+	w := NewWriteFile(&out)
+	Fputs([]byte("go\x00"), w)
+	Fputs([]byte("file\x00"), f)
+
+	if err := FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "go" {
+		t.Errorf("Go stream is not flushed: %q", out.String())
+	}
+	if b, err := ioutil.ReadFile(name); err != nil || string(b) != "file" {
+		t.Errorf("file is not flushed: %q %v", b, err)
+	}
+
+	pr, pw := io.Pipe()
+	pr.Close()
+	e := NewWriteFile(pw)
+	Fputc('x', e)
+	if err := FlushAll(); err != io.ErrClosedPipe {
+		t.Errorf("error of stream is not returned: %v", err)
+	}
+	Fclose(e)
+}
+
+func TestStdio(t *testing.T) {
+	var out1, out2, errs bytes.Buffer
+	s1 := NewStdio(strings.NewReader("7 x\nline\n"), &out1, &errs)
+	s2 := NewStdio(nil, &out2, nil)
+
+	a := make([]int32, 1)
+	if s1.Scanf([]byte("%d \x00"), a) != 1 || a[0] != 7 {
+		t.Errorf("not valid scanf: %d", a[0])
+	}
+	if c := s1.Getchar(); c != 'x' {
+		t.Errorf("not valid getchar: %q", c)
+	}
+	s1.Getchar()
+	line := make([]byte, 10)
+	if s1.Gets(line) == nil || CStringToString(line) != "line" {
+		t.Errorf("not valid gets: %q", line)
+	}
+	if s1.Getchar() != -1 {
+		t.Errorf("end of file is not found")
+	}
+
+	s1.Printf([]byte("%s=%d\n\x00"), []byte("a\x00"), a[0])
+	s1.Putchar('!')
+	s1.Puts([]byte("end\x00"))
+	s1.Perror([]byte("file\x00"))
+	s2.Printf([]byte("other\x00"))
+
+	if out1.String() != "a=7\n!end\n" {
+		t.Errorf("not valid stdout: %q", out1.String())
+	}
+	if errs.String() != "file: No such file or directory\n" {
+		t.Errorf("not valid stderr: %q", errs.String())
+	}
+	if out2.String() != "other" {
+		t.Errorf("not valid stdout of other program: %q", out2.String())
+	}
+	if s2.Getchar() != -1 || Ferror(s2.Stdin) == 0 {
+		t.Errorf("reading from nil stdin")
+	}
+}
+
+func TestStreamThreads(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriteFile(&out)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Fputs([]byte("line\n\x00"), w)
+				if j%10 == 0 {
+					Fflush(nil)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if Fclose(w) != 0 {
+		t.Fatal("cannot close stream")
+	}
+	if out.String() != strings.Repeat("line\n", 800) {
+		t.Errorf("not valid output of threads: %d bytes", out.Len())
+	}
+}
@@ -497,10 +497,13 @@ func Atexit(f func()) {
 	AtexitFuncs = append(AtexitFuncs, f)
 }
 
+// AtexitRun runs functions registered by atexit and writes unwritten data
+// of all opened streams, as at the end of C program.
 func AtexitRun() {
 	for i := len(AtexitFuncs) - 1; i >= 0; i-- {
 		AtexitFuncs[i]()
 	}
+	FlushAll()
 }
 
 func Int32() int32 {
@@ -512,5 +515,6 @@ func Int32() int32 {
 }
 
 func Exit(e int32) {
+	FlushAll()
 	os.Exit(int(e))
 }
@@ -0,0 +1,434 @@
+package noarch
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// Modes of stream buffering as defined in stdio.h: _IOFBF, _IOLBF, _IONBF.
+const (
+	ioFullyBuffered int32 = 0
+	ioLineBuffered  int32 = 1
+	ioUnbuffered    int32 = 2
+)
+
+// bufferSize is default size of stream buffer, as BUFSIZ in stdio.h.
+const bufferSize = 8192
+
+var (
+	errNotReadable = errors.New("stream is not opened for reading")
+	errNotWritable = errors.New("stream is not opened for writing")
+	errNotSeekable = errors.New("stream is not seekable")
+)
+
+// streams is list of opened streams for flushing at the end of program.
+var streams = struct {
+	sync.Mutex
+	list map[*File]bool
+}{list: map[*File]bool{}}
+
+// newStream creates stream for reading from r and writing into w.
+func newStream(r io.Reader, w io.Writer, mode int32) *File {
+	stream := new(File)
+	stream.open(r, w, mode)
+	return stream
+}
+
+// open initialize stream for reading from r and writing into w. Any of them
+// may be nil. If r or w is io.Seeker or io.Closer, then it is used for
+// seeking and closing of stream.
+//
+// Mutex of stream is kept, because freopen() opens stream under lock.
+func (f *File) open(r io.Reader, w io.Writer, mode int32) {
+	f.OsFile, f.tie, f.sync = nil, nil, nil
+	f.reader, f.writer, f.seeker, f.closer = r, w, nil, nil
+	f.mode, f.size = mode, bufferSize
+	f.input, f.pos, f.output, f.pushback = nil, 0, nil, nil
+	f.eof, f.err, f.temporary = false, nil, false
+	for _, v := range []interface{}{r, w} {
+		if s, ok := v.(io.Seeker); ok && f.seeker == nil {
+			f.seeker = s
+		}
+		if c, ok := v.(io.Closer); ok && f.closer == nil {
+			f.closer = c
+		}
+		if file, ok := v.(*os.File); ok {
+			f.OsFile = file
+		}
+	}
+	streams.Lock()
+	streams.list[f] = true
+	streams.Unlock()
+}
+
+// NewReadFile creates read-only stream from Go reader. If r implements
+// io.Seeker, then stream is seekable. If r implements io.Closer, then it is
+// closed by fclose().
+func NewReadFile(r io.Reader) *File {
+	return newStream(r, nil, ioFullyBuffered)
+}
+
+// NewWriteFile creates write-only stream into Go writer. Stream is fully
+// buffered, so data is written into w only after fflush() or fclose(). If w
+// implements io.Seeker, then stream is seekable. If w implements io.Closer,
+// then it is closed by fclose().
+//
+// Unclosed streams are flushed only by exit() or at the end of main(). Go
+// program that calls transpiled code, for example library created with flag
+// -lib, must call Flush, Close or FlushAll itself.
+func NewWriteFile(w io.Writer) *File {
+	return newStream(nil, w, ioFullyBuffered)
+}
+
+// NewReadWriteFile creates stream for reading and writing by Go value, for
+// example *os.File or network connection. Stream is flushed as stream of
+// NewWriteFile.
+func NewReadWriteFile(rw io.ReadWriter) *File {
+	return newStream(rw, rw, ioFullyBuffered)
+}
+
+// Read reads data from stream, so File may be used as io.Reader in Go code.
+func (f *File) Read(p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(f.pushback) == 0 && f.pos >= len(f.input) && !f.fill() {
+		if f.err != nil {
+			return 0, f.err
+		}
+		return 0, io.EOF
+	}
+	for n < len(p) && len(f.pushback) > 0 {
+		p[n] = byte(f.readByte())
+		n++
+	}
+	m := copy(p[n:], f.input[f.pos:])
+	f.pos += m
+	return n + m, nil
+}
+
+// Write writes data into stream in according to buffering mode, so File
+// may be used as io.Writer in Go code.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if n := f.write(p); n < len(p) {
+		if f.err != nil {
+			return n, f.err
+		}
+		return n, io.ErrShortWrite
+	}
+	return len(p), nil
+}
+
+// Flush writes unwritten data of stream.
+func (f *File) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.flush() {
+		return f.err
+	}
+	return nil
+}
+
+// Close writes unwritten data and closes stream as fclose().
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.close()
+}
+
+// FlushAll writes unwritten data of all opened streams as fflush(NULL),
+// including streams opened by fopen() in transpiled code. Streams are
+// flushed automatically only by exit() and at the end of main(), so Go
+// program that calls transpiled code must call FlushAll before it exits.
+// The first error of streams is returned.
+func FlushAll() (err error) {
+	streams.Lock()
+	list := make([]*File, 0, len(streams.list))
+	for f := range streams.list {
+		list = append(list, f)
+	}
+	streams.Unlock()
+
+	for _, f := range list {
+		f.mu.Lock()
+		if !f.flush() && err == nil {
+			err = f.err
+		}
+		f.mu.Unlock()
+	}
+	return
+}
+
+// flush writes unwritten data of output buffer.
+func (f *File) flush() bool {
+	if len(f.output) > 0 && f.writer == nil {
+		f.output = nil
+		f.err = errNotWritable
+		return false
+	}
+	for len(f.output) > 0 {
+		n, err := f.writer.Write(f.output)
+		f.output = f.output[n:]
+		if err != nil {
+			f.output = nil
+			f.err = err
+			return false
+		}
+	}
+	f.output = nil
+	if f.sync != nil {
+		f.sync()
+	}
+	return true
+}
+
+// unreadAmount return amount of read from file bytes, that was not used
+// by program.
+func (f *File) unreadAmount() int {
+	return len(f.input) - f.pos + len(f.pushback)
+}
+
+// dropInput removes data of input buffer and returns position of file to
+// the position of stream, if possible.
+func (f *File) dropInput() {
+	if n := f.unreadAmount(); n > 0 && f.seeker != nil {
+		f.seeker.Seek(-int64(n), io.SeekCurrent)
+	}
+	f.input, f.pos, f.pushback = f.input[:0], 0, nil
+}
+
+// fill reads next part of file into input buffer.
+func (f *File) fill() bool {
+	if !f.flush() {
+		return false
+	}
+	if f.reader == nil {
+		f.err = errNotReadable
+		return false
+	}
+	if f.tie != nil {
+		// Like in C, output of stdout is flushed before reading of stdin.
+		f.tie.mu.Lock()
+		f.tie.flush()
+		f.tie.mu.Unlock()
+	}
+	size := f.size
+	if f.mode == ioUnbuffered || size <= 0 {
+		size = 1
+	}
+	if cap(f.input) < size {
+		f.input = make([]byte, size)
+	}
+	n, err := f.reader.Read(f.input[:size])
+	f.input, f.pos = f.input[:n], 0
+	if n > 0 {
+		return true
+	}
+	if err == io.EOF || err == nil {
+		f.eof = true
+	} else {
+		f.err = err
+	}
+	return false
+}
+
+// readByte reads next byte of stream. Returns -1 at the end of file or
+// on error.
+func (f *File) readByte() int32 {
+	if n := len(f.pushback); n > 0 {
+		c := f.pushback[n-1]
+		f.pushback = f.pushback[:n-1]
+		return int32(c)
+	}
+	if f.pos >= len(f.input) && !f.fill() {
+		return -1
+	}
+	c := f.input[f.pos]
+	f.pos++
+	return int32(c)
+}
+
+// unget pushes character back into stream as ungetc().
+func (f *File) unget(c byte) {
+	f.pushback = append(f.pushback, c)
+	f.eof = false
+}
+
+// read reads bytes of stream into p until p is full or the end of file.
+func (f *File) read(p []byte) (n int) {
+	for n < len(p) && len(f.pushback) > 0 {
+		p[n] = byte(f.readByte())
+		n++
+	}
+	for n < len(p) {
+		if f.pos >= len(f.input) {
+			if len(p)-n >= f.size && f.mode != ioUnbuffered &&
+				f.reader != nil && f.flush() {
+				// large read directly into destination
+				m, err := io.ReadFull(f.reader, p[n:])
+				n += m
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					f.eof = true
+				} else if err != nil {
+					f.err = err
+				}
+				return
+			}
+			if !f.fill() {
+				return
+			}
+		}
+		m := copy(p[n:], f.input[f.pos:])
+		f.pos += m
+		n += m
+	}
+	return
+}
+
+// write writes bytes into stream in according to buffering mode.
+func (f *File) write(p []byte) int {
+	f.dropInput()
+	if f.writer == nil {
+		f.err = errNotWritable
+		return 0
+	}
+	if f.mode == ioUnbuffered {
+		if !f.flush() {
+			return 0
+		}
+		n, err := f.writer.Write(p)
+		if err != nil {
+			f.err = err
+		}
+		return n
+	}
+	f.output = append(f.output, p...)
+	if len(f.output) >= f.size {
+		if !f.flush() {
+			return 0
+		}
+	} else if f.mode == ioLineBuffered {
+		for _, c := range p {
+			if c == '\n' {
+				if !f.flush() {
+					return 0
+				}
+				break
+			}
+		}
+	}
+	return len(p)
+}
+
+// seek changes position of stream. Data of buffers are written or removed.
+func (f *File) seek(offset int64, whence int) (int64, error) {
+	if !f.flush() {
+		return -1, f.err
+	}
+	if f.seeker == nil {
+		return -1, errNotSeekable
+	}
+	if whence == io.SeekCurrent {
+		offset -= int64(f.unreadAmount())
+	}
+	f.input, f.pos, f.pushback = f.input[:0], 0, nil
+	pos, err := f.seeker.Seek(offset, whence)
+	if err != nil {
+		return -1, err
+	}
+	f.eof = false
+	return pos, nil
+}
+
+// tell returns position of stream.
+func (f *File) tell() int64 {
+	if f.seeker == nil {
+		return -1
+	}
+	pos, err := f.seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	return pos - int64(f.unreadAmount()) + int64(len(f.output))
+}
+
+// close writes unwritten data and closes file of stream.
+func (f *File) close() (err error) {
+	streams.Lock()
+	delete(streams.list, f)
+	streams.Unlock()
+
+	if !f.flush() {
+		err = f.err
+	}
+	f.input, f.pos, f.pushback = nil, 0, nil
+	if f.closer != nil {
+		if e := f.closer.Close(); err == nil {
+			err = e
+		}
+	}
+	if f.temporary && f.OsFile != nil {
+		os.Remove(f.OsFile.Name())
+	}
+	return
+}
+
+// memoryFile is file in memory of fmemopen() and open_memstream().
+type memoryFile struct {
+	buf   []byte // memory of file
+	pos   int    // position in file
+	end   int    // size of file
+	fixed bool   // memory has fixed size
+}
+
+var errNoSpace = errors.New("no space left in memory of stream")
+
+func (m *memoryFile) Read(p []byte) (int, error) {
+	if m.pos >= m.end {
+		return 0, io.EOF
+	}
+	n := copy(p, m.buf[m.pos:m.end])
+	m.pos += n
+	return n, nil
+}
+
+func (m *memoryFile) Write(p []byte) (n int, err error) {
+	if need := m.pos + len(p); need > len(m.buf) && !m.fixed {
+		m.buf = append(m.buf, make([]byte, need-len(m.buf))...)
+	}
+	if m.pos < len(m.buf) {
+		n = copy(m.buf[m.pos:], p)
+	}
+	m.pos += n
+	if m.pos > m.end {
+		m.end = m.pos
+	}
+	if m.end < len(m.buf) {
+		// C string in memory is always terminated
+		m.buf[m.end] = 0
+	}
+	if n < len(p) {
+		err = errNoSpace
+	}
+	return
+}
+
+func (m *memoryFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += int64(m.pos)
+	case io.SeekEnd:
+		offset += int64(m.end)
+	}
+	if offset < 0 || (m.fixed && offset > int64(len(m.buf))) {
+		return -1, os.ErrInvalid
+	}
+	m.pos = int(offset)
+	return offset, nil
+}
@@ -0,0 +1,148 @@
+package noarch
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ThrdT is identifier of thread from threads.h.
+type ThrdT = PthreadT
+
+// MtxT is mutex from threads.h.
+type MtxT = PthreadMutexT
+
+// CndT is condition variable from threads.h.
+type CndT = PthreadCondT
+
+// OnceFlag is control of once initialization from threads.h. Zero value is
+// ready for use, so ONCE_FLAG_INIT is zero value.
+type OnceFlag struct {
+	once sync.Once
+}
+
+// Results of functions from threads.h
+const (
+	ThrdSuccess  int32 = 0
+	ThrdBusy     int32 = 1
+	ThrdError    int32 = 2
+	ThrdNomem    int32 = 3
+	ThrdTimedout int32 = 4
+)
+
+// Types of mutex from threads.h
+const (
+	MtxPlain     int32 = 0
+	MtxRecursive int32 = 1
+	MtxTimed     int32 = 2
+)
+
+func thrdResult(r int32) int32 {
+	if r != 0 {
+		return ThrdError
+	}
+	return ThrdSuccess
+}
+
+// ThrdCreate - creates a thread.
+func ThrdCreate(thr []ThrdT, f func(interface{}) int32, arg interface{}) int32 {
+	if f == nil {
+		return ThrdError
+	}
+	return thrdResult(PthreadCreate(thr, nil, func(arg interface{}) interface{} {
+		return f(arg)
+	}, arg))
+}
+
+// ThrdJoin - blocks until a thread terminates.
+func ThrdJoin(thr ThrdT, res []int32) int32 {
+	value := make([]interface{}, 1)
+	if r := PthreadJoin(thr, value); r != 0 {
+		return ThrdError
+	}
+	if len(res) > 0 {
+		res[0], _ = value[0].(int32)
+	}
+	return ThrdSuccess
+}
+
+// ThrdDetach - detaches a thread.
+func ThrdDetach(thr ThrdT) int32 {
+	return thrdResult(PthreadDetach(thr))
+}
+
+// ThrdExit - terminates the calling thread. Function cannot be called in
+// main thread.
+func ThrdExit(res int32) {
+	PthreadExit(res)
+}
+
+// ThrdEqual - checks if two identifiers refer to the same thread.
+func ThrdEqual(lhs, rhs ThrdT) int32 {
+	return PthreadEqual(lhs, rhs)
+}
+
+// ThrdYield - yields the current time slice.
+func ThrdYield() {
+	runtime.Gosched()
+}
+
+// MtxInit - creates a mutex. Recursive mutexes are not supported.
+func MtxInit(mutex []MtxT, typ int32) int32 {
+	if typ&MtxRecursive != 0 {
+		return ThrdError
+	}
+	return thrdResult(PthreadMutexInit(mutex, nil))
+}
+
+// MtxLock - blocks until locks a mutex.
+func MtxLock(mutex []MtxT) int32 {
+	return thrdResult(PthreadMutexLock(mutex))
+}
+
+// MtxTrylock - locks a mutex or returns without blocking if already locked.
+func MtxTrylock(mutex []MtxT) int32 {
+	if PthreadMutexTrylock(mutex) != 0 {
+		return ThrdBusy
+	}
+	return ThrdSuccess
+}
+
+// MtxUnlock - unlocks a mutex.
+func MtxUnlock(mutex []MtxT) int32 {
+	return thrdResult(PthreadMutexUnlock(mutex))
+}
+
+// MtxDestroy - destroys a mutex.
+func MtxDestroy(mutex []MtxT) {
+	PthreadMutexDestroy(mutex)
+}
+
+// CndInit - creates a condition variable.
+func CndInit(cond []CndT) int32 {
+	return thrdResult(PthreadCondInit(cond, nil))
+}
+
+// CndSignal - unblocks one thread blocked on a condition variable.
+func CndSignal(cond []CndT) int32 {
+	return thrdResult(PthreadCondSignal(cond))
+}
+
+// CndBroadcast - unblocks all threads blocked on a condition variable.
+func CndBroadcast(cond []CndT) int32 {
+	return thrdResult(PthreadCondBroadcast(cond))
+}
+
+// CndWait - blocks on a condition variable.
+func CndWait(cond []CndT, mutex []MtxT) int32 {
+	return thrdResult(PthreadCondWait(cond, mutex))
+}
+
+// CndDestroy - destroys a condition variable.
+func CndDestroy(cond []CndT) {
+	PthreadCondDestroy(cond)
+}
+
+// CallOnce - calls a function exactly once.
+func CallOnce(flag []OnceFlag, f func()) {
+	flag[0].once.Do(f)
+}
@@ -0,0 +1,138 @@
+package preprocessor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// CompileCommand is entry of compilation database `compile_commands.json`,
+// generated by CMake or Bear.
+//
+// See: https://clang.llvm.org/docs/JSONCompilationDatabase.html
+type CompileCommand struct {
+	Directory string   `json:"directory"`
+	File      string   `json:"file"`
+	Command   string   `json:"command,omitempty"`
+	Arguments []string `json:"arguments,omitempty"`
+	Output    string   `json:"output,omitempty"`
+}
+
+// ReadCompilationDatabase reads compilation database from file.
+func ReadCompilationDatabase(filename string) (cs []CompileCommand, err error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read compilation database: %v", err)
+	}
+	if err = json.Unmarshal(b, &cs); err != nil {
+		return nil, fmt.Errorf("cannot parse compilation database %s: %v", filename, err)
+	}
+	for i := range cs {
+		if cs[i].File == "" {
+			return nil, fmt.Errorf("compilation database %s: entry %d without file", filename, i)
+		}
+	}
+	return
+}
+
+// SourceFile returns absolute path of source file.
+func (c CompileCommand) SourceFile() string {
+	return c.path(c.File)
+}
+
+func (c CompileCommand) path(file string) string {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(c.Directory, file)
+	}
+	return filepath.Clean(file)
+}
+
+// Flags returns flags of compiler, that changes preprocessing and parsing of
+// source: include paths, macro definitions and version of C standard.
+// Relative include paths are converted to absolute.
+func (c CompileCommand) Flags() (flags []string) {
+	args := c.Arguments
+	if len(args) == 0 {
+		args = SplitCommand(c.Command)
+	}
+
+	// flags with argument, true for path argument
+	known := []struct {
+		flag   string
+		isPath bool
+	}{
+		{"-include", true}, {"-imacros", true}, {"-isystem", true},
+		{"-iquote", true}, {"-idirafter", true}, {"-I", true},
+		{"-D", false}, {"-U", false},
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-std=") || strings.HasPrefix(arg, "--std=") {
+			flags = append(flags, "-std="+arg[strings.Index(arg, "=")+1:])
+			continue
+		}
+		for _, k := range known {
+			if !strings.HasPrefix(arg, k.flag) {
+				continue
+			}
+			value := arg[len(k.flag):]
+			if value == "" {
+				if i+1 >= len(args) {
+					break
+				}
+				i++
+				value = args[i]
+			}
+			if k.isPath {
+				value = c.path(value)
+			}
+			flags = append(flags, k.flag+value)
+			break
+		}
+	}
+	return
+}
+
+// SplitCommand splits command line into arguments in according to rules
+// of shell: arguments are separated by spaces, quotes and backslash are
+// used for arguments with spaces.
+func SplitCommand(command string) (args []string) {
+	var (
+		arg     []rune
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range command {
+		switch {
+		case escaped:
+			arg = append(arg, r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg = append(arg, r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, string(arg))
+				arg, inArg = arg[:0], false
+			}
+		default:
+			arg = append(arg, r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, string(arg))
+	}
+	return
+}
@@ -0,0 +1,83 @@
+package preprocessor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tcs := []struct {
+		command string
+		args    []string
+	}{
+		{"cc -c a.c", []string{"cc", "-c", "a.c"}},
+		{`cc  -DMSG="hello world" -I'my dir' a\ b.c`,
+			[]string{"cc", "-DMSG=hello world", "-Imy dir", "a b.c"}},
+		{`cc -DQ=\"q\" ""`, []string{"cc", `-DQ="q"`, ""}},
+	}
+	for _, tc := range tcs {
+		if args := SplitCommand(tc.command); !reflect.DeepEqual(args, tc.args) {
+			t.Errorf("command %s: %q != %q", tc.command, args, tc.args)
+		}
+	}
+}
+
+func TestCompilationDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c4go-compdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "compile_commands.json")
+	err = ioutil.WriteFile(filename, []byte(`[
+{
+	"directory": "/project/build",
+	"command": "/usr/bin/cc -DNDEBUG -I../include -I /usr/local/include -std=gnu99 -O2 -Wall -o list.o -c ../src/list.c",
+	"file": "../src/list.c"
+},
+{
+	"directory": "/project",
+	"arguments": ["clang", "-U", "DEBUG", "-isystem", "third_party", "--std=c11", "-c", "src/map.c"],
+	"file": "/project/src/map.c"
+}
+]`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := ReadCompilationDatabase(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 {
+		t.Fatalf("not valid amount of entries: %d", len(cs))
+	}
+	tcs := []struct {
+		file  string
+		flags []string
+	}{
+		{"/project/src/list.c", []string{"-DNDEBUG", "-I/project/include",
+			"-I/usr/local/include", "-std=gnu99"}},
+		{"/project/src/map.c", []string{"-UDEBUG", "-isystem/project/third_party",
+			"-std=c11"}},
+	}
+	for i, tc := range tcs {
+		if file := cs[i].SourceFile(); file != tc.file {
+			t.Errorf("not valid file: %s != %s", file, tc.file)
+		}
+		if flags := cs[i].Flags(); !reflect.DeepEqual(flags, tc.flags) {
+			t.Errorf("not valid flags of %s: %q != %q", tc.file, flags, tc.flags)
+		}
+	}
+
+	if err = ioutil.WriteFile(filename, []byte(`[{"directory": "/"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadCompilationDatabase(filename); err == nil {
+		t.Errorf("entry without file is accepted")
+	}
+}
@@ -0,0 +1,250 @@
+package preprocessor
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// Macro - macro defined in user source
+// Example:
+//
+//	#define N 100
+//	#define MAX(a,b) ((a)>(b)?(a):(b))
+//
+// Name = "N", Body = "100"
+// Name = "MAX", Params = {"a","b"}, Body = "((a)>(b)?(a):(b))"
+type Macro struct {
+	Name string
+	Body string
+	File string // absolute path of file with macro definition
+	Line int    // line of macro definition
+
+	// only for function-like macro
+	IsFunction bool
+	Params     []string
+
+	// Column, ColumnEnd - position of first and last token of body in
+	// line of macro definition. Zero for multiline macros.
+	Column    int
+	ColumnEnd int
+}
+
+// GetMacros return object-like macros defined in user sources and
+// still defined at the end of preprocessing
+func (f FilePP) GetMacros() (macros []Macro) {
+	for _, m := range f.macros {
+		if !m.IsFunction {
+			macros = append(macros, m)
+		}
+	}
+	return
+}
+
+// GetFunctionMacros return function-like macros defined in user sources
+// and still defined at the end of preprocessing
+func (f FilePP) GetFunctionMacros() (macros []Macro) {
+	for _, m := range f.macros {
+		if m.IsFunction {
+			macros = append(macros, m)
+		}
+	}
+	return
+}
+
+// getMacroDefinitions - get list of all macros defined at the end of
+// preprocessing in format `-dM`.
+// Example:
+//
+//	#define N 100
+//	#define __STDC__ 1
+func getMacroDefinitions(inputFiles, clangFlags []string, cppCode bool) (
+	defines map[string]Macro, err error) {
+	var out bytes.Buffer
+	out, err = runPreprocessor(inputFiles, clangFlags, []string{"-dM"}, cppCode)
+	if err != nil {
+		return
+	}
+	defines = map[string]Macro{}
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		m, ok := parseDefine(scanner.Text())
+		if !ok {
+			continue
+		}
+		defines[m.Name] = m
+	}
+	return
+}
+
+// parseDefine parse macro definition.
+// Variadic macros are ignored.
+func parseDefine(line string) (m Macro, ok bool) {
+	line = removeComments(line)
+	groups := util.GetRegex(`^\s*#\s*define\s+([A-Za-z_]\w*)(\(([^()]*)\))?(\s+.*)?$`).
+		FindStringSubmatchIndex(line)
+	if len(groups) == 0 {
+		return
+	}
+	m.Name = line[groups[2]:groups[3]]
+	if groups[4] >= 0 {
+		m.IsFunction = true
+		for _, param := range strings.Split(line[groups[6]:groups[7]], ",") {
+			param = strings.TrimSpace(param)
+			if param == "" {
+				continue
+			}
+			if strings.Contains(param, "...") {
+				return
+			}
+			m.Params = append(m.Params, param)
+		}
+	}
+	if groups[8] >= 0 {
+		body := line[groups[8]:groups[9]]
+		m.Body = strings.Join(strings.Fields(body), " ")
+		if tokens := tokenPositions(body); len(tokens) > 0 {
+			m.Column = groups[8] + tokens[0] + 1
+			m.ColumnEnd = groups[8] + tokens[len(tokens)-1] + 1
+		}
+	}
+	ok = true
+	return
+}
+
+// tokenPositions return start positions of C tokens in line
+func tokenPositions(line string) (positions []int) {
+	punctuators := []string{
+		"...", "<<=", ">>=",
+		"->", "++", "--", "<<", ">>", "<=", ">=", "==", "!=", "&&", "||",
+		"*=", "/=", "%=", "+=", "-=", "&=", "^=", "|=", "##",
+	}
+	isWord := func(c byte) bool {
+		return c == '_' || c == '.' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') ||
+			('0' <= c && c <= '9')
+	}
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+			continue
+		case c == '"' || c == '\'':
+			positions = append(positions, i)
+			for i++; i < len(line) && line[i] != c; i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+			i++
+			continue
+		case isWord(c):
+			positions = append(positions, i)
+			for i < len(line) && isWord(line[i]) {
+				i++
+			}
+			continue
+		}
+		positions = append(positions, i)
+		size := 1
+		for _, p := range punctuators {
+			if strings.HasPrefix(line[i:], p) {
+				size = len(p)
+				break
+			}
+		}
+		i += size
+	}
+	return
+}
+
+// removeComments replace C comments in single line of code by spaces
+func removeComments(line string) string {
+	b := []byte(line)
+	var quote byte
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '/' && i+1 < len(b) && b[i+1] == '/':
+			return string(b[:i])
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			end := strings.Index(string(b[i+2:]), "*/")
+			if end < 0 {
+				return string(b[:i])
+			}
+			for j := i; j < i+end+4; j++ {
+				b[j] = ' '
+			}
+			i += end + 3
+		}
+	}
+	return string(b)
+}
+
+// parseMacrosFromSource return all macro definitions from C source with
+// position of definition
+func parseMacrosFromSource(file string, source []byte) (macros []Macro) {
+	lines := strings.Split(string(source), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		pos := i + 1
+		// multiline macro
+		multiline := false
+		for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + " " + strings.TrimRight(lines[i], "\r")
+			multiline = true
+		}
+		m, ok := parseDefine(line)
+		if !ok {
+			continue
+		}
+		if multiline {
+			m.Column, m.ColumnEnd = 0, 0
+		}
+		m.File = file
+		m.Line = pos
+		macros = append(macros, m)
+	}
+	return
+}
+
+// getUserMacros return macros from user sources, which are still
+// defined at the end of preprocessing with the same body
+func getUserMacros(userSources []string, defines map[string]Macro) (
+	macros []Macro, err error) {
+	for _, us := range userSources {
+		var file string
+		file, err = filepath.Abs(us)
+		if err != nil {
+			return
+		}
+		var source []byte
+		source, err = ioutil.ReadFile(file)
+		if err != nil {
+			return
+		}
+		for _, m := range parseMacrosFromSource(file, source) {
+			d, ok := defines[m.Name]
+			if !ok || d.Body != m.Body || d.IsFunction != m.IsFunction ||
+				strings.Join(d.Params, ",") != strings.Join(m.Params, ",") {
+				continue
+			}
+			macros = append(macros, m)
+		}
+	}
+	return
+}
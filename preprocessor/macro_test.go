@@ -0,0 +1,69 @@
+package preprocessor
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseDefine(t *testing.T) {
+	testCases := []struct {
+		line string
+		ok   bool
+		m    Macro
+	}{
+		{`#define N 100`, true, Macro{Name: "N", Body: "100", Column: 11, ColumnEnd: 11}},
+		{`  #  define   N   100  `, true, Macro{Name: "N", Body: "100", Column: 19, ColumnEnd: 19}},
+		{`#define B "hello world"`, true, Macro{Name: "B", Body: `"hello world"`, Column: 11, ColumnEnd: 11}},
+		{`#define PT_ANY 0 /* Any property - matches all chars */`, true,
+			Macro{Name: "PT_ANY", Body: "0", Column: 16, ColumnEnd: 16}},
+		{`#define S "/* not comment */" // comment`, true,
+			Macro{Name: "S", Body: `"/* not comment */"`, Column: 11, ColumnEnd: 11}},
+		{`#define EMPTY`, true, Macro{Name: "EMPTY"}},
+		{`#define MAX(a,b) ((a)>(b)?(a):(b))`, true, Macro{
+			Name: "MAX", Body: "((a)>(b)?(a):(b))", IsFunction: true,
+			Params: []string{"a", "b"}, Column: 18, ColumnEnd: 34,
+		}},
+		{`#define SQR( x ) x * x`, true, Macro{
+			Name: "SQR", Body: "x * x", IsFunction: true,
+			Params: []string{"x"}, Column: 18, ColumnEnd: 22,
+		}},
+		{`#define SHIFT(x) (x >>= 1)`, true, Macro{
+			Name: "SHIFT", Body: "(x >>= 1)", IsFunction: true,
+			Params: []string{"x"}, Column: 18, ColumnEnd: 26,
+		}},
+		{`#define LOG(...) printf(__VA_ARGS__)`, false, Macro{}},
+		{`int a = 5;`, false, Macro{}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			m, ok := parseDefine(tc.line)
+			if ok != tc.ok || (ok && !reflect.DeepEqual(m, tc.m)) {
+				t.Errorf("Not same: `%s`\nactual   : %#v %v\nexpected : %#v %v",
+					tc.line, m, ok, tc.m, tc.ok)
+			}
+		})
+	}
+}
+
+func TestParseMacrosFromSource(t *testing.T) {
+	source := "#define A 1\n" +
+		"int a = A;\n" +
+		"#define LONG \\\n" +
+		"    42\n" +
+		"#define F(x) x\n" +
+		"#define C 'c'\n"
+	macros := parseMacrosFromSource("f.c", []byte(source))
+	expected := []Macro{
+		{Name: "A", Body: "1", File: "f.c", Line: 1, Column: 11, ColumnEnd: 11},
+		{Name: "LONG", Body: "42", File: "f.c", Line: 3},
+		{Name: "F", Body: "x", File: "f.c", Line: 5, IsFunction: true,
+			Params: []string{"x"}, Column: 14, ColumnEnd: 14},
+		{Name: "C", Body: "'c'", File: "f.c", Line: 6, Column: 11, ColumnEnd: 11},
+	}
+	if !reflect.DeepEqual(macros, expected) {
+		t.Errorf("Not same macros:\nactual   : %#v\nexpected : %#v",
+			macros, expected)
+	}
+}
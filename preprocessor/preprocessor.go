@@ -3,6 +3,7 @@ package preprocessor
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -113,6 +114,7 @@ type FilePP struct {
 	pp       []byte
 	comments []Comment
 	includes []IncludeHeader
+	macros   []Macro
 }
 
 // NewFilePP create a struct FilePP with results of analyzing
@@ -152,6 +154,17 @@ func NewFilePP(inputFiles, clangFlags []string, cppCode bool) (
 		userSource[us[j]] = true
 	}
 
+	// Generate list of user macros
+	var defines map[string]Macro
+	defines, err = getMacroDefinitions(inputFiles, clangFlags, cppCode)
+	if err != nil {
+		return
+	}
+	f.macros, err = getUserMacros(us, defines)
+	if err != nil {
+		return
+	}
+
 	// Merge the entities
 	var lines []string
 	for i := range allItems {
@@ -288,6 +301,73 @@ func (f FilePP) GetIncludeFiles() []IncludeHeader {
 	return f.includes
 }
 
+// filePPJSON is FilePP in JSON format.
+type filePPJSON struct {
+	Entities []entityJSON
+	Source   string
+	Comments []Comment
+	Includes []IncludeHeader
+	Macros   []Macro
+}
+
+type entityJSON struct {
+	Position int
+	Include  string
+	Other    string
+	Lines    []string
+}
+
+// MarshalJSON returns FilePP in JSON format, so results of preprocessor
+// may be stored in cache.
+func (f FilePP) MarshalJSON() ([]byte, error) {
+	v := filePPJSON{
+		Source:   string(f.pp),
+		Comments: f.comments,
+		Includes: f.includes,
+		Macros:   f.macros,
+	}
+	for _, e := range f.entities {
+		lines := make([]string, len(e.lines))
+		for i := range e.lines {
+			lines[i] = *e.lines[i]
+		}
+		v.Entities = append(v.Entities, entityJSON{
+			Position: e.positionInSource,
+			Include:  e.include,
+			Other:    e.other,
+			Lines:    lines,
+		})
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON restores FilePP from JSON format.
+func (f *FilePP) UnmarshalJSON(b []byte) error {
+	var v filePPJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*f = FilePP{
+		pp:       []byte(v.Source),
+		comments: v.Comments,
+		includes: v.Includes,
+		macros:   v.Macros,
+	}
+	for _, e := range v.Entities {
+		lines := make([]*string, len(e.Lines))
+		for i := range e.Lines {
+			lines[i] = &e.Lines[i]
+		}
+		f.entities = append(f.entities, entity{
+			positionInSource: e.Position,
+			include:          e.Include,
+			other:            e.Other,
+			lines:            lines,
+		})
+	}
+	return nil
+}
+
 // IsUserSource get is it source from user
 func (f FilePP) IsUserSource(in string) bool {
 	for i := range f.includes {
@@ -459,6 +539,13 @@ func analyzeFiles(inputFiles, clangFlags []string, cppCode bool) (
 // See : https://clang.llvm.org/docs/CommandGuide/clang.html
 // clang -E <file>    Run the preprocessor stage.
 func getPreprocessSources(inputFiles, clangFlags []string, cppCode bool) (
+	out bytes.Buffer, err error) {
+	return runPreprocessor(inputFiles, clangFlags, []string{"-C"}, cppCode)
+}
+
+// runPreprocessor run the preprocessor stage for union of all input files
+// with additional flags
+func runPreprocessor(inputFiles, clangFlags, flags []string, cppCode bool) (
 	out bytes.Buffer, err error) {
 	// get temp dir
 	dir, err := ioutil.TempDir("", "c4go-union")
@@ -494,7 +581,8 @@ func getPreprocessSources(inputFiles, clangFlags []string, cppCode bool) (
 	var stderr bytes.Buffer
 
 	var args []string
-	args = append(args, "-E", "-C")
+	args = append(args, "-E")
+	args = append(args, flags...)
 	args = append(args, clangFlags...)
 	args = append(args, unionFileName) // All inputFiles
 
@@ -1,6 +1,10 @@
 package preprocessor
 
-import "testing"
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
 
 func TestNewFilePPFail(t *testing.T) {
 	_, err := NewFilePP([]string{""}, []string{""}, false)
@@ -15,3 +19,30 @@ func TestGetIncludeListFail(t *testing.T) {
 		t.Fatalf("Haven`t error")
 	}
 }
+
+func TestFilePPJSON(t *testing.T) {
+	header, line := "# 1 \"a.c\"", "int a; // comment"
+	f := FilePP{
+		entities: []entity{{positionInSource: 1, include: "a.c",
+			lines: []*string{&header, &line}}},
+		pp:       []byte("# 1 \"a.c\"\nint a; // comment"),
+		comments: []Comment{{File: "a.c", Line: 1, Comment: "// comment"}},
+		includes: []IncludeHeader{{HeaderName: "a.c", BaseHeaderName: "a.c", IsUserSource: true}},
+		macros:   []Macro{{Name: "MAX", Body: "((a)>(b)?(a):(b))", IsFunction: true, Params: []string{"a", "b"}}},
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f2 FilePP
+	if err := json.Unmarshal(b, &f2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f, f2) {
+		t.Errorf("not same results of preprocessor:\n%#v\n%#v", f, f2)
+	}
+	snippet, err := f2.GetSnippet("a.c", 1, 0, 1, 5)
+	if err != nil || string(snippet) != "int a" {
+		t.Errorf("not valid snippet: %q %v", snippet, err)
+	}
+}
@@ -0,0 +1,470 @@
+package program
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/ast"
+)
+
+// BitField is bit-field of C struct.
+//
+// Example:
+//
+//	struct bitstr {
+//		unsigned int a : 1;
+//		unsigned int b : 2;
+//	};
+//
+// All neighbor bit-fields are packed into storage fields of unsigned integer
+// type. In Go code the bit-field is available only by getter and setter
+// methods. Bit-fields of union have no storage fields, because they are
+// located at offset 0 of union memory.
+type BitField struct {
+	Name    string
+	CType   string // declared C type of bit-field
+	Signed  bool   // true, if value of bit-field is signed
+	Run     int    // index of sequence of neighbor bit-fields
+	Storage string // name of storage field
+	Offset  int    // offset in bits inside storage field
+	Width   int    // width in bits
+}
+
+// BitFieldStorage is storage field for packed bit-fields.
+type BitFieldStorage struct {
+	Name   string
+	CType  string // unsigned C integer type or array of bytes
+	Run    int    // index of sequence of neighbor bit-fields
+	Offset int    // offset in bytes inside struct
+}
+
+// BitFieldSetterPrefix is prefix of setter method name for bit-field.
+const BitFieldSetterPrefix = "set_"
+
+// IsBitField return true, if field is C bit-field.
+func IsBitField(f *ast.FieldDecl) bool {
+	return bitFieldWidthExpr(f) != nil
+}
+
+// GetBitFieldStorages return storage fields of sequence of neighbor
+// bit-fields in order of definition.
+func (s *Struct) GetBitFieldStorages(run int) (storages []BitFieldStorage) {
+	for _, st := range s.Storages {
+		if st.Run == run {
+			storages = append(storages, st)
+		}
+	}
+	return
+}
+
+// GetBitFields return all bit-fields of struct in order of offset.
+// Bit-fields of union have no storage fields and are located at offset 0
+// of union, so they are returned in order of definition.
+func (s *Struct) GetBitFields() (bfs []BitField) {
+	if s.Type == UnionType {
+		for k := 0; k < len(s.FieldNames); k++ {
+			if bf, ok := s.BitFields[s.FieldNames[k]]; ok {
+				bfs = append(bfs, bf)
+			}
+		}
+		return
+	}
+	for _, st := range s.Storages {
+		for k := 0; k < len(s.FieldNames); k++ {
+			if bf, ok := s.BitFields[s.FieldNames[k]]; ok && bf.Storage == st.Name {
+				bfs = append(bfs, bf)
+			}
+		}
+	}
+	return
+}
+
+func bitFieldWidthExpr(f *ast.FieldDecl) ast.Node {
+	for _, c := range f.Children() {
+		switch c.(type) {
+		case *ast.ConstantExpr, *ast.IntegerLiteral, *ast.ParenExpr,
+			*ast.ImplicitCastExpr, *ast.CStyleCastExpr,
+			*ast.BinaryOperator, *ast.UnaryOperator:
+			return c
+		}
+	}
+	return nil
+}
+
+// bitFieldWidth return width of bit-field.
+//
+// Example of AST:
+//
+//	FieldDecl 0x2b9e0d0 <line:3:5, col:22> col:18 a 'unsigned int'
+//	`-ConstantExpr 0x2b9e0b0 <col:22> 'int'
+//	  `-IntegerLiteral 0x2b9e090 <col:22> 'int' 1
+func bitFieldWidth(f *ast.FieldDecl) (width int, err error) {
+	node := bitFieldWidthExpr(f)
+	if node == nil {
+		return 0, fmt.Errorf("field `%s` is not bit-field", f.Name)
+	}
+	v, err := evalIntegerExpr(node)
+	if err != nil {
+		return 0, fmt.Errorf("cannot calculate width of bit-field `%s`: %v",
+			f.Name, err)
+	}
+	return int(v), nil
+}
+
+// evalIntegerExpr calculate value of simple integer constant expression
+func evalIntegerExpr(node ast.Node) (v int64, err error) {
+	switch n := node.(type) {
+	case *ast.IntegerLiteral:
+		return strconv.ParseInt(strings.TrimRight(strings.ToLower(n.Value), "ul"), 0, 64)
+
+	case *ast.ConstantExpr:
+		if n.Value != "" {
+			return strconv.ParseInt(n.Value, 0, 64)
+		}
+		if len(n.Children()) == 1 {
+			return evalIntegerExpr(n.Children()[0])
+		}
+
+	case *ast.ParenExpr, *ast.ImplicitCastExpr, *ast.CStyleCastExpr:
+		if len(node.Children()) == 1 {
+			return evalIntegerExpr(node.Children()[0])
+		}
+
+	case *ast.UnaryOperator:
+		if len(n.Children()) != 1 {
+			break
+		}
+		if v, err = evalIntegerExpr(n.Children()[0]); err != nil {
+			return
+		}
+		switch n.Operator {
+		case "-":
+			return -v, nil
+		case "+":
+			return v, nil
+		}
+
+	case *ast.BinaryOperator:
+		if len(n.Children()) != 2 {
+			break
+		}
+		var l, r int64
+		if l, err = evalIntegerExpr(n.Children()[0]); err != nil {
+			return
+		}
+		if r, err = evalIntegerExpr(n.Children()[1]); err != nil {
+			return
+		}
+		switch n.Operator {
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		case "/":
+			if r != 0 {
+				return l / r, nil
+			}
+		case "<<":
+			return l << uint(r), nil
+		case ">>":
+			return l >> uint(r), nil
+		}
+	}
+	return 0, fmt.Errorf("not supported expression: %T", node)
+}
+
+// bitFieldTypeSize return size in bytes of C integer type of bit-field
+func bitFieldTypeSize(p *Program, cType string) int {
+	cType = resolveBitFieldType(p, cType)
+	switch {
+	case strings.HasPrefix(cType, "enum "):
+		return 4
+	case strings.Contains(cType, "char"),
+		cType == "_Bool", cType == "bool":
+		return 1
+	case strings.Contains(cType, "short"):
+		return 2
+	case strings.Contains(cType, "long"):
+		return 8
+	}
+	return 4
+}
+
+// isSignedBitField return true, if bit-field type is signed.
+// Plain `int` bit-fields are signed as in GCC and clang.
+func isSignedBitField(p *Program, cType string) bool {
+	cType = resolveBitFieldType(p, cType)
+	return !(strings.Contains(cType, "unsigned") ||
+		strings.HasPrefix(cType, "enum ") ||
+		cType == "_Bool" || cType == "bool")
+}
+
+func resolveBitFieldType(p *Program, cType string) string {
+	for i := 0; i < 100; i++ {
+		cType = strings.TrimSpace(cType)
+		cType = strings.TrimPrefix(cType, "const ")
+		cType = strings.TrimPrefix(cType, "volatile ")
+		t, ok := p.TypedefType[cType]
+		if !ok || t == cType {
+			break
+		}
+		cType = t
+	}
+	return cType
+}
+
+// storageType return unsigned C integer type with size in bytes or array
+// of bytes for other sizes
+func storageType(size int) string {
+	switch size {
+	case 1:
+		return "unsigned char"
+	case 2:
+		return "unsigned short"
+	case 4:
+		return "unsigned int"
+	case 8:
+		return "unsigned long long"
+	}
+	return fmt.Sprintf("unsigned char [%d]", size)
+}
+
+// bitFieldRun is sequence of neighbor bit-fields with location in struct.
+type bitFieldRun struct {
+	fields []*ast.FieldDecl
+
+	placed    bool
+	start     int // end of previous member in bytes
+	nextAlign int // alignment of next member in bytes
+	end       int // end of sequence in bytes
+}
+
+// BitFieldRunName return name of sequence of neighbor bit-fields in
+// members of struct.
+func BitFieldRunName(run int) string {
+	return fmt.Sprintf("c4go_bitfield_run_%d", run)
+}
+
+// GetBitFieldRun return index of sequence of neighbor bit-fields by name of
+// member of struct.
+func (s *Struct) GetBitFieldRun(member string) (run int, ok bool) {
+	for run = range s.bitFieldRuns {
+		if BitFieldRunName(run) == member {
+			return run, true
+		}
+	}
+	return 0, false
+}
+
+// PlaceBitFields locate sequence of neighbor bit-fields right after the
+// previous member of struct, which is ended at byte `start`. Storage fields
+// are not located over the next member with alignment `nextAlign`. Zero
+// alignment is used for the sequence at the end of struct. Returns the end
+// of sequence in bytes.
+func (s *Struct) PlaceBitFields(p *Program, run, start, nextAlign int) (
+	end int, err error) {
+	if run < 0 || len(s.bitFieldRuns) <= run {
+		return 0, fmt.Errorf("cannot find sequence of bit-fields %d in struct `%s`",
+			run, s.Name)
+	}
+	r := &s.bitFieldRuns[run]
+	if r.placed && r.start == start && r.nextAlign == nextAlign {
+		return r.end, nil
+	}
+	bfs, sts, end, err := bitFieldLayout(p, s, run, r.fields, start, nextAlign)
+	if err != nil {
+		return 0, fmt.Errorf("cannot place bit-fields of struct `%s`: %v",
+			s.Name, err)
+	}
+	r.placed, r.start, r.nextAlign, r.end = true, start, nextAlign, end
+
+	for name, bf := range s.BitFields {
+		if bf.Run == run {
+			delete(s.BitFields, name)
+		}
+	}
+	var storages []BitFieldStorage
+	for _, st := range s.Storages {
+		if st.Run < run {
+			storages = append(storages, st)
+		}
+	}
+	storages = append(storages, sts...)
+	for _, st := range s.Storages {
+		if run < st.Run {
+			storages = append(storages, st)
+		}
+	}
+
+	// storage fields are named in order of definition
+	names := map[int]map[string]string{}
+	for i := range storages {
+		st := &storages[i]
+		if names[st.Run] == nil {
+			names[st.Run] = map[string]string{}
+		}
+		names[st.Run][st.Name] = fmt.Sprintf("c4go_bitfield_%d", i)
+		st.Name = names[st.Run][st.Name]
+	}
+	for name, bf := range s.BitFields {
+		bf.Storage = names[bf.Run][bf.Storage]
+		s.BitFields[name] = bf
+	}
+	for _, bf := range bfs {
+		bf.Storage = names[bf.Run][bf.Storage]
+		s.BitFields[bf.Name] = bf
+	}
+	s.Storages = storages
+	return end, nil
+}
+
+// isPackedBitField return true, if bit-field may be located across boundary
+// of own C type.
+func (s *Struct) isPackedBitField(name string) bool {
+	return s.Packed || s.PackedFields[name] || 0 < s.MaxFieldAlign
+}
+
+// bitFieldLayout calculate location of neighbor bit-fields in storage fields
+// as in System V ABI for x86_64:
+//   - the first bit-field is located right after the previous member,
+//     so it may be located in the storage unit of C type of the previous
+//     member, for example bit-field `x` of `struct { char c; int x : 3; }`
+//     is located at bits 8-10 of the first `int`;
+//   - bit-field is never located across boundary of own C type, except
+//     bit-fields of packed structs and structs with `#pragma pack`;
+//   - bit-field with zero width move next bit-field to next boundary of
+//     own C type.
+//
+// Storage field covers storage units of own C type for neighbor bit-fields,
+// but only bytes not used by previous and next members.
+func bitFieldLayout(p *Program, s *Struct, run int, fields []*ast.FieldDecl,
+	start, nextAlign int) (bfs []BitField, storages []BitFieldStorage,
+	end int, err error) {
+
+	offset := start * 8
+	offsets := make([]int, len(fields))
+	widths := make([]int, len(fields))
+	for i, f := range fields {
+		widths[i], err = bitFieldWidth(f)
+		if err != nil {
+			return
+		}
+		unit := bitFieldTypeSize(p, f.Type) * 8
+		if widths[i] < 0 || unit < widths[i] {
+			err = fmt.Errorf("width of bit-field `%s` is not valid: %d",
+				f.Name, widths[i])
+			return
+		}
+		switch {
+		case widths[i] == 0:
+			offset = alignUp(offset, unit)
+			offsets[i] = -1
+			continue
+		case s.isPackedBitField(f.Name):
+		case offset/unit != (offset+widths[i]-1)/unit:
+			offset = alignUp(offset, unit)
+		}
+		offsets[i] = offset
+		offset += widths[i]
+	}
+	end = alignUp(offset, 8) / 8
+
+	// bytes of storage fields
+	type bytes struct{ from, to, used int }
+	var units []bytes
+	index := make([]int, len(fields))
+	for i, f := range fields {
+		if offsets[i] < 0 || f.Name == "" {
+			// unnamed bit-fields are used only for padding
+			continue
+		}
+		u := bytes{from: offsets[i] / 8, to: alignUp(offsets[i]+widths[i], 8) / 8}
+		u.used = u.to
+		if !s.isPackedBitField(f.Name) {
+			size := bitFieldTypeSize(p, f.Type)
+			u.from = offsets[i] / 8 / size * size
+			u.to = u.from + size
+		}
+		last := len(units) - 1
+		if 0 <= last && offsets[i]/8 < units[last].to {
+			// bit-field is located in the last storage field
+			if units[last].from < u.from {
+				u.from = units[last].from
+			}
+			if u.to < units[last].to {
+				u.to = units[last].to
+			}
+			if u.used < units[last].used {
+				u.used = units[last].used
+			}
+			units = units[:last]
+		}
+		from := start
+		if 0 < len(units) {
+			from = units[len(units)-1].to
+		}
+		if u.from < from {
+			u.from = from
+		}
+		if limit := alignUp(end, nextAlign); 0 < nextAlign && limit < u.to {
+			u.to = limit
+		}
+		if 8 < u.to-u.from {
+			err = fmt.Errorf("bit-field `%s` is located in storage with "+
+				"size more 8 bytes", f.Name)
+			return
+		}
+		units = append(units, u)
+		index[i] = len(units) - 1
+	}
+
+	// storage field is unsigned integer instead of array of bytes,
+	// if it is possible
+	for i := range units {
+		u := &units[i]
+		if !strings.HasSuffix(storageType(u.to-u.from), "]") {
+			continue
+		}
+		for _, size := range []int{1, 2, 4} {
+			if u.used <= u.from+size && u.from+size < u.to {
+				u.to = u.from + size
+				break
+			}
+		}
+	}
+
+	for i, u := range units {
+		storages = append(storages, BitFieldStorage{
+			Name:   fmt.Sprintf("c4go_bitfield_%d", i),
+			CType:  storageType(u.to - u.from),
+			Run:    run,
+			Offset: u.from,
+		})
+	}
+	for i, f := range fields {
+		if offsets[i] < 0 || f.Name == "" {
+			continue
+		}
+		u := units[index[i]]
+		bfs = append(bfs, BitField{
+			Name:    f.Name,
+			CType:   f.Type,
+			Signed:  isSignedBitField(p, f.Type),
+			Run:     run,
+			Storage: storages[index[i]].Name,
+			Offset:  offsets[i] - u.from*8,
+			Width:   widths[i],
+		})
+	}
+	return
+}
+
+func alignUp(offset, align int) int {
+	if align <= 1 {
+		return offset
+	}
+	return (offset + align - 1) / align * align
+}
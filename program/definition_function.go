@@ -74,6 +74,162 @@ var builtInFunctionDefinitions = map[string][]string{
 		"void (*signal(int , void (*)(int)))(int) -> noarch.Signal",
 		"int raise(int ) -> noarch.Raise",
 	},
+	"setjmp.h": {
+		// setjmp.h
+		// Calls of setjmp and longjmp functions are transpiled in
+		// specific way. See transpiler/setjmp.go
+		"int setjmp(jmp_buf) -> noarch.Setjmp",
+		"int _setjmp(jmp_buf) -> noarch.Setjmp",
+		"int __sigsetjmp(sigjmp_buf, int) -> noarch.Setjmp",
+		"void longjmp(jmp_buf, int) -> noarch.Longjmp",
+		"void _longjmp(jmp_buf, int) -> noarch.Longjmp",
+		"void siglongjmp(sigjmp_buf, int) -> noarch.Longjmp",
+	},
+	"pthread.h": {
+		// pthread.h
+		"int pthread_create(pthread_t *, const pthread_attr_t *, void *(*)(void *), void *) -> noarch.PthreadCreate",
+		"int pthread_join(pthread_t, void **) -> noarch.PthreadJoin",
+		"int pthread_detach(pthread_t) -> noarch.PthreadDetach",
+		"void pthread_exit(void *) -> noarch.PthreadExit",
+		"int pthread_equal(pthread_t, pthread_t) -> noarch.PthreadEqual",
+		"int pthread_attr_init(pthread_attr_t *) -> noarch.PthreadAttrInit",
+		"int pthread_attr_destroy(pthread_attr_t *) -> noarch.PthreadAttrDestroy",
+		"int pthread_attr_setdetachstate(pthread_attr_t *, int) -> noarch.PthreadAttrSetdetachstate",
+		"int pthread_attr_getdetachstate(const pthread_attr_t *, int *) -> noarch.PthreadAttrGetdetachstate",
+		"int pthread_mutex_init(pthread_mutex_t *, const pthread_mutexattr_t *) -> noarch.PthreadMutexInit",
+		"int pthread_mutex_destroy(pthread_mutex_t *) -> noarch.PthreadMutexDestroy",
+		"int pthread_mutex_lock(pthread_mutex_t *) -> noarch.PthreadMutexLock",
+		"int pthread_mutex_trylock(pthread_mutex_t *) -> noarch.PthreadMutexTrylock",
+		"int pthread_mutex_unlock(pthread_mutex_t *) -> noarch.PthreadMutexUnlock",
+		"int pthread_mutexattr_init(pthread_mutexattr_t *) -> noarch.PthreadMutexattrInit",
+		"int pthread_mutexattr_destroy(pthread_mutexattr_t *) -> noarch.PthreadMutexattrDestroy",
+		"int pthread_cond_init(pthread_cond_t *, const pthread_condattr_t *) -> noarch.PthreadCondInit",
+		"int pthread_cond_destroy(pthread_cond_t *) -> noarch.PthreadCondDestroy",
+		"int pthread_cond_wait(pthread_cond_t *, pthread_mutex_t *) -> noarch.PthreadCondWait",
+		"int pthread_cond_signal(pthread_cond_t *) -> noarch.PthreadCondSignal",
+		"int pthread_cond_broadcast(pthread_cond_t *) -> noarch.PthreadCondBroadcast",
+		"int pthread_condattr_init(pthread_condattr_t *) -> noarch.PthreadCondattrInit",
+		"int pthread_condattr_destroy(pthread_condattr_t *) -> noarch.PthreadCondattrDestroy",
+		"int pthread_once(pthread_once_t *, void (*)(void)) -> noarch.PthreadOnce",
+	},
+	"threads.h": {
+		// threads.h
+		"int thrd_create(thrd_t *, int (*)(void *), void *) -> noarch.ThrdCreate",
+		"int thrd_join(thrd_t, int *) -> noarch.ThrdJoin",
+		"int thrd_detach(thrd_t) -> noarch.ThrdDetach",
+		"void thrd_exit(int) -> noarch.ThrdExit",
+		"int thrd_equal(thrd_t, thrd_t) -> noarch.ThrdEqual",
+		"void thrd_yield(void) -> noarch.ThrdYield",
+		"int mtx_init(mtx_t *, int) -> noarch.MtxInit",
+		"int mtx_lock(mtx_t *) -> noarch.MtxLock",
+		"int mtx_trylock(mtx_t *) -> noarch.MtxTrylock",
+		"int mtx_unlock(mtx_t *) -> noarch.MtxUnlock",
+		"void mtx_destroy(mtx_t *) -> noarch.MtxDestroy",
+		"int cnd_init(cnd_t *) -> noarch.CndInit",
+		"int cnd_signal(cnd_t *) -> noarch.CndSignal",
+		"int cnd_broadcast(cnd_t *) -> noarch.CndBroadcast",
+		"int cnd_wait(cnd_t *, mtx_t *) -> noarch.CndWait",
+		"void cnd_destroy(cnd_t *) -> noarch.CndDestroy",
+		"void call_once(once_flag *, void (*)(void)) -> noarch.CallOnce",
+	},
+	"stdatomic.h": {
+		// stdatomic.h
+		// Atomic operations are transpiled in specific way.
+		// See transpiler/stdatomic.go
+		"void __c11_atomic_thread_fence(int) -> noarch.AtomicThreadFence",
+		"void __c11_atomic_signal_fence(int) -> noarch.AtomicSignalFence",
+		"_Bool __c11_atomic_is_lock_free(unsigned long) -> noarch.AtomicIsLockFree",
+	},
+	"complex.h": {
+		// complex.h
+		"double cabs(_Complex double) -> math/cmplx.Abs",
+		"float cabsf(_Complex float) -> noarch.Cabsf",
+		"long double cabsl(_Complex long double) -> math/cmplx.Abs",
+
+		"double carg(_Complex double) -> math/cmplx.Phase",
+		"float cargf(_Complex float) -> noarch.Cargf",
+		"long double cargl(_Complex long double) -> math/cmplx.Phase",
+
+		"double creal(_Complex double) -> noarch.Creal",
+		"float crealf(_Complex float) -> noarch.Crealf",
+		"long double creall(_Complex long double) -> noarch.Creal",
+
+		"double cimag(_Complex double) -> noarch.Cimag",
+		"float cimagf(_Complex float) -> noarch.Cimagf",
+		"long double cimagl(_Complex long double) -> noarch.Cimag",
+
+		"_Complex double conj(_Complex double) -> math/cmplx.Conj",
+		"_Complex float conjf(_Complex float) -> noarch.Conjf",
+		"_Complex long double conjl(_Complex long double) -> math/cmplx.Conj",
+
+		"_Complex double cproj(_Complex double) -> noarch.Cproj",
+		"_Complex float cprojf(_Complex float) -> noarch.Cprojf",
+		"_Complex long double cprojl(_Complex long double) -> noarch.Cproj",
+
+		"_Complex double cexp(_Complex double) -> math/cmplx.Exp",
+		"_Complex float cexpf(_Complex float) -> noarch.Cexpf",
+		"_Complex long double cexpl(_Complex long double) -> math/cmplx.Exp",
+
+		"_Complex double clog(_Complex double) -> math/cmplx.Log",
+		"_Complex float clogf(_Complex float) -> noarch.Clogf",
+		"_Complex long double clogl(_Complex long double) -> math/cmplx.Log",
+
+		"_Complex double cpow(_Complex double, _Complex double) -> math/cmplx.Pow",
+		"_Complex float cpowf(_Complex float, _Complex float) -> noarch.Cpowf",
+		"_Complex long double cpowl(_Complex long double, _Complex long double) -> math/cmplx.Pow",
+
+		"_Complex double csqrt(_Complex double) -> math/cmplx.Sqrt",
+		"_Complex float csqrtf(_Complex float) -> noarch.Csqrtf",
+		"_Complex long double csqrtl(_Complex long double) -> math/cmplx.Sqrt",
+
+		"_Complex double csin(_Complex double) -> math/cmplx.Sin",
+		"_Complex float csinf(_Complex float) -> noarch.Csinf",
+		"_Complex long double csinl(_Complex long double) -> math/cmplx.Sin",
+
+		"_Complex double ccos(_Complex double) -> math/cmplx.Cos",
+		"_Complex float ccosf(_Complex float) -> noarch.Ccosf",
+		"_Complex long double ccosl(_Complex long double) -> math/cmplx.Cos",
+
+		"_Complex double ctan(_Complex double) -> math/cmplx.Tan",
+		"_Complex float ctanf(_Complex float) -> noarch.Ctanf",
+		"_Complex long double ctanl(_Complex long double) -> math/cmplx.Tan",
+
+		"_Complex double casin(_Complex double) -> math/cmplx.Asin",
+		"_Complex float casinf(_Complex float) -> noarch.Casinf",
+		"_Complex long double casinl(_Complex long double) -> math/cmplx.Asin",
+
+		"_Complex double cacos(_Complex double) -> math/cmplx.Acos",
+		"_Complex float cacosf(_Complex float) -> noarch.Cacosf",
+		"_Complex long double cacosl(_Complex long double) -> math/cmplx.Acos",
+
+		"_Complex double catan(_Complex double) -> math/cmplx.Atan",
+		"_Complex float catanf(_Complex float) -> noarch.Catanf",
+		"_Complex long double catanl(_Complex long double) -> math/cmplx.Atan",
+
+		"_Complex double csinh(_Complex double) -> math/cmplx.Sinh",
+		"_Complex float csinhf(_Complex float) -> noarch.Csinhf",
+		"_Complex long double csinhl(_Complex long double) -> math/cmplx.Sinh",
+
+		"_Complex double ccosh(_Complex double) -> math/cmplx.Cosh",
+		"_Complex float ccoshf(_Complex float) -> noarch.Ccoshf",
+		"_Complex long double ccoshl(_Complex long double) -> math/cmplx.Cosh",
+
+		"_Complex double ctanh(_Complex double) -> math/cmplx.Tanh",
+		"_Complex float ctanhf(_Complex float) -> noarch.Ctanhf",
+		"_Complex long double ctanhl(_Complex long double) -> math/cmplx.Tanh",
+
+		"_Complex double casinh(_Complex double) -> math/cmplx.Asinh",
+		"_Complex float casinhf(_Complex float) -> noarch.Casinhf",
+		"_Complex long double casinhl(_Complex long double) -> math/cmplx.Asinh",
+
+		"_Complex double cacosh(_Complex double) -> math/cmplx.Acosh",
+		"_Complex float cacoshf(_Complex float) -> noarch.Cacoshf",
+		"_Complex long double cacoshl(_Complex long double) -> math/cmplx.Acosh",
+
+		"_Complex double catanh(_Complex double) -> math/cmplx.Atanh",
+		"_Complex float catanhf(_Complex float) -> noarch.Catanhf",
+		"_Complex long double catanhl(_Complex long double) -> math/cmplx.Atanh",
+	},
 	"errno.h": {
 		// errno.h
 		"int * __errno_location(void ) -> noarch.ErrnoLocation",
@@ -159,10 +315,10 @@ var builtInFunctionDefinitions = map[string][]string{
 		"int _IO_putc(int, FILE*) -> noarch.Fputc",
 
 		// stdio.h
-		"int printf(const char*, ...) -> noarch.Printf",
-		"int scanf(const char*, ...) -> noarch.Scanf",
-		"int putchar(int) -> noarch.Putchar",
-		"int puts(const char *) -> noarch.Puts",
+		"int printf(const char*, ...) -> C4goStdio.Printf",
+		"int scanf(const char*, ...) -> C4goStdio.Scanf",
+		"int putchar(int) -> C4goStdio.Putchar",
+		"int puts(const char *) -> C4goStdio.Puts",
 		"FILE* fopen(const char *, const char *) -> noarch.Fopen",
 		"int fclose(FILE*) -> noarch.Fclose",
 		"int remove(const char*) -> noarch.Remove",
@@ -179,8 +335,8 @@ var builtInFunctionDefinitions = map[string][]string{
 		"int fgetc(FILE*) -> noarch.Fgetc",
 		"int fputc(int, FILE*) -> noarch.Fputc",
 		"int getc(FILE*) -> noarch.Fgetc",
-		"char * gets(char*) -> noarch.Gets",
-		"int getchar() -> noarch.Getchar",
+		"char * gets(char*) -> C4goStdio.Gets",
+		"int getchar() -> C4goStdio.Getchar",
 		"int putc(int, FILE*) -> noarch.Fputc",
 		"int fseek(FILE*, long int, int) -> noarch.Fseek",
 		"long ftell(FILE*) -> noarch.Ftell",
@@ -191,12 +347,20 @@ var builtInFunctionDefinitions = map[string][]string{
 		"int sprintf(char*, const char *, ...) -> noarch.Sprintf",
 		"int snprintf(char*, int, const char *, ...) -> noarch.Snprintf",
 		"int vsprintf(char*, const char *, ...) -> noarch.Vsprintf",
-		"int vprintf(const char *, ...) -> noarch.Vprintf",
+		"int vprintf(const char *, ...) -> C4goStdio.Vprintf",
 		"int vfprintf(FILE *, const char *, ...) -> noarch.Vfprintf",
 		"int vsnprintf(char*, int, const char *, ...) -> noarch.Vsnprintf",
-		"void perror( const char *) -> noarch.Perror",
+		"void perror( const char *) -> C4goStdio.Perror",
 		"ssize_t getline(char **, size_t *, FILE *) -> noarch.Getline",
 		"int sscanf( const char *, const char *, ...) -> noarch.Sscanf",
+		"int ungetc(int, FILE*) -> noarch.Ungetc",
+		"int setvbuf(FILE*, char*, int, int) -> noarch.Setvbuf",
+		"void setbuf(FILE*, char*) -> noarch.Setbuf",
+		"int ferror(FILE*) -> noarch.Ferror",
+		"void clearerr(FILE*) -> noarch.Clearerr",
+		"FILE* freopen(const char*, const char*, FILE*) -> noarch.Freopen",
+		"FILE* fmemopen(void*, size_t, const char*) -> noarch.Fmemopen",
+		"FILE* open_memstream(char**, size_t*) -> noarch.OpenMemstream",
 	},
 	"wchar.h": {
 		// wchar.h
@@ -556,7 +720,7 @@ func (p *Program) GetOutsideCalledFunctions() (ds []DefinitionFunction) {
 		if v.IsCstdFunction {
 			continue
 		}
-		if p.PreprocessorFile.IsUserSource(v.IncludeFile) {
+		if p.PreprocessorFile.IsUserSource(v.IncludeFile) || p.IsUnitFile(v.IncludeFile) {
 			continue
 		}
 		ds = append(ds, v)
@@ -46,6 +46,14 @@ var DefinitionType = map[string]string{
 	"wchar_t": "github.com/Konstantin8105/c4go/noarch.WcharT",
 	"github.com/Konstantin8105/c4go/noarch.WcharT": "rune",
 
+	// complex.h
+	"_Complex float":       "complex64",
+	"_Complex double":      "complex128",
+	"_Complex long double": "complex128",
+	"float _Complex":       "complex64",
+	"double _Complex":      "complex128",
+	"long double _Complex": "complex128",
+
 	// void*
 	"void*":  "interface{}",
 	"void *": "interface{}",
@@ -75,7 +83,40 @@ var DefinitionType = map[string]string{
 	// signal.h
 	"sig_atomic_t": "int64",
 
+	// setjmp.h
+	"jmp_buf":    "github.com/Konstantin8105/c4go/noarch.JmpBuf",
+	"sigjmp_buf": "github.com/Konstantin8105/c4go/noarch.JmpBuf",
+	// jmp_buf is array of one struct, so argument of function is pointer
+	"struct __jmp_buf_tag [1]": "github.com/Konstantin8105/c4go/noarch.JmpBuf",
+	"struct __jmp_buf_tag *":   "*github.com/Konstantin8105/c4go/noarch.JmpBuf",
+
+	// pthread.h
+	"pthread_t":           "github.com/Konstantin8105/c4go/noarch.PthreadT",
+	"pthread_attr_t":      "github.com/Konstantin8105/c4go/noarch.PthreadAttrT",
+	"pthread_mutex_t":     "github.com/Konstantin8105/c4go/noarch.PthreadMutexT",
+	"pthread_mutexattr_t": "github.com/Konstantin8105/c4go/noarch.PthreadMutexattrT",
+	"pthread_cond_t":      "github.com/Konstantin8105/c4go/noarch.PthreadCondT",
+	"pthread_condattr_t":  "github.com/Konstantin8105/c4go/noarch.PthreadCondattrT",
+	"pthread_once_t":      "github.com/Konstantin8105/c4go/noarch.PthreadOnceT",
+
+	// threads.h
+	"thrd_t":    "github.com/Konstantin8105/c4go/noarch.ThrdT",
+	"mtx_t":     "github.com/Konstantin8105/c4go/noarch.MtxT",
+	"cnd_t":     "github.com/Konstantin8105/c4go/noarch.CndT",
+	"once_flag": "github.com/Konstantin8105/c4go/noarch.OnceFlag",
+
 	// sys/types.h
 	"off_t":   "int64",
 	"__off_t": "int64",
 }
+
+// DefinitionZeroInitType - C types of standard library, which static
+// initializers are zero values of Go types.
+//
+//	C  : pthread_mutex_t m = PTHREAD_MUTEX_INITIALIZER;
+//	Go : var m noarch.PthreadMutexT = noarch.PthreadMutexT{}
+var DefinitionZeroInitType = map[string]bool{
+	"pthread_mutex_t": true,
+	"pthread_cond_t":  true,
+	"once_flag":       true,
+}
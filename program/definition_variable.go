@@ -1,11 +1,15 @@
 package program
 
+// StdioVariable is name of package variable of generated code with standard
+// streams of program. See noarch.Stdio.
+const StdioVariable = "C4goStdio"
+
 // DefinitionVariable is map of conversion from C var to C4go variable
 var DefinitionVariable = map[string]string{
 	// stdio.h
-	"stdin":  "github.com/Konstantin8105/c4go/noarch.Stdin",
-	"stdout": "github.com/Konstantin8105/c4go/noarch.Stdout",
-	"stderr": "github.com/Konstantin8105/c4go/noarch.Stderr",
+	"stdin":  StdioVariable + ".Stdin",
+	"stdout": StdioVariable + ".Stdout",
+	"stderr": StdioVariable + ".Stderr",
 
 	// ctype.h
 	"_ISupper":  "github.com/Konstantin8105/c4go/noarch.ISupper",
@@ -44,6 +44,14 @@ func (p *Program) AddImports(importPaths ...string) {
 // Will import "github.com/Konstantin8105/c4go/noarch" and return (value of t)
 // "noarch.CtRuneT".
 func (p *Program) ImportType(name string) string {
+	if strings.HasPrefix(name, StdioVariable+".") {
+		// standard streams of program are not imported
+		p.IsHaveStdio = true
+		return name
+	}
+	if strings.HasPrefix(name, "*") {
+		return "*" + p.ImportType(name[1:])
+	}
 	if strings.Contains(name, ".") {
 		parts := strings.Split(name, ".")
 		p.AddImport(strings.Join(parts[:len(parts)-1], "."))
@@ -0,0 +1,69 @@
+package program
+
+import (
+	"path/filepath"
+
+	"github.com/Konstantin8105/c4go/ast"
+)
+
+// LibraryFunction is public function of C library, declared in user
+// header. In library mode exported Go wrapper is generated for each of them.
+type LibraryFunction struct {
+	Name       string   // name of function
+	Parameters []string // names of parameters, empty if unknown
+	File       string   // header with declaration
+}
+
+// isLibraryFunction return true, if function is public function of C
+// library: not static function declared in user header.
+func (p *Program) isLibraryFunction(n *ast.FunctionDecl) bool {
+	if n.IsStatic || n.IsImplicit || n.Name == "main" {
+		return false
+	}
+	if filepath.Ext(n.Pos.File) != ".h" {
+		return false
+	}
+	return p.PreprocessorFile.IsUserSource(n.Pos.File)
+}
+
+// AddLibraryFunction adds function declaration, if that is public function
+// of C library. Function may be declared a few times, so unknown names of
+// parameters are taken from the next declarations, for example from
+// the function definition in C source.
+func (p *Program) AddLibraryFunction(n *ast.FunctionDecl) {
+	var names []string
+	for _, c := range n.Children() {
+		if v, ok := c.(*ast.ParmVarDecl); ok {
+			names = append(names, v.Name)
+		}
+	}
+	for i := range p.libraryFunctions {
+		lf := &p.libraryFunctions[i]
+		if lf.Name != n.Name {
+			continue
+		}
+		if len(lf.Parameters) != len(names) {
+			return
+		}
+		for j := range names {
+			if lf.Parameters[j] == "" {
+				lf.Parameters[j] = names[j]
+			}
+		}
+		return
+	}
+	if !p.isLibraryFunction(n) {
+		return
+	}
+	p.libraryFunctions = append(p.libraryFunctions, LibraryFunction{
+		Name:       n.Name,
+		Parameters: names,
+		File:       n.Pos.File,
+	})
+}
+
+// GetLibraryFunctions return public functions of C library in order of
+// declaration.
+func (p *Program) GetLibraryFunctions() []LibraryFunction {
+	return p.libraryFunctions
+}
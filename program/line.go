@@ -0,0 +1,63 @@
+package program
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/ast"
+)
+
+// LineMarker is prefix of comment with position of C source in generated
+// Go code. Markers are replaced by `//line` directives or removed after
+// creating source map.
+const LineMarker = "//c4go:line "
+
+// GetLineMarker returns marker comment with position of C source. Empty
+// string is returned, if markers are not used or position is not valid.
+func (p *Program) GetLineMarker(pos ast.Position) string {
+	if !p.SourceLines || pos.File == "" || pos.Line == 0 {
+		return ""
+	}
+	file := pos.File
+	if f, err := filepath.Abs(file); err == nil {
+		file = f
+	}
+	if pos.Column == 0 {
+		return fmt.Sprintf("%s%s:%d", LineMarker, file, pos.Line)
+	}
+	return fmt.Sprintf("%s%s:%d:%d", LineMarker, file, pos.Line, pos.Column)
+}
+
+// ParseLineMarker returns position of C source from marker comment.
+func ParseLineMarker(comment string) (pos ast.Position, ok bool) {
+	comment = strings.TrimSpace(comment)
+	if !strings.HasPrefix(comment, LineMarker) {
+		return
+	}
+	s := comment[len(LineMarker):]
+
+	// file name may contain colon, so numbers are taken from the end
+	var numbers []int
+	for len(numbers) < 2 {
+		index := strings.LastIndex(s, ":")
+		if index < 0 {
+			break
+		}
+		n, err := strconv.Atoi(s[index+1:])
+		if err != nil {
+			break
+		}
+		numbers = append([]int{n}, numbers...)
+		s = s[:index]
+	}
+	if len(numbers) == 0 || s == "" {
+		return
+	}
+	pos.File, pos.Line = s, numbers[0]
+	if len(numbers) == 2 {
+		pos.Column = numbers[1]
+	}
+	return pos, true
+}
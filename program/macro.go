@@ -0,0 +1,260 @@
+package program
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/preprocessor"
+)
+
+// MacroConstant is object-like macro from user source with single literal
+// in the body. That macro is transpiled to Go constant.
+//
+// Example:
+//
+//	C  : #define N 100
+//	Go : const N int32 = 100
+type MacroConstant struct {
+	Name  string
+	Value string // Go literal
+	CType string // C type of literal
+	File  string
+	Line  int
+}
+
+// GetMacroConstants return all macro constants from user sources in order
+// of definition.
+func (p *Program) GetMacroConstants() []MacroConstant {
+	p.initMacroConstants()
+	return p.macroConstants
+}
+
+// GetMacroConstant return macro constant, if literal at that position is
+// located in macro definition.
+func (p *Program) GetMacroConstant(pos ast.Position) (mc MacroConstant, ok bool) {
+	if pos.File == "" || pos.Line == 0 {
+		return
+	}
+	p.initMacroConstants()
+	index, ok := p.macroIndex[macroKey(pos.File, pos.Line)]
+	if !ok {
+		return
+	}
+	return p.macroConstants[index], true
+}
+
+func macroKey(file string, line int) string {
+	if f, err := filepath.Abs(file); err == nil {
+		file = f
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+func (p *Program) initMacroConstants() {
+	if p.macroIndex != nil {
+		return
+	}
+	p.macroIndex = map[string]int{}
+	defined := map[string]bool{}
+	for _, m := range p.PreprocessorFile.GetMacros() {
+		mc, ok := newMacroConstant(m)
+		if !ok {
+			continue
+		}
+		if defined[mc.Name] {
+			// same macro in few user sources or in different
+			// preprocessor branches with same body
+			for i := range p.macroConstants {
+				if p.macroConstants[i].Name == mc.Name {
+					p.macroIndex[macroKey(mc.File, mc.Line)] = i
+				}
+			}
+			continue
+		}
+		defined[mc.Name] = true
+		p.macroIndex[macroKey(mc.File, mc.Line)] = len(p.macroConstants)
+		p.macroConstants = append(p.macroConstants, mc)
+	}
+}
+
+// newMacroConstant return macro constant only if body of macro is single
+// integer, floating or character literal.
+func newMacroConstant(m preprocessor.Macro) (mc MacroConstant, ok bool) {
+	body := m.Body
+	for len(body) > 2 && body[0] == '(' && body[len(body)-1] == ')' {
+		body = strings.TrimSpace(body[1 : len(body)-1])
+	}
+	if body == "" {
+		return
+	}
+	mc = MacroConstant{
+		Name: m.Name,
+		File: m.File,
+		Line: m.Line,
+	}
+	switch {
+	case body[0] == '\'':
+		mc.Value, ok = charLiteral(body)
+		mc.CType = "char"
+	case body[0] >= '0' && body[0] <= '9' || body[0] == '.':
+		mc.Value, mc.CType, ok = numberLiteral(body)
+	}
+	return
+}
+
+// charLiteral convert C character literal to Go rune literal
+func charLiteral(body string) (value string, ok bool) {
+	if len(body) < 3 || body[len(body)-1] != '\'' {
+		return
+	}
+	s := body[1 : len(body)-1]
+	// C octal escape may have 1 or 2 digits, but Go expect 3 digits
+	if len(s) > 1 && s[0] == '\\' && s[1] >= '0' && s[1] <= '7' {
+		v, err := strconv.ParseUint(s[1:], 8, 8)
+		if err != nil {
+			return
+		}
+		return fmt.Sprintf("%q", rune(v)), true
+	}
+	r, _, tail, err := strconv.UnquoteChar(s, '\'')
+	if err != nil || tail != "" {
+		return
+	}
+	return fmt.Sprintf("%q", r), true
+}
+
+// numberLiteral convert C integer or floating literal to Go literal with
+// C type in according to suffix and value.
+func numberLiteral(body string) (value, cType string, ok bool) {
+	lower := strings.ToLower(body)
+	isHex := strings.HasPrefix(lower, "0x")
+	isFloat := strings.ContainsAny(lower, ".p") ||
+		(!isHex && strings.Contains(lower, "e"))
+
+	if isFloat {
+		cType = "double"
+		switch lower[len(lower)-1] {
+		case 'f':
+			cType = "float"
+			body = body[:len(body)-1]
+		case 'l':
+			cType = "long double"
+			body = body[:len(body)-1]
+		}
+		if _, err := strconv.ParseFloat(body, 64); err != nil {
+			return
+		}
+		return body, cType, true
+	}
+
+	suffix := strings.TrimLeft(lower, "0123456789abcdefx")
+	body = body[:len(body)-len(suffix)]
+	v, err := strconv.ParseUint(body, 0, 64)
+	if err != nil {
+		return
+	}
+	unsigned := strings.Contains(suffix, "u")
+	long := strings.Contains(suffix, "l")
+	if strings.Trim(suffix, "ul") != "" {
+		return
+	}
+	decimal := !isHex && (body == "0" || body[0] != '0')
+	switch {
+	case !long && !unsigned && v <= math.MaxInt32:
+		cType = "int"
+	case !long && v <= math.MaxUint32 && (unsigned || !decimal):
+		cType = "unsigned int"
+	case !unsigned && v <= math.MaxInt64:
+		cType = "long long"
+	default:
+		cType = "unsigned long long"
+	}
+	return body, cType, true
+}
+
+// MacroFunction is Go function for function-like macro from user source.
+// Used only if MacroFunctionMode is true.
+//
+// Example:
+//
+//	C  : #define MAX(a,b) ((a)>(b)?(a):(b))
+//	Go : func MAX[T int32 | float64](a T, b T) T { ... }
+type MacroFunction struct {
+	Macro preprocessor.Macro
+
+	// Signatures - Go types of parameters and result for each
+	// transpiled variant of macro.
+	Signatures [][]string
+
+	// Bodies - Go expression of function body for each signature.
+	Bodies []string
+
+	// Failed is true, if macro cannot be transpiled to Go function.
+	Failed bool
+}
+
+// GetMacroFunction return function-like macro from user source, if
+// the position is the body of macro definition.
+func (p *Program) GetMacroFunction(pos ast.Position) (mf *MacroFunction, ok bool) {
+	if pos.File == "" || pos.Line == 0 {
+		return
+	}
+	p.initMacroFunctions()
+	mf, ok = p.macroFunctions[macroKey(pos.File, pos.Line)]
+	if !ok {
+		return
+	}
+	m := mf.Macro
+	if m.Column == 0 || pos.Column != m.Column {
+		return nil, false
+	}
+	if pos.LineEnd != 0 && pos.LineEnd != m.Line {
+		return nil, false
+	}
+	if pos.ColumnEnd != m.ColumnEnd && !(pos.ColumnEnd == 0 && m.Column == m.ColumnEnd) {
+		return nil, false
+	}
+	return mf, true
+}
+
+// GetMacroFunctions return all function-like macros from user sources in
+// order of definition.
+func (p *Program) GetMacroFunctions() (mfs []*MacroFunction) {
+	p.initMacroFunctions()
+	for _, m := range p.PreprocessorFile.GetFunctionMacros() {
+		if mf, ok := p.macroFunctions[macroKey(m.File, m.Line)]; ok {
+			mfs = append(mfs, mf)
+		}
+	}
+	return
+}
+
+// IsMacroDefinition return true, if position is located in line of any
+// macro definition from user sources.
+func (p *Program) IsMacroDefinition(pos ast.Position) bool {
+	if pos.File == "" || pos.Line == 0 {
+		return false
+	}
+	p.initMacroFunctions()
+	return p.macroLines[macroKey(pos.File, pos.Line)]
+}
+
+func (p *Program) initMacroFunctions() {
+	if p.macroFunctions != nil {
+		return
+	}
+	p.macroFunctions = map[string]*MacroFunction{}
+	p.macroLines = map[string]bool{}
+	for _, m := range p.PreprocessorFile.GetMacros() {
+		p.macroLines[macroKey(m.File, m.Line)] = true
+	}
+	for _, m := range p.PreprocessorFile.GetFunctionMacros() {
+		key := macroKey(m.File, m.Line)
+		p.macroLines[key] = true
+		p.macroFunctions[key] = &MacroFunction{Macro: m}
+	}
+}
@@ -75,6 +75,10 @@ type Program struct {
 	// in output Go code
 	messagePosition int
 
+	// diagnostics - structured warnings and errors of transpiling.
+	// See GenerateWarningMessage().
+	diagnostics []Diagnostic
+
 	// A map of all the global variables (variables that exist outside of a
 	// function) and their types.
 	GlobalVariables map[string]string
@@ -111,10 +115,53 @@ type Program struct {
 	// IsHaveVaList
 	IsHaveVaList bool
 
+	// IsHaveStdio - generated code uses standard streams of program.
+	// See StdioVariable.
+	IsHaveStdio bool
+
 	DoNotAddComments bool
 
 	// for binding parse FunctionDecl one time
 	Binding bool
+
+	// macroConstants - object-like macros from user sources, transpiled
+	// to Go constants. See GetMacroConstants().
+	macroConstants []MacroConstant
+	// macroIndex - index of macroConstants by location of definition
+	macroIndex map[string]int
+
+	// MacroFunctionMode - transpile expansions of function-like macros
+	// from user sources to calls of Go functions
+	MacroFunctionMode bool
+	// macroFunctions - function-like macros by location of definition
+	macroFunctions map[string]*MacroFunction
+	// macroLines - locations of all macro definitions
+	macroLines map[string]bool
+	// MacroBodiesInProgress - expansions of function-like macros, which
+	// are transpiled to body of Go function at the moment
+	MacroBodiesInProgress map[ast.Node]bool
+
+	// CLayout - generate structs with memory layout of C: explicit
+	// padding fields, attributes `packed` and `aligned`.
+	CLayout bool
+
+	// LibraryMode - transpile C library without function main and
+	// generate exported Go wrappers for public functions from user headers.
+	LibraryMode bool
+
+	// SourceLines - add markers with position of C source before each
+	// statement. See GetLineMarker().
+	SourceLines bool
+
+	// libraryFunctions - public functions of C library.
+	// See AddLibraryFunction().
+	libraryFunctions []LibraryFunction
+
+	// UnitFile - C source file of translation unit, if program is part
+	// of multi-file Go package. See AddUnitNames().
+	UnitFile string
+	// unitNames - names of Go declarations from UnitFile
+	unitNames map[string]bool
 }
 
 type commentPos struct {
@@ -151,6 +198,7 @@ func NewProgram() (p *Program) {
 		builtInFunctionDefinitionsHaveBeenLoaded: false,
 		UnsafeConvertValueToPointer:              map[string]bool{},
 		UnsafeConvertPointerArith:                map[string]bool{},
+		MacroBodiesInProgress:                    map[ast.Node]bool{},
 	}
 }
 
@@ -33,6 +33,41 @@ type Struct struct {
 	// int    - position of field
 	// string - name of field
 	FieldNames map[int]string
+
+	// BitFields - bit-fields of struct. Key is name of field.
+	// Bit-fields are also present in Fields and FieldNames.
+	BitFields map[string]BitField
+
+	// Storages - storage fields of packed bit-fields in order of
+	// definition.
+	Storages []BitFieldStorage
+
+	// Members - names of fields and sequences of neighbor bit-fields in
+	// order of definition. Used for calculation of C memory layout.
+	// Sequences of bit-fields are named by function BitFieldRunName.
+	Members []string
+
+	// Packed is true for struct with attribute `packed`.
+	Packed bool
+
+	// Align - alignment in bytes from attribute `aligned` of struct.
+	// Zero, if attribute is not defined.
+	Align int
+
+	// MaxFieldAlign - maximal alignment of fields in bytes from
+	// `#pragma pack`. Zero, if pragma is not defined.
+	MaxFieldAlign int
+
+	// FieldAligns - alignment in bytes from attribute `aligned` of fields.
+	// Key is name of field.
+	FieldAligns map[string]int
+
+	// PackedFields - fields with attribute `packed`.
+	// Key is name of field.
+	PackedFields map[string]bool
+
+	// sequences of neighbor bit-fields
+	bitFieldRuns []bitFieldRun
 }
 
 // NewStruct creates a new Struct definition from an ast.RecordDecl.
@@ -47,13 +82,77 @@ func NewStruct(p *Program, n *ast.RecordDecl) (st *Struct, err error) {
 	}()
 	fields := make(map[string]interface{})
 	names := map[int]string{}
+	var members []string
+	var packed bool
+	var align, maxFieldAlign int
+	fieldAligns := map[string]int{}
+	packedFields := map[string]bool{}
+
+	// bit-fields of union are located at offset 0
+	unionBitFields := map[string]BitField{}
+
+	// neighbor bit-fields
+	var run []*ast.FieldDecl
+	var runs []bitFieldRun
+	closeRun := func() {
+		if len(run) == 0 {
+			return
+		}
+		members = append(members, BitFieldRunName(len(runs)))
+		runs = append(runs, bitFieldRun{fields: run})
+		run = nil
+	}
 
 	counter := 0
 	for _, field := range n.Children() {
+		if f, ok := field.(*ast.FieldDecl); ok && n.Kind == "struct" && IsBitField(f) {
+			run = append(run, f)
+			if f.Name == "" {
+				// unnamed bit-field is not a member of struct
+				continue
+			}
+		} else {
+			closeRun()
+		}
+		if f, ok := field.(*ast.FieldDecl); ok && n.Kind == "union" && IsBitField(f) {
+			if f.Name == "" {
+				// unnamed bit-field is not a member of union
+				continue
+			}
+			var width int
+			if width, err = bitFieldWidth(f); err != nil {
+				return
+			}
+			if width < 0 || bitFieldTypeSize(p, f.Type)*8 < width {
+				err = fmt.Errorf("width of bit-field `%s` is not valid: %d",
+					f.Name, width)
+				return
+			}
+			unionBitFields[f.Name] = BitField{
+				Name:   f.Name,
+				CType:  f.Type,
+				Signed: isSignedBitField(p, f.Type),
+				Width:  width,
+			}
+		}
 		switch f := field.(type) {
 		case *ast.FieldDecl:
 			fields[f.Name] = f.Type
 			names[counter] = f.Name
+			if !IsBitField(f) || n.Kind == "union" {
+				members = append(members, f.Name)
+			}
+			for _, c := range f.Children() {
+				switch a := c.(type) {
+				case *ast.AlignedAttr:
+					fieldAligns[f.Name], err = alignedAttrValue(a)
+					if err != nil {
+						return
+					}
+				case *ast.PackedAttr:
+					packedFields[f.Name] = true
+				}
+			}
 
 		case *ast.IndirectFieldDecl:
 			fields[f.Name] = f.Type
@@ -65,10 +164,27 @@ func NewStruct(p *Program, n *ast.RecordDecl) (st *Struct, err error) {
 				return
 			}
 
-		case *ast.MaxFieldAlignmentAttr,
-			*ast.AlignedAttr,
-			*ast.PackedAttr,
-			*ast.EnumDecl,
+		case *ast.MaxFieldAlignmentAttr:
+			// value in bits
+			maxFieldAlign = f.Size / 8
+			continue
+
+		case *ast.AlignedAttr:
+			var a int
+			a, err = alignedAttrValue(f)
+			if err != nil {
+				return
+			}
+			if align < a {
+				align = a
+			}
+			continue
+
+		case *ast.PackedAttr:
+			packed = true
+			continue
+
+		case *ast.EnumDecl,
 			*ast.TransparentUnionAttr,
 			*ast.FullComment:
 			// FIXME: Should these really be ignored?
@@ -79,6 +195,7 @@ func NewStruct(p *Program, n *ast.RecordDecl) (st *Struct, err error) {
 		}
 		counter++
 	}
+	closeRun()
 
 	var t TypeOfStruct
 	switch n.Kind {
@@ -93,13 +210,53 @@ func NewStruct(p *Program, n *ast.RecordDecl) (st *Struct, err error) {
 		return
 	}
 
-	return &Struct{
+	st = &Struct{
 		Name:       n.Name,
 		IsGlobal:   p.Function == nil,
 		Type:       t,
 		Fields:     fields,
 		FieldNames: names,
-	}, nil
+		BitFields:  map[string]BitField{},
+
+		Members:       members,
+		Packed:        packed,
+		Align:         align,
+		MaxFieldAlign: maxFieldAlign,
+		FieldAligns:   fieldAligns,
+		PackedFields:  packedFields,
+
+		bitFieldRuns: runs,
+	}
+	for name, bf := range unionBitFields {
+		st.BitFields[name] = bf
+	}
+
+	// sequences of bit-fields are located right after previous members by
+	// types.GetRecordLayout, until then they are started at offset 0
+	for run := range runs {
+		if _, err = st.PlaceBitFields(p, run, 0, 0); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// alignedAttrValue return alignment in bytes of attribute `aligned`.
+//
+//	AlignedAttr 0x2c2b6d8 <col:37, col:50> aligned
+//	`-ConstantExpr 0x2c2b6b8 <col:45> 'int'
+//	  `-IntegerLiteral 0x2c2b698 <col:45> 'int' 16
+func alignedAttrValue(a *ast.AlignedAttr) (int, error) {
+	if len(a.Children()) == 0 {
+		// attribute without value is the largest alignment
+		// for target machine
+		return 16, nil
+	}
+	v, err := evalIntegerExpr(a.Children()[0])
+	if err != nil {
+		return 0, fmt.Errorf("cannot get value of aligned attribute: %v", err)
+	}
+	return int(v), nil
 }
 
 // IsUnion - return true if the cType is 'union' or
@@ -0,0 +1,39 @@
+package program
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// IsUnitFile return true, if file is C source file of translation unit.
+func (p *Program) IsUnitFile(file string) bool {
+	return p.UnitFile != "" && filepath.Clean(file) == filepath.Clean(p.UnitFile)
+}
+
+// AddUnitNames adds names of Go declarations, transpiled from C source file
+// of translation unit.
+func (p *Program) AddUnitNames(names ...string) {
+	if p.unitNames == nil {
+		p.unitNames = map[string]bool{}
+	}
+	for _, name := range names {
+		p.unitNames[name] = true
+	}
+}
+
+// IsUnitName return true, if Go declaration with that name is transpiled
+// from C source file of translation unit. Other declarations, like types
+// from headers and helper functions, are shared by all files of package.
+func (p *Program) IsUnitName(name string) bool {
+	return p.unitNames[name]
+}
+
+// GetUnitNames return names of Go declarations, transpiled from C source
+// file of translation unit, in alphabetical order.
+func (p *Program) GetUnitNames() (names []string) {
+	for name := range p.unitNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
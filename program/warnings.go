@@ -29,6 +29,19 @@ func (p *Program) GenerateWarningMessage(e error, n ast.Node) string {
 	}
 	message += fmt.Sprintf("%s", e.Error())
 	message = PathSimplification(message)
+
+	d := Diagnostic{
+		Category: diagnosticCategory(e.Error()),
+		Message:  PathSimplification(e.Error()),
+		Comment:  message,
+	}
+	if n != nil {
+		pos := n.Position()
+		d.NodeType = fmt.Sprintf("%T", n)
+		d.File, d.Line, d.Column = pos.File, pos.Line, pos.Column
+	}
+	p.diagnostics = append(p.diagnostics, d)
+
 	return message
 }
 
@@ -39,3 +52,60 @@ func PathSimplification(message string) string {
 	}
 	return message
 }
+
+// Categories of diagnostics
+const (
+	DiagnosticWarning     = "warning"
+	DiagnosticUnsafe      = "unsafe"
+	DiagnosticUnsupported = "unsupported"
+	DiagnosticAstError    = "ast-error"
+)
+
+// Diagnostic is warning or error of transpiling with position in C source
+// and in output Go source.
+type Diagnostic struct {
+	Category string `json:"category"`
+	NodeType string `json:"node_type,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	GoFile   string `json:"go_file,omitempty"`
+	GoLine   int    `json:"go_line,omitempty"`
+	Message  string `json:"message"`
+
+	// Comment is text of message in output Go source
+	Comment string `json:"comment"`
+}
+
+// diagnosticCategory returns category of warning by text of error.
+func diagnosticCategory(message string) string {
+	message = strings.ToLower(message)
+	switch {
+	case strings.Contains(message, "unsafe"):
+		return DiagnosticUnsafe
+	case strings.Contains(message, "not support"),
+		strings.Contains(message, "unsupported"),
+		strings.Contains(message, "not implemented"),
+		strings.Contains(message, "cannot transpile to expr"),
+		strings.Contains(message, "cannot transpile to node"):
+		return DiagnosticUnsupported
+	}
+	return DiagnosticWarning
+}
+
+// AddAstError adds error of clang AST as message and diagnostic of C
+// source file.
+func (p *Program) AddAstError(e error, file string) {
+	p.AddMessage(e.Error())
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Category: DiagnosticAstError,
+		File:     file,
+		Message:  PathSimplification(e.Error()),
+		Comment:  e.Error(),
+	})
+}
+
+// GetDiagnostics returns all diagnostics of transpiling.
+func (p *Program) GetDiagnostics() []Diagnostic {
+	return p.diagnostics
+}
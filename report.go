@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/program"
+)
+
+// report is JSON report with diagnostics of transpiling. Report is used
+// for tracking progress of migration C source files.
+type report struct {
+	Diagnostics []program.Diagnostic `json:"diagnostics"`
+	Summary     reportSummary        `json:"summary"`
+}
+
+// reportSummary is amount of diagnostics in all files and in each C
+// source file and amount of unsafe.Pointer in output Go files.
+type reportSummary struct {
+	reportCounts
+	UnsafePointers int                     `json:"unsafe_pointers"`
+	Files          map[string]reportCounts `json:"files"`
+	GoFiles        map[string]int          `json:"go_files"` // unsafe.Pointer in each Go file
+}
+
+// reportCounts is amount of diagnostics by categories.
+type reportCounts struct {
+	Total          int `json:"total"`
+	Warnings       int `json:"warnings"`
+	UnsafeWarnings int `json:"unsafe_warnings"` // warnings about unsafe conversions
+	Unsupported    int `json:"unsupported"`
+	AstErrors      int `json:"ast_errors"`
+}
+
+func (c *reportCounts) add(d program.Diagnostic) {
+	c.Total++
+	switch d.Category {
+	case program.DiagnosticUnsafe:
+		c.UnsafeWarnings++
+	case program.DiagnosticUnsupported:
+		c.Unsupported++
+	case program.DiagnosticAstError:
+		c.AstErrors++
+	default:
+		c.Warnings++
+	}
+}
+
+// newReport returns report with summary of diagnostics and uses of
+// unsafe.Pointer in sources of output Go files.
+func newReport(ds []program.Diagnostic, files map[string]string) (r report) {
+	r.Diagnostics = ds
+	if r.Diagnostics == nil {
+		r.Diagnostics = []program.Diagnostic{}
+	}
+	r.Summary.Files = map[string]reportCounts{}
+	for _, d := range ds {
+		r.Summary.add(d)
+		c := r.Summary.Files[d.File]
+		c.add(d)
+		r.Summary.Files[d.File] = c
+	}
+	r.Summary.GoFiles = map[string]int{}
+	for name, source := range files {
+		n := countUnsafePointers(source)
+		r.Summary.GoFiles[name] = n
+		r.Summary.UnsafePointers += n
+	}
+	return
+}
+
+// countUnsafePointers returns amount of unsafe.Pointer in Go source.
+// Comments and strings are not counted.
+func countUnsafePointers(source string) (n int) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", source, 0)
+	if err != nil {
+		return 0
+	}
+	goast.Inspect(f, func(node goast.Node) bool {
+		if sel, ok := node.(*goast.SelectorExpr); ok && sel.Sel.Name == "Pointer" {
+			if x, ok := sel.X.(*goast.Ident); ok && x.Name == "unsafe" {
+				n++
+			}
+		}
+		return true
+	})
+	return
+}
+
+// writeReport writes JSON report with diagnostics and summary of output
+// Go files into file.
+func writeReport(filename string, ds []program.Diagnostic, files map[string]string) error {
+	b, err := json.MarshalIndent(newReport(ds, files), "", "\t")
+	if err != nil {
+		return fmt.Errorf("cannot create report: %v", err)
+	}
+	if err = ioutil.WriteFile(filename, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing report file failed: %v", err)
+	}
+	return nil
+}
+
+// commentLocator finds position of diagnostics in output Go files by
+// comments with text of diagnostic.
+type commentLocator struct {
+	names []string                    // sorted names of Go files
+	lines map[string]map[string][]int // Go file - comment - free lines
+}
+
+// newCommentLocator reads comments from output Go files.
+func newCommentLocator(files map[string]string) *commentLocator {
+	l := &commentLocator{lines: map[string]map[string][]int{}}
+	for name, source := range files {
+		l.names = append(l.names, name)
+		comments := map[string][]int{}
+		for i, line := range strings.Split(source, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "//") || strings.HasPrefix(line, "/*") {
+				comments[line] = append(comments[line], i+1)
+			}
+		}
+		l.lines[name] = comments
+	}
+	sort.Strings(l.names)
+	return l
+}
+
+// locate sets position of diagnostic in Go file. Files with preferred
+// names are checked first. Each comment is used only for one diagnostic.
+func (l *commentLocator) locate(d *program.Diagnostic, preferred ...string) {
+	comment := strings.TrimSpace(strings.SplitN(d.Comment, "\n", 2)[0])
+	for _, name := range append(preferred, l.names...) {
+		lines := l.lines[name][comment]
+		if len(lines) == 0 {
+			continue
+		}
+		d.GoFile, d.GoLine = name, lines[0]
+		l.lines[name][comment] = lines[1:]
+		return
+	}
+}
+
+// readGoFiles returns sources of Go files in directory.
+func readGoFiles(dir string, names []string) (files map[string]string, err error) {
+	files = map[string]string{}
+	for _, name := range names {
+		filename := filepath.Join(dir, name)
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read Go file for report: %v", err)
+		}
+		files[filename] = string(b)
+	}
+	return
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+)
+
+func TestReport(t *testing.T) {
+	p := program.NewProgram()
+	n, err := ast.Parse("IntegerLiteral 0x5 <a.c:3:7> 'int' 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.AddMessage(p.GenerateWarningMessage(
+		fmt.Errorf("used unsafe convert from integer to pointer"), n))
+	p.AddMessage(p.GenerateWarningMessage(
+		fmt.Errorf("atomic operation `x` is not supported"), n))
+	p.AddMessage(p.GenerateWarningMessage(fmt.Errorf("sizeof is zero"), nil))
+	p.AddAstError(fmt.Errorf("/* AST Error :\nnot valid line\n*/"), "b.c")
+
+	ds := p.GetDiagnostics()
+	if len(ds) != 4 {
+		t.Fatalf("not valid amount of diagnostics: %d", len(ds))
+	}
+	d := ds[0]
+	if d.Category != program.DiagnosticUnsafe || d.NodeType != "*ast.IntegerLiteral" ||
+		d.File != "a.c" || d.Line != 3 || d.Column != 7 {
+		t.Errorf("not valid diagnostic: %#v", d)
+	}
+
+	// the second comment with the same text is not used
+	source := "package main\n\n" + strings.Join([]string{
+		ds[1].Comment, ds[0].Comment, "/* AST Error :", ds[1].Comment,
+	}, "\n") + "\n"
+	l := newCommentLocator(map[string]string{"a.go": source})
+	for i := range ds {
+		l.locate(&ds[i])
+	}
+	for i, line := range []int{4, 3, 0, 5} {
+		if ds[i].GoLine != line {
+			t.Errorf("not valid Go line of diagnostic %d: %d != %d", i, ds[i].GoLine, line)
+		}
+	}
+
+	goSource := `package main
+
+import "unsafe"
+
+// unsafe.Pointer in comment
+func f(a *int32) *int64 {
+	_ = "unsafe.Pointer"
+	return (*int64)(unsafe.Pointer(uintptr(unsafe.Pointer(a)) + 1))
+}
+`
+	r := newReport(ds, map[string]string{"a.go": goSource, "b.go": "package main\n"})
+	if c := r.Summary.reportCounts; c != (reportCounts{Total: 4, Warnings: 1,
+		UnsafeWarnings: 1, Unsupported: 1, AstErrors: 1}) {
+		t.Errorf("not valid summary: %#v", c)
+	}
+	if r.Summary.UnsafePointers != 2 || r.Summary.GoFiles["a.go"] != 2 ||
+		r.Summary.GoFiles["b.go"] != 0 {
+		t.Errorf("not valid amount of unsafe.Pointer: %d %v",
+			r.Summary.UnsafePointers, r.Summary.GoFiles)
+	}
+	if c := r.Summary.Files["a.c"]; c.Total != 2 {
+		t.Errorf("not valid summary of file: %#v", c)
+	}
+}
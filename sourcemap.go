@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/Konstantin8105/c4go/program"
+)
+
+// sourceMapping is position of C source for line of generated Go code.
+type sourceMapping struct {
+	GoFile string `json:"go_file"`
+	GoLine int    `json:"go_line"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+}
+
+// sourceMap is mapping of generated Go code to C source.
+type sourceMap struct {
+	Mappings []sourceMapping `json:"mappings"`
+}
+
+// applyLineMarkers replaces markers with position of C source in Go file
+// by `//line` directives or removes them, if directives are not needed.
+// Each line of Go code after marker is mapped to position of marker up
+// to next marker or next top-level declaration. Directives restore
+// position of Go file before top-level declarations, so that generated
+// helpers are not shown as C code.
+func applyLineMarkers(filename string, directives bool) (
+	mappings []sourceMapping, err error) {
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Go file for source map: %v", err)
+	}
+
+	var (
+		out     []string
+		mapped  bool
+		current sourceMapping
+		pending *sourceMapping
+	)
+	for _, line := range strings.Split(string(b), "\n") {
+		if pos, ok := program.ParseLineMarker(line); ok {
+			pending = &sourceMapping{File: pos.File, Line: pos.Line, Column: pos.Column}
+			continue
+		}
+		switch {
+		case pending != nil:
+			if directives {
+				directive := fmt.Sprintf("//line %s:%d", pending.File, pending.Line)
+				if pending.Column > 0 {
+					directive += fmt.Sprintf(":%d", pending.Column)
+				}
+				out = append(out, directive)
+			}
+			current, mapped, pending = *pending, true, nil
+
+		case mapped && isTopLevelLine(line):
+			if directives {
+				// relative file name of directive is joined with directory
+				// of Go file
+				out = append(out, fmt.Sprintf("//line %s:%d",
+					filepath.Base(filename), len(out)+2))
+			}
+			mapped = false
+		}
+		out = append(out, line)
+		if mapped && strings.TrimSpace(line) != "" {
+			m := current
+			m.GoFile, m.GoLine = filename, len(out)
+			mappings = append(mappings, m)
+		}
+	}
+
+	err = ioutil.WriteFile(filename, []byte(strings.Join(out, "\n")), 0644)
+	if err != nil {
+		return nil, fmt.Errorf("writing Go output file failed: %v", err)
+	}
+	return
+}
+
+// isTopLevelLine return true, if line is begin of top-level declaration
+// or comment of declaration.
+func isTopLevelLine(line string) bool {
+	if line == "" {
+		return false
+	}
+	r := rune(line[0])
+	return r == '/' || unicode.IsLetter(r)
+}
+
+// writeSourceMap writes JSON source map into file.
+func writeSourceMap(filename string, mappings []sourceMapping) error {
+	if mappings == nil {
+		mappings = []sourceMapping{}
+	}
+	b, err := json.MarshalIndent(sourceMap{Mappings: mappings}, "", "\t")
+	if err != nil {
+		return fmt.Errorf("cannot create source map: %v", err)
+	}
+	if err = ioutil.WriteFile(filename, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing source map file failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/preprocessor"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/transpiler"
+)
+
+func TestSourceMap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c4go-sourcemap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfile := filepath.Join(dir, "a.c")
+
+	// a.c : int main() {
+	//           int a = 1;
+	//           return a;
+	//       }
+	lines := strings.Split(`TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>
+`+"`"+`-FunctionDecl 0x2 <`+cfile+`:1:1, line:4:1> line:1:5 main 'int ()'
+  `+"`"+`-CompoundStmt 0x3 <col:12, line:4:1>
+    |-DeclStmt 0x4 <line:2:5, col:14>
+    | `+"`"+`-VarDecl 0x5 <col:5, col:13> col:9 used a 'int' cinit
+    |   `+"`"+`-IntegerLiteral 0x6 <col:13> 'int' 1
+    `+"`"+`-ReturnStmt 0x7 <line:3:5, col:12>
+      `+"`"+`-ImplicitCastExpr 0x8 <col:12> 'int' <LValueToRValue>
+        `+"`"+`-DeclRefExpr 0x9 <col:12> 'int' lvalue Var 0x5 'a' 'int'`, "\n")
+	tree, errs := fromLinesToTree(false, false, lines, preprocessor.FilePP{})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	p := program.NewProgram()
+	p.SourceLines = true
+	source, err := transpiler.TranspileAST("a.go", "main", true, p, tree[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(source, program.LineMarker+cfile+":2:5") {
+		t.Fatalf("marker is not found:\n%s", source)
+	}
+
+	filename := filepath.Join(dir, "a.go")
+	if err = ioutil.WriteFile(filename, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mappings, err := applyLineMarkers(filename, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), program.LineMarker) {
+		t.Errorf("markers are not removed:\n%s", string(b))
+	}
+
+	// positions of statements are taken from C source
+	found := map[int]bool{}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, b, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("not valid Go code: %v\n%s", err, string(b))
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.DeclStmt, *ast.ExprStmt:
+			pos := fset.Position(n.Pos())
+			if pos.Filename == cfile {
+				found[pos.Line] = true
+			}
+		}
+		return true
+	})
+	if !found[2] || !found[3] {
+		t.Errorf("positions of C source are not found: %v\n%s", found, string(b))
+	}
+
+	goLines := strings.Split(string(b), "\n")
+	lineOf := map[int]bool{}
+	for _, m := range mappings {
+		if m.File != cfile || m.GoFile != filename {
+			t.Errorf("not valid mapping: %#v", m)
+			continue
+		}
+		lineOf[m.Line] = true
+		if strings.HasPrefix(goLines[m.GoLine-1], "//line") {
+			t.Errorf("directive is mapped: %#v", m)
+		}
+	}
+	if !lineOf[2] || !lineOf[3] {
+		t.Errorf("not valid mappings: %#v", mappings)
+	}
+}
@@ -0,0 +1,48 @@
+#include "tests.h"
+#include <complex.h>
+#include <math.h>
+
+double complex multiply(double complex a, double complex b)
+{
+    return a * b;
+}
+
+int main()
+{
+    plan(16);
+
+    double complex z = 3.0 + 4.0 * I;
+    double complex w = 1.0 - 2.0 * I;
+    float complex f = 1.0f + 1.0f * I;
+
+    diag("parts");
+    is_eq(creal(z), 3.0);
+    is_eq(cimag(z), 4.0);
+    is_eq(crealf(f), 1.0);
+    is_eq(cimagf(f), 1.0);
+
+    diag("arithmetic");
+    double complex sum = z + w;
+    is_eq(creal(sum), 4.0);
+    is_eq(cimag(sum), 2.0);
+    double complex product = multiply(z, w);
+    is_eq(creal(product), 11.0);
+    is_eq(cimag(product), -2.0);
+    double complex quotient = z / 2.0;
+    is_eq(creal(quotient), 1.5);
+    z *= 2;
+    is_eq(cimag(z), 8.0);
+    z /= 2;
+
+    diag("functions");
+    is_eq(cabs(z), 5.0);
+    is_eq(cimag(conj(z)), -4.0);
+    is_eq(carg(I), M_PI / 2);
+    double complex e = cexp(I * M_PI);
+    is_eq(creal(e), -1.0);
+    double complex s = csqrt(-4.0);
+    is_eq(cimag(s), 2.0);
+    is_eq(cabsf(f * f), 2.0);
+
+    done_testing();
+}
@@ -0,0 +1,61 @@
+#include "tests.h"
+#include <stdio.h>
+
+void test_printf_specifiers()
+{
+    char buf[100];
+    int n = 0;
+
+    sprintf(buf, "%i|%*d|%-*d|", -42, 5, 42, 4, 1);
+    is_streq(buf, "-42|   42|1   |");
+
+    sprintf(buf, "%hhu %hd %u", 257, 65537, -1);
+    is_streq(buf, "1 1 4294967295");
+
+    sprintf(buf, "%Lf %a", (long double)1.5, 1.0);
+    is_streq(buf, "1.500000 0x1p+0");
+
+    sprintf(buf, "%g %g %g %.3g", 100000.0, 1000000.0, 0.00001, 3.14159);
+    is_streq(buf, "100000 1e+06 1e-05 3.14");
+
+    sprintf(buf, "%#x %#o %+.3e %05d", 255, 8, 12345.678, -42);
+    is_streq(buf, "0xff 010 +1.235e+04 -0042");
+
+    sprintf(buf, "%.0f %.0f %-5s|", 2.5, 3.5, "ab");
+    is_streq(buf, "2 4 ab   |");
+
+    sprintf(buf, "abc%n", &n);
+    is_eq(n, 3);
+
+    is_eq(snprintf(buf, 4, "%d-%s", 12, "ab"), 5);
+    is_streq(buf, "12-");
+}
+
+void test_scanf_specifiers()
+{
+    char name[10];
+    char rest[10];
+    int a = 0, b = 0, c = 0, n = 0;
+
+    is_eq(sscanf("abc,def", "%[^,],%s", name, rest), 2);
+    is_streq(name, "abc");
+    is_streq(rest, "def");
+
+    is_eq(sscanf(" 1 2 0x1f 017", "%*d %d %i %i%n", &a, &b, &c, &n), 3);
+    is_eq(a, 2);
+    is_eq(b, 31);
+    is_eq(c, 15);
+    is_eq(n, 13);
+
+    is_eq(sscanf("", "%d", &a), EOF);
+}
+
+int main()
+{
+    plan(18);
+
+    test_printf_specifiers();
+    test_scanf_specifiers();
+
+    done_testing();
+}
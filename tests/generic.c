@@ -0,0 +1,59 @@
+#include "tests.h"
+#include <math.h>
+#include <stdio.h>
+
+float sqrt_float(float x)
+{
+    return sqrtf(x);
+}
+
+double sqrt_double(double x)
+{
+    return sqrt(x);
+}
+
+#define SQRT(X) _Generic((X), float \
+                         : sqrt_float, default \
+                         : sqrt_double)(X)
+
+#define TYPE_NAME(X) _Generic((X), int \
+                              : "int", float \
+                              : "float", double \
+                              : "double", char* \
+                              : "string", default \
+                              : "unknown")
+
+#define TYPE_ID(X) _Generic((X), int \
+                            : 1, long \
+                            : 2, default \
+                            : 0)
+
+int main()
+{
+    plan(10);
+
+    int i = 4;
+    long l = 5;
+    float f = 9.0f;
+    double d = 16.0;
+    char* s = "text";
+    char c = 'c';
+
+    diag("type name");
+    is_streq(TYPE_NAME(i), "int");
+    is_streq(TYPE_NAME(f), "float");
+    is_streq(TYPE_NAME(d), "double");
+    is_streq(TYPE_NAME(s), "string");
+    is_streq(TYPE_NAME(c), "unknown");
+
+    diag("value");
+    is_eq(TYPE_ID(i), 1);
+    is_eq(TYPE_ID(l), 2);
+    is_eq(TYPE_ID(d), 0);
+
+    diag("function");
+    is_eq(SQRT(f), 3.0);
+    is_eq(SQRT(d), 4.0);
+
+    done_testing();
+}
@@ -0,0 +1,133 @@
+#include "tests.h"
+#include <stddef.h>
+#include <stdio.h>
+
+struct rec {
+    char c;
+    int i;
+    double d;
+    short s;
+};
+
+struct __attribute__((packed)) pk {
+    char c;
+    int i;
+    short s;
+};
+
+struct al {
+    char c;
+    int i __attribute__((aligned(8)));
+};
+
+#pragma pack(push, 2)
+struct pp {
+    char c;
+    double d;
+};
+#pragma pack(pop)
+
+struct bits {
+    char c;
+    int x : 3;
+    int y : 20;
+    char d;
+};
+
+union un {
+    char c[5];
+    int i;
+};
+
+void test_sizeof()
+{
+    is_eq(sizeof(struct rec), 24);
+    is_eq(sizeof(struct pk), 7);
+    is_eq(sizeof(struct al), 16);
+    is_eq(sizeof(struct pp), 10);
+    is_eq(sizeof(struct bits), 8);
+    is_eq(sizeof(union un), 8);
+}
+
+void test_offsetof()
+{
+    is_eq(offsetof(struct rec, i), 4);
+    is_eq(offsetof(struct rec, d), 8);
+    is_eq(offsetof(struct rec, s), 16);
+    is_eq(offsetof(struct pk, i), 1);
+    is_eq(offsetof(struct pk, s), 5);
+    is_eq(offsetof(struct al, i), 8);
+    is_eq(offsetof(struct pp, d), 2);
+    is_eq(offsetof(struct bits, d), 4);
+}
+
+void test_fread()
+{
+    struct rec out[2] = { { 'a', 1, 1.5, 10 }, { 'b', 2, 2.5, 20 } };
+    struct rec in[2];
+    struct pk p = { 'p', 258, 7 };
+    struct pk q;
+    struct bits b = { 'b', 2, -100000, 'd' };
+    struct bits bb;
+    unsigned char raw[8];
+    FILE* f;
+
+    f = fopen("./testdata/layout.bin", "w");
+    is_eq(fwrite(out, sizeof(struct rec), 2, f), 2);
+    fclose(f);
+
+    f = fopen("./testdata/layout.bin", "r");
+    is_eq(fread(in, sizeof(struct rec), 2, f), 2);
+    fclose(f);
+    is_eq(in[0].c, 'a');
+    is_eq(in[0].i, 1);
+    is_eq(in[1].d, 2.5);
+    is_eq(in[1].s, 20);
+
+    f = fopen("./testdata/layout.bin", "w");
+    is_eq(fwrite(&p, sizeof(struct pk), 1, f), 1);
+    fclose(f);
+
+    f = fopen("./testdata/layout.bin", "r");
+    is_eq(fread(raw, 1, 7, f), 7);
+    fclose(f);
+    is_eq(raw[1], 2);
+    is_eq(raw[2], 1);
+
+    f = fopen("./testdata/layout.bin", "r");
+    is_eq(fread(&q, sizeof(struct pk), 1, f), 1);
+    fclose(f);
+    is_eq(q.i, 258);
+    is_eq(q.s, 7);
+
+    f = fopen("./testdata/layout.bin", "w");
+    is_eq(fwrite(&b, sizeof(struct bits), 1, f), 1);
+    fclose(f);
+
+    f = fopen("./testdata/layout.bin", "r");
+    is_eq(fread(raw, 1, 8, f), 8);
+    fclose(f);
+    is_eq(raw[0], 'b');
+    is_eq(raw[1], 2);
+    is_eq(raw[2], 203);
+    is_eq(raw[3], 115);
+    is_eq(raw[4], 'd');
+
+    f = fopen("./testdata/layout.bin", "r");
+    is_eq(fread(&bb, sizeof(struct bits), 1, f), 1);
+    fclose(f);
+    is_eq((int)(bb.x), 2);
+    is_eq((int)(bb.y), -100000);
+    is_eq(bb.d, 'd');
+}
+
+int main()
+{
+    plan(38);
+
+    test_sizeof();
+    test_offsetof();
+    test_fread();
+
+    done_testing();
+}
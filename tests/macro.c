@@ -0,0 +1,74 @@
+// Tests for function-like macros transpiled with option -macro-func.
+
+#include "tests.h"
+
+#define MAX(a, b) ((a) > (b) ? (a) : (b))
+#define SQR(x) ((x) * (x))
+#define AVG(a, b) (((a) + (b)) / 2)
+#define IS_EVEN(n) ((n) % 2 == 0)
+#define SCALE 3
+#define MUL3(x) ((x) * SCALE)
+
+int counter = 0;
+
+int next()
+{
+    counter++;
+    return counter;
+}
+
+void test_simple()
+{
+    diag("simple macros");
+    int x = 5;
+    is_eq(MAX(x, 42), 42);
+    is_eq(MAX(x, 2), 5);
+    is_eq(SQR(x), 25);
+    is_eq(SQR(x + 1), 36);
+    is_eq(AVG(x, 7), 6);
+    is_true(IS_EVEN(x + 1));
+    is_false(IS_EVEN(x));
+    is_eq(MUL3(x), 15);
+}
+
+void test_generic()
+{
+    diag("macros with different types");
+    double d = 2.5;
+    long l = 7;
+    is_eq(MAX(d, 1.5), 2.5);
+    is_eq(SQR(d), 6.25);
+    is_eq(MAX(l, 3L), 7);
+    is_eq(AVG(d, 3.5), 3.0);
+}
+
+void test_nested()
+{
+    diag("nested macros");
+    int a = 3, b = 4;
+    is_eq(MAX(SQR(a), SQR(b)), 16);
+    is_eq(SQR(MAX(a, b)), 16);
+}
+
+void test_side_effects()
+{
+    diag("arguments with side effects");
+    counter = 0;
+    is_eq(SQR(next()), 2);
+    is_eq(counter, 2);
+    counter = 0;
+    is_eq(MUL3(next()), 3);
+    is_eq(counter, 1);
+}
+
+int main()
+{
+    plan(18);
+
+    test_simple();
+    test_generic();
+    test_nested();
+    test_side_effects();
+
+    done_testing();
+}
@@ -0,0 +1,99 @@
+#include "tests.h"
+#include <pthread.h>
+#include <stdio.h>
+
+#define THREADS 4
+#define ITERATIONS 1000
+
+pthread_mutex_t mutex = PTHREAD_MUTEX_INITIALIZER;
+pthread_cond_t cond = PTHREAD_COND_INITIALIZER;
+pthread_once_t once = PTHREAD_ONCE_INIT;
+
+int counter = 0;
+int ready = 0;
+int inits = 0;
+
+void init_once(void)
+{
+    inits++;
+}
+
+void* worker(void* arg)
+{
+    int* id = (int*)arg;
+    int i;
+    pthread_once(&once, init_once);
+
+    pthread_mutex_lock(&mutex);
+    while (ready == 0) {
+        pthread_cond_wait(&cond, &mutex);
+    }
+    pthread_mutex_unlock(&mutex);
+
+    for (i = 0; i < ITERATIONS; i++) {
+        pthread_mutex_lock(&mutex);
+        counter++;
+        pthread_mutex_unlock(&mutex);
+    }
+    *id = *id * 10;
+    return arg;
+}
+
+void* exit_worker(void* arg)
+{
+    pthread_exit(arg);
+    return NULL;
+}
+
+int main()
+{
+    plan(10);
+
+    pthread_t threads[THREADS];
+    int ids[THREADS];
+    int i;
+
+    diag("create");
+    for (i = 0; i < THREADS; i++) {
+        ids[i] = i + 1;
+        is_eq(pthread_create(&threads[i], NULL, worker, &ids[i]), 0);
+    }
+
+    pthread_mutex_lock(&mutex);
+    ready = 1;
+    pthread_cond_broadcast(&cond);
+    pthread_mutex_unlock(&mutex);
+
+    diag("join");
+    int sum = 0;
+    for (i = 0; i < THREADS; i++) {
+        void* result;
+        pthread_join(threads[i], &result);
+        sum += *(int*)result;
+    }
+    is_eq(sum, 100);
+    is_eq(counter, THREADS * ITERATIONS);
+    is_eq(inits, 1);
+
+    diag("pthread_exit");
+    {
+        pthread_t t;
+        int value = 42;
+        void* result;
+        pthread_create(&t, NULL, exit_worker, &value);
+        pthread_join(t, &result);
+        is_eq(*(int*)result, 42);
+    }
+
+    diag("trylock");
+    {
+        pthread_mutex_t m;
+        pthread_mutex_init(&m, NULL);
+        is_eq(pthread_mutex_trylock(&m), 0);
+        is_true(pthread_mutex_trylock(&m) != 0);
+        pthread_mutex_unlock(&m);
+        pthread_mutex_destroy(&m);
+    }
+
+    done_testing();
+}
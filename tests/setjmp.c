@@ -0,0 +1,141 @@
+#include "tests.h"
+#include <setjmp.h>
+#include <stdio.h>
+
+jmp_buf env;
+int counter = 0;
+
+void jump(int value)
+{
+    counter++;
+    longjmp(env, value);
+    fail("%s", "It shouldn't make it to here!");
+}
+
+void jump_to(jmp_buf buf, int value)
+{
+    counter++;
+    longjmp(buf, value);
+    fail("%s", "It shouldn't make it to here!");
+}
+
+int test_argument()
+{
+    jmp_buf local;
+    int value = setjmp(local);
+    if (value < 2) {
+        jump_to(local, value + 1);
+    }
+    return value;
+}
+
+int test_if()
+{
+    if (setjmp(env) == 0) {
+        jump(5);
+        return 1;
+    } else {
+        return 2;
+    }
+    return 3;
+}
+
+int test_value()
+{
+    int value = setjmp(env);
+    if (value < 3) {
+        jump(value + 1);
+    }
+    return value;
+}
+
+int test_zero()
+{
+    switch (setjmp(env)) {
+    case 0:
+        jump(0);
+        break;
+    case 1:
+        return 42;
+    }
+    return -1;
+}
+
+int test_loop()
+{
+    int sum = 0;
+    int i;
+    for (i = 0; i < 5; i++) {
+        if (setjmp(env) != 0) {
+            continue;
+        }
+        sum += i;
+        if (i == 3) {
+            break;
+        }
+        jump(1);
+    }
+    return sum;
+}
+
+int test_nested()
+{
+    jmp_buf local;
+    int result = 0;
+    if (setjmp(env) != 0) {
+        return result;
+    }
+    if (setjmp(local) == 0) {
+        result += 10;
+        longjmp(local, 1);
+    }
+    result += 5;
+    longjmp(env, 1);
+    return -1;
+}
+
+int main()
+{
+    plan(12);
+
+    diag("setjmp in if");
+    counter = 0;
+    is_eq(test_if(), 2);
+    is_eq(counter, 1);
+
+    diag("value of setjmp");
+    counter = 0;
+    is_eq(test_value(), 3);
+    is_eq(counter, 3);
+
+    diag("longjmp with zero");
+    counter = 0;
+    is_eq(test_zero(), 42);
+    is_eq(counter, 1);
+
+    diag("setjmp in loop");
+    counter = 0;
+    is_eq(test_loop(), 6);
+    is_eq(counter, 3);
+
+    diag("nested setjmp");
+    is_eq(test_nested(), 15);
+
+    diag("jmp_buf as argument");
+    counter = 0;
+    is_eq(test_argument(), 2);
+    is_eq(counter, 2);
+
+    diag("setjmp as statement");
+    {
+        volatile int step = 0;
+        setjmp(env);
+        step++;
+        if (step < 3) {
+            longjmp(env, 1);
+        }
+        is_eq(step, 3);
+    }
+
+    done_testing();
+}
@@ -0,0 +1,168 @@
+#include "tests.h"
+#include <stdatomic.h>
+#include <threads.h>
+
+#define THREADS 4
+#define ITERATIONS 1000
+
+atomic_int counter = 0;
+atomic_uint flags = 0;
+mtx_t mutex;
+int shared = 0;
+once_flag once = ONCE_FLAG_INIT;
+int inits = 0;
+
+struct node {
+    int value;
+    struct node* next;
+};
+struct node nodes[THREADS * ITERATIONS];
+_Atomic(struct node*) head = NULL;
+atomic_flag lock = ATOMIC_FLAG_INIT;
+int locked = 0;
+
+void init_once(void)
+{
+    inits++;
+}
+
+int worker(void* arg)
+{
+    int id = *(int*)arg;
+    int i;
+    call_once(&once, init_once);
+    for (i = 0; i < ITERATIONS; i++) {
+        struct node* n = &nodes[id * ITERATIONS + i];
+        struct node* expected = atomic_load(&head);
+        atomic_fetch_add(&counter, 1);
+        counter++;
+        mtx_lock(&mutex);
+        shared++;
+        mtx_unlock(&mutex);
+        // lock-free stack
+        n->value = 1;
+        do {
+            n->next = expected;
+        } while (!atomic_compare_exchange_weak(&head, &expected, n));
+        // spinlock
+        while (atomic_flag_test_and_set(&lock)) {
+        }
+        locked++;
+        atomic_flag_clear(&lock);
+    }
+    atomic_fetch_or(&flags, 1u << id);
+    return id;
+}
+
+int main()
+{
+    plan(41);
+
+    diag("operations");
+    {
+        atomic_int a = 5;
+        int expected = 5;
+        is_eq(atomic_load(&a), 5);
+        atomic_store(&a, 7);
+        is_eq(a, 7);
+        is_eq(atomic_exchange(&a, 10), 7);
+        is_eq(atomic_fetch_add(&a, 2), 10);
+        is_eq(atomic_fetch_sub(&a, 4), 12);
+        a += 3;
+        a *= 2;
+        is_eq(a, 22);
+        is_true(!atomic_compare_exchange_strong(&a, &expected, 1));
+        is_eq(expected, 22);
+        is_true(atomic_compare_exchange_strong(&a, &expected, 1));
+        is_eq(a, 1);
+        a = 3;
+        --a;
+        is_eq(a++, 2);
+        is_eq(a, 3);
+        is_eq(atomic_fetch_and(&a, 6), 3);
+        is_eq(atomic_fetch_xor(&a, 7), 2);
+    }
+
+    diag("atomic_bool");
+    {
+        atomic_bool b = 0;
+        atomic_store(&b, 1);
+        is_true(atomic_load(&b));
+    }
+
+    diag("atomic char and short");
+    {
+        atomic_char c = 'a';
+        _Atomic(unsigned char) uc = 255;
+        atomic_short s = 32767;
+        atomic_ushort us = 1;
+        c++;
+        is_eq(atomic_load(&c), 'b');
+        is_eq(atomic_fetch_add(&uc, 2), 255);
+        is_eq(uc, 1);
+        s += 1;
+        is_eq(s, -32768);
+        is_eq(atomic_fetch_sub(&us, 2), 1);
+        is_eq(us, 65535);
+        is_eq(atomic_exchange(&c, 'z'), 'b');
+        is_eq(atomic_fetch_or(&us, 1), 65535);
+    }
+
+    diag("atomic pointer");
+    {
+        int values[2] = { 1, 2 };
+        _Atomic(int*) p = &values[0];
+        int* expected = &values[1];
+        is_true(atomic_load(&p) == &values[0]);
+        is_true(!atomic_compare_exchange_strong(&p, &expected, NULL));
+        is_true(expected == &values[0]);
+        is_true(atomic_compare_exchange_strong(&p, &expected, &values[1]));
+        is_eq(*atomic_load(&p), 2);
+        is_true(atomic_exchange(&p, NULL) == &values[1]);
+        is_true(p == NULL);
+    }
+
+    diag("atomic_flag");
+    {
+        atomic_flag f = ATOMIC_FLAG_INIT;
+        is_false(atomic_flag_test_and_set(&f));
+        is_true(atomic_flag_test_and_set(&f));
+        atomic_flag_clear(&f);
+        is_false(atomic_flag_test_and_set_explicit(&f, memory_order_acquire));
+    }
+
+    diag("threads");
+    {
+        thrd_t threads[THREADS];
+        int ids[THREADS];
+        int i;
+        int sum = 0;
+        is_eq(mtx_init(&mutex, mtx_plain), thrd_success);
+        for (i = 0; i < THREADS; i++) {
+            ids[i] = i;
+            thrd_create(&threads[i], worker, &ids[i]);
+        }
+        for (i = 0; i < THREADS; i++) {
+            int res;
+            thrd_join(threads[i], &res);
+            sum += res;
+        }
+        mtx_destroy(&mutex);
+        is_eq(sum, 6);
+        is_eq(atomic_load(&counter), 2 * THREADS * ITERATIONS);
+        is_eq(shared, THREADS * ITERATIONS);
+        is_eq(flags, 15);
+        is_eq(inits, 1);
+        is_eq(locked, THREADS * ITERATIONS);
+        {
+            struct node* n;
+            int count = 0;
+            for (n = atomic_load(&head); n != NULL; n = n->next) {
+                count += n->value;
+            }
+            is_eq(count, THREADS * ITERATIONS);
+        }
+    }
+
+    done_testing();
+}
@@ -5,6 +5,7 @@
 #include <assert.h>
 #include <stdarg.h>
 #include <stdio.h>
+#include <stdlib.h>
 #include <string.h>
 
 #define START_TEST(t) \
@@ -681,9 +682,90 @@ void test_setvbuf()
     fclose(pFile);
 }
 
+void test_ungetc()
+{
+    FILE* pFile;
+    char line[20];
+
+    pFile = fopen("./testdata/ungetc.txt", "w+");
+    fputs("ab\ncd", pFile);
+    rewind(pFile);
+
+    is_eq(fgetc(pFile), 'a');
+    is_eq(ungetc('x', pFile), 'x');
+    is_eq(ftell(pFile), 0);
+    is_not_null(fgets(line, 20, pFile));
+    is_streq(line, "xb\n");
+    is_eq(ungetc(EOF, pFile), EOF);
+
+    fclose(pFile);
+}
+
+void test_ferror()
+{
+    FILE* pFile;
+
+    pFile = fopen("./testdata/ferror.txt", "w+");
+    is_eq(fgetc(pFile), EOF);
+    is_true(feof(pFile));
+    is_false(ferror(pFile));
+    clearerr(pFile);
+    is_false(feof(pFile));
+
+    fclose(pFile);
+}
+
+void test_freopen()
+{
+    FILE* pFile;
+
+    pFile = fopen("./testdata/freopen.txt", "w");
+    fputs("freopen", pFile);
+    pFile = freopen("./testdata/freopen.txt", "r", pFile);
+    is_not_null(pFile);
+    is_eq(fgetc(pFile), 'f');
+    fclose(pFile);
+}
+
+void test_fmemopen()
+{
+    char buffer[20];
+    int value = 0;
+    FILE* pFile;
+
+    pFile = fmemopen(buffer, sizeof(buffer), "w+");
+    is_not_null(pFile);
+    fprintf(pFile, "%d-%s", 42, "ab");
+    fflush(pFile);
+    is_streq(buffer, "42-ab");
+    rewind(pFile);
+    is_eq(fscanf(pFile, "%d", &value), 1);
+    is_eq(value, 42);
+    fclose(pFile);
+}
+
+void test_open_memstream()
+{
+    char* ptr = NULL;
+    size_t size = 0;
+    FILE* pFile;
+
+    pFile = open_memstream(&ptr, &size);
+    is_not_null(pFile);
+    fputs("hello", pFile);
+    fflush(pFile);
+    is_streq(ptr, "hello");
+    is_eq(size, 5);
+    fprintf(pFile, ", %s", "world");
+    fclose(pFile);
+    is_streq(ptr, "hello, world");
+    is_eq(size, 12);
+    free(ptr);
+}
+
 int main()
 {
-    plan(71);
+    plan(94);
 
     START_TEST(putchar);
     START_TEST(puts);
@@ -724,6 +806,11 @@ int main()
     START_TEST(vfprintf);
     START_TEST(setbuf);
     START_TEST(setvbuf);
+    START_TEST(ungetc);
+    START_TEST(ferror);
+    START_TEST(freopen);
+    START_TEST(fmemopen);
+    START_TEST(open_memstream);
 
     // that test must be last test
     START_TEST(perror);
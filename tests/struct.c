@@ -741,6 +741,26 @@ struct bitstr {
     unsigned int b : 2;
 };
 
+struct bitsigned {
+    int s : 3;
+    unsigned int : 0;
+    unsigned char c : 4;
+    double d;
+};
+
+struct bitafter {
+    char c;
+    int x : 3;
+};
+
+struct bitbetween {
+    char c;
+    int x : 3;
+    int y : 20;
+    char d;
+    short s : 4;
+};
+
 void test_struct_bit()
 {
     diag("struct bit");
@@ -749,6 +769,68 @@ void test_struct_bit()
     bs.b = 2;
     is_eq((int)(bs.a), 1);
     is_eq((int)(bs.b), 2);
+    bs.b += 3;
+    is_eq((int)(bs.b), 1);
+    bs.a++;
+    is_eq((int)(bs.a), 0);
+    is_eq((int)(bs.b), 1);
+
+    struct bitsigned bss = { -2, 15, 1.5 };
+    is_eq((int)(bss.s), -2);
+    is_eq((int)(bss.c), 15);
+    is_eq(bss.d, 1.5);
+    bss.s = 3;
+    bss.s++;
+    is_eq((int)(bss.s), -4);
+    struct bitsigned* pbss = &bss;
+    pbss->c = pbss->c - 1;
+    is_eq((int)(pbss->c), 14);
+
+    diag("bit-fields after other members");
+    struct bitafter ba = { 'a', -3 };
+    is_eq((int)(ba.c), 'a');
+    is_eq((int)(ba.x), -3);
+    ba.x = 3;
+    is_eq((int)(ba.c), 'a');
+    is_eq((int)(ba.x), 3);
+
+    struct bitbetween bb = { 'b', 2, -100000, 'd', 7 };
+    is_eq((int)(bb.c), 'b');
+    is_eq((int)(bb.x), 2);
+    is_eq((int)(bb.y), -100000);
+    is_eq(bb.d, 'd');
+    is_eq((int)(bb.s), 7);
+    bb.y += 524287;
+    bb.x--;
+    is_eq((int)(bb.x), 1);
+    is_eq((int)(bb.y), 424287);
+    is_eq(bb.d, 'd');
+    bb.s = -8;
+    is_eq((int)(bb.s), -8);
+    is_eq((int)(bb.c), 'b');
+}
+
+union bitreg {
+    unsigned int raw;
+    unsigned int low : 4;
+    int sign : 3;
+};
+
+void test_union_bit()
+{
+    diag("union bit");
+    union bitreg r;
+    r.raw = 0xfe;
+    is_eq((int)(r.low), 14);
+    is_eq((int)(r.sign), -2);
+    r.low = 3;
+    is_eq(r.raw, 0xf3);
+    r.sign = -1;
+    is_eq(r.raw, 0xf7);
+    union bitreg* pr = &r;
+    pr->low += 2;
+    is_eq((int)(pr->low), 9);
+    is_eq(r.raw, 0xf9);
 }
 
 union MyNumber {
@@ -800,7 +882,7 @@ void test_union_function()
 
 int main()
 {
-    plan(127);
+    plan(155);
 
     pointer_arithm_in_struct();
     test_extern_vec();
@@ -1208,6 +1290,7 @@ int main()
     typedef_struct_with_typedef_union();
     test_struct_with_func();
     test_struct_bit();
+    test_union_bit();
     test_union_function();
 
     done_testing();
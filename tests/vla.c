@@ -0,0 +1,101 @@
+#include "tests.h"
+#include <alloca.h>
+#include <stdlib.h>
+#include <string.h>
+
+struct dims {
+    int n;
+};
+
+int sum(int n)
+{
+    int values[n];
+    int i, s = 0;
+    for (i = 0; i < n; i++) {
+        values[i] = i + 1;
+    }
+    for (i = 0; i < n; i++) {
+        s += values[i];
+    }
+    return s;
+}
+
+double trace(int n)
+{
+    double matrix[n][n];
+    double t = 0;
+    int i, j;
+    for (i = 0; i < n; i++) {
+        for (j = 0; j < n; j++) {
+            matrix[i][j] = i == j ? 1.5 : 0.0;
+        }
+    }
+    for (i = 0; i < n; i++) {
+        t += matrix[i][i];
+    }
+    return t;
+}
+
+int main()
+{
+    plan(16);
+
+    int n = 5;
+    int m = 3;
+
+    diag("one dimension");
+    is_eq(sum(n), 15);
+    {
+        char buf[n + 1];
+        is_eq(sizeof(buf), 6);
+    }
+
+    diag("multidimensional");
+    is_eq(trace(4), 6.0);
+    {
+        int grid[n][m];
+        grid[n - 1][m - 1] = 42;
+        is_eq(grid[4][2], 42);
+        is_eq(sizeof(grid), n * m * sizeof(int));
+        is_eq(sizeof(grid[0]), m * sizeof(int));
+        is_eq(sizeof(int[n][m + 1]), n * (m + 1) * sizeof(int));
+    }
+    {
+        // size is fixed by declaration
+        double a[n][m];
+        m++;
+        is_eq(sizeof(a), n * (m - 1) * sizeof(double));
+        is_eq(sizeof(a[1]), (m - 1) * sizeof(double));
+        m--;
+    }
+
+    diag("sizes of expressions");
+    {
+        struct dims d = { 3 };
+        struct dims* pd = &d;
+        const char* s = "hello";
+        int a1[pd->n];
+        int a2[n + 2u];
+        char a3[strlen(s) + 1];
+        int a4[d.n * m];
+        a1[pd->n - 1] = 7;
+        is_eq(a1[2], 7);
+        is_eq(sizeof(a1), 3 * sizeof(int));
+        is_eq(sizeof(a2), 7 * sizeof(int));
+        is_eq(sizeof(a3), 6);
+        is_eq(sizeof(a4), 9 * sizeof(int));
+    }
+
+    diag("alloca");
+    {
+        int* p = alloca(n * sizeof(int));
+        int i;
+        for (i = 0; i < n; i++) {
+            p[i] = i * i;
+        }
+        is_eq(p[4], 16);
+        is_eq(p[0] + p[1] + p[2], 5);
+    }
+
+    done_testing();
+}
@@ -81,6 +81,12 @@ func transpileBinaryOperator(n *ast.BinaryOperator, p *program.Program, exprIsSt
 		}
 	}()
 
+	// assignment of atomic value
+	if operator == token.ASSIGN && isAtomicNode(n.Children()[0], p) {
+		return transpileAtomicAssign(n.Children()[0], n.Children()[1],
+			operator, p, exprIsStmt)
+	}
+
 	// Char overflow
 	// BinaryOperator  'int' '!='
 	// |-ImplicitCastExpr 'int' <IntegralCast>
@@ -617,6 +623,11 @@ func transpileBinaryOperator(n *ast.BinaryOperator, p *program.Program, exprIsSt
 		return nil, "", nil, nil, err
 	}
 
+	// assignment of bit-field
+	if setter, ok := bitFieldAssign(n.Children()[0], left, operator, right); ok {
+		return setter, n.Type, preStmts, postStmts, nil
+	}
+
 	return util.NewBinaryExpr(left, operator, right, resolvedLeftType, exprIsStmt),
 		types.ResolveTypeForBinaryOperator(p, n.Operator, leftType, rightType),
 		preStmts, postStmts, nil
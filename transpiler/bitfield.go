@@ -0,0 +1,422 @@
+package transpiler
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// transpileBitFieldStorages return storage fields for sequence of neighbor
+// bit-fields.
+func transpileBitFieldStorages(p *program.Program, s *program.Struct, run int) (
+	fields []*goast.Field, err error) {
+	for _, st := range s.GetBitFieldStorages(run) {
+		var goType string
+		if _, size := types.GetArrayTypeAndSize(st.CType); size > 0 {
+			// array of bytes is value in struct
+			goType = fmt.Sprintf("[%d]byte", size)
+		} else {
+			goType, err = types.ResolveType(p, st.CType)
+			if err != nil {
+				return
+			}
+		}
+		fields = append(fields, &goast.Field{
+			Names: []*goast.Ident{util.NewIdent(st.Name)},
+			Type:  util.NewTypeIdent(goType),
+		})
+	}
+	return
+}
+
+// transpileBitFieldMethods return getter and setter methods for all
+// bit-fields of struct.
+//
+// Example:
+//
+//	C  : struct bitstr { unsigned int a : 1; unsigned int b : 2; };
+//	Go : type bitstr struct { c4go_bitfield_0 uint32 }
+//	     func (structVar bitstr) a() uint32 { ... }
+//	     func (structVar *bitstr) set_a(value uint32) uint32 { ... }
+func transpileBitFieldMethods(p *program.Program, name string, s *program.Struct) (
+	_ []goast.Decl, err error) {
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpileBitFieldMethods : err = %v", err)
+		}
+	}()
+
+	bfs := s.GetBitFields()
+	if len(bfs) == 0 {
+		return
+	}
+
+	src := `package main
+
+{{ range .Fields }}
+{{- if .Load }}
+func (structVar {{ $.Name }}) {{ .Name }}() {{ .Type }} {
+	storage := {{ .Load }}
+{{- if .Signed }}
+	return {{ .Type }}(int64(storage<<{{ .ShiftLeft }}) >> {{ .ShiftRight }})
+{{- else }}
+	return {{ .Type }}(storage >> {{ .Offset }} & {{ .Mask }})
+{{- end }}
+}
+
+func (structVar *{{ $.Name }}) {{ .Setter }}(value {{ .Type }}) {{ .Type }} {
+	storage := {{ .Load }}&^({{ .Mask }}<<{{ .Offset }}) |
+		uint64(value)&{{ .Mask }}<<{{ .Offset }}
+	{{ .Store }}
+	return structVar.{{ .Name }}()
+}
+{{ else }}
+func (structVar {{ $.Name }}) {{ .Name }}() {{ .Type }} {
+{{- if .Signed }}
+	return {{ .Type }}(int64(uint64(structVar.{{ .Storage }})<<{{ .ShiftLeft }}) >> {{ .ShiftRight }})
+{{- else }}
+	return {{ .Type }}(structVar.{{ .Storage }} >> {{ .Offset }} & {{ .Mask }})
+{{- end }}
+}
+
+func (structVar *{{ $.Name }}) {{ .Setter }}(value {{ .Type }}) {{ .Type }} {
+	structVar.{{ .Storage }} = structVar.{{ .Storage }}&^({{ .Mask }}<<{{ .Offset }}) |
+		{{ .StorageType }}(value)&{{ .Mask }}<<{{ .Offset }}
+	return structVar.{{ .Name }}()
+}
+{{ end }}
+{{- end }}
+`
+	type field struct {
+		Name        string
+		Setter      string
+		Type        string
+		Signed      bool
+		Storage     string
+		StorageType string
+		Offset      int
+		Mask        string
+		ShiftLeft   int
+		ShiftRight  int
+
+		// load and store of storage with array of bytes
+		Load  string
+		Store string
+	}
+
+	var st struct {
+		Name   string
+		Fields []field
+	}
+	st.Name = name
+
+	storages := map[string]string{}
+	for _, sto := range s.Storages {
+		storages[sto.Name] = sto.CType
+	}
+
+	for _, bf := range bfs {
+		var f field
+		f.Name = util.NewIdent(bf.Name).Name
+		f.Setter = program.BitFieldSetterPrefix + f.Name
+		f.Type, err = types.ResolveType(p, bf.CType)
+		if err != nil {
+			return
+		}
+		f.Signed = bf.Signed
+		f.Storage = bf.Storage
+		f.StorageType, err = types.ResolveType(p, storages[bf.Storage])
+		if err != nil {
+			return
+		}
+		f.Offset = bf.Offset
+		f.Mask = fmt.Sprintf("0x%x", uint64(1)<<uint(bf.Width)-1)
+		f.ShiftLeft = 64 - bf.Offset - bf.Width
+		f.ShiftRight = 64 - bf.Width
+		if _, size := types.GetArrayTypeAndSize(storages[bf.Storage]); size > 0 {
+			f.Load, f.Store = bitFieldArrayStorage(bf.Storage, size)
+		}
+		st.Fields = append(st.Fields, f)
+	}
+
+	tmpl := template.Must(template.New("").Parse(src))
+	var source bytes.Buffer
+	err = tmpl.Execute(&source, st)
+	if err != nil {
+		err = fmt.Errorf("cannot execute template \"%s\" for data %v : %v",
+			source.String(), st, err)
+		return
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "", source.String(), 0)
+	if err != nil {
+		err = fmt.Errorf("cannot parse source \"%s\" : %v",
+			source.String(), err)
+		return
+	}
+
+	return f.Decls, nil
+}
+
+// transpileUnionBitFieldMethods return getter and setter methods for all
+// bit-fields of union. Bit-fields of union are located at offset 0 of
+// union memory, so storage is unsigned integer with size of C type of
+// bit-field.
+//
+// Example:
+//
+//	C  : union reg { unsigned int raw; unsigned int low : 4; };
+//	Go : func (unionVar *reg) low() uint32 { ... }
+//	     func (unionVar *reg) set_low(value uint32) uint32 { ... }
+func transpileUnionBitFieldMethods(p *program.Program, name string, size int,
+	s *program.Struct) (_ []goast.Decl, err error) {
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpileUnionBitFieldMethods : err = %v", err)
+		}
+	}()
+
+	bfs := s.GetBitFields()
+	if len(bfs) == 0 {
+		return
+	}
+
+	src := `package main
+
+{{ range .Fields }}
+func (unionVar *{{ $.Name }}) {{ .Name }}() {{ .Type }} {
+	if unionVar.memory == nil {
+		var buffer [{{ $.Size }}]byte
+		unionVar.memory = unsafe.Pointer(&buffer)
+	}
+	storage := uint64(*(*{{ .StorageType }})(unionVar.memory))
+{{- if .Signed }}
+	return {{ .Type }}(int64(storage<<{{ .ShiftLeft }}) >> {{ .ShiftRight }})
+{{- else }}
+	return {{ .Type }}(storage & {{ .Mask }})
+{{- end }}
+}
+
+func (unionVar *{{ $.Name }}) {{ .Setter }}(value {{ .Type }}) {{ .Type }} {
+	if unionVar.memory == nil {
+		var buffer [{{ $.Size }}]byte
+		unionVar.memory = unsafe.Pointer(&buffer)
+	}
+	storage := (*{{ .StorageType }})(unionVar.memory)
+	*storage = *storage&^{{ .Mask }} | {{ .StorageType }}(value)&{{ .Mask }}
+	return unionVar.{{ .Name }}()
+}
+{{- end }}
+`
+	type field struct {
+		Name        string
+		Setter      string
+		Type        string
+		Signed      bool
+		StorageType string
+		Mask        string
+		ShiftLeft   int
+		ShiftRight  int
+	}
+
+	var un struct {
+		Name   string
+		Size   int
+		Fields []field
+	}
+	un.Name = name
+	un.Size = size
+
+	for _, bf := range bfs {
+		var f field
+		f.Name = util.NewIdent(bf.Name).Name
+		f.Setter = program.BitFieldSetterPrefix + f.Name
+		f.Type, err = types.ResolveType(p, bf.CType)
+		if err != nil {
+			return
+		}
+		f.Signed = bf.Signed
+		var typeSize int
+		typeSize, err = types.SizeOf(p, bf.CType)
+		if err != nil {
+			return
+		}
+		f.StorageType = fmt.Sprintf("uint%d", typeSize*8)
+		f.Mask = fmt.Sprintf("0x%x", uint64(1)<<uint(bf.Width)-1)
+		f.ShiftLeft = 64 - bf.Width
+		f.ShiftRight = 64 - bf.Width
+		un.Fields = append(un.Fields, f)
+	}
+
+	tmpl := template.Must(template.New("").Parse(src))
+	var source bytes.Buffer
+	err = tmpl.Execute(&source, un)
+	if err != nil {
+		err = fmt.Errorf("cannot execute template \"%s\" for data %v : %v",
+			source.String(), un, err)
+		return
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "", source.String(), 0)
+	if err != nil {
+		err = fmt.Errorf("cannot parse source \"%s\" : %v",
+			source.String(), err)
+		return
+	}
+
+	return f.Decls, nil
+}
+
+// bitFieldArrayStorage return expression of load of storage with array of
+// bytes as uint64 value in order little-endian and statement of store of
+// value `storage` back.
+//
+// Example:
+//
+//	load  : (uint64(structVar.c4go_bitfield_0[0]) | uint64(structVar.c4go_bitfield_0[1])<<8)
+//	store : structVar.c4go_bitfield_0[0], structVar.c4go_bitfield_0[1] = byte(storage), byte(storage>>8)
+func bitFieldArrayStorage(storage string, size int) (load, store string) {
+	var loads, lefts, rights []string
+	for i := 0; i < size; i++ {
+		b := fmt.Sprintf("structVar.%s[%d]", storage, i)
+		if i == 0 {
+			loads = append(loads, fmt.Sprintf("uint64(%s)", b))
+			rights = append(rights, "byte(storage)")
+		} else {
+			loads = append(loads, fmt.Sprintf("uint64(%s)<<%d", b, 8*i))
+			rights = append(rights, fmt.Sprintf("byte(storage>>%d)", 8*i))
+		}
+		lefts = append(lefts, b)
+	}
+	load = "(" + strings.Join(loads, " | ") + ")"
+	store = strings.Join(lefts, ", ") + " = " + strings.Join(rights, ", ")
+	return
+}
+
+// isBitFieldMemberExpr return true, if node is access to bit-field.
+//
+// Example of AST:
+//
+//	MemberExpr 0x2b9e6b8 <col:5, col:8> 'unsigned int' lvalue bitfield .a 0x2b9e0d0
+//	`-DeclRefExpr 0x2b9e690 <col:5> 'struct bitstr':'struct bitstr' lvalue Var 0x2b9e5d8 'bs' 'struct bitstr':'struct bitstr'
+func isBitFieldMemberExpr(node ast.Node) bool {
+	for {
+		par, ok := node.(*ast.ParenExpr)
+		if !ok || len(par.Children()) != 1 {
+			break
+		}
+		node = par.Children()[0]
+	}
+	member, ok := node.(*ast.MemberExpr)
+	return ok && member.IsBitfield
+}
+
+// bitFieldAssign convert assignment of bit-field to call of setter method.
+//
+// Example:
+//
+//	C  : bs.a += 2
+//	Go : bs.set_a(bs.a() + 2)
+func bitFieldAssign(node ast.Node, left goast.Expr, operator token.Token,
+	right goast.Expr) (_ goast.Expr, ok bool) {
+
+	if !isBitFieldMemberExpr(node) {
+		return
+	}
+	for {
+		par, ok := left.(*goast.ParenExpr)
+		if !ok {
+			break
+		}
+		left = par.X
+	}
+	getter, ok := left.(*goast.CallExpr)
+	if !ok || len(getter.Args) != 0 {
+		return nil, false
+	}
+	sel, ok := getter.Fun.(*goast.SelectorExpr)
+	if !ok {
+		return nil, false
+	}
+
+	switch {
+	case operator == token.ASSIGN:
+	case token.ADD_ASSIGN <= operator && operator <= token.AND_NOT_ASSIGN:
+		// operators in package go/token have the same order:
+		// from `+=` to `&^=` and from `+` to `&^`
+		right = &goast.BinaryExpr{
+			X:  getter,
+			Op: operator - token.ADD_ASSIGN + token.ADD,
+			Y:  right,
+		}
+	default:
+		return nil, false
+	}
+
+	return &goast.CallExpr{
+		Fun: &goast.SelectorExpr{
+			X:   sel.X,
+			Sel: util.NewIdent(program.BitFieldSetterPrefix + sel.Sel.Name),
+		},
+		Args: []goast.Expr{right},
+	}, true
+}
+
+// initBitFieldStruct return initialization of struct with bit-fields.
+//
+// Example:
+//
+//	C  : struct bitstr bs = {1, 2};
+//	Go : var bs bitstr = func() bitstr {
+//		var c4go_bitfield_struct bitstr
+//		c4go_bitfield_struct.set_a(1)
+//		c4go_bitfield_struct.set_b(2)
+//		return c4go_bitfield_struct
+//	}()
+func initBitFieldStruct(s *program.Struct, goType string, values []goast.Expr) goast.Expr {
+	varName := "c4go_bitfield_struct"
+	body := []goast.Stmt{&goast.DeclStmt{Decl: &goast.GenDecl{
+		Tok: token.VAR,
+		Specs: []goast.Spec{&goast.ValueSpec{
+			Names: []*goast.Ident{util.NewIdent(varName)},
+			Type:  util.NewTypeIdent(goType),
+		}},
+	}}}
+	for pos, value := range values {
+		name, ok := s.FieldNames[pos]
+		if !ok || value == nil {
+			continue
+		}
+		if _, ok := s.BitFields[name]; ok {
+			body = append(body, &goast.ExprStmt{X: &goast.CallExpr{
+				Fun: &goast.SelectorExpr{
+					X:   util.NewIdent(varName),
+					Sel: util.NewIdent(program.BitFieldSetterPrefix + util.NewIdent(name).Name),
+				},
+				Args: []goast.Expr{value},
+			}})
+			continue
+		}
+		body = append(body, &goast.AssignStmt{
+			Lhs: []goast.Expr{&goast.SelectorExpr{
+				X:   util.NewIdent(varName),
+				Sel: util.NewIdent(name),
+			}},
+			Tok: token.ASSIGN,
+			Rhs: []goast.Expr{value},
+		})
+	}
+	return util.NewAnonymousFunction(body, nil, util.NewIdent(varName), goType)
+}
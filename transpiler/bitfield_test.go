@@ -0,0 +1,261 @@
+package transpiler
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+)
+
+func newBitField(name, cType, width string) *ast.FieldDecl {
+	return &ast.FieldDecl{
+		Name: name,
+		Type: cType,
+		ChildNodes: []ast.Node{&ast.ConstantExpr{
+			Type: "int",
+			ChildNodes: []ast.Node{&ast.IntegerLiteral{
+				Type:  "int",
+				Value: width,
+			}},
+		}},
+	}
+}
+
+func TestBitFields(t *testing.T) {
+	p := program.NewProgram()
+	// struct bitstr {
+	//     unsigned int  a : 1;
+	//     int           b : 3;
+	//     unsigned int    : 0;
+	//     unsigned char c : 4;
+	//     double d;
+	//     unsigned int  e : 30;
+	//     unsigned int  f : 4;
+	// };
+	n := &ast.RecordDecl{
+		Kind:         "struct",
+		Name:         "bitstr",
+		IsDefinition: true,
+		ChildNodes: []ast.Node{
+			newBitField("a", "unsigned int", "1"),
+			newBitField("b", "int", "3"),
+			newBitField("", "unsigned int", "0"),
+			newBitField("c", "unsigned char", "4"),
+			&ast.FieldDecl{Name: "d", Type: "double"},
+			newBitField("e", "unsigned int", "30"),
+			newBitField("f", "unsigned int", "4"),
+		},
+	}
+	decls, err := transpileRecordDecl(p, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := p.GetStruct("struct bitstr")
+	if s == nil {
+		t.Fatalf("struct is not registered")
+	}
+	expected := map[string]program.BitField{
+		"a": {Name: "a", CType: "unsigned int", Run: 0, Storage: "c4go_bitfield_0", Offset: 0, Width: 1},
+		"b": {Name: "b", CType: "int", Signed: true, Run: 0, Storage: "c4go_bitfield_0", Offset: 1, Width: 3},
+		"c": {Name: "c", CType: "unsigned char", Run: 0, Storage: "c4go_bitfield_1", Offset: 0, Width: 4},
+		"e": {Name: "e", CType: "unsigned int", Run: 1, Storage: "c4go_bitfield_2", Offset: 0, Width: 30},
+		"f": {Name: "f", CType: "unsigned int", Run: 1, Storage: "c4go_bitfield_3", Offset: 0, Width: 4},
+	}
+	if len(s.BitFields) != len(expected) {
+		t.Errorf("Not same amount of bit-fields: %d != %d",
+			len(s.BitFields), len(expected))
+	}
+	for name, bf := range expected {
+		if s.BitFields[name] != bf {
+			t.Errorf("Not same bit-field `%s`:\nactual   : %#v\nexpected : %#v",
+				name, s.BitFields[name], bf)
+		}
+	}
+
+	size, err := types.SizeOf(p, "struct bitstr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 24 {
+		t.Errorf("Not same size: %d", size)
+	}
+
+	var buf bytes.Buffer
+	for _, d := range decls {
+		if err := format.Node(&buf, token.NewFileSet(), d); err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteString("\n")
+	}
+	code := buf.String()
+	for _, part := range []string{
+		"c4go_bitfield_0 uint32",
+		"func (structVar bitstr) a() uint32",
+		"func (structVar *bitstr) set_a(value uint32) uint32",
+		"return int32(int64(uint64(structVar.c4go_bitfield_0)<<60) >> 61)",
+		"structVar.c4go_bitfield_0&^(0x7<<1) | uint32(value)&0x7<<1",
+	} {
+		if !strings.Contains(code, part) {
+			t.Errorf("Cannot find `%s` in code:\n%s", part, code)
+		}
+	}
+}
+
+func TestBitFieldsPlacement(t *testing.T) {
+	p := program.NewProgram()
+	// struct bitplace {
+	//     char c;
+	//     int  x : 3;
+	//     int  y : 20;
+	//     char d;
+	//     short s : 4;
+	// };
+	n := &ast.RecordDecl{
+		Kind:         "struct",
+		Name:         "bitplace",
+		IsDefinition: true,
+		ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			newBitField("x", "int", "3"),
+			newBitField("y", "int", "20"),
+			&ast.FieldDecl{Name: "d", Type: "char"},
+			newBitField("s", "short", "4"),
+		},
+	}
+	decls, err := transpileRecordDecl(p, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := p.GetStruct("struct bitplace")
+	if s == nil {
+		t.Fatalf("struct is not registered")
+	}
+	expected := map[string]program.BitField{
+		"x": {Name: "x", CType: "int", Signed: true, Run: 0, Storage: "c4go_bitfield_0", Offset: 0, Width: 3},
+		"y": {Name: "y", CType: "int", Signed: true, Run: 0, Storage: "c4go_bitfield_0", Offset: 3, Width: 20},
+		"s": {Name: "s", CType: "short", Signed: true, Run: 1, Storage: "c4go_bitfield_1", Offset: 0, Width: 4},
+	}
+	for name, bf := range expected {
+		if s.BitFields[name] != bf {
+			t.Errorf("Not same bit-field `%s`:\nactual   : %#v\nexpected : %#v",
+				name, s.BitFields[name], bf)
+		}
+	}
+	storages := []program.BitFieldStorage{
+		{Name: "c4go_bitfield_0", CType: "unsigned char [3]", Run: 0, Offset: 1},
+		{Name: "c4go_bitfield_1", CType: "unsigned char", Run: 1, Offset: 5},
+	}
+	if !reflect.DeepEqual(s.Storages, storages) {
+		t.Errorf("Not same storages:\nactual   : %#v\nexpected : %#v",
+			s.Storages, storages)
+	}
+
+	var buf bytes.Buffer
+	for _, d := range decls {
+		if err := format.Node(&buf, token.NewFileSet(), d); err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteString("\n")
+	}
+	code := buf.String()
+	for _, part := range []string{
+		"c4go_bitfield_0 [3]byte",
+		"c4go_bitfield_1 uint8",
+		"storage := (uint64(structVar.c4go_bitfield_0[0]) | uint64(structVar.c4go_bitfield_0[1])<<8 | uint64(structVar.c4go_bitfield_0[2])<<16)",
+		"return int32(int64(storage<<41) >> 44)",
+		"structVar.c4go_bitfield_0[0], structVar.c4go_bitfield_0[1], structVar.c4go_bitfield_0[2] = byte(storage), byte(storage>>8), byte(storage>>16)",
+	} {
+		if !strings.Contains(code, part) {
+			t.Errorf("Cannot find `%s` in code:\n%s", part, code)
+		}
+	}
+}
+
+func TestBitFieldsUnion(t *testing.T) {
+	p := program.NewProgram()
+	// union reg {
+	//     unsigned int raw;
+	//     unsigned int low : 4;
+	//     int          sign : 3;
+	//     unsigned int     : 0;
+	//     unsigned char c : 2;
+	// };
+	n := &ast.RecordDecl{
+		Kind:         "union",
+		Name:         "reg",
+		IsDefinition: true,
+		ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "raw", Type: "unsigned int"},
+			newBitField("low", "unsigned int", "4"),
+			newBitField("sign", "int", "3"),
+			newBitField("", "unsigned int", "0"),
+			newBitField("c", "unsigned char", "2"),
+		},
+	}
+	decls, err := transpileRecordDecl(p, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := p.GetStruct("union reg")
+	if s == nil {
+		t.Fatalf("union is not registered")
+	}
+	expected := []program.BitField{
+		{Name: "low", CType: "unsigned int", Width: 4},
+		{Name: "sign", CType: "int", Signed: true, Width: 3},
+		{Name: "c", CType: "unsigned char", Width: 2},
+	}
+	if bfs := s.GetBitFields(); !reflect.DeepEqual(bfs, expected) {
+		t.Errorf("Not same bit-fields:\nactual   : %#v\nexpected : %#v",
+			bfs, expected)
+	}
+
+	// size of union is rounded up to size of pointer
+	size, err := types.SizeOf(p, "union reg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 8 {
+		t.Errorf("Not same size: %d", size)
+	}
+	l, err := types.GetRecordLayout(p, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Size != 4 || l.Offsets["low"] != 0 || l.Sizes["c"] != 1 {
+		t.Errorf("Not valid layout of union: %#v", l)
+	}
+
+	var buf bytes.Buffer
+	for _, d := range decls {
+		if err := format.Node(&buf, token.NewFileSet(), d); err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteString("\n")
+	}
+	code := buf.String()
+	for _, part := range []string{
+		"func (unionVar *reg) raw() *uint32",
+		"func (unionVar *reg) low() uint32",
+		"func (unionVar *reg) set_low(value uint32) uint32",
+		"storage := uint64(*(*uint32)(unionVar.memory))",
+		"return int32(int64(storage<<61) >> 61)",
+		"*storage = *storage&^0x3 | uint8(value)&0x3",
+	} {
+		if !strings.Contains(code, part) {
+			t.Errorf("Cannot find `%s` in code:\n%s", part, code)
+		}
+	}
+	if strings.Contains(code, "func (unionVar *reg) low() *uint32") {
+		t.Errorf("bit-field is full-width field of union:\n%s", code)
+	}
+}
@@ -43,6 +43,11 @@ func getName(p *program.Program, firstChild ast.Node) (name string, err error) {
 		return fc.Name, nil
 
 	case *ast.GenericSelectionExpr:
+		selected, err := genericSelectedExpr(p, fc)
+		if err == nil {
+			return getName(p, selected)
+		}
+		// older clang does not show associations of generic
 		if len(fc.Children()) == 0 {
 			return undefineFunctionName, nil
 		}
@@ -117,7 +122,7 @@ func getName(p *program.Program, firstChild ast.Node) (name string, err error) {
 
 // simplificationCallExprPrintf - minimize Go code
 // transpile C code : printf("Hello")
-// to Go code       : fmt_Printf("Hello")
+// to Go code       : fmt.Fprint(C4goStdio.Stdout, "Hello")
 // AST example :
 // CallExpr <> 'int'
 // |-ImplicitCastExpr <> 'int (*)(const char *, ...)' <FunctionToPointerDecay>
@@ -187,8 +192,9 @@ func simplificationCallExprPrintf(call *ast.CallExpr, p *program.Program) (
 	// .  }
 	// }
 	p.AddImport("fmt")
-	printfText = strconv.Quote(printfText)
-	return util.NewCallExpr("fmt"+"."+"Printf",
+	printfText = strconv.Quote(strings.Replace(printfText, "%%", "%", -1))
+	return util.NewCallExpr("fmt"+"."+"Fprint",
+		goast.NewIdent(p.ImportType(program.StdioVariable+".Stdout")),
 		&goast.BasicLit{
 			Kind:  token.STRING,
 			Value: printfText,
@@ -232,6 +238,11 @@ func transpileCallExpr(n *ast.CallExpr, p *program.Program) (
 	// specific for va_list
 	changeVaListFuncs(&functionName)
 
+	// function "longjmp" from setjmp.h
+	if p.IncludeHeaderIsExists("setjmp.h") && longjmpFunctions[functionName] {
+		return transpileLongjmp(n, p)
+	}
+
 	// function "malloc" from stdlib.h
 	//
 	// Change from "malloc" to "calloc"
@@ -250,25 +261,29 @@ func transpileCallExpr(n *ast.CallExpr, p *program.Program) (
 	// `-UnaryExprOrTypeTraitExpr <> 'unsigned long' sizeof 'char'
 	if p.IncludeHeaderIsExists("stdlib.h") {
 		if functionName == "malloc" && len(n.Children()) == 2 {
-			// Change from "malloc" to "calloc"
-			unary, expression, back, err := findAndReplaceUnaryExprOrTypeTraitExpr(&n.Children()[1])
-			if err != nil {
-				back()
-				return transpileCallExprCalloc(n.Children()[1],
-					&ast.UnaryExprOrTypeTraitExpr{
-						Function: "sizeof",
-						Type1:    "unsigned long",
-						Type2:    "char",
-					}, p)
-			}
-			return transpileCallExprCalloc(expression, unary.(*ast.UnaryExprOrTypeTraitExpr), p)
+			return transpileCallExprMalloc(n, p)
 		}
 	}
 
-	if p.IncludeHeaderIsExists("stdio.h") &&
-		(functionName == "setbuf" || functionName == "setvbuf") {
-		// ignore function
-		return
+	// function "alloca" from alloca.h
+	//
+	// Memory of alloca is allocated in stack frame of function and
+	// free automatically after return. Slice in Go is free by garbage
+	// collector, so "alloca" is same as "malloc".
+	//
+	// CallExpr <> 'void *'
+	// |-ImplicitCastExpr <> 'void *(*)(unsigned long)' <BuiltinFnToFnPtr>
+	// | `-DeclRefExpr <> '<builtin fn type>' Function '__builtin_alloca' 'void *(unsigned long)'
+	// `-BinaryOperator <> 'unsigned long' '*'
+	//   `- ...
+	if (functionName == "alloca" || functionName == "__builtin_alloca") &&
+		len(n.Children()) == 2 {
+		return transpileCallExprMalloc(n, p)
+	}
+
+	// functions "fread", "fwrite" for structs with memory layout of C
+	if p.IncludeHeaderIsExists("stdio.h") && isLayoutIOCall(p, n, functionName) {
+		return transpileCallExprLayoutIO(n, p, functionName)
 	}
 
 	// function "calloc" from stdlib.h
@@ -413,12 +428,7 @@ func transpileCallExpr(n *ast.CallExpr, p *program.Program) (
 	}
 
 	if functionDef.Substitution != "" {
-		parts := strings.Split(functionDef.Substitution, ".")
-		importName := strings.Join(parts[:len(parts)-1], ".")
-		p.AddImport(importName)
-
-		parts2 := strings.Split(functionDef.Substitution, "/")
-		functionName = parts2[len(parts2)-1]
+		functionName = p.ImportType(functionDef.Substitution)
 	}
 
 	args := []goast.Expr{}
@@ -603,41 +613,31 @@ func findAndReplaceUnaryExprOrTypeTraitExpr(node *ast.Node) (
 	}()
 
 	var counter int
-	var lastNode *ast.Node
+	var parent ast.Node
+	var index int
 	one := &ast.IntegerLiteral{Type: "int", Value: "1"}
 
-	var searcher func(*ast.Node) bool
-	replacer := func(node *ast.Node) {
-		unary = *node
-		lastNode = node
-		*node = one
-		counter++
-	}
-	searcher = func(node *ast.Node) (modify bool) {
-		// find
-		if u, ok := (*node).(*ast.UnaryExprOrTypeTraitExpr); ok &&
+	*node = ast.Apply(*node, func(c *ast.Cursor) bool {
+		if u, ok := c.Node().(*ast.UnaryExprOrTypeTraitExpr); ok &&
 			u.Function == "sizeof" {
-			return true
-		}
-		for i := range (*node).Children() {
-			if searcher(&((*node).Children()[i])) {
-				replacer(&((*node).Children()[i]))
-			}
+			unary, parent, index = u, c.Parent(), c.Index()
+			c.Replace(one)
+			counter++
+			return false
 		}
-		return false
-	}
-	if searcher(node) {
-		unary = *node
-		lastNode = node
-		*node = one
-		counter++
-	}
+		return true
+	}, nil)
 
 	back = func() {
 		// return back node
-		if unary != nil {
-			*lastNode = unary
+		if unary == nil {
+			return
 		}
+		if parent == nil {
+			*node = unary
+			return
+		}
+		parent.Children()[index] = unary
 	}
 
 	if counter != 1 {
@@ -654,6 +654,22 @@ func findAndReplaceUnaryExprOrTypeTraitExpr(node *ast.Node) (
 	return
 }
 
+// transpileCallExprMalloc change from "malloc" to "calloc"
+func transpileCallExprMalloc(n *ast.CallExpr, p *program.Program) (
+	expr *goast.CallExpr, resultType string, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	unary, expression, back, err := findAndReplaceUnaryExprOrTypeTraitExpr(&n.Children()[1])
+	if err != nil {
+		back()
+		return transpileCallExprCalloc(n.Children()[1],
+			&ast.UnaryExprOrTypeTraitExpr{
+				Function: "sizeof",
+				Type1:    "unsigned long",
+				Type2:    "char",
+			}, p)
+	}
+	return transpileCallExprCalloc(expression, unary.(*ast.UnaryExprOrTypeTraitExpr), p)
+}
+
 // calloc nodes:
 // [0] - function identification
 // [1] - expression
@@ -34,6 +34,23 @@ func transpileImplicitCastExpr(n *ast.ImplicitCastExpr, p *program.Program, expr
 		return
 	}
 
+	// C11 atomic types
+	switch n.Kind {
+	case "AtomicToNonAtomic":
+		return transpileAtomicToNonAtomic(n, p)
+	case "NonAtomicToAtomic":
+		// value is stored in atomic value by parent node
+		return transpileToExpr(n.Children()[0], p, exprIsStmt)
+	}
+
+	// jmp_buf argument of function
+	if n.Kind == ast.ImplicitCastExprArrayToPointerDecay &&
+		util.CleanCType(n.Type) == jmpBufPointer {
+		expr, preStmts, postStmts, err = jmpBufArgument(n, p)
+		exprType = n.Type
+		return
+	}
+
 	// avoid unsigned overflow
 	// ImplicitCastExpr 0x2e649b8 <col:6, col:7> 'unsigned int' <IntegralCast>
 	// `-UnaryOperator 0x2e64998 <col:6, col:7> 'int' prefix '-'
@@ -147,7 +164,8 @@ func transpileImplicitCastExpr(n *ast.ImplicitCastExpr, p *program.Program, expr
 	}
 
 	var cast bool = true
-	if in, ok := n.Children()[0].(*ast.IntegerLiteral); ok && in.Type == "int" {
+	if in, ok := n.Children()[0].(*ast.IntegerLiteral); ok && in.Type == "int" &&
+		!isMacroLiteral(in, p) {
 		if types.IsCInteger(p, n.Type) || types.IsCFloat(p, n.Type) {
 			cast = false
 			exprType = n.Type
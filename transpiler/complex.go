@@ -0,0 +1,89 @@
+package transpiler
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/token"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+func transpileImaginaryLiteral(n *ast.ImaginaryLiteral, p *program.Program) (
+	expr goast.Expr,
+	exprType string,
+	preStmts []goast.Stmt,
+	postStmts []goast.Stmt,
+	err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpileImaginaryLiteral. %v", err)
+		}
+	}()
+	// ImaginaryLiteral '_Complex double'
+	// `-FloatingLiteral 'double' 2.000000e+00
+	if len(n.Children()) != 1 {
+		err = fmt.Errorf("not valid amount of children: %d", len(n.Children()))
+		return
+	}
+	expr, _, preStmts, postStmts, err = transpileToExpr(n.Children()[0], p, false)
+	if err != nil {
+		return
+	}
+	exprType = n.Type
+
+	if lit, ok := expr.(*goast.BasicLit); ok &&
+		(lit.Kind == token.INT || lit.Kind == token.FLOAT) {
+		// 2i
+		expr = &goast.BasicLit{
+			Kind:  token.IMAG,
+			Value: lit.Value + "i",
+		}
+		return
+	}
+
+	// complex(0, value)
+	expr = &goast.CallExpr{
+		Fun:  goast.NewIdent("complex"),
+		Args: []goast.Expr{util.NewIntLit(0), expr},
+	}
+	return
+}
+
+// transpileComplexPart transpile GNU extension operators `__real__` and
+// `__imag__`.
+func transpileComplexPart(n *ast.UnaryOperator, p *program.Program) (
+	expr goast.Expr,
+	exprType string,
+	preStmts []goast.Stmt,
+	postStmts []goast.Stmt,
+	err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpileComplexPart. %v", err)
+		}
+	}()
+	// UnaryOperator 'double' prefix '__real'
+	// `-ImplicitCastExpr '_Complex double' <LValueToRValue>
+	//   `-DeclRefExpr '_Complex double' lvalue Var 'z' '_Complex double'
+	expr, exprType, preStmts, postStmts, err = transpileToExpr(n.Children()[0], p, false)
+	if err != nil {
+		return
+	}
+
+	if !types.IsCComplex(p, exprType) {
+		// for real types: __real__ x is x, __imag__ x is zero
+		if n.Operator == "__imag" {
+			expr = util.NewIntLit(0)
+		}
+		return
+	}
+
+	name := "real"
+	if n.Operator == "__imag" {
+		name = "imag"
+	}
+	return util.NewCallExpr(name, expr), n.Type, preStmts, postStmts, nil
+}
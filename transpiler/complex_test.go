@@ -0,0 +1,120 @@
+package transpiler
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+)
+
+func TestComplex(t *testing.T) {
+	p := program.NewProgram()
+
+	z := func(name, typ string) ast.Node {
+		return &ast.ImplicitCastExpr{
+			Kind: "LValueToRValue",
+			Type: typ,
+			ChildNodes: []ast.Node{
+				&ast.DeclRefExpr{Name: name, Type: typ},
+			},
+		}
+	}
+
+	tcs := []struct {
+		node ast.Node
+		code string
+	}{
+		{
+			node: &ast.ImaginaryLiteral{
+				Type: "_Complex double",
+				ChildNodes: []ast.Node{
+					&ast.FloatingLiteral{Type: "double", Value: 2.5},
+				},
+			},
+			code: "2.5i",
+		},
+		{
+			node: &ast.BinaryOperator{
+				Type:     "_Complex double",
+				Operator: "+",
+				ChildNodes: []ast.Node{
+					&ast.BinaryOperator{
+						Type:     "_Complex double",
+						Operator: "*",
+						ChildNodes: []ast.Node{
+							z("a", "_Complex double"),
+							z("b", "_Complex double"),
+						},
+					},
+					&ast.ImplicitCastExpr{
+						Kind: "FloatingRealToComplex",
+						Type: "_Complex double",
+						ChildNodes: []ast.Node{
+							z("x", "double"),
+						},
+					},
+				},
+			},
+			code: "a*b + complex(float64(x), 0)",
+		},
+		{
+			node: &ast.ImplicitCastExpr{
+				Kind:       "FloatingComplexToReal",
+				Type:       "float",
+				ChildNodes: []ast.Node{z("a", "_Complex double")},
+			},
+			code: "float32(real(a))",
+		},
+		{
+			node: &ast.ImplicitCastExpr{
+				Kind:       "FloatingComplexCast",
+				Type:       "_Complex double",
+				ChildNodes: []ast.Node{z("f", "_Complex float")},
+			},
+			code: "complex128(f)",
+		},
+		{
+			node: &ast.UnaryOperator{
+				Type:       "double",
+				Operator:   "__imag",
+				IsPrefix:   true,
+				ChildNodes: []ast.Node{z("a", "_Complex double")},
+			},
+			code: "imag(a)",
+		},
+	}
+
+	for _, tc := range tcs {
+		expr, _, _, _, err := transpileToExpr(tc.node, p, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tc.code {
+			t.Errorf("not valid code: `%s` != `%s`", buf.String(), tc.code)
+		}
+	}
+
+	for cType, goType := range map[string]string{
+		"_Complex float":       "complex64",
+		"_Complex double":      "complex128",
+		"_Complex long double": "complex128",
+		"double _Complex":      "complex128",
+		"_Complex double *":    "[]complex128",
+	} {
+		r, err := types.ResolveType(p, cType)
+		if err != nil || r != goType {
+			t.Errorf("type %s: %s != %s. %v", cType, r, goType, err)
+		}
+	}
+	if size, err := types.SizeOf(p, "_Complex double"); err != nil || size != 16 {
+		t.Errorf("not valid size of complex type: %d. %v", size, err)
+	}
+}
@@ -152,6 +152,8 @@ func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) (
 	}()
 
 	var fields []*goast.Field
+	// names of C fields in order of Go fields
+	var members []string
 
 	// repair name for anonymous RecordDecl
 	for pos := range n.Children() {
@@ -170,7 +172,26 @@ func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) (
 		}
 	}
 
+	// positions of storage fields for bit-fields
+	var runStarts []int
+	var inRun bool
+
 	for pos := range n.Children() {
+		if field, ok := n.Children()[pos].(*ast.FieldDecl); ok &&
+			(n.Kind == "struct" || n.Kind == "union") && program.IsBitField(field) {
+			field.Type = util.GenerateCorrectType(field.Type)
+			if n.Kind == "union" {
+				// bit-fields of union are methods of union memory
+				continue
+			}
+			if !inRun {
+				runStarts = append(runStarts, len(fields))
+			}
+			inRun = true
+			continue
+		}
+		inRun = false
+
 		switch field := n.Children()[pos].(type) {
 		case *ast.FieldDecl:
 			field.Type = util.GenerateCorrectType(field.Type)
@@ -208,11 +229,15 @@ func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) (
 					f.Names[0].Name += strconv.Itoa(pos)
 				}
 				fields = append(fields, f)
+				members = append(members, field.Name)
 			}
 
 		case *ast.IndirectFieldDecl:
 			// ignore
 
+		case *ast.PackedAttr, *ast.AlignedAttr, *ast.MaxFieldAlignmentAttr:
+			// attributes of memory layout are used by program.NewStruct
+
 		case *ast.TransparentUnionAttr:
 			// Don't do anything
 			// Example of AST:
@@ -328,8 +353,43 @@ func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) (
 		if err != nil {
 			return nil, err
 		}
+		var methods []goast.Decl
+		methods, err = transpileUnionBitFieldMethods(p, name, size, s)
+		if err != nil {
+			return
+		}
+		d = append(d, methods...)
 
 	case program.StructType:
+		// bit-fields are located right after previous members as in C.
+		// Error of layout is not important there, because it is shown
+		// only for memory layout of C in function transpileLayoutPadding.
+		if len(runStarts) > 0 {
+			_, _ = types.GetRecordLayout(p, s)
+		}
+		for run := len(runStarts) - 1; run >= 0; run-- {
+			var storages []*goast.Field
+			storages, err = transpileBitFieldStorages(p, s, run)
+			if err != nil {
+				return
+			}
+			pos := runStarts[run]
+			fields = append(fields[:pos], append(storages, fields[pos:]...)...)
+			var names []string
+			for _, st := range s.GetBitFieldStorages(run) {
+				names = append(names, st.Name)
+			}
+			members = append(members[:pos], append(names, members[pos:]...)...)
+		}
+		if p.CLayout {
+			fields = transpileLayoutPadding(p, n, s, fields, members)
+		}
+		var methods []goast.Decl
+		methods, err = transpileBitFieldMethods(p, name, s)
+		if err != nil {
+			return
+		}
+
 		d = append(d, &goast.GenDecl{
 			Tok: token.TYPE,
 			Specs: []goast.Spec{
@@ -343,6 +403,7 @@ func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) (
 				},
 			},
 		})
+		d = append(d, methods...)
 
 	default:
 		err = fmt.Errorf("undefine type of struct : %v", s.Type)
@@ -408,6 +469,9 @@ func transpileCXXRecordDecl(p *program.Program, n *ast.RecordDecl) (
 			}
 			fields = append(fields, f)
 
+		case *ast.PackedAttr, *ast.AlignedAttr, *ast.MaxFieldAlignmentAttr:
+			// ignore attributes of memory layout
+
 		default:
 			p.AddMessage(p.GenerateWarningMessage(
 				fmt.Errorf("cannot transpilation field: %T", v), n))
@@ -456,6 +520,12 @@ func transpileTypedefDecl(p *program.Program, n *ast.TypedefDecl) (
 		return
 	}
 
+	// C11 atomic types are types of package "sync/atomic"
+	if types.IsCAtomic(p, n.Type) {
+		p.TypedefType[n.Name] = n.Type
+		return
+	}
+
 	// |-RecordDecl 0x1b733f0 <line:603:9, line:606:1> line:603:9 struct definition
 	// | |-FieldDecl 0x1b734c0 <line:604:5, col:12> col:12 x 'pointx':'int'
 	// | `-FieldDecl 0x1b73528 <line:605:5, col:9> col:9 y 'int'
@@ -761,6 +831,17 @@ func transpileVarDecl(p *program.Program, n *ast.VarDecl) (
 	// 		}
 	// 	}
 
+	// Allocate slice of variable length array. Declaration without
+	// allocation is not valid, because slice is nil.
+	if types.IsVariableArray(n.Type) && defaultValue == nil {
+		var vla goast.Expr
+		vla, err = transpileVariableArray(p, n.Type)
+		if err != nil {
+			return
+		}
+		defaultValue = []goast.Expr{vla}
+	}
+
 	arrayType, arraySize := types.GetArrayTypeAndSize(n.Type)
 
 	if arraySize != -1 && defaultValue == nil {
@@ -812,7 +893,7 @@ func transpileVarDecl(p *program.Program, n *ast.VarDecl) (
 
 ignoreType:
 
-	return []goast.Decl{&goast.GenDecl{
+	decls = []goast.Decl{&goast.GenDecl{
 		Tok: token.VAR,
 		Specs: []goast.Spec{
 			&goast.ValueSpec{
@@ -822,5 +903,49 @@ ignoreType:
 				Doc:    p.GetMessageComments(),
 			},
 		},
-	}}, "", nil
+	}}
+	if init := atomicInitialization(p, n); init != nil {
+		decls = append(decls, init)
+	}
+	return decls, "", nil
+}
+
+// atomicInitialization return declaration for initialization of C11
+// atomic variable with not zero value. If variable is not atomic, then
+// nil is returned.
+//
+//	C  : atomic_int counter = 5;
+//	Go : var counter atomic.Int32
+//	     var _ = func(c4go_atomic *atomic.Int32, c4go_value int32) struct{} {
+//	     	...
+//	     }(&counter, 5)
+func atomicInitialization(p *program.Program, n *ast.VarDecl) goast.Decl {
+	if !types.IsCAtomic(p, n.Type) || len(n.Children()) == 0 {
+		return nil
+	}
+	init, preStmts, postStmts, err := initAtomicValue(n.Children()[0], n.Name, n.Type, p)
+	if err != nil {
+		p.AddMessage(p.GenerateWarningMessage(err, n))
+		return nil
+	}
+	if len(preStmts) != 0 || len(postStmts) != 0 {
+		p.AddMessage(p.GenerateWarningMessage(
+			fmt.Errorf("not acceptable length of Stmt : pre(%d), post(%d)",
+				len(preStmts), len(postStmts)), n))
+	}
+	if call, ok := init.(*goast.CallExpr); ok && len(call.Args) == 2 {
+		// zero value is not stored
+		if bl, ok := call.Args[1].(*goast.BasicLit); ok && bl.Value == "0" {
+			return nil
+		}
+	}
+	return &goast.GenDecl{
+		Tok: token.VAR,
+		Specs: []goast.Spec{
+			&goast.ValueSpec{
+				Names:  []*goast.Ident{goast.NewIdent("_")},
+				Values: []goast.Expr{init},
+			},
+		},
+	}
 }
@@ -98,6 +98,10 @@ func transpileFunctionDecl(n *ast.FunctionDecl, p *program.Program) (
 		}
 	}
 
+	if p.LibraryMode {
+		p.AddLibraryFunction(n)
+	}
+
 	if p.Binding {
 		// probably a few function in result with same names
 		decls, err = bindingFunctionDecl(n, p)
@@ -116,6 +120,13 @@ func transpileFunctionDecl(n *ast.FunctionDecl, p *program.Program) (
 		return
 	}
 
+	if p.LibraryMode && n.Name == "main" {
+		// library is used by Go program, so function main is not needed
+		p.AddMessage(p.GenerateWarningMessage(
+			fmt.Errorf("function main is ignored in library mode"), n))
+		return
+	}
+
 	if err = define(); err != nil {
 		return
 	}
@@ -133,7 +144,8 @@ func transpileFunctionDecl(n *ast.FunctionDecl, p *program.Program) (
 		err = nil // Error is ignored
 	}
 
-	if p.IncludeHeaderIsExists("stdlib.h") && n.Name == "main" {
+	if (p.IncludeHeaderIsExists("stdlib.h") || p.IncludeHeaderIsExists("stdio.h")) &&
+		n.Name == "main" {
 		body.List = append([]goast.Stmt{&goast.DeferStmt{
 			Call: util.NewCallExpr("noarch.AtexitRun"),
 		}}, body.List...)
@@ -0,0 +1,121 @@
+package transpiler
+
+import (
+	"fmt"
+	goast "go/ast"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// genericControllingType return type of controlling expression after
+// lvalue conversion: qualifiers are removed and arrays decay to pointers.
+func genericControllingType(p *program.Program, n *ast.GenericSelectionExpr) (
+	t string, ok bool) {
+	if len(n.Children()) == 0 || n.Children()[0] == nil {
+		return
+	}
+	if _, isAssoc := n.Children()[0].(*ast.GenericAssociation); isAssoc {
+		return
+	}
+	typ, ok := ast.GetTypeIfExist(n.Children()[0])
+	if !ok {
+		return
+	}
+	t = util.CleanCType(*typ)
+	if index := strings.Index(t, "["); index > 0 {
+		t = strings.TrimSpace(t[:index]) + " *"
+	}
+	return t, true
+}
+
+// isGenericTypeEqual compare C types of generic association with
+// typedef resolving.
+func isGenericTypeEqual(p *program.Program, left, right string) bool {
+	resolve := func(t string) string {
+		t = util.CleanCType(t)
+		for i := 0; i < 10; i++ {
+			real, ok := p.TypedefType[t]
+			if !ok || real == t {
+				break
+			}
+			t = util.CleanCType(real)
+		}
+		return t
+	}
+	return util.CleanCType(left) == util.CleanCType(right) ||
+		resolve(left) == resolve(right)
+}
+
+// genericSelectedExpr return expression of selected association.
+// Clang marks selected association, if it is not marked, then
+// association is found by type of controlling expression.
+func genericSelectedExpr(p *program.Program, n *ast.GenericSelectionExpr) (
+	_ ast.Node, err error) {
+	var assocs []*ast.GenericAssociation
+	for _, child := range n.Children() {
+		if assoc, ok := child.(*ast.GenericAssociation); ok {
+			assocs = append(assocs, assoc)
+		}
+	}
+
+	var selected, def *ast.GenericAssociation
+	for _, assoc := range assocs {
+		if assoc.IsSelected {
+			selected = assoc
+			break
+		}
+		if assoc.IsDefault {
+			def = assoc
+		}
+	}
+	if selected == nil {
+		if t, ok := genericControllingType(p, n); ok {
+			for _, assoc := range assocs {
+				if !assoc.IsDefault && isGenericTypeEqual(p, t, assoc.Type) {
+					selected = assoc
+					break
+				}
+			}
+		}
+	}
+	if selected == nil {
+		selected = def
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("cannot find selected association of generic")
+	}
+	// last child of association is expression, first is type
+	if len(selected.Children()) == 0 {
+		return nil, fmt.Errorf("association of generic have not expression")
+	}
+	return selected.Children()[len(selected.Children())-1], nil
+}
+
+func transpileGenericSelectionExpr(n *ast.GenericSelectionExpr, p *program.Program) (
+	expr goast.Expr,
+	exprType string,
+	preStmts []goast.Stmt,
+	postStmts []goast.Stmt,
+	err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpileGenericSelectionExpr. %v", err)
+		}
+	}()
+	// GenericSelectionExpr 'double'
+	// |-DeclRefExpr 'float' lvalue Var 'x' 'float'
+	// |-case 'float'
+	// | |-BuiltinType 'float'
+	// | `-CallExpr 'float'
+	// `-default selected
+	//   `-CallExpr 'double'
+
+	selected, err := genericSelectedExpr(p, n)
+	if err != nil {
+		return
+	}
+	return transpileToExpr(selected, p, false)
+}
@@ -0,0 +1,87 @@
+package transpiler
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+)
+
+func TestGenericSelection(t *testing.T) {
+	p := program.NewProgram()
+	p.TypedefType["real"] = "double"
+
+	generic := func(controlType string, selected bool) ast.Node {
+		assoc := func(typ, name string) *ast.GenericAssociation {
+			return &ast.GenericAssociation{
+				Type: typ,
+				ChildNodes: []ast.Node{
+					&ast.BuiltinType{Type: typ},
+					&ast.DeclRefExpr{Name: name, Type: typ},
+				},
+			}
+		}
+		def := &ast.GenericAssociation{
+			IsDefault: true,
+			ChildNodes: []ast.Node{
+				&ast.DeclRefExpr{Name: "d", Type: "long"},
+			},
+		}
+		children := []ast.Node{
+			&ast.DeclRefExpr{Name: "x", Type: controlType},
+			assoc("float", "f"),
+			assoc("char *", "s"),
+			def,
+		}
+		if selected {
+			def.IsSelected = true
+		}
+		return &ast.GenericSelectionExpr{ChildNodes: children}
+	}
+
+	tcs := []struct {
+		node ast.Node
+		code string
+	}{
+		{node: generic("float", false), code: "f"},
+		{node: generic("const float", false), code: "f"},
+		{node: generic("char [6]", false), code: "s"},
+		{node: generic("int", false), code: "d"},
+		{node: generic("real", false), code: "d"},
+		{node: generic("float", true), code: "d"},
+	}
+
+	for _, tc := range tcs {
+		expr, _, _, _, err := transpileToExpr(tc.node, p, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tc.code {
+			t.Errorf("not valid selection: %s != %s", buf.String(), tc.code)
+		}
+
+		name, err := getName(p, tc.node)
+		if err != nil || name != tc.code {
+			t.Errorf("not valid name: %s != %s. %v", name, tc.code, err)
+		}
+	}
+
+	if _, err := genericSelectedExpr(p, &ast.GenericSelectionExpr{}); err == nil {
+		t.Errorf("generic without associations must be fail")
+	}
+
+	// name is taken from the first child, if associations are not shown
+	name, err := getName(p, &ast.GenericSelectionExpr{ChildNodes: []ast.Node{
+		&ast.DeclRefExpr{Name: "f", Type: "float (float)"},
+	}})
+	if err != nil || name != "f" {
+		t.Errorf("not valid name without associations: %s. %v", name, err)
+	}
+}
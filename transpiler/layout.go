@@ -0,0 +1,149 @@
+package transpiler
+
+import (
+	"fmt"
+	goast "go/ast"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// transpileLayoutPadding insert padding fields between fields of struct
+// for memory layout of C. Padding is calculated for sequential binary
+// representation of Go struct, so fields after packed fields are
+// also at offsets of C struct.
+//
+//	C  : struct s { char c; int i; };
+//	Go : type s struct { c byte; _ [3]byte; i int32 }
+func transpileLayoutPadding(p *program.Program, n ast.Node, s *program.Struct,
+	fields []*goast.Field, members []string) []*goast.Field {
+
+	l, err := types.GetRecordLayout(p, s)
+	if err != nil {
+		p.AddMessage(p.GenerateWarningMessage(err, n))
+		return fields
+	}
+	if len(fields) != len(members) {
+		p.AddMessage(p.GenerateWarningMessage(fmt.Errorf(
+			"cannot add padding to struct `%s`: not all fields are transpiled",
+			s.Name), n))
+		return fields
+	}
+
+	var result []*goast.Field
+	var end int
+	for i := range fields {
+		offset, ok := l.Offsets[members[i]]
+		if !ok || offset < end {
+			p.AddMessage(p.GenerateWarningMessage(fmt.Errorf(
+				"cannot add padding to struct `%s`: not valid layout of field `%s`",
+				s.Name, members[i]), n))
+			return fields
+		}
+		if end < offset {
+			result = append(result, layoutPaddingField(offset-end))
+		}
+		result = append(result, fields[i])
+		end = offset + l.Sizes[members[i]]
+
+		if t, ok := s.Fields[members[i]].(string); ok &&
+			(types.IsPointer(t, p) || strings.Contains(t, "(")) {
+			p.AddMessage(p.GenerateWarningMessage(fmt.Errorf(
+				"pointer field `%s` of struct `%s` have not fixed size in Go",
+				members[i], s.Name), n))
+		}
+	}
+	if end < l.Size {
+		result = append(result, layoutPaddingField(l.Size-end))
+	}
+	return result
+}
+
+func layoutPaddingField(size int) *goast.Field {
+	return &goast.Field{
+		Names: []*goast.Ident{goast.NewIdent("_")},
+		Type:  util.NewTypeIdent(fmt.Sprintf("[%d]byte", size)),
+	}
+}
+
+// isLayoutIOCall return true for functions "fread", "fwrite" with
+// pointer to struct as first argument.
+//
+//	CallExpr <> 'unsigned long'
+//	|-ImplicitCastExpr <> 'unsigned long (*)(void *, unsigned long, unsigned long, FILE *)' <FunctionToPointerDecay>
+//	| `-DeclRefExpr <> 'unsigned long (void *, unsigned long, unsigned long, FILE *)' Function 'fread' 'unsigned long (void *, unsigned long, unsigned long, FILE *)'
+//	|-ImplicitCastExpr <> 'void *' <BitCast>
+//	| `-UnaryOperator <> 'struct rec *' prefix '&'
+//	|   `-DeclRefExpr <> 'struct rec' lvalue Var 'r' 'struct rec'
+//	|-UnaryExprOrTypeTraitExpr <> 'unsigned long' sizeof 'struct rec'
+//	|-IntegerLiteral <> 'unsigned long' 1
+//	`-ImplicitCastExpr <> 'FILE *' <LValueToRValue>
+//	  `-DeclRefExpr <> 'FILE *' lvalue Var 'f' 'FILE *'
+func isLayoutIOCall(p *program.Program, n *ast.CallExpr, functionName string) bool {
+	if !p.CLayout || len(n.Children()) != 5 ||
+		(functionName != "fread" && functionName != "fwrite") {
+		return false
+	}
+	node := layoutIOValue(n)
+	t, ok := ast.GetTypeIfExist(node)
+	if !ok {
+		return false
+	}
+	cType := util.CleanCType(*t)
+	if !strings.HasSuffix(cType, "*") {
+		return false
+	}
+	_, ok = types.GetRecord(p, strings.TrimSpace(cType[:len(cType)-1]))
+	return ok
+}
+
+// layoutIOValue return pointer argument of "fread", "fwrite" without
+// cast to `void *`.
+func layoutIOValue(n *ast.CallExpr) ast.Node {
+	node := n.Children()[1]
+	if impl, ok := node.(*ast.ImplicitCastExpr); ok && impl.Type == "void *" &&
+		len(impl.Children()) == 1 {
+		node = impl.Children()[0]
+	}
+	return node
+}
+
+// transpileCallExprLayoutIO transpile functions "fread", "fwrite" for
+// structs with memory layout of C.
+func transpileCallExprLayoutIO(n *ast.CallExpr, p *program.Program, functionName string) (
+	expr *goast.CallExpr, resultType string, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("function: %s. err = %v", functionName, err)
+		}
+	}()
+
+	var args []goast.Expr
+	for pos, node := range n.Children()[1:] {
+		if pos == 0 {
+			node = layoutIOValue(n)
+		}
+		arg, argType, newPre, newPost, err := transpileToExpr(node, p, false)
+		if err != nil {
+			return nil, "", nil, nil, err
+		}
+		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+		if pos == 1 || pos == 2 {
+			arg, err = types.CastExpr(p, arg, argType, "int")
+			if err != nil {
+				return nil, "", nil, nil, err
+			}
+		}
+		args = append(args, arg)
+	}
+
+	name := "noarch.FreadValue"
+	if functionName == "fwrite" {
+		name = "noarch.FwriteValue"
+	}
+	p.AddImport("github.com/Konstantin8105/c4go/noarch")
+	return util.NewCallExpr(name, args...), "int", preStmts, postStmts, nil
+}
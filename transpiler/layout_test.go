@@ -0,0 +1,156 @@
+package transpiler
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+)
+
+func TestLayoutPadding(t *testing.T) {
+	p := program.NewProgram()
+	p.CLayout = true
+
+	// struct rec { char c; int i; double d; short s; };
+	rec := &ast.RecordDecl{
+		Kind:         "struct",
+		Name:         "rec",
+		IsDefinition: true,
+		ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			&ast.FieldDecl{Name: "i", Type: "int"},
+			&ast.FieldDecl{Name: "d", Type: "double"},
+			&ast.FieldDecl{Name: "s", Type: "short"},
+		},
+	}
+	// struct __attribute__((packed)) pk { char c; int i; };
+	pk := &ast.RecordDecl{
+		Kind:         "struct",
+		Name:         "pk",
+		IsDefinition: true,
+		ChildNodes: []ast.Node{
+			&ast.PackedAttr{},
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			&ast.FieldDecl{Name: "i", Type: "int"},
+		},
+	}
+
+	tcs := []struct {
+		n    *ast.RecordDecl
+		code string
+		size int
+	}{
+		{
+			n: rec,
+			code: `type rec struct {
+	c byte
+	_ [3]byte
+	i int32
+	d float64
+	s int16
+	_ [6]byte
+}`,
+			size: 24,
+		},
+		{
+			n: pk,
+			code: `type pk struct {
+	c byte
+	i int32
+}`,
+			size: 5,
+		},
+	}
+
+	for _, tc := range tcs {
+		decls, err := transpileRecordDecl(p, tc.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		for _, d := range decls {
+			if err := format.Node(&buf, token.NewFileSet(), d); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if code := buf.String(); !strings.Contains(code, tc.code) {
+			t.Errorf("cannot find %q in code:\n%s", tc.code, code)
+		}
+		size, err := types.SizeOf(p, "struct "+tc.n.Name)
+		if err != nil || size != tc.size {
+			t.Errorf("not valid size of struct %s: %d. %v", tc.n.Name, size, err)
+		}
+	}
+
+	// struct initialization with padding fields
+	init := &ast.InitListExpr{
+		Type1: "struct rec",
+		ChildNodes: []ast.Node{
+			&ast.IntegerLiteral{Type: "char", Value: "1"},
+			&ast.IntegerLiteral{Type: "int", Value: "2"},
+		},
+	}
+	expr, _, _, _, err := transpileToExpr(init, p, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range []string{".c = 1", ".i = 2"} {
+		if !strings.Contains(buf.String(), part) {
+			t.Errorf("cannot find %q in code:\n%s", part, buf.String())
+		}
+	}
+
+	// fread(&r, sizeof(struct rec), 1, f)
+	call := &ast.CallExpr{
+		Type: "unsigned long",
+		ChildNodes: []ast.Node{
+			&ast.ImplicitCastExpr{
+				Kind: "FunctionToPointerDecay",
+				Type: "unsigned long (*)(void *, unsigned long, unsigned long, FILE *)",
+				ChildNodes: []ast.Node{&ast.DeclRefExpr{
+					Name: "fread",
+					Type: "unsigned long (void *, unsigned long, unsigned long, FILE *)",
+				}},
+			},
+			&ast.ImplicitCastExpr{
+				Kind: "BitCast",
+				Type: "void *",
+				ChildNodes: []ast.Node{&ast.ImplicitCastExpr{
+					Kind:       "LValueToRValue",
+					Type:       "struct rec *",
+					ChildNodes: []ast.Node{&ast.DeclRefExpr{Name: "r", Type: "struct rec *"}},
+				}},
+			},
+			&ast.IntegerLiteral{Type: "unsigned long", Value: "24"},
+			&ast.IntegerLiteral{Type: "unsigned long", Value: "1"},
+			&ast.ImplicitCastExpr{
+				Kind:       "LValueToRValue",
+				Type:       "FILE *",
+				ChildNodes: []ast.Node{&ast.DeclRefExpr{Name: "f", Type: "FILE *"}},
+			},
+		},
+	}
+	if !isLayoutIOCall(p, call, "fread") {
+		t.Fatalf("fread of struct is not found")
+	}
+	call2, _, _, _, err := transpileCallExprLayoutIO(call, p, "fread")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := format.Node(&buf, token.NewFileSet(), call2); err != nil {
+		t.Fatal(err)
+	}
+	if code := buf.String(); code != "noarch.FreadValue(r, 24, 1, f)" {
+		t.Errorf("not valid code: %s", code)
+	}
+}
@@ -0,0 +1,310 @@
+package transpiler
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	ctypes "github.com/Konstantin8105/c4go/types"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// libraryRecoverName is name of function for converting panic of C code
+// into error of exported function.
+const libraryRecoverName = "c4goLibraryRecover"
+
+// libraryType is Go type of argument or result in exported function and
+// conversions between that type and type of transpiled C function.
+type libraryType struct {
+	goType string
+	toC    string // format for converting Go value into C value
+	fromC  string // format for converting C value into Go value
+}
+
+// getLibraryType returns type of exported function for C type. Strings
+// of C are converted to Go strings, integers to int or uint, other types
+// are used as is: pointers as slices, floating types as float32 and float64.
+func getLibraryType(p *program.Program, cType string, isResult bool) (
+	lt libraryType, err error) {
+	t, err := ctypes.ResolveType(p, cType)
+	if err != nil {
+		return
+	}
+	lt = libraryType{goType: t, toC: "%s", fromC: "%s"}
+
+	clean := strings.Replace(strings.Join(strings.Fields(cType), ""), "const", "", -1)
+	switch {
+	case clean == "char*" && isResult:
+		lt.goType = "string"
+		lt.fromC = "noarch.CStringToString(%s)"
+	case clean == "char*" && strings.Contains(cType, "const"):
+		// argument is not changed by function
+		lt.goType = "string"
+		lt.toC = `[]byte(%s + "\x00")`
+	case t == "int16" || t == "int32" || t == "int64":
+		lt.goType = "int"
+		lt.toC = t + "(%s)"
+		lt.fromC = "int(%s)"
+	case t == "uint16" || t == "uint32" || t == "uint64":
+		lt.goType = "uint"
+		lt.toC = t + "(%s)"
+		lt.fromC = "uint(%s)"
+	}
+	return
+}
+
+// getLibraryName returns name of exported function for C function name.
+// Prefix with package name is removed, words are joined in camel case.
+//
+// Example for package "vec":
+//
+//	vec_add_scaled -> AddScaled
+//	dot_product    -> DotProduct
+func getLibraryName(packageName, name string) string {
+	prefix := strings.ToLower(packageName) + "_"
+	if strings.HasPrefix(strings.ToLower(name), prefix) && len(name) > len(prefix) {
+		name = name[len(prefix):]
+	}
+	var out string
+	for _, word := range strings.Split(name, "_") {
+		out += util.Ucfirst(word)
+	}
+	if out == "" || !('A' <= out[0] && out[0] <= 'Z') {
+		return ""
+	}
+	return out
+}
+
+// unexportLibraryNames renames defined functions and global variables of
+// C library with the first uppercase letter, because only wrappers of
+// public functions are exported from Go package. The first letter is
+// changed to lowercase or prefix "c4go_" is added, if that name is used.
+//
+// Example: Helper -> helper, Len -> c4go_Len.
+func unexportLibraryNames(p *program.Program, root ast.Node) {
+	used := map[string]bool{}
+	for _, m := range append(p.PreprocessorFile.GetMacros(),
+		p.PreprocessorFile.GetFunctionMacros()...) {
+		used[m.Name] = true
+	}
+	isExported := func(name string) bool {
+		return name != "" && unicode.IsUpper(rune(name[0]))
+	}
+	functions := map[string]bool{} // names of defined functions
+	variables := map[string]bool{} // names of defined global variables
+	ast.Inspect(root, func(node ast.Node) bool {
+		name, ok := ast.DeclarationName(node)
+		if !ok {
+			return true
+		}
+		used[name] = true
+		if n, ok := node.(*ast.FunctionDecl); ok && isExported(name) &&
+			getFunctionBody(n) != nil {
+			functions[name] = true
+		}
+		return true
+	})
+	globals := map[ast.Address]*ast.VarDecl{}
+	for _, c := range root.Children() {
+		if n, ok := c.(*ast.VarDecl); ok {
+			globals[n.Addr] = n
+			if isExported(n.Name) && !n.IsExtern {
+				variables[n.Name] = true
+			}
+		}
+	}
+	if len(functions) == 0 && len(variables) == 0 {
+		return
+	}
+
+	rename := map[string]string{}
+	for _, names := range []map[string]bool{functions, variables} {
+		for name := range names {
+			if _, ok := rename[name]; ok {
+				continue
+			}
+			newName := strings.ToLower(name[:1]) + name[1:]
+			if used[newName] || util.IsGoKeyword(newName) ||
+				types.Universe.Lookup(newName) != nil {
+				newName = "c4go_" + name
+			}
+			used[newName] = true
+			rename[name] = newName
+		}
+	}
+
+	ast.Inspect(root, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FunctionDecl:
+			if functions[n.Name] {
+				n.Name = rename[n.Name]
+			}
+		case *ast.VarDecl:
+			if globals[n.Addr] == n && variables[n.Name] {
+				n.Name = rename[n.Name]
+			}
+		case *ast.DeclRefExpr:
+			switch {
+			case n.For == "Function" && functions[n.Name]:
+				n.Name = rename[n.Name]
+			case n.For == "Var" && variables[n.Name] &&
+				globals[ast.ParseAddress(n.Address2)] != nil:
+				n.Name = rename[n.Name]
+			}
+		}
+		return true
+	})
+}
+
+// getPackageNames returns names of package-level identifiers declared in
+// Go file and in Go sources without package clause.
+func getPackageNames(f *goast.File, sources ...string) map[string]bool {
+	names := map[string]bool{program.StdioVariable: true}
+	decls := f.Decls
+	for _, src := range sources {
+		sf, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+src, 0)
+		if err == nil {
+			decls = append(decls, sf.Decls...)
+		}
+	}
+	for _, decl := range decls {
+		switch d := decl.(type) {
+		case *goast.FuncDecl:
+			if d.Recv == nil {
+				names[d.Name.Name] = true
+			}
+		case *goast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *goast.TypeSpec:
+					names[s.Name.Name] = true
+				case *goast.ValueSpec:
+					for _, n := range s.Names {
+						names[n.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// getLibraryFunctions returns source of exported Go functions for public
+// functions of C library. Each of exported functions calls the transpiled
+// C function with conversion of arguments and result. Panic of C code
+// is returned as error. Names of exported functions must not be used by
+// other package-level identifiers.
+//
+// Example:
+//
+//	C  : int vec_len(const char * name, double * v);
+//	Go : func Len(name string, v []float64) (_ int, err error) {
+//		defer c4goLibraryRecover("vec_len", &err)
+//		return int(vec_len([]byte(name+"\x00"), v)), nil
+//	}
+func getLibraryFunctions(p *program.Program, packageName string,
+	names map[string]bool) (src string) {
+	exported := map[string]bool{}
+	for _, lf := range p.GetLibraryFunctions() {
+		code, name, err := getLibraryFunction(p, packageName, lf)
+		if err == nil && (exported[name] || names[name]) {
+			err = fmt.Errorf("exported name `%s` is used", name)
+		}
+		if err != nil {
+			p.AddMessage(p.GenerateWarningMessage(fmt.Errorf(
+				"cannot export function `%s` from %s: %v", lf.Name, lf.File, err), nil))
+			continue
+		}
+		exported[name] = true
+		src += code
+	}
+	if src == "" {
+		return
+	}
+	p.AddImport("fmt")
+	if strings.Contains(src, "noarch.") {
+		p.AddImport("github.com/Konstantin8105/c4go/noarch")
+	}
+	src += fmt.Sprintf(`
+// %[1]s converts panic of C code into error of exported function.
+func %[1]s(name string, err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%%s: %%v", name, r)
+	}
+}
+`, libraryRecoverName)
+	return
+}
+
+// getLibraryFunction returns source of exported function for public
+// function of C library.
+func getLibraryFunction(p *program.Program, packageName string,
+	lf program.LibraryFunction) (code, name string, err error) {
+	d := p.GetFunctionDefinition(lf.Name)
+	if d == nil || !d.HaveBody {
+		err = fmt.Errorf("function is not implemented")
+		return
+	}
+	name = getLibraryName(packageName, lf.Name)
+	if name == "" || name == lf.Name || p.GetFunctionDefinition(name) != nil {
+		err = fmt.Errorf("cannot create exported name")
+		return
+	}
+
+	var argTypes []string
+	for _, t := range d.ArgumentTypes {
+		if t == "..." {
+			err = fmt.Errorf("variadic function is not supported")
+			return
+		}
+		if t != "void" && strings.TrimSpace(t) != "" {
+			argTypes = append(argTypes, t)
+		}
+	}
+
+	var params, args []string
+	used := map[string]bool{"err": true, "noarch": true, "fmt": true, lf.Name: true}
+	for i, t := range argTypes {
+		var lt libraryType
+		lt, err = getLibraryType(p, t, false)
+		if err != nil {
+			return
+		}
+		arg := fmt.Sprintf("arg%d", i)
+		if len(lf.Parameters) == len(argTypes) && lf.Parameters[i] != "" &&
+			!used[lf.Parameters[i]] {
+			arg = lf.Parameters[i]
+		}
+		used[arg] = true
+		params = append(params, arg+" "+lt.goType)
+		args = append(args, fmt.Sprintf(lt.toC, arg))
+	}
+
+	call := fmt.Sprintf("%s(%s)", lf.Name, strings.Join(args, ", "))
+	results, body := "(err error)", call+"\n\treturn"
+	if d.ReturnType != "void" && d.ReturnType != "" {
+		var lt libraryType
+		lt, err = getLibraryType(p, d.ReturnType, true)
+		if err != nil {
+			return
+		}
+		results = fmt.Sprintf("(_ %s, err error)", lt.goType)
+		body = fmt.Sprintf("return "+lt.fromC+", nil", call)
+	}
+
+	code = fmt.Sprintf(`
+// %s calls C function %s. Panic of C code is returned as error.
+func %s(%s) %s {
+	defer %s(%q, &err)
+	%s
+}
+`, name, lf.Name, name, strings.Join(params, ", "), results,
+		libraryRecoverName, lf.Name, body)
+	return
+}
@@ -0,0 +1,146 @@
+package transpiler
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+)
+
+func TestLibraryName(t *testing.T) {
+	tcs := []struct {
+		pkg, name, out string
+	}{
+		{"vec", "vec_add", "Add"},
+		{"vec", "VEC_add_scaled", "AddScaled"},
+		{"vec", "dot_product", "DotProduct"},
+		{"vec", "vec_", "Vec"},
+		{"vec", "norm2", "Norm2"},
+		{"vec", "_", ""},
+		{"vec", "_1", ""},
+	}
+	for _, tc := range tcs {
+		if out := getLibraryName(tc.pkg, tc.name); out != tc.out {
+			t.Errorf("name of `%s`: %q != %q", tc.name, out, tc.out)
+		}
+	}
+}
+
+func TestLibraryType(t *testing.T) {
+	p := program.NewProgram()
+	tcs := []struct {
+		cType    string
+		isResult bool
+		goType   string
+		toC      string
+		fromC    string
+	}{
+		{"const char *", false, "string", `[]byte(%s + "\x00")`, "%s"},
+		{"char *", false, "[]byte", "%s", "%s"},
+		{"char *", true, "string", "%s", "noarch.CStringToString(%s)"},
+		{"int", false, "int", "int32(%s)", "int(%s)"},
+		{"unsigned long", true, "uint", "uint32(%s)", "uint(%s)"},
+		{"double", false, "float64", "%s", "%s"},
+		{"double *", false, "[]float64", "%s", "%s"},
+	}
+	for _, tc := range tcs {
+		lt, err := getLibraryType(p, tc.cType, tc.isResult)
+		if err != nil {
+			t.Fatalf("type `%s`: %v", tc.cType, err)
+		}
+		if lt.goType != tc.goType || lt.toC != tc.toC || lt.fromC != tc.fromC {
+			t.Errorf("type `%s`: %#v", tc.cType, lt)
+		}
+	}
+}
+
+func TestUnexportLibraryNames(t *testing.T) {
+	// int Count;
+	// int Len(void) { return Count; }
+	// int Helper(void) { return Len(); }
+	// int Extern(void);
+	lines := []struct {
+		depth int
+		line  string
+	}{
+		{0, "TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>"},
+		{1, "VarDecl 0x2 <a.c:1:1, col:5> col:5 used Count 'int'"},
+		{1, "FunctionDecl 0x3 <line:2:1, col:31> col:5 used Len 'int (void)'"},
+		{2, "CompoundStmt 0x4 <col:17, col:31>"},
+		{3, "ReturnStmt 0x5 <col:19, col:26>"},
+		{4, "ImplicitCastExpr 0x6 <col:26> 'int' <LValueToRValue>"},
+		{5, "DeclRefExpr 0x7 <col:26> 'int' lvalue Var 0x2 'Count' 'int'"},
+		{1, "FunctionDecl 0x8 <line:3:1, col:34> col:5 Helper 'int (void)'"},
+		{2, "CompoundStmt 0x9 <col:20, col:34>"},
+		{3, "ReturnStmt 0xa <col:22, col:31>"},
+		{4, "CallExpr 0xb <col:29, col:31> 'int'"},
+		{5, "ImplicitCastExpr 0xc <col:29> 'int (*)(void)' <FunctionToPointerDecay>"},
+		{6, "DeclRefExpr 0xd <col:29> 'int (void)' Function 0x3 'Len' 'int (void)'"},
+		{1, "FunctionDecl 0xe <line:4:1, col:16> col:5 Extern 'int (void)'"},
+	}
+	var stack []ast.Node
+	nodes := map[ast.Address]ast.Node{}
+	for _, l := range lines {
+		n, err := ast.Parse(l.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stack = append(stack[:l.depth], n)
+		if l.depth > 0 {
+			stack[l.depth-1].AddChild(n)
+		}
+		nodes[n.Address()] = n
+	}
+	unexportLibraryNames(program.NewProgram(), stack[0])
+
+	tcs := []struct {
+		addr ast.Address
+		name string
+	}{
+		{0x2, "count"},
+		{0x7, "count"},
+		{0x3, "c4go_Len"},
+		{0xd, "c4go_Len"},
+		{0x8, "helper"},
+		{0xe, "Extern"},
+	}
+	for _, tc := range tcs {
+		var got string
+		switch n := nodes[tc.addr].(type) {
+		case *ast.VarDecl:
+			got = n.Name
+		case *ast.FunctionDecl:
+			got = n.Name
+		case *ast.DeclRefExpr:
+			got = n.Name
+		}
+		if got != tc.name {
+			t.Errorf("not valid name of %#x: %q != %q", tc.addr, got, tc.name)
+		}
+	}
+}
+
+func TestLibraryPackageNames(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", `package vec
+type Vector struct{}
+var Count, size int32
+const Max = 1
+func Norm() {}
+func (v Vector) Len() int32 { return 0 }
+`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := getPackageNames(f, "func Dot() {}\n", "not valid Go code")
+	for _, name := range []string{"Vector", "Count", "size", "Max", "Norm", "Dot",
+		program.StdioVariable} {
+		if !names[name] {
+			t.Errorf("name `%s` is not found", name)
+		}
+	}
+	if names["Len"] {
+		t.Errorf("method is package-level name")
+	}
+}
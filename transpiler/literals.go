@@ -33,6 +33,9 @@ func init() {
 }
 
 // ConvertToGoFlagFormat convert format flags from C to Go
+//
+// Deprecated: format strings are interpreted by noarch in C style and
+// must be kept without changes.
 func ConvertToGoFlagFormat(str string) string {
 	// %u to %d
 	{
@@ -54,9 +57,6 @@ func ConvertToGoFlagFormat(str string) string {
 func transpileStringLiteral(p *program.Program, n *ast.StringLiteral, arrayToArray bool) (
 	expr goast.Expr, exprType string, err error) {
 
-	// Convert format flags
-	n.Value = ConvertToGoFlagFormat(n.Value)
-
 	// Example:
 	// StringLiteral 0x280b918 <col:29> 'char [30]' lvalue "%0"
 	baseType := types.GetBaseType(n.Type)
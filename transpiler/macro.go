@@ -0,0 +1,600 @@
+package transpiler
+
+import (
+	"bytes"
+	"fmt"
+	goast "go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/preprocessor"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// transpileMacroConstants return typed Go constants for object-like macros
+// from user sources.
+//
+// Example:
+//
+//	C  : #define N 100
+//	Go : const N int32 = 100
+func transpileMacroConstants(p *program.Program) (decls []goast.Decl) {
+	for _, mc := range p.GetMacroConstants() {
+		goType, err := types.ResolveType(p, mc.CType)
+		if err != nil {
+			p.AddMessage(p.GenerateWarningMessage(err, nil))
+			continue
+		}
+		kind := token.INT
+		switch mc.CType {
+		case "char":
+			kind = token.CHAR
+		case "float", "double", "long double":
+			kind = token.FLOAT
+		}
+		location := program.PathSimplification(
+			ast.Position{File: mc.File, Line: mc.Line}.GetSimpleLocation())
+		decls = append(decls, &goast.GenDecl{
+			Doc: &goast.CommentGroup{List: []*goast.Comment{{
+				Text: fmt.Sprintf("// %s - transpiled macro from %s",
+					mc.Name, location),
+			}}},
+			Tok: token.CONST,
+			Specs: []goast.Spec{&goast.ValueSpec{
+				Names:  []*goast.Ident{util.NewIdent(mc.Name)},
+				Type:   goast.NewIdent(goType),
+				Values: []goast.Expr{&goast.BasicLit{Kind: kind, Value: mc.Value}},
+			}},
+		})
+	}
+	return
+}
+
+// transpileMacroLiteral return name of macro constant, if literal is
+// located in object-like macro definition.
+//
+// Example:
+//
+//	#define N 100
+//	...
+//	int a = N;
+//
+// AST of literal is located in macro definition:
+//
+//	IntegerLiteral 0x2d9a9c8 <define.c:1:11> 'int' 100
+func transpileMacroLiteral(n ast.Node, p *program.Program) (
+	expr goast.Expr, exprType string, ok bool) {
+	mc, ok := p.GetMacroConstant(n.Position())
+	if !ok {
+		return
+	}
+	return util.NewIdent(mc.Name), mc.CType, true
+}
+
+// isMacroLiteral return true, if literal is located in object-like macro
+// definition and transpiled to typed Go constant.
+func isMacroLiteral(n ast.Node, p *program.Program) bool {
+	_, ok := p.GetMacroConstant(n.Position())
+	return ok
+}
+
+// macroArgument is argument of function-like macro expansion
+type macroArgument struct {
+	// core - argument expression without implicit promotions
+	core ast.Node
+	// occurrences - places of argument in expansion, where argument is
+	// the child of parent with index
+	occurrences []macroOccurrence
+}
+
+type macroOccurrence struct {
+	parent ast.Node
+	index  int
+}
+
+// node return node of argument in expansion
+func (o macroOccurrence) node() ast.Node {
+	return o.parent.Children()[o.index]
+}
+
+// transpileMacroExpansion transpile expansion of function-like macro from
+// user source to the call of Go function. Used only in MacroFunctionMode.
+//
+// Example:
+//
+//	#define MAX(a,b) ((a)>(b)?(a):(b))
+//	...
+//	int m = MAX(x, 42);
+//
+// Go code:
+//
+//	var m int32 = MAX(x, int32(42))
+//
+// Expansion is transpiled inline, if macro is not simple expression,
+// macro uses variables of caller or argument with side effects is
+// evaluated few times.
+func transpileMacroExpansion(n ast.Node, p *program.Program) (
+	expr goast.Expr, exprType string,
+	preStmts []goast.Stmt, postStmts []goast.Stmt, ok bool) {
+
+	mf, ok := p.GetMacroFunction(n.Position())
+	if !ok || mf.Failed || p.MacroBodiesInProgress[n] {
+		return nil, "", nil, nil, false
+	}
+	ok = false
+
+	// ignore implicit casts around of macro expansion
+	if len(n.Children()) == 1 && n.Children()[0] != nil &&
+		n.Children()[0].Position() == n.Position() {
+		if _, isCast := n.(*ast.ImplicitCastExpr); isCast {
+			return
+		}
+	}
+
+	resultType, found := ast.GetTypeIfExist(n)
+	if !found || *resultType == "" {
+		return
+	}
+
+	args, found := findMacroArguments(n, mf.Macro, p)
+	if !found {
+		return
+	}
+
+	// signature of macro function
+	var signature []string
+	for _, param := range append(args, macroArgument{core: n}) {
+		t, _ := ast.GetTypeIfExist(param.core)
+		goType, err := types.ResolveType(p, *t)
+		if err != nil || strings.Contains(goType, "interface{}") {
+			return
+		}
+		signature = append(signature, goType)
+	}
+
+	body, found := transpileMacroBody(n, args, mf.Macro, p)
+	if !found {
+		return
+	}
+	if !addMacroSignature(mf, signature, body) {
+		return
+	}
+
+	// arguments of function call
+	var callArgs []goast.Expr
+	for _, arg := range args {
+		t, _ := ast.GetTypeIfExist(arg.core)
+		e, eType, newPre, newPost, err := transpileToExpr(arg.core, p, false)
+		if err != nil {
+			return
+		}
+		e, err = types.CastExpr(p, e, eType, *t)
+		if err != nil {
+			return
+		}
+		if isUntypedConst(e) {
+			goType, _ := types.ResolveType(p, *t)
+			e = util.NewCallExpr(goType, e)
+		}
+		preStmts = append(preStmts, newPre...)
+		postStmts = append(postStmts, newPost...)
+		callArgs = append(callArgs, e)
+	}
+
+	return util.NewCallExpr(mf.Macro.Name, callArgs...), *resultType,
+		preStmts, postStmts, true
+}
+
+// findMacroArguments return arguments of function-like macro expansion in
+// order of macro parameters. Arguments are subtrees of expansion spelled
+// outside of macro definitions.
+func findMacroArguments(n ast.Node, m preprocessor.Macro, p *program.Program) (
+	args []macroArgument, ok bool) {
+
+	args = make([]macroArgument, len(m.Params))
+	lines := map[string][]string{}
+
+	var walk func(parent ast.Node) bool
+	walk = func(parent ast.Node) bool {
+		for i, child := range parent.Children() {
+			if child == nil {
+				continue
+			}
+			if p.IsMacroDefinition(child.Position()) {
+				if _, nested := p.GetMacroFunction(child.Position()); nested {
+					// nested expansion of function-like macro
+					return false
+				}
+				if d, isRef := child.(*ast.DeclRefExpr); isRef &&
+					(d.For == "Var" || d.For == "ParmVar") {
+					// macro uses variable of caller
+					return false
+				}
+				if !walk(child) {
+					return false
+				}
+				continue
+			}
+			index, found := macroArgumentIndex(child.Position(), m, lines)
+			if !found {
+				return false
+			}
+			parent, i, core := promotedArgument(parent, i, child)
+			if t, isType := ast.GetTypeIfExist(core); !isType || *t == "" {
+				return false
+			}
+			if args[index].core == nil {
+				args[index].core = core
+			} else {
+				t1, _ := ast.GetTypeIfExist(args[index].core)
+				t2, _ := ast.GetTypeIfExist(core)
+				if *t1 != *t2 {
+					return false
+				}
+			}
+			args[index].occurrences = append(args[index].occurrences,
+				macroOccurrence{parent: parent, index: i})
+		}
+		return true
+	}
+	if !walk(n) {
+		return nil, false
+	}
+
+	for _, arg := range args {
+		if arg.core == nil {
+			return nil, false
+		}
+		if len(arg.occurrences) > 1 && haveSideEffects(arg.core) {
+			return nil, false
+		}
+	}
+	return args, true
+}
+
+// promotedArgument return argument without implicit promotions, which
+// are added by compiler in macro expansion.
+func promotedArgument(parent ast.Node, index int, n ast.Node) (
+	_ ast.Node, _ int, core ast.Node) {
+	for {
+		ic, ok := n.(*ast.ImplicitCastExpr)
+		if !ok || len(ic.Children()) != 1 {
+			return parent, index, n
+		}
+		switch ic.Kind {
+		case "IntegralCast", "IntegralToFloating", "FloatingCast",
+			"FloatingToIntegral", "IntegralToBoolean", "FloatingToBoolean":
+		default:
+			return parent, index, n
+		}
+		parent, index, n = n, 0, ic.Children()[0]
+	}
+}
+
+// macroArgumentIndex return index of macro argument at position by
+// source line of macro invocation.
+func macroArgumentIndex(pos ast.Position, m preprocessor.Macro,
+	lines map[string][]string) (index int, ok bool) {
+	if pos.File == "" || pos.Line == 0 || pos.Column == 0 {
+		return
+	}
+	if _, read := lines[pos.File]; !read {
+		content, err := ioutil.ReadFile(pos.File)
+		if err != nil {
+			return
+		}
+		lines[pos.File] = strings.Split(string(content), "\n")
+	}
+	if pos.Line > len(lines[pos.File]) {
+		return
+	}
+	line := lines[pos.File][pos.Line-1]
+	col := pos.Column - 1
+
+	size := -1
+	re := util.GetRegex(`\b` + m.Name + `\s*\(`)
+	for _, loc := range re.FindAllStringIndex(line, -1) {
+		ranges := macroInvocationArguments(line, loc[1])
+		if len(ranges) != len(m.Params) || len(ranges) == 0 {
+			continue
+		}
+		begin, end := ranges[0][0], ranges[len(ranges)-1][1]
+		if col < begin || end <= col {
+			continue
+		}
+		if size >= 0 && size <= end-begin {
+			continue
+		}
+		for i := range ranges {
+			if ranges[i][0] <= col && col < ranges[i][1] {
+				index, size, ok = i, end-begin, true
+			}
+		}
+	}
+	return
+}
+
+// macroInvocationArguments return ranges of arguments of macro invocation
+// in line. Position is located after open parenthesis.
+func macroInvocationArguments(line string, pos int) (ranges [][2]int) {
+	depth := 0
+	begin := pos
+	for i := pos; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '"', '\'':
+			for i++; i < len(line) && line[i] != c; i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return append(ranges, [2]int{begin, i})
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				ranges = append(ranges, [2]int{begin, i})
+				begin = i + 1
+			}
+		}
+	}
+	// invocation is not finished in that line
+	return nil
+}
+
+// haveSideEffects return true, if expression can change any value
+func haveSideEffects(n ast.Node) bool {
+	if n == nil {
+		return false
+	}
+	switch v := n.(type) {
+	case *ast.CallExpr, *ast.CompoundAssignOperator:
+		return true
+	case *ast.UnaryOperator:
+		if v.Operator == "++" || v.Operator == "--" {
+			return true
+		}
+	case *ast.BinaryOperator:
+		if v.Operator == "=" {
+			return true
+		}
+	}
+	for _, child := range n.Children() {
+		if haveSideEffects(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// transpileMacroBody return Go expression for body of function-like macro.
+// Arguments of expansion are replaced by macro parameters.
+func transpileMacroBody(n ast.Node, args []macroArgument,
+	m preprocessor.Macro, p *program.Program) (body string, ok bool) {
+
+	// replace arguments by parameters
+	params := map[ast.Node]ast.Node{}
+	for i, arg := range args {
+		t, _ := ast.GetTypeIfExist(arg.core)
+		param := &ast.DeclRefExpr{
+			Pos:  arg.core.Position(),
+			Type: *t,
+			For:  "ParmVar",
+			Name: m.Params[i],
+		}
+		for _, o := range arg.occurrences {
+			params[o.node()] = param
+		}
+	}
+	n, ok = replaceMacroArguments(n, params)
+	if !ok {
+		return
+	}
+	ok = false
+
+	p.MacroBodiesInProgress[n] = true
+	defer delete(p.MacroBodiesInProgress, n)
+
+	resultType, _ := ast.GetTypeIfExist(n)
+	expr, exprType, preStmts, postStmts, err := transpileToExpr(n, p, false)
+	if err != nil || len(preStmts) > 0 || len(postStmts) > 0 {
+		return
+	}
+	expr, err = types.CastExpr(p, expr, exprType, *resultType)
+	if err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	if err = format.Node(&buf, token.NewFileSet(), expr); err != nil {
+		return
+	}
+	return buf.String(), true
+}
+
+// replaceMacroArguments return copy of expansion of function-like macro,
+// where arguments are replaced by parameters. Only nodes with replaced
+// children are copied, so nodes of expansion are not changed.
+func replaceMacroArguments(n ast.Node, params map[ast.Node]ast.Node) (
+	_ ast.Node, ok bool) {
+	if param, found := params[n]; found {
+		return param, true
+	}
+	var children []ast.Node
+	for i, child := range n.Children() {
+		if child == nil {
+			continue
+		}
+		c, ok := replaceMacroArguments(child, params)
+		if !ok {
+			return nil, false
+		}
+		if c == child {
+			continue
+		}
+		if children == nil {
+			children = append([]ast.Node{}, n.Children()...)
+		}
+		children[i] = c
+	}
+	if children == nil {
+		return n, true
+	}
+
+	// copy of node with new children
+	v := reflect.ValueOf(n)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+	field, found := v.Elem().Type().FieldByName("ChildNodes")
+	if !found || len(field.Index) != 1 ||
+		field.Type != reflect.TypeOf(children) {
+		return nil, false
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	c.Elem().Field(field.Index[0]).Set(reflect.ValueOf(children))
+	return c.Interface().(ast.Node), true
+}
+
+// addMacroSignature add signature of function-like macro, if that is
+// possible. Few signatures are acceptable only for generic function, where
+// all parameters and result have same type.
+func addMacroSignature(mf *program.MacroFunction, signature []string, body string) bool {
+	for i := range mf.Signatures {
+		if strings.Join(mf.Signatures[i], ",") == strings.Join(signature, ",") {
+			return mf.Bodies[i] == body
+		}
+	}
+	if len(mf.Signatures) > 0 {
+		if !isUniformSignature(signature) ||
+			genericMacroBody(mf.Signatures[0], mf.Bodies[0]) !=
+				genericMacroBody(signature, body) {
+			return false
+		}
+	}
+	mf.Signatures = append(mf.Signatures, signature)
+	mf.Bodies = append(mf.Bodies, body)
+	return true
+}
+
+func isUniformSignature(signature []string) bool {
+	for i := range signature {
+		if signature[i] != signature[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// macroTypeParameter is name of type parameter in generic macro function
+const macroTypeParameter = "T"
+
+// genericMacroBody return body of macro function, where Go type of
+// uniform signature is replaced by type parameter
+func genericMacroBody(signature []string, body string) string {
+	if !isUniformSignature(signature) {
+		return ""
+	}
+	re := util.GetRegex(`\b` + regexp.QuoteMeta(signature[0]) + `\b`)
+	return re.ReplaceAllString(body, macroTypeParameter)
+}
+
+// isUntypedConst return true for Go constant expressions without type
+func isUntypedConst(e goast.Expr) bool {
+	switch v := e.(type) {
+	case *goast.BasicLit:
+		return true
+	case *goast.ParenExpr:
+		return isUntypedConst(v.X)
+	case *goast.UnaryExpr:
+		return isUntypedConst(v.X)
+	case *goast.BinaryExpr:
+		return isUntypedConst(v.X) && isUntypedConst(v.Y)
+	}
+	return false
+}
+
+// transpileMacroFunctions return Go functions for function-like macros
+// from user sources. Generic function is used for macros with few
+// signatures.
+func transpileMacroFunctions(p *program.Program) (decls []goast.Decl) {
+	if !p.MacroFunctionMode {
+		return
+	}
+	for _, mf := range p.GetMacroFunctions() {
+		if len(mf.Signatures) == 0 {
+			continue
+		}
+		m := mf.Macro
+		signature := mf.Signatures[0]
+		body := mf.Bodies[0]
+		var typeParams *goast.FieldList
+		if len(mf.Signatures) > 1 {
+			body = genericMacroBody(signature, body)
+			var constraint goast.Expr
+			for _, s := range mf.Signatures {
+				if constraint == nil {
+					constraint = goast.NewIdent(s[0])
+					continue
+				}
+				constraint = &goast.BinaryExpr{
+					X:  constraint,
+					Op: token.OR,
+					Y:  goast.NewIdent(s[0]),
+				}
+			}
+			typeParams = &goast.FieldList{List: []*goast.Field{{
+				Names: []*goast.Ident{goast.NewIdent(macroTypeParameter)},
+				Type:  constraint,
+			}}}
+			signature = make([]string, len(signature))
+			for i := range signature {
+				signature[i] = macroTypeParameter
+			}
+		}
+		result, err := parser.ParseExpr(body)
+		if err != nil {
+			p.AddMessage(p.GenerateWarningMessage(
+				fmt.Errorf("cannot parse body of macro `%s`: %v", m.Name, err), nil))
+			continue
+		}
+		var params []*goast.Field
+		for i := range m.Params {
+			params = append(params, &goast.Field{
+				Names: []*goast.Ident{util.NewIdent(m.Params[i])},
+				Type:  goast.NewIdent(signature[i]),
+			})
+		}
+		location := program.PathSimplification(
+			ast.Position{File: m.File, Line: m.Line}.GetSimpleLocation())
+		decls = append(decls, &goast.FuncDecl{
+			Doc: &goast.CommentGroup{List: []*goast.Comment{{
+				Text: fmt.Sprintf("// %s - transpiled macro from %s",
+					m.Name, location),
+			}}},
+			Name: goast.NewIdent(m.Name),
+			Type: &goast.FuncType{
+				TypeParams: typeParams,
+				Params:     &goast.FieldList{List: params},
+				Results: &goast.FieldList{List: []*goast.Field{{
+					Type: goast.NewIdent(signature[len(signature)-1]),
+				}}},
+			},
+			Body: &goast.BlockStmt{List: []goast.Stmt{
+				&goast.ReturnStmt{Results: []goast.Expr{result}},
+			}},
+		})
+	}
+	return
+}
@@ -0,0 +1,120 @@
+package transpiler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/preprocessor"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+func TestMacroArgumentIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "c4go-macro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "main.c")
+	source := "int m = MAX(x, y + 1);\n" +
+		"int n = MAX(MAX(a, \"),\"), f(b, c));\n"
+	if err = ioutil.WriteFile(file, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := preprocessor.Macro{Name: "MAX", Params: []string{"a", "b"}}
+	testCases := []struct {
+		line, column int
+		index        int
+		ok           bool
+	}{
+		{1, 13, 0, true}, // x
+		{1, 16, 1, true}, // y
+		{1, 20, 1, true}, // 1
+		{1, 5, 0, false}, // m
+		{2, 17, 0, true}, // a of internal macro
+		{2, 20, 1, true}, // "),"
+		{2, 26, 1, true}, // f(b, c)
+		{2, 30, 1, true}, // c
+		{3, 1, 0, false}, // outside of file
+	}
+	lines := map[string][]string{}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			index, ok := macroArgumentIndex(ast.Position{
+				File:   file,
+				Line:   tc.line,
+				Column: tc.column,
+			}, m, lines)
+			if index != tc.index || ok != tc.ok {
+				t.Errorf("Not same: {%d,%d}: %d %v != %d %v",
+					tc.line, tc.column, index, ok, tc.index, tc.ok)
+			}
+		})
+	}
+}
+
+func TestGenericMacroBody(t *testing.T) {
+	testCases := []struct {
+		signature []string
+		body      string
+		generic   string
+	}{
+		{
+			signature: []string{"int32", "int32", "int32"},
+			body:      "func() int32 {\n\tif a > b {\n\t\treturn a\n\t}\n\treturn b\n}()",
+			generic:   "func() T {\n\tif a > b {\n\t\treturn a\n\t}\n\treturn b\n}()",
+		},
+		{
+			signature: []string{"float64", "float64"},
+			body:      "float64(a) * xfloat64",
+			generic:   "T(a) * xfloat64",
+		},
+		{
+			signature: []string{"int32", "float64"},
+			body:      "float64(a)",
+			generic:   "",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if g := genericMacroBody(tc.signature, tc.body); g != tc.generic {
+				t.Errorf("Not same:\n%s\n%s", g, tc.generic)
+			}
+		})
+	}
+}
+
+func TestReplaceMacroArguments(t *testing.T) {
+	// MAX(x, 42) : (x) > (42) ? (x) : (42)
+	x := &ast.DeclRefExpr{Type: "int", For: "Var", Name: "x"}
+	literal := &ast.IntegerLiteral{Type: "int", Value: "42"}
+	cond := &ast.BinaryOperator{Type: "int", Operator: ">", ChildNodes: []ast.Node{
+		&ast.ParenExpr{Type: "int", ChildNodes: []ast.Node{x}},
+		&ast.ParenExpr{Type: "int", ChildNodes: []ast.Node{literal}},
+	}}
+	n := &ast.ParenExpr{Type: "int", ChildNodes: []ast.Node{cond}}
+	original := ast.Atos(n)
+
+	a := &ast.DeclRefExpr{Type: "int", For: "ParmVar", Name: "a"}
+	r, ok := replaceMacroArguments(n, map[ast.Node]ast.Node{x: a})
+	if !ok {
+		t.Fatalf("arguments are not replaced")
+	}
+	if r == ast.Node(n) || r.Children()[0] == ast.Node(cond) {
+		t.Errorf("nodes with replaced children are not copied")
+	}
+	if r.Children()[0].Children()[1] != ast.Node(cond.Children()[1]) {
+		t.Errorf("nodes without replaced children are copied")
+	}
+	if r.Children()[0].Children()[0].Children()[0] != ast.Node(a) {
+		t.Errorf("argument is not replaced")
+	}
+	if actual := ast.Atos(n); actual != original {
+		t.Errorf("expansion is changed:\n%s", util.ShowDiff(original, actual))
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	goast "go/ast"
+	"go/token"
+	"strconv"
 
 	"github.com/Konstantin8105/c4go/ast"
 	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
 	"github.com/Konstantin8105/c4go/util"
 )
 
@@ -60,6 +63,21 @@ func transpileOffsetOfExpr(n *ast.OffsetOfExpr, p *program.Program) (
 		arguments[0] = arguments[0][len("struct "):]
 	}
 
+	// offset in struct with memory layout of C
+	if p.CLayout {
+		offset, err := types.OffsetOf(p,
+			string(arguments[0]), string(arguments[1]))
+		if err == nil {
+			expr = &goast.BasicLit{
+				Kind:  token.INT,
+				Value: strconv.Itoa(offset),
+			}
+			exprType = n.Type
+			return expr, exprType, nil
+		}
+		p.AddMessage(p.GenerateWarningMessage(err, n))
+	}
+
 	p.AddImport("unsafe")
 	expr = util.NewCallExpr("unsafe.Offsetof",
 		&goast.SelectorExpr{
@@ -285,6 +285,11 @@ func transpileCompoundAssignOperator(
 		return
 	}
 
+	if isAtomicNode(n.ChildNodes[0], p) {
+		return transpileAtomicAssign(n.ChildNodes[0], n.ChildNodes[1],
+			getTokenForOperatorNoError(n.Opcode), p, exprIsStmt)
+	}
+
 	if !types.IsCPointer(n.Type, p) && !types.IsCArray(n.Type, p) {
 		return transpileBinaryOperator(&ast.BinaryOperator{
 			Type:       n.Type,
@@ -16,7 +16,18 @@ func transpileCompoundStmt(n *ast.CompoundStmt, p *program.Program) (
 	_ *goast.BlockStmt, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
 	stmts := []goast.Stmt{}
 
-	for _, x := range n.Children() {
+	for i, x := range n.Children() {
+		// all statements after setjmp call are part of body function
+		if findSetjmpCall(x, p) != nil {
+			var result []goast.Stmt
+			result, err = transpileSetjmp(n.Children()[i:], p)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			stmts = append(stmts, result...)
+			break
+		}
+
 		// add '_ = '
 		var addPrefix bool
 		if impl, ok := x.(*ast.ImplicitCastExpr); ok && len(impl.Children()) == 1 {
@@ -0,0 +1,414 @@
+package transpiler
+
+import (
+	"fmt"
+
+	goast "go/ast"
+	"go/token"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// setjmpValueName is name of parameter of body function for noarch.Setjmp.
+// The result of setjmp call is replaced by that parameter.
+const setjmpValueName = "c4go_setjmp"
+
+// names of setjmp and longjmp functions after preprocessing
+var (
+	setjmpFunctions = map[string]bool{
+		"setjmp":      true,
+		"_setjmp":     true,
+		"sigsetjmp":   true,
+		"__sigsetjmp": true,
+	}
+	longjmpFunctions = map[string]bool{
+		"longjmp":    true,
+		"_longjmp":   true,
+		"siglongjmp": true,
+	}
+)
+
+// isSetjmpCall return true, if node is call of setjmp function
+func isSetjmpCall(n *ast.CallExpr, p *program.Program) bool {
+	if !p.IncludeHeaderIsExists("setjmp.h") || len(n.Children()) < 2 {
+		return false
+	}
+	name, err := getName(p, n)
+	return err == nil && setjmpFunctions[name]
+}
+
+// findSetjmpCall return call of setjmp function in statement. Search is not
+// located inside of compound statements, because that compound statements
+// are transpiled separately.
+func findSetjmpCall(node ast.Node, p *program.Program) *ast.CallExpr {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *ast.CompoundStmt:
+		return nil
+	case *ast.CallExpr:
+		if isSetjmpCall(n, p) {
+			return n
+		}
+	}
+	for _, c := range node.Children() {
+		if call := findSetjmpCall(c, p); call != nil {
+			return call
+		}
+	}
+	return nil
+}
+
+// jmpBufPointer is type of jmp_buf after array to pointer decay.
+const jmpBufPointer = "struct __jmp_buf_tag *"
+
+// jmpBufArgument return jmp_buf argument without array to pointer decay.
+// Argument is pointer to noarch.JmpBuf, so address is taken only for
+// buffer variable, but not for parameter of function.
+//
+// Example of AST:
+//
+//	ImplicitCastExpr 'struct __jmp_buf_tag *' <ArrayToPointerDecay>
+//	`-DeclRefExpr 'jmp_buf':'struct __jmp_buf_tag [1]' lvalue Var 0x2d2b9f8 'buf' 'jmp_buf':'struct __jmp_buf_tag [1]'
+//
+//	ImplicitCastExpr 'struct __jmp_buf_tag *' <LValueToRValue>
+//	`-DeclRefExpr 'struct __jmp_buf_tag *' lvalue ParmVar 0x2d2c1a0 'env' 'struct __jmp_buf_tag *'
+func jmpBufArgument(node ast.Node, p *program.Program) (
+	expr goast.Expr, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	for {
+		switch n := node.(type) {
+		case *ast.ImplicitCastExpr:
+			if n.Kind == "ArrayToPointerDecay" && len(n.Children()) == 1 {
+				node = n.Children()[0]
+				continue
+			}
+		case *ast.ParenExpr:
+			if len(n.Children()) == 1 {
+				node = n.Children()[0]
+				continue
+			}
+		}
+		break
+	}
+	expr, exprType, preStmts, postStmts, err := transpileToExpr(node, p, false)
+	if err != nil {
+		return
+	}
+	if util.CleanCType(exprType) == jmpBufPointer {
+		return
+	}
+	expr = &goast.UnaryExpr{Op: token.AND, X: expr}
+	return
+}
+
+// transpileLongjmp transpile call of longjmp function.
+//
+//	C  : longjmp(buf, 1);
+//	Go : noarch.Longjmp(&buf, 1)
+func transpileLongjmp(n *ast.CallExpr, p *program.Program) (
+	expr *goast.CallExpr, resultType string,
+	preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpile longjmp: %v", err)
+		}
+	}()
+	if len(n.Children()) != 3 {
+		err = fmt.Errorf("not valid amount of arguments: %d", len(n.Children())-1)
+		return
+	}
+
+	buf, newPre, newPost, err := jmpBufArgument(n.Children()[1], p)
+	if err != nil {
+		return
+	}
+	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+	value, valueType, newPre, newPost, err := transpileToExpr(n.Children()[2], p, false)
+	if err != nil {
+		return
+	}
+	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+	value, err = types.CastExpr(p, value, valueType, "int")
+	if err != nil {
+		return
+	}
+
+	p.AddImport("github.com/Konstantin8105/c4go/noarch")
+	return &goast.CallExpr{
+		Fun:  goast.NewIdent("noarch.Longjmp"),
+		Args: []goast.Expr{buf, value},
+	}, "void", preStmts, postStmts, nil
+}
+
+// transpileSetjmp transpile statement with setjmp call and all next
+// statements of the same block. Result of setjmp call is replaced by
+// parameter of body function. All return, break and continue statements
+// inside body are replaced by flag of escape.
+//
+// Example:
+//
+//	C :
+//	if (setjmp(buf) == 0) {
+//		body();
+//	} else {
+//		return -1;
+//	}
+//	return 0;
+//
+//	Go :
+//	var c4go_setjmp_escape0 int
+//	var c4go_setjmp_return0 int32
+//	noarch.Setjmp(&buf, func(c4go_setjmp int32) {
+//		if c4go_setjmp == 0 {
+//			body()
+//		} else {
+//			{
+//				c4go_setjmp_return0 = -1
+//				c4go_setjmp_escape0 = 1
+//				return
+//			}
+//		}
+//		...
+//	})
+//	if c4go_setjmp_escape0 == 1 {
+//		return c4go_setjmp_return0
+//	}
+func transpileSetjmp(nodes []ast.Node, p *program.Program) (
+	stmts []goast.Stmt, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpile setjmp: %v", err)
+		}
+	}()
+
+	call := findSetjmpCall(nodes[0], p)
+	buf, preStmts, postStmts, err := jmpBufArgument(call.Children()[1], p)
+	if err != nil {
+		return
+	}
+
+	// body of setjmp
+	var body []goast.Stmt
+	if !isSetjmpStmt(nodes[0], call) {
+		body, err = transpileToStmts(nodes[0], p)
+		if err != nil {
+			return
+		}
+	}
+	rest, _, _, err := transpileCompoundStmt(&ast.CompoundStmt{ChildNodes: nodes[1:]}, p)
+	if err != nil {
+		return
+	}
+	body = append(body, rest.List...)
+
+	escape := setjmpEscape{
+		flag:  p.GetNextIdentifier("c4go_setjmp_escape"),
+		value: p.GetNextIdentifier("c4go_setjmp_return"),
+	}
+	body = escape.list(body, false, false)
+
+	stmts = append(stmts, preStmts...)
+	if escape.used[escapeReturn] || escape.used[escapeBreak] || escape.used[escapeContinue] {
+		stmts = append(stmts, newVarStmt(escape.flag, "int"))
+	}
+	if escape.returnValue {
+		f := p.GetFunctionDefinition(p.Function.Name)
+		var goType string
+		goType, err = types.ResolveType(p, f.ReturnType)
+		if err != nil {
+			return
+		}
+		stmts = append(stmts, newVarStmt(escape.value, goType))
+	}
+
+	p.AddImport("github.com/Konstantin8105/c4go/noarch")
+	stmts = append(stmts, &goast.ExprStmt{X: &goast.CallExpr{
+		Fun: goast.NewIdent("noarch.Setjmp"),
+		Args: []goast.Expr{
+			buf,
+			&goast.FuncLit{
+				Type: &goast.FuncType{
+					Params: &goast.FieldList{List: []*goast.Field{{
+						Names: []*goast.Ident{util.NewIdent(setjmpValueName)},
+						Type:  goast.NewIdent("int32"),
+					}}},
+				},
+				Body: &goast.BlockStmt{List: body},
+			},
+		},
+	}})
+	stmts = append(stmts, postStmts...)
+
+	// escape from body
+	for _, kind := range []int{escapeReturn, escapeBreak, escapeContinue} {
+		if !escape.used[kind] {
+			continue
+		}
+		var s goast.Stmt
+		switch kind {
+		case escapeReturn:
+			r := &goast.ReturnStmt{}
+			if escape.returnValue {
+				r.Results = []goast.Expr{util.NewIdent(escape.value)}
+			}
+			s = r
+		case escapeBreak:
+			s = &goast.BranchStmt{Tok: token.BREAK}
+		case escapeContinue:
+			s = &goast.BranchStmt{Tok: token.CONTINUE}
+		}
+		stmts = append(stmts, &goast.IfStmt{
+			Cond: &goast.BinaryExpr{
+				X:  util.NewIdent(escape.flag),
+				Op: token.EQL,
+				Y:  util.NewIntLit(kind),
+			},
+			Body: &goast.BlockStmt{List: []goast.Stmt{s}},
+		})
+	}
+	if len(escape.labels) > 0 {
+		err = fmt.Errorf("goto or labeled branch to outside of setjmp "+
+			"block is not supported: %v", escape.labels)
+		p.AddMessage(p.GenerateWarningMessage(err, nodes[0]))
+		err = nil
+	}
+
+	return
+}
+
+// isSetjmpStmt return true, if statement is only setjmp call.
+//
+//	setjmp(buf);
+//	(void)setjmp(buf);
+func isSetjmpStmt(node ast.Node, call *ast.CallExpr) bool {
+	for node != call {
+		switch node.(type) {
+		case *ast.CStyleCastExpr, *ast.ImplicitCastExpr, *ast.ParenExpr:
+			if len(node.Children()) != 1 {
+				return false
+			}
+			node = node.Children()[0]
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newVarStmt(name, goType string) goast.Stmt {
+	return &goast.DeclStmt{Decl: &goast.GenDecl{
+		Tok: token.VAR,
+		Specs: []goast.Spec{&goast.ValueSpec{
+			Names: []*goast.Ident{util.NewIdent(name)},
+			Type:  util.NewTypeIdent(goType),
+		}},
+	}}
+}
+
+// kinds of escape from setjmp body
+const (
+	escapeReturn = iota + 1
+	escapeBreak
+	escapeContinue
+)
+
+// setjmpEscape replace statements for escape from body of setjmp function,
+// because return, break and continue cannot be used inside function literal
+// for outside statements.
+type setjmpEscape struct {
+	flag        string // name of escape flag
+	value       string // name of return value
+	returnValue bool   // true, if return value is used
+	used        [escapeContinue + 1]bool
+	labels      []string
+}
+
+func (e *setjmpEscape) list(stmts []goast.Stmt, inLoop, inSwitch bool) []goast.Stmt {
+	for i := range stmts {
+		stmts[i] = e.stmt(stmts[i], inLoop, inSwitch)
+	}
+	return stmts
+}
+
+func (e *setjmpEscape) stmt(stmt goast.Stmt, inLoop, inSwitch bool) goast.Stmt {
+	switch s := stmt.(type) {
+	case *goast.BlockStmt:
+		if s != nil {
+			e.list(s.List, inLoop, inSwitch)
+		}
+
+	case *goast.IfStmt:
+		e.stmt(s.Body, inLoop, inSwitch)
+		if s.Else != nil {
+			s.Else = e.stmt(s.Else, inLoop, inSwitch)
+		}
+
+	case *goast.ForStmt:
+		e.stmt(s.Body, true, false)
+
+	case *goast.RangeStmt:
+		e.stmt(s.Body, true, false)
+
+	case *goast.SwitchStmt:
+		e.stmt(s.Body, inLoop, true)
+
+	case *goast.TypeSwitchStmt:
+		e.stmt(s.Body, inLoop, true)
+
+	case *goast.SelectStmt:
+		e.stmt(s.Body, inLoop, true)
+
+	case *goast.CaseClause:
+		e.list(s.Body, inLoop, inSwitch)
+
+	case *goast.CommClause:
+		e.list(s.Body, inLoop, inSwitch)
+
+	case *goast.LabeledStmt:
+		s.Stmt = e.stmt(s.Stmt, inLoop, inSwitch)
+
+	case *goast.ReturnStmt:
+		var list []goast.Stmt
+		if len(s.Results) > 0 {
+			e.returnValue = true
+			list = append(list, &goast.AssignStmt{
+				Lhs: []goast.Expr{util.NewIdent(e.value)},
+				Tok: token.ASSIGN,
+				Rhs: s.Results,
+			})
+		}
+		return e.escape(escapeReturn, list)
+
+	case *goast.BranchStmt:
+		if s.Label != nil || s.Tok == token.GOTO {
+			if s.Label != nil {
+				e.labels = append(e.labels, s.Label.Name)
+			}
+			break
+		}
+		if s.Tok == token.BREAK && !inLoop && !inSwitch {
+			return e.escape(escapeBreak, nil)
+		}
+		if s.Tok == token.CONTINUE && !inLoop {
+			return e.escape(escapeContinue, nil)
+		}
+	}
+	return stmt
+}
+
+func (e *setjmpEscape) escape(kind int, list []goast.Stmt) goast.Stmt {
+	e.used[kind] = true
+	list = append(list,
+		&goast.AssignStmt{
+			Lhs: []goast.Expr{util.NewIdent(e.flag)},
+			Tok: token.ASSIGN,
+			Rhs: []goast.Expr{util.NewIntLit(kind)},
+		},
+		&goast.ReturnStmt{},
+	)
+	return &goast.BlockStmt{List: list}
+}
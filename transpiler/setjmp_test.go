@@ -0,0 +1,65 @@
+package transpiler
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	goast "go/ast"
+)
+
+func TestSetjmpEscape(t *testing.T) {
+	src := `package main
+func f() {
+	if c4go_setjmp != 0 {
+		return 2
+	}
+	for {
+		if c4go_setjmp == 1 {
+			break
+		}
+		continue
+	}
+	switch c4go_setjmp {
+	case 1:
+		break
+	}
+	func() {
+		return
+	}()
+	continue
+}`
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[0].(*goast.FuncDecl).Body
+
+	e := setjmpEscape{flag: "flag", value: "value"}
+	body.List = e.list(body.List, false, false)
+
+	if !e.returnValue || !e.used[escapeReturn] || e.used[escapeBreak] ||
+		!e.used[escapeContinue] {
+		t.Errorf("not valid escapes: %#v", e)
+	}
+
+	var buf bytes.Buffer
+	if err = format.Node(&buf, token.NewFileSet(), body); err != nil {
+		t.Fatal(err)
+	}
+	code := buf.String()
+	for _, part := range []string{
+		"value = 2\n\t\t\tflag = 1\n\t\t\treturn",
+		"\t\t\tbreak\n",
+		"\tcontinue\n\t}",
+		"func() {\n\t\treturn\n\t}()",
+		"flag = 3\n\t\treturn",
+	} {
+		if !strings.Contains(code, part) {
+			t.Errorf("cannot find %q in code:\n%s", part, code)
+		}
+	}
+}
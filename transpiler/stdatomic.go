@@ -0,0 +1,532 @@
+package transpiler
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// C11 atomic types are transpiled to types of package "sync/atomic".
+// All operations of that package are sequentially consistent, so memory
+// order of C operations is ignored. Type `atomic_bool` is atomic `_Bool`
+// and it is transpiled as atomic int32, like `_Bool`. Type `atomic_flag`
+// is structure with one `atomic_bool`, so it is transpiled as atomic int32
+// too.
+//
+// Package "sync/atomic" has no types for characters, shorts and pointers,
+// so they are transpiled to types of package "noarch" with the same
+// methods. Characters and shorts are widened to int32. Arithmetic of
+// atomic pointers is not supported.
+//
+// Example:
+//
+//	C  : atomic_int counter = 0;
+//	     counter++;
+//	     atomic_fetch_add(&counter, 2);
+//	     int value = counter;
+//	Go : var counter atomic.Int32
+//	     counter.Add(1)
+//	     func(c4go_atomic *atomic.Int32, c4go_value int32) int32 {
+//	     	return c4go_atomic.Add(c4go_value) - c4go_value
+//	     }(&counter, 2)
+//	     var value int32 = counter.Load()
+
+// atomicFunctions - Go sources of functions for atomic operations.
+// Function have arguments: pointer to atomic value, and values.
+// Go type of atomic value is placed instead of `%[1]s` and
+// Go type of value is placed instead of `%[2]s`.
+var atomicFunctions = map[string]string{
+	"fetch_add": `func(c4go_atomic *%[1]s, c4go_value %[2]s) %[2]s {
+		return c4go_atomic.Add(c4go_value) - c4go_value
+	}`,
+	"fetch_sub": `func(c4go_atomic *%[1]s, c4go_value %[2]s) %[2]s {
+		return c4go_atomic.Add(-c4go_value) + c4go_value
+	}`,
+	"sub_fetch": `func(c4go_atomic *%[1]s, c4go_value %[2]s) %[2]s {
+		return c4go_atomic.Add(-c4go_value)
+	}`,
+	"store_fetch": `func(c4go_atomic *%[1]s, c4go_value %[2]s) %[2]s {
+		c4go_atomic.Store(c4go_value)
+		return c4go_value
+	}`,
+	"compare_exchange": `func(c4go_atomic *%[1]s, c4go_expected []%[2]s, c4go_value %[2]s) bool {
+		for {
+			if c4go_atomic.CompareAndSwap(c4go_expected[0], c4go_value) {
+				return true
+			}
+			if old := c4go_atomic.Load(); old != c4go_expected[0] {
+				c4go_expected[0] = old
+				return false
+			}
+		}
+	}`,
+	"init": `func(c4go_atomic *%[1]s, c4go_value %[2]s) struct{} {
+		c4go_atomic.Store(c4go_value)
+		return struct{}{}
+	}`,
+}
+
+// atomicFetchOpFunction return Go source of function for bitwise
+// operation, like `atomic_fetch_or`. Function returns old value.
+// Methods `And` and `Or` of package "sync/atomic" are not used, because
+// they are not exist before Go 1.23.
+func atomicFetchOpFunction(op token.Token) string {
+	return `func(c4go_atomic *%[1]s, c4go_value %[2]s) %[2]s {
+		for {
+			old := c4go_atomic.Load()
+			if c4go_atomic.CompareAndSwap(old, old ` + op.String() + ` c4go_value) {
+				return old
+			}
+		}
+	}`
+}
+
+// atomicOpFunction return Go source of function for compound assignment,
+// like `*=`. Function returns new value.
+func atomicOpFunction(op token.Token) string {
+	return `func(c4go_atomic *%[1]s, c4go_value %[2]s) %[2]s {
+		for {
+			old := c4go_atomic.Load()
+			if value := old ` + op.String() + ` c4go_value; c4go_atomic.CompareAndSwap(old, value) {
+				return value
+			}
+		}
+	}`
+}
+
+// atomicCall return call of atomic function.
+func atomicCall(p *program.Program, src string, cBase string, args ...goast.Expr) (
+	_ goast.Expr, err error) {
+	atomicType, err := types.ResolveType(p, "_Atomic("+cBase+")")
+	if err != nil {
+		return
+	}
+	valueType, err := types.ResolveType(p, cBase)
+	if err != nil {
+		return
+	}
+	src = fmt.Sprintf(src, atomicType, valueType)
+	f, err := parser.ParseExpr(src)
+	if err != nil {
+		err = fmt.Errorf("cannot parse source \"%s\" : %v", src, err)
+		return
+	}
+	return &goast.CallExpr{Fun: f, Args: args}, nil
+}
+
+// atomicMethod return call of method of atomic value.
+func atomicMethod(obj goast.Expr, method string, args ...goast.Expr) goast.Expr {
+	return &goast.CallExpr{
+		Fun:  &goast.SelectorExpr{X: obj, Sel: goast.NewIdent(method)},
+		Args: args,
+	}
+}
+
+func addressOf(obj goast.Expr) goast.Expr {
+	return &goast.UnaryExpr{Op: token.AND, X: obj}
+}
+
+// withoutLValueToRValue return lvalue node without implicit casts.
+func withoutLValueToRValue(node ast.Node) ast.Node {
+	for {
+		switch n := node.(type) {
+		case *ast.ImplicitCastExpr:
+			if n.Kind == "LValueToRValue" || n.Kind == "NoOp" {
+				node = n.Children()[0]
+				continue
+			}
+		case *ast.ParenExpr:
+			node = n.Children()[0]
+			continue
+		}
+		return node
+	}
+}
+
+// transpileAtomicObject transpile lvalue of atomic type.
+//
+// Member of `atomic_flag` is the flag:
+//
+//	C  : atomic_flag_test_and_set(&flag)
+//	     __c11_atomic_exchange(&(&flag)->_Value, 1, 5)
+//	Go : flag.Swap(1)
+func transpileAtomicObject(node ast.Node, p *program.Program) (
+	obj goast.Expr, cBase string, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	node = withoutLValueToRValue(node)
+	if m, ok := node.(*ast.MemberExpr); ok && len(m.Children()) == 1 {
+		if t, ok := ast.GetTypeIfExist(m.Children()[0]); ok && types.IsAtomicFlag(p, *t) {
+			if m.IsPointer {
+				return transpileAtomicPointer(m.Children()[0], p)
+			}
+			return transpileAtomicObject(m.Children()[0], p)
+		}
+	}
+	obj, cType, preStmts, postStmts, err := transpileToExpr(node, p, false)
+	if err != nil {
+		return
+	}
+	cBase, ok := types.GetAtomicBaseType(p, cType)
+	if !ok {
+		err = fmt.Errorf("type `%s` is not atomic", cType)
+	}
+	return
+}
+
+// transpileAtomicPointer transpile pointer to atomic value and return
+// atomic value.
+//
+//	C  : &counter
+//	Go : counter
+//
+//	C  : ptr
+//	Go : ptr[0]
+func transpileAtomicPointer(node ast.Node, p *program.Program) (
+	obj goast.Expr, cBase string, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	node = withoutLValueToRValue(node)
+	if u, ok := node.(*ast.UnaryOperator); ok && u.Operator == "&" && len(u.Children()) == 1 {
+		return transpileAtomicObject(u.Children()[0], p)
+	}
+	obj, cType, preStmts, postStmts, err := transpileToExpr(node, p, false)
+	if err != nil {
+		return
+	}
+	cType = strings.TrimSpace(util.CleanCType(cType))
+	if !strings.HasSuffix(cType, "*") {
+		err = fmt.Errorf("type `%s` is not pointer", cType)
+		return
+	}
+	cBase, ok := types.GetAtomicBaseType(p, strings.TrimSpace(cType[:len(cType)-1]))
+	if !ok {
+		err = fmt.Errorf("type `%s` is not pointer to atomic", cType)
+		return
+	}
+	obj = &goast.IndexExpr{X: obj, Index: util.NewIntLit(0)}
+	return
+}
+
+// atomicArithmetic return error, if arithmetic operation is not
+// supported for atomic type.
+func atomicArithmetic(p *program.Program, cBase string) error {
+	if types.IsCPointer(cBase, p) {
+		return fmt.Errorf("arithmetic of atomic pointer `%s` is not supported", cBase)
+	}
+	return nil
+}
+
+// transpileAtomicValue transpile value and cast to C type.
+func transpileAtomicValue(node ast.Node, cType string, p *program.Program) (
+	expr goast.Expr, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	expr, exprType, preStmts, postStmts, err := transpileToExpr(node, p, false)
+	if err != nil {
+		return
+	}
+	expr, err = types.CastExpr(p, expr, exprType, cType)
+	return
+}
+
+// atomicOperationName return name of atomic operation without prefix.
+// Name of operation is not shown by all versions of clang, so name is taken
+// from preprocessor C code.
+//
+//	AtomicExpr 0x55d7a1e0c9e8 <line:8:5, col:53> 'int'
+func atomicOperationName(n *ast.AtomicExpr, p *program.Program) (name string, err error) {
+	name = n.Name
+	if name == "" {
+		pos := n.Position()
+		var buffer []byte
+		buffer, err = p.PreprocessorFile.GetSnippet(pos.File,
+			pos.Line, pos.LineEnd,
+			pos.Column, pos.ColumnEnd)
+		if err != nil {
+			err = fmt.Errorf("cannot found snippet position is %v. %v",
+				n.Position(), err)
+			return
+		}
+		index := bytes.IndexByte(buffer, '(')
+		if index < 0 {
+			err = fmt.Errorf("cannot found name of operation in `%s`", string(buffer))
+			return
+		}
+		name = string(bytes.TrimSpace(buffer[:index]))
+	}
+	if !strings.HasPrefix(name, "__c11_atomic_") {
+		err = fmt.Errorf("atomic operation `%s` is not supported", name)
+		return
+	}
+	return name[len("__c11_atomic_"):], nil
+}
+
+// transpileAtomicExpr transpile C11 atomic operation.
+//
+// Children of AtomicExpr are: pointer to atomic value, memory order,
+// value, memory order for fail, second value.
+//
+//	C  : atomic_fetch_add(&counter, 1)
+//	AST:
+//	AtomicExpr 'int'
+//	|-UnaryOperator '_Atomic(int) *' prefix '&'
+//	| `-DeclRefExpr 'atomic_int':'_Atomic(int)' lvalue Var 'counter' 'atomic_int':'_Atomic(int)'
+//	|-IntegerLiteral 'int' 5
+//	`-IntegerLiteral 'int' 1
+func transpileAtomicExpr(n *ast.AtomicExpr, p *program.Program) (
+	expr goast.Expr, exprType string, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpile AtomicExpr : %v", err)
+		}
+	}()
+
+	name, err := atomicOperationName(n, p)
+	if err != nil {
+		return
+	}
+
+	ch := n.Children()
+	if len(ch) < 2 {
+		err = fmt.Errorf("not enough children: %d", len(ch))
+		return
+	}
+
+	obj, cBase, preStmts, postStmts, err := transpileAtomicPointer(ch[0], p)
+	if err != nil {
+		return
+	}
+	exprType = n.Type
+
+	// value of operation
+	value := func(i int) (goast.Expr, error) {
+		if len(ch) <= i {
+			return nil, fmt.Errorf("not enough children for `%s`: %d", name, len(ch))
+		}
+		v, newPre, newPost, err := transpileAtomicValue(ch[i], cBase, p)
+		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+		return v, err
+	}
+
+	var v goast.Expr
+	switch name {
+	case "init":
+		// value is in place of memory order
+		if v, err = value(1); err != nil {
+			return
+		}
+		expr = atomicMethod(obj, "Store", v)
+
+	case "load":
+		expr = atomicMethod(obj, "Load")
+		exprType = cBase
+
+	case "store":
+		if v, err = value(2); err != nil {
+			return
+		}
+		expr = atomicMethod(obj, "Store", v)
+
+	case "exchange":
+		if v, err = value(2); err != nil {
+			return
+		}
+		expr = atomicMethod(obj, "Swap", v)
+		exprType = cBase
+
+	case "fetch_and", "fetch_or", "fetch_xor":
+		if err = atomicArithmetic(p, cBase); err != nil {
+			return
+		}
+		if v, err = value(2); err != nil {
+			return
+		}
+		op := map[string]token.Token{
+			"fetch_and": token.AND,
+			"fetch_or":  token.OR,
+			"fetch_xor": token.XOR,
+		}[name]
+		expr, err = atomicCall(p, atomicFetchOpFunction(op), cBase, addressOf(obj), v)
+		exprType = cBase
+
+	case "fetch_add", "fetch_sub":
+		if err = atomicArithmetic(p, cBase); err != nil {
+			return
+		}
+		if v, err = value(2); err != nil {
+			return
+		}
+		expr, err = atomicCall(p, atomicFunctions[name], cBase, addressOf(obj), v)
+		exprType = cBase
+
+	case "compare_exchange_strong", "compare_exchange_weak":
+		var expected goast.Expr
+		var newPre, newPost []goast.Stmt
+		if len(ch) < 5 {
+			err = fmt.Errorf("not enough children for `%s`: %d", name, len(ch))
+			return
+		}
+		expected, _, newPre, newPost, err = transpileToExpr(ch[2], p, false)
+		if err != nil {
+			return
+		}
+		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+		if v, err = value(4); err != nil {
+			return
+		}
+		if types.IsCPointer(cBase, p) {
+			// pointers cannot be compared in Go
+			expr = atomicMethod(obj, "CompareExchange", expected, v)
+		} else {
+			expr, err = atomicCall(p, atomicFunctions["compare_exchange"], cBase,
+				addressOf(obj), expected, v)
+			if err != nil {
+				return
+			}
+		}
+		expr, err = types.CastExpr(p, expr, "bool", n.Type)
+
+	default:
+		err = fmt.Errorf("atomic operation `%s` is not supported", name)
+	}
+	return
+}
+
+// transpileAtomicToNonAtomic transpile load of atomic value.
+//
+//	ImplicitCastExpr 'int' <AtomicToNonAtomic>
+//	`-ImplicitCastExpr 'atomic_int':'_Atomic(int)' <LValueToRValue>
+//	  `-DeclRefExpr 'atomic_int':'_Atomic(int)' lvalue Var 'counter' 'atomic_int':'_Atomic(int)'
+func transpileAtomicToNonAtomic(n *ast.ImplicitCastExpr, p *program.Program) (
+	expr goast.Expr, exprType string, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	obj, cBase, preStmts, postStmts, err := transpileAtomicObject(n.Children()[0], p)
+	if err != nil {
+		return
+	}
+	return atomicMethod(obj, "Load"), cBase, preStmts, postStmts, nil
+}
+
+// transpileAtomicAssign transpile assignment of atomic value.
+//
+//	C  : counter = 5
+//	Go : counter.Store(5)
+//
+//	C  : counter *= 5
+//	Go : func(c4go_atomic *atomic.Int32, c4go_value int32) int32 {
+//		...
+//	}(&counter, 5)
+func transpileAtomicAssign(left, right ast.Node, operator token.Token,
+	p *program.Program, exprIsStmt bool) (
+	expr goast.Expr, exprType string, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpile assignment of atomic value : %v", err)
+		}
+	}()
+
+	obj, cBase, preStmts, postStmts, err := transpileAtomicObject(left, p)
+	if err != nil {
+		return
+	}
+	v, newPre, newPost, err := transpileAtomicValue(right, cBase, p)
+	if err != nil {
+		return
+	}
+	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+	exprType = cBase
+	if operator != token.ASSIGN {
+		if err = atomicArithmetic(p, cBase); err != nil {
+			return
+		}
+	}
+
+	switch operator {
+	case token.ASSIGN:
+		if exprIsStmt {
+			expr = atomicMethod(obj, "Store", v)
+			return
+		}
+		expr, err = atomicCall(p, atomicFunctions["store_fetch"], cBase, addressOf(obj), v)
+	case token.ADD_ASSIGN:
+		expr = atomicMethod(obj, "Add", v)
+	case token.SUB_ASSIGN:
+		expr, err = atomicCall(p, atomicFunctions["sub_fetch"], cBase, addressOf(obj), v)
+	default:
+		expr, err = atomicCall(p, atomicOpFunction(convertToWithoutAssign(operator)),
+			cBase, addressOf(obj), v)
+	}
+	return
+}
+
+// transpileAtomicInc transpile increment and decrement of atomic value.
+//
+//	C  : counter++
+//	Go : func(c4go_atomic *atomic.Int32, c4go_value int32) int32 {
+//		return c4go_atomic.Add(c4go_value) - c4go_value
+//	}(&counter, 1)
+func transpileAtomicInc(n *ast.UnaryOperator, p *program.Program) (
+	expr goast.Expr, exprType string, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	obj, cBase, preStmts, postStmts, err := transpileAtomicObject(n.Children()[0], p)
+	if err != nil {
+		return
+	}
+	if err = atomicArithmetic(p, cBase); err != nil {
+		return
+	}
+	one, err := types.CastExpr(p, util.NewIntLit(1), "int", cBase)
+	if err != nil {
+		return
+	}
+	exprType = cBase
+
+	switch {
+	case n.Operator == "++" && n.IsPrefix:
+		expr = atomicMethod(obj, "Add", one)
+	case n.Operator == "--" && n.IsPrefix:
+		expr, err = atomicCall(p, atomicFunctions["sub_fetch"], cBase, addressOf(obj), one)
+	case n.Operator == "++":
+		expr, err = atomicCall(p, atomicFunctions["fetch_add"], cBase, addressOf(obj), one)
+	default:
+		expr, err = atomicCall(p, atomicFunctions["fetch_sub"], cBase, addressOf(obj), one)
+	}
+	return
+}
+
+// isAtomicNode return true, if node has atomic type.
+func isAtomicNode(node ast.Node, p *program.Program) bool {
+	t, ok := ast.GetTypeIfExist(node)
+	return ok && types.IsCAtomic(p, *t)
+}
+
+// initAtomicValue return initialization of atomic variable. Values of
+// package "sync/atomic" must not be copied, so value is stored by pointer
+// to variable.
+//
+//	C  : atomic_int counter = 5;
+//	Go : var counter atomic.Int32
+//	     var _ = func(c4go_atomic *atomic.Int32, c4go_value int32) struct{} {
+//	     	c4go_atomic.Store(c4go_value)
+//	     	return struct{}{}
+//	     }(&counter, 5)
+//
+// Initialization of `atomic_flag` is list with one value:
+//
+//	C  : atomic_flag flag = ATOMIC_FLAG_INIT; // { 0 }
+func initAtomicValue(node ast.Node, name string, cType string, p *program.Program) (
+	expr goast.Expr, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	cBase, _ := types.GetAtomicBaseType(p, cType)
+	if list, ok := node.(*ast.InitListExpr); ok && len(list.Children()) == 1 {
+		node = list.Children()[0]
+	}
+	v, preStmts, postStmts, err := transpileAtomicValue(node, cBase, p)
+	if err != nil {
+		return
+	}
+	expr, err = atomicCall(p, atomicFunctions["init"], cBase,
+		addressOf(util.NewIdent(name)), v)
+	return
+}
@@ -0,0 +1,335 @@
+package transpiler
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+)
+
+func TestAtomic(t *testing.T) {
+	p := program.NewProgram()
+	p.TypedefType["atomic_uint"] = "_Atomic(unsigned int)"
+
+	counter := func() ast.Node {
+		return &ast.DeclRefExpr{Name: "counter", Type: "atomic_int", Type2: "_Atomic(int)"}
+	}
+	p.TypedefType["atomic_int"] = "_Atomic(int)"
+	p.TypedefType["atomic_flag"] = "struct atomic_flag"
+	literal := func(v string) ast.Node {
+		return &ast.IntegerLiteral{Type: "int", Value: v}
+	}
+	head := func() ast.Node {
+		return &ast.DeclRefExpr{Name: "head", Type: "_Atomic(struct node *)"}
+	}
+	flag := func() ast.Node {
+		return &ast.DeclRefExpr{Name: "flag", Type: "atomic_flag", Type2: "struct atomic_flag"}
+	}
+
+	tcs := []struct {
+		node ast.Node
+		code []string
+	}{
+		{
+			node: &ast.ImplicitCastExpr{
+				Kind: "AtomicToNonAtomic",
+				Type: "int",
+				ChildNodes: []ast.Node{&ast.ImplicitCastExpr{
+					Kind:       "LValueToRValue",
+					Type:       "atomic_int",
+					ChildNodes: []ast.Node{counter()},
+				}},
+			},
+			code: []string{"counter.Load()"},
+		},
+		{
+			node: &ast.UnaryOperator{
+				Type:       "int",
+				Operator:   "++",
+				ChildNodes: []ast.Node{counter()},
+			},
+			code: []string{
+				"func(c4go_atomic *atomic.Int32, c4go_value int32) int32",
+				"return c4go_atomic.Add(c4go_value) - c4go_value",
+				"(&counter, 1)",
+			},
+		},
+		{
+			node: &ast.UnaryOperator{
+				Type:       "int",
+				Operator:   "++",
+				IsPrefix:   true,
+				ChildNodes: []ast.Node{counter()},
+			},
+			code: []string{"counter.Add(1)"},
+		},
+		{
+			node: &ast.CompoundAssignOperator{
+				Type:       "atomic_int",
+				Opcode:     "*=",
+				ChildNodes: []ast.Node{counter(), literal("3")},
+			},
+			code: []string{
+				"value := old * c4go_value",
+				"c4go_atomic.CompareAndSwap(old, value)",
+				"(&counter, 3)",
+			},
+		},
+		{
+			node: &ast.BinaryOperator{
+				Type:     "atomic_int",
+				Operator: "=",
+				ChildNodes: []ast.Node{counter(), &ast.ImplicitCastExpr{
+					Kind:       "NonAtomicToAtomic",
+					Type:       "_Atomic(int)",
+					ChildNodes: []ast.Node{literal("5")},
+				}},
+			},
+			code: []string{"counter.Store(5)"},
+		},
+		{
+			node: &ast.AtomicExpr{
+				Type: "int",
+				Name: "__c11_atomic_fetch_or",
+				ChildNodes: []ast.Node{
+					&ast.UnaryOperator{
+						Type:       "_Atomic(int) *",
+						Operator:   "&",
+						IsPrefix:   true,
+						ChildNodes: []ast.Node{counter()},
+					},
+					literal("5"),
+					literal("4"),
+				},
+			},
+			code: []string{
+				"c4go_atomic.CompareAndSwap(old, old|c4go_value)",
+				"return old",
+				"(&counter, 4)",
+			},
+		},
+		{
+			node: &ast.AtomicExpr{
+				Type: "int",
+				Name: "__c11_atomic_fetch_sub",
+				ChildNodes: []ast.Node{
+					&ast.ImplicitCastExpr{
+						Kind: "LValueToRValue",
+						Type: "_Atomic(int) *",
+						ChildNodes: []ast.Node{&ast.DeclRefExpr{
+							Name: "ptr",
+							Type: "_Atomic(int) *",
+						}},
+					},
+					literal("5"),
+					literal("4"),
+				},
+			},
+			code: []string{
+				"return c4go_atomic.Add(-c4go_value) + c4go_value",
+				"(&ptr[0], 4)",
+			},
+		},
+		{
+			node: &ast.AtomicExpr{
+				Type: "_Bool",
+				Name: "__c11_atomic_compare_exchange_strong",
+				ChildNodes: []ast.Node{
+					&ast.UnaryOperator{
+						Type:       "_Atomic(struct node *) *",
+						Operator:   "&",
+						IsPrefix:   true,
+						ChildNodes: []ast.Node{head()},
+					},
+					literal("5"),
+					&ast.DeclRefExpr{Name: "expected", Type: "struct node **"},
+					literal("5"),
+					&ast.DeclRefExpr{Name: "n", Type: "struct node *"},
+				},
+			},
+			code: []string{"head.CompareExchange(expected, n)"},
+		},
+		{
+			// atomic_flag_test_and_set(&flag)
+			node: &ast.AtomicExpr{
+				Type: "_Bool",
+				Name: "__c11_atomic_exchange",
+				ChildNodes: []ast.Node{
+					&ast.UnaryOperator{
+						Type:     "atomic_bool *",
+						Operator: "&",
+						IsPrefix: true,
+						ChildNodes: []ast.Node{&ast.MemberExpr{
+							Type:      "atomic_bool",
+							Name:      "_Value",
+							IsPointer: true,
+							ChildNodes: []ast.Node{&ast.ParenExpr{
+								Type: "atomic_flag *",
+								ChildNodes: []ast.Node{&ast.UnaryOperator{
+									Type:       "atomic_flag *",
+									Operator:   "&",
+									IsPrefix:   true,
+									ChildNodes: []ast.Node{flag()},
+								}},
+							}},
+						}},
+					},
+					literal("5"),
+					literal("1"),
+				},
+			},
+			code: []string{"flag.Swap(int32((1)))"},
+		},
+		{
+			// atomic_flag_clear(&flag)
+			node: &ast.AtomicExpr{
+				Type: "void",
+				Name: "__c11_atomic_store",
+				ChildNodes: []ast.Node{
+					&ast.UnaryOperator{
+						Type:     "atomic_bool *",
+						Operator: "&",
+						IsPrefix: true,
+						ChildNodes: []ast.Node{&ast.MemberExpr{
+							Type:       "atomic_bool",
+							Name:       "_Value",
+							ChildNodes: []ast.Node{flag()},
+						}},
+					},
+					literal("5"),
+					literal("0"),
+				},
+			},
+			code: []string{"flag.Store(int32((0)))"},
+		},
+		{
+			node: &ast.UnaryOperator{
+				Type:       "signed char",
+				Operator:   "--",
+				IsPrefix:   true,
+				ChildNodes: []ast.Node{&ast.DeclRefExpr{Name: "c", Type: "_Atomic(signed char)"}},
+			},
+			code: []string{
+				"func(c4go_atomic *noarch.AtomicInt8, c4go_value int8) int8",
+				"(&c, int8(1))",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		expr, _, _, _, err := transpileToExpr(tc.node, p, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+			t.Fatal(err)
+		}
+		code := buf.String()
+		for _, part := range tc.code {
+			if !strings.Contains(code, part) {
+				t.Errorf("cannot find %q in code:\n%s", part, code)
+			}
+		}
+	}
+
+	t.Run("pointer arithmetic", func(t *testing.T) {
+		_, _, _, _, err := transpileToExpr(&ast.UnaryOperator{
+			Type:       "struct node *",
+			Operator:   "++",
+			ChildNodes: []ast.Node{head()},
+		}, program.NewProgram(), true)
+		if err == nil || !strings.Contains(err.Error(), "arithmetic of atomic pointer") {
+			t.Errorf("not valid error: %v", err)
+		}
+	})
+
+	t.Run("atomic_flag", func(t *testing.T) {
+		decls, _, err := transpileVarDecl(p, &ast.VarDecl{
+			Name: "flag",
+			Type: "atomic_flag",
+			ChildNodes: []ast.Node{&ast.InitListExpr{
+				Type1:      "atomic_flag",
+				ChildNodes: []ast.Node{literal("0")},
+			}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		for _, d := range decls {
+			if err := format.Node(&buf, token.NewFileSet(), d); err != nil {
+				t.Fatal(err)
+			}
+		}
+		code := buf.String()
+		for _, part := range []string{
+			"var flag atomic.Int32",
+			"(&flag, int32((0)))",
+		} {
+			if !strings.Contains(code, part) {
+				t.Errorf("cannot find %q in code:\n%s", part, code)
+			}
+		}
+	})
+
+	t.Run("initialization", func(t *testing.T) {
+		decls, _, err := transpileVarDecl(p, &ast.VarDecl{
+			Name:       "counter",
+			Type:       "atomic_int",
+			ChildNodes: []ast.Node{literal("5")},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		for _, d := range decls {
+			if err := format.Node(&buf, token.NewFileSet(), d); err != nil {
+				t.Fatal(err)
+			}
+			buf.WriteString("\n")
+		}
+		code := buf.String()
+		for _, part := range []string{
+			"var counter atomic.Int32\n",
+			"var _ = func(c4go_atomic *atomic.Int32, c4go_value int32) struct{}",
+			"(&counter, 5)",
+		} {
+			if !strings.Contains(code, part) {
+				t.Errorf("cannot find %q in code:\n%s", part, code)
+			}
+		}
+	})
+
+	for cType, goType := range map[string]string{
+		"_Atomic(int)":                "atomic.Int32",
+		"_Atomic(_Bool)":              "atomic.Int32",
+		"atomic_uint":                 "atomic.Uint32",
+		"_Atomic(long long)":          "atomic.Int64",
+		"_Atomic(unsigned long long)": "atomic.Uint64",
+		"_Atomic(int) *":              "[]atomic.Int32",
+		"_Atomic(char)":               "noarch.AtomicUint8",
+		"_Atomic(signed char)":        "noarch.AtomicInt8",
+		"_Atomic(unsigned char)":      "noarch.AtomicUint8",
+		"_Atomic(short)":              "noarch.AtomicInt16",
+		"_Atomic(unsigned short)":     "noarch.AtomicUint16",
+		"_Atomic(struct node *)":      "noarch.AtomicPointer[[]node]",
+		"_Atomic(void *)":             "noarch.AtomicPointer[interface{}]",
+		"_Atomic(FILE *)":             "noarch.AtomicPointer[*noarch.File]",
+		"atomic_flag":                 "atomic.Int32",
+		"struct atomic_flag":          "atomic.Int32",
+	} {
+		r, err := types.ResolveType(p, cType)
+		if err != nil || r != goType {
+			t.Errorf("type %s: %s != %s. %v", cType, r, goType, err)
+		}
+	}
+	if size, err := types.SizeOf(p, "atomic_uint"); err != nil || size != 4 {
+		t.Errorf("not valid size of atomic type: %d. %v", size, err)
+	}
+}
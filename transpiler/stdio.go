@@ -0,0 +1,16 @@
+package transpiler
+
+import "github.com/Konstantin8105/c4go/program"
+
+// getStdioVariable returns declaration of package variable with standard
+// streams of program. All functions of "stdio.h" without stream argument,
+// like printf or getchar, and variables stdin, stdout, stderr are
+// transpiled to usage of that variable.
+func getStdioVariable() string {
+	return `
+
+// ` + program.StdioVariable + ` - standard streams of program. Replace it by
+// noarch.NewStdio for redirection of input and output of program.
+var ` + program.StdioVariable + ` = noarch.DefaultStdio
+`
+}
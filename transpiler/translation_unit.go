@@ -5,6 +5,7 @@ import (
 
 	"github.com/Konstantin8105/c4go/ast"
 	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/util"
 	errorTree "github.com/Konstantin8105/errors"
 )
 
@@ -20,6 +21,11 @@ func transpileTranslationUnitDecl(p *program.Program, n *ast.TranslationUnitDecl
 			continue
 		}
 		decls = append(decls, ds...)
+		if p.IsUnitFile(childs[i].Position().File) {
+			for _, d := range ds {
+				p.AddUnitNames(util.GetDeclNames(d)...)
+			}
+		}
 	}
 	if et.IsError() {
 		err = et
@@ -99,6 +99,11 @@ func TranspileAST(fileName, packageName string, withOutsideStructs bool,
 		replacer(root)
 	}
 
+	// internal functions and variables of library are not exported
+	if p.LibraryMode {
+		unexportLibraryNames(p, root)
+	}
+
 	// Now begin building the Go AST.
 	decls, err := transpileToNode(root, p)
 	if err != nil {
@@ -108,6 +113,11 @@ func TranspileAST(fileName, packageName string, withOutsideStructs bool,
 	}
 	p.File.Decls = append(p.File.Decls, decls...)
 
+	// add constants from object-like macros and functions from
+	// function-like macros
+	p.File.Decls = append(append(transpileMacroConstants(p),
+		transpileMacroFunctions(p)...), p.File.Decls...)
+
 	// only for "stdbool.h"
 	if p.IncludeHeaderIsExists("stdbool.h") {
 		p.File.Decls = append(p.File.Decls, &goast.GenDecl{
@@ -130,6 +140,18 @@ func TranspileAST(fileName, packageName string, withOutsideStructs bool,
 	// checking implementation for all called functions
 	bindHeader, bindCode := generateBinding(p, clangFlags)
 
+	// standard streams of program
+	if p.IsHaveStdio {
+		p.AddImport("github.com/Konstantin8105/c4go/noarch")
+	}
+
+	// exported functions of library
+	var library string
+	if p.LibraryMode {
+		library = getLibraryFunctions(p, packageName,
+			getPackageNames(p.File, std, bindCode))
+	}
+
 	// Add the imports after everything else so we can ensure that they are all
 	// placed at the top.
 	for _, quotedImportPath := range p.Imports() {
@@ -172,6 +194,12 @@ func TranspileAST(fileName, packageName string, withOutsideStructs bool,
 		source += getVaListStruct()
 	}
 
+	if p.IsHaveStdio {
+		source += getStdioVariable()
+	}
+
+	source += library
+
 	// generate pointer arithmetic functions
 	source += getPointerArithFunctions(p)
 
@@ -198,12 +226,24 @@ func transpileToExpr(node ast.Node, p *program.Program, exprIsStmt bool) (
 		postStmts = nilFilterStmts(postStmts)
 	}()
 
+	if p.MacroFunctionMode {
+		var ok bool
+		expr, exprType, preStmts, postStmts, ok = transpileMacroExpansion(node, p)
+		if ok {
+			return
+		}
+	}
+
 	switch n := node.(type) {
 	case *ast.StringLiteral:
 		expr, exprType, err = transpileStringLiteral(p, n, false)
 		return
 
 	case *ast.FloatingLiteral:
+		var ok bool
+		if expr, exprType, ok = transpileMacroLiteral(n, p); ok {
+			return
+		}
 		expr, exprType, err = transpileFloatingLiteral(n), "double", nil
 
 	case *ast.PredefinedExpr:
@@ -233,7 +273,14 @@ func transpileToExpr(node ast.Node, p *program.Program, exprIsStmt bool) (
 	case *ast.DeclRefExpr:
 		expr, exprType, err = transpileDeclRefExpr(n, p)
 
+	case *ast.ImaginaryLiteral:
+		expr, exprType, preStmts, postStmts, err = transpileImaginaryLiteral(n, p)
+
 	case *ast.IntegerLiteral:
+		var ok bool
+		if expr, exprType, ok = transpileMacroLiteral(n, p); ok {
+			return
+		}
 		expr, exprType, err = transpileIntegerLiteral(n), "int", nil
 
 	case *ast.ParenExpr:
@@ -243,9 +290,19 @@ func transpileToExpr(node ast.Node, p *program.Program, exprIsStmt bool) (
 		expr, exprType, preStmts, postStmts, err = transpileCStyleCastExpr(n, p, exprIsStmt)
 
 	case *ast.CharacterLiteral:
+		var ok bool
+		if expr, exprType, ok = transpileMacroLiteral(n, p); ok {
+			return
+		}
 		expr, exprType, err = transpileCharacterLiteral(n), "char", nil
 
 	case *ast.CallExpr:
+		if isSetjmpCall(n, p) {
+			// result of setjmp is parameter of body function
+			// see function transpileSetjmp
+			expr, exprType = util.NewIdent(setjmpValueName), "int"
+			break
+		}
 		expr, exprType, preStmts, postStmts, err = transpileCallExpr(n, p)
 
 	case *ast.CompoundAssignOperator:
@@ -269,6 +326,12 @@ func transpileToExpr(node ast.Node, p *program.Program, exprIsStmt bool) (
 	case *ast.OffsetOfExpr:
 		expr, exprType, err = transpileOffsetOfExpr(n, p)
 
+	case *ast.AtomicExpr:
+		expr, exprType, preStmts, postStmts, err = transpileAtomicExpr(n, p)
+
+	case *ast.GenericSelectionExpr:
+		expr, exprType, preStmts, postStmts, err = transpileGenericSelectionExpr(n, p)
+
 	case *ast.VAArgExpr:
 		expr, exprType, preStmts, postStmts, err = transpileVAArgExpr(n, p)
 
@@ -358,6 +421,11 @@ func transpileToStmt(node ast.Node, p *program.Program) (
 				X: goast.NewIdent(cg.List[i].Text),
 			})
 		}
+		if marker := p.GetLineMarker(node.Position()); marker != "" {
+			preStmts = append([]goast.Stmt{&goast.ExprStmt{
+				X: goast.NewIdent(marker),
+			}}, preStmts...)
+		}
 	}()
 
 	var expr goast.Expr
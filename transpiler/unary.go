@@ -587,6 +587,11 @@ func transpileUnaryOperator(n *ast.UnaryOperator, p *program.Program) (
 		}
 	}()
 
+	// GNU extension for complex types
+	if n.Operator == "__real" || n.Operator == "__imag" {
+		return transpileComplexPart(n, p)
+	}
+
 	operator, err := getTokenForOperator(n.Operator)
 	if err != nil {
 		err = nil
@@ -601,6 +606,9 @@ func transpileUnaryOperator(n *ast.UnaryOperator, p *program.Program) (
 		// *(t + 1) = ...
 		return transpilePointerArith(n, p)
 	case token.INC, token.DEC: // ++, --
+		if isAtomicNode(n.Children()[0], p) {
+			return transpileAtomicInc(n, p)
+		}
 		return transpileUnaryOperatorInc(n, p, operator)
 	case token.NOT: // !
 		return transpileUnaryOperatorNot(n, p)
@@ -641,7 +649,7 @@ func transpileUnaryOperator(n *ast.UnaryOperator, p *program.Program) (
 }
 
 func transpileUnaryExprOrTypeTraitExpr(n *ast.UnaryExprOrTypeTraitExpr, p *program.Program) (
-	*goast.BasicLit, string, []goast.Stmt, []goast.Stmt, error) {
+	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
 	t := n.Type2
 
 	// It will have children if the sizeof() is referencing a variable.
@@ -654,6 +662,15 @@ func transpileUnaryExprOrTypeTraitExpr(n *ast.UnaryExprOrTypeTraitExpr, p *progr
 		}
 	}
 
+	// size of variable length array is calculated in runtime
+	if n.Function == "sizeof" && types.IsVariableArray(t) {
+		expr, preStmts, postStmts, err := transpileVariableArraySizeof(n, p, t)
+		if err != nil {
+			return nil, "", nil, nil, err
+		}
+		return expr, n.Type1, preStmts, postStmts, nil
+	}
+
 	sizeInBytes, err := types.SizeOf(p, t)
 	if err != nil {
 		p.AddMessage(p.GenerateWarningMessage(err, n))
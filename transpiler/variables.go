@@ -36,7 +36,9 @@ func transpileDeclRefExpr(n *ast.DeclRefExpr, p *program.Program) (
 		p.AddMessage(p.GenerateWarningMessage(err, n))
 		if includeFile != "" && p.IncludeHeaderIsExists(includeFile) {
 			name := p.GetFunctionDefinition(n.Name).Substitution
-			if strings.Contains(name, ".") && !strings.Contains(name, "github") {
+			if strings.HasPrefix(name, program.StdioVariable+".") {
+				name = p.ImportType(name)
+			} else if strings.Contains(name, ".") && !strings.Contains(name, "github") {
 				p.AddImport(strings.Split(name, ".")[0])
 			}
 			return goast.NewIdent(name), n.Type, nil
@@ -60,6 +62,11 @@ func getDefaultValueForVar(p *program.Program, a *ast.VarDecl) (
 		return nil, "", nil, nil, nil
 	}
 
+	// atomic variable is initialized in function atomicInitialization
+	if types.IsCAtomic(p, a.Type) {
+		return nil, "", nil, nil, nil
+	}
+
 	defaultValue, defaultValueType, newPre, newPost, err := atomicOperation(a.Children()[0], p)
 	if err != nil {
 		return nil, defaultValueType, newPre, newPost, err
@@ -154,6 +161,14 @@ func transpileInitListExpr(e *ast.InitListExpr, p *program.Program) (
 	e.Type2 = util.GenerateCorrectType(e.Type2)
 	exprType = e.Type1
 
+	if program.DefinitionZeroInitType[e.Type1] {
+		goType, err := types.ResolveType(p, e.Type1)
+		if err != nil {
+			return nil, "", err
+		}
+		return &goast.CompositeLit{Type: goast.NewIdent(goType)}, exprType, nil
+	}
+
 	for _, node := range e.Children() {
 		// Skip ArrayFiller
 		if _, ok := node.(*ast.ArrayFiller); ok {
@@ -245,6 +260,12 @@ func transpileInitListExpr(e *ast.InitListExpr, p *program.Program) (
 		}
 	}
 
+	// struct with bit-fields or padding fields cannot be initialized
+	// by positional composite literal
+	if isStruct && (len(structType.BitFields) > 0 || p.CLayout) {
+		return initBitFieldStruct(structType, goType, resp), exprType, nil
+	}
+
 	if len(resp) == 1 && goType == "[]byte" {
 		return resp[0], exprType, nil
 	}
@@ -527,6 +548,18 @@ func transpileMemberExpr(n *ast.MemberExpr, p *program.Program) (
 		rhs = "anon"
 	}
 
+	// bit-field getter
+	if structType != nil {
+		if _, ok := structType.BitFields[n.Name]; ok {
+			return &goast.CallExpr{
+				Fun: &goast.SelectorExpr{
+					X:   x,
+					Sel: util.NewIdent(rhs),
+				},
+			}, n.Type, preStmts, postStmts, nil
+		}
+	}
+
 	if isUnionMemberExpr(p, n) {
 		return &goast.ParenExpr{
 			Lparen: 1,
@@ -0,0 +1,493 @@
+package transpiler
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// vlaSizes returns Go expressions of sizes of variable length array.
+// Sizes are taken from C type, because clang does not show size
+// expression of variable length array in AST.
+func vlaSizes(p *program.Program, cType string) (baseType string, sizes []string, err error) {
+	baseType, cSizes, ok := types.GetVariableArraySizes(cType)
+	if !ok {
+		err = fmt.Errorf("type `%s` is not variable length array", cType)
+		return
+	}
+	for _, size := range cSizes {
+		var s string
+		if s, err = transpileVlaSize(p, size); err != nil {
+			err = fmt.Errorf("cannot transpile size `%s` of variable length array: %v",
+				size, err)
+			return
+		}
+		sizes = append(sizes, s)
+	}
+	return
+}
+
+// vlaValue is Go expression of size of variable length array.
+type vlaValue struct {
+	src      string
+	constant bool // untyped constant
+	integer  bool // value of type int
+	binary   bool // binary expression
+}
+
+// operand returns source of value for operand of binary expression.
+func (v vlaValue) operand() string {
+	if v.binary {
+		return "(" + v.src + ")"
+	}
+	return v.src
+}
+
+// toInt returns source of value converted to type int.
+func (v vlaValue) toInt() string {
+	if v.constant || v.integer {
+		return v.operand()
+	}
+	return "int(" + v.src + ")"
+}
+
+// vlaParser is parser of C expression of size of variable length array.
+// Only integer expressions of literals, variables, members, elements of
+// arrays, `sizeof` of types and calls of functions are supported. Types of
+// variables are unknown, so operands of binary expressions are converted
+// to type int, if types of operands may be different.
+//
+//	C  : n
+//	Go : n
+//
+//	C  : strlen(s) + 1u
+//	Go : noarch.Strlen(s) + 1
+//
+//	C  : p->n * m
+//	Go : int(p[0].n) * int(m)
+type vlaParser struct {
+	p      *program.Program
+	tokens []string
+	pos    int
+}
+
+// vlaTokens returns tokens of C expression.
+func vlaTokens(expr string) (tokens []string, err error) {
+	operators := []string{"->", "<<", ">>",
+		"+", "-", "*", "/", "%", "&", "|", "^", "~", "(", ")", "[", "]", ".", ","}
+	isWord := func(c byte) bool {
+		return c == '_' || '0' <= c && c <= '9' ||
+			'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+	}
+	for i := 0; i < len(expr); {
+		if expr[i] == ' ' || expr[i] == '\t' {
+			i++
+			continue
+		}
+		if isWord(expr[i]) {
+			j := i
+			for j < len(expr) && isWord(expr[j]) {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+			continue
+		}
+		found := false
+		for _, op := range operators {
+			if strings.HasPrefix(expr[i:], op) {
+				tokens = append(tokens, op)
+				i += len(op)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("not supported symbol `%c`", expr[i])
+		}
+	}
+	return
+}
+
+// transpileVlaSize returns Go expression of C expression of size of
+// variable length array.
+func transpileVlaSize(p *program.Program, size string) (_ string, err error) {
+	tokens, err := vlaTokens(size)
+	if err != nil {
+		return
+	}
+	vp := vlaParser{p: p, tokens: tokens}
+	v, err := vp.binary(0)
+	if err != nil {
+		return
+	}
+	if vp.pos != len(vp.tokens) {
+		return "", fmt.Errorf("unexpected `%s`", vp.tokens[vp.pos])
+	}
+	if _, err = parser.ParseExpr(v.src); err != nil {
+		return
+	}
+	return v.src, nil
+}
+
+func (vp *vlaParser) peek() string {
+	if vp.pos < len(vp.tokens) {
+		return vp.tokens[vp.pos]
+	}
+	return ""
+}
+
+func (vp *vlaParser) next() string {
+	t := vp.peek()
+	vp.pos++
+	return t
+}
+
+func (vp *vlaParser) expect(t string) error {
+	if next := vp.next(); next != t {
+		return fmt.Errorf("expected `%s` instead of `%s`", t, next)
+	}
+	return nil
+}
+
+// vlaPrecedences - precedences of C binary operators
+var vlaPrecedences = map[string]int{
+	"|": 1, "^": 2, "&": 3, "<<": 4, ">>": 4, "+": 5, "-": 5, "*": 6, "/": 6, "%": 6,
+}
+
+// binary parses binary expression with operators of precedence more than
+// prec.
+func (vp *vlaParser) binary(prec int) (v vlaValue, err error) {
+	if v, err = vp.unary(); err != nil {
+		return
+	}
+	for {
+		op := vp.peek()
+		opPrec, ok := vlaPrecedences[op]
+		if !ok || opPrec <= prec {
+			return
+		}
+		vp.next()
+		var right vlaValue
+		if right, err = vp.binary(opPrec); err != nil {
+			return
+		}
+		switch {
+		case v.constant && right.constant:
+			v = vlaValue{src: v.operand() + " " + op + " " + right.operand(), constant: true}
+		case v.constant || right.constant || op == "<<" || op == ">>":
+			// type of expression is type of not constant operand or
+			// type of left operand of shift
+			integer := v.integer
+			if v.constant {
+				integer = right.integer
+			}
+			v = vlaValue{src: v.operand() + " " + op + " " + right.operand(), integer: integer}
+		default:
+			v = vlaValue{src: v.toInt() + " " + op + " " + right.toInt(), integer: true}
+		}
+		v.binary = true
+	}
+}
+
+func (vp *vlaParser) unary() (v vlaValue, err error) {
+	switch op := vp.peek(); op {
+	case "-", "+", "~":
+		vp.next()
+		if v, err = vp.unary(); err != nil {
+			return
+		}
+		if op == "~" {
+			op = "^"
+		}
+		v.src = op + v.operand()
+		v.binary = false
+		return
+	}
+	return vp.postfix()
+}
+
+func (vp *vlaParser) postfix() (v vlaValue, err error) {
+	t := vp.next()
+	switch {
+	case t == "(":
+		if v, err = vp.binary(0); err != nil {
+			return
+		}
+		err = vp.expect(")")
+		return
+
+	case t == "sizeof":
+		return vp.sizeof()
+
+	case t != "" && '0' <= t[0] && t[0] <= '9':
+		return vlaLiteral(t)
+
+	case !isVlaIdentifier(t):
+		err = fmt.Errorf("unexpected `%s`", t)
+		return
+	}
+
+	if vp.peek() == "(" {
+		return vp.call(t)
+	}
+	v.src = util.NewIdent(t).Name
+	for {
+		switch op := vp.peek(); op {
+		case "->", ".":
+			vp.next()
+			field := vp.next()
+			if !isVlaIdentifier(field) {
+				err = fmt.Errorf("not valid field `%s`", field)
+				return
+			}
+			if op == "->" {
+				// pointer to struct is slice
+				v.src += "[0]"
+			}
+			v.src += "." + util.NewIdent(field).Name
+		case "[":
+			vp.next()
+			var index vlaValue
+			if index, err = vp.binary(0); err != nil {
+				return
+			}
+			if err = vp.expect("]"); err != nil {
+				return
+			}
+			v.src += "[" + index.src + "]"
+		default:
+			return
+		}
+	}
+}
+
+// isVlaIdentifier returns true, if token is identifier.
+func isVlaIdentifier(t string) bool {
+	return t != "" && (t[0] == '_' || 'a' <= t[0] && t[0] <= 'z' || 'A' <= t[0] && t[0] <= 'Z')
+}
+
+// vlaLiteral returns integer literal without suffixes, like `u` or `ul`.
+func vlaLiteral(t string) (v vlaValue, err error) {
+	lit := strings.TrimRight(t, "uUlL")
+	if _, err = strconv.ParseInt(lit, 0, 64); err != nil {
+		err = fmt.Errorf("not valid integer literal `%s`", t)
+		return
+	}
+	return vlaValue{src: lit, constant: true}, nil
+}
+
+// sizeof parses `sizeof(type)`. Size of type is constant.
+func (vp *vlaParser) sizeof() (v vlaValue, err error) {
+	if err = vp.expect("("); err != nil {
+		return
+	}
+	var parts []string
+	for depth := 1; ; {
+		t := vp.next()
+		switch t {
+		case "":
+			err = fmt.Errorf("not closed sizeof")
+			return
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+		parts = append(parts, t)
+	}
+	size, err := types.SizeOf(vp.p, strings.Join(parts, " "))
+	if err != nil {
+		return
+	}
+	return vlaValue{src: strconv.Itoa(size), constant: true}, nil
+}
+
+// call parses call of function. Function must be defined before and must
+// return integer value.
+func (vp *vlaParser) call(name string) (v vlaValue, err error) {
+	def := vp.p.GetFunctionDefinition(name)
+	if def == nil {
+		err = fmt.Errorf("function `%s` is not defined", name)
+		return
+	}
+	if !types.IsCInteger(vp.p, def.ReturnType) {
+		err = fmt.Errorf("function `%s` returns not integer type `%s`",
+			name, def.ReturnType)
+		return
+	}
+	function := util.NewIdent(name).Name
+	if def.Substitution != "" {
+		function = vp.p.ImportType(def.Substitution)
+	}
+	if err = vp.expect("("); err != nil {
+		return
+	}
+	var args []string
+	for vp.peek() != ")" {
+		if len(args) > 0 {
+			if err = vp.expect(","); err != nil {
+				return
+			}
+		}
+		var arg vlaValue
+		if arg, err = vp.binary(0); err != nil {
+			return
+		}
+		src := arg.src
+		if i := len(args); i < len(def.ArgumentTypes) &&
+			types.IsCInteger(vp.p, def.ArgumentTypes[i]) {
+			var goType string
+			if goType, err = types.ResolveType(vp.p, def.ArgumentTypes[i]); err != nil {
+				return
+			}
+			src = goType + "(" + src + ")"
+		}
+		args = append(args, src)
+	}
+	vp.next()
+	return vlaValue{src: function + "(" + strings.Join(args, ", ") + ")"}, nil
+}
+
+// transpileVariableArray returns allocation of variable length array.
+//
+// Example for type `double [n][m]`:
+//
+//	func() [][]float64 {
+//		c4go_vla := make([][]float64, n)
+//		for i := range c4go_vla {
+//			c4go_vla[i] = make([]float64, m)
+//		}
+//		return c4go_vla
+//	}()
+func transpileVariableArray(p *program.Program, cType string) (
+	expr goast.Expr, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpile variable length array: %v", err)
+		}
+	}()
+
+	baseType, sizes, err := vlaSizes(p, cType)
+	if err != nil {
+		return
+	}
+	goType, err := types.ResolveType(p, baseType)
+	if err != nil {
+		return
+	}
+
+	var allocate func(level int) string
+	allocate = func(level int) string {
+		sliceType := strings.Repeat("[]", len(sizes)-level) + goType
+		if level == len(sizes)-1 {
+			return fmt.Sprintf("make(%s, %s)", sliceType, sizes[level])
+		}
+		return fmt.Sprintf(`func() %[1]s {
+	c4go_vla := make(%[1]s, %[2]s)
+	for i := range c4go_vla {
+		c4go_vla[i] = %[3]s
+	}
+	return c4go_vla
+}()`, sliceType, sizes[level], allocate(level+1))
+	}
+
+	return parser.ParseExpr(allocate(0))
+}
+
+// transpileVariableArraySizeof returns size of variable length array in
+// bytes. Sizes of array are fixed by declaration, so lengths of dimensions
+// are taken from slices. Sizes of type are calculated once before
+// expression.
+//
+// Example of C code:
+//
+//	double a[n][m];
+//	sizeof(a);
+//	sizeof(int [n + 1]);
+//
+// Go code:
+//
+//	uint32(len(a)) * uint32(len(a[0])) * 8
+//
+//	c4go_vla_size0 := n + 1
+//	uint32(c4go_vla_size0) * 4
+func transpileVariableArraySizeof(n *ast.UnaryExprOrTypeTraitExpr,
+	p *program.Program, cType string) (
+	expr goast.Expr, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot transpile sizeof of variable length array: %v", err)
+		}
+	}()
+
+	baseType, sizes, err := vlaSizes(p, cType)
+	if err != nil {
+		return
+	}
+	baseSize, err := types.SizeOf(p, baseType)
+	if err != nil {
+		return
+	}
+	goType, err := types.ResolveType(p, n.Type1)
+	if err != nil {
+		return
+	}
+
+	var arr goast.Expr
+	if len(n.Children()) > 0 {
+		// sizeof(array)
+		arr, _, preStmts, postStmts, err = transpileToExpr(n.Children()[0], p, false)
+		if err != nil {
+			return
+		}
+	}
+
+	var factors []goast.Expr
+	for _, size := range sizes {
+		var e goast.Expr
+		if arr != nil {
+			e = &goast.CallExpr{
+				Fun:  goast.NewIdent("len"),
+				Args: []goast.Expr{arr},
+			}
+			arr = &goast.IndexExpr{X: arr, Index: util.NewIntLit(0)}
+		} else {
+			// sizeof(int [n])
+			var value goast.Expr
+			value, err = parser.ParseExpr(size)
+			if err != nil {
+				return
+			}
+			name := p.GetNextIdentifier("c4go_vla_size")
+			preStmts = append(preStmts, &goast.AssignStmt{
+				Lhs: []goast.Expr{util.NewIdent(name)},
+				Tok: token.DEFINE,
+				Rhs: []goast.Expr{value},
+			})
+			e = util.NewIdent(name)
+		}
+		factors = append(factors, &goast.CallExpr{
+			Fun:  goast.NewIdent(goType),
+			Args: []goast.Expr{e},
+		})
+	}
+
+	expr = factors[0]
+	for _, f := range factors[1:] {
+		expr = &goast.BinaryExpr{X: expr, Op: token.MUL, Y: f}
+	}
+	expr = &goast.BinaryExpr{X: expr, Op: token.MUL, Y: util.NewIntLit(baseSize)}
+	return
+}
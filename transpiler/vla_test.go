@@ -0,0 +1,118 @@
+package transpiler
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"strings"
+	"testing"
+
+	goast "go/ast"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+)
+
+func TestVariableArray(t *testing.T) {
+	p := program.NewProgram()
+	p.AddFunctionDefinition(program.DefinitionFunction{
+		Name:          "strlen",
+		ReturnType:    "int",
+		ArgumentTypes: []string{"const char *"},
+		Substitution:  "github.com/Konstantin8105/c4go/noarch.Strlen",
+	})
+
+	toString := func(expr goast.Node) string {
+		var buf bytes.Buffer
+		if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	for cType, code := range map[string][]string{
+		"int [n]": {"make([]int32, n)"},
+		"double [n][m + 1]": {
+			"c4go_vla := make([][]float64, n)",
+			"c4go_vla[i] = make([]float64, m+1)",
+		},
+		"int [n + 2u]":             {"make([]int32, n+2)"},
+		"int [p->n]":               {"make([]int32, p[0].n)"},
+		"int [n * (m - 1)]":        {"make([]int32, int(n)*int(m-1))"},
+		"int [a[i] << 1UL]":        {"make([]int32, a[i]<<1)"},
+		"char [strlen(s) + 1]":     {"make([]byte, noarch.Strlen(s)+1)"},
+		"double [sizeof(int) * n]": {"make([]float64, 4*n)"},
+		"int [type]":               {"make([]int32, type_c4go_postfix)"},
+		"char [n][4][k]": {
+			"c4go_vla := make([][][]byte, n)",
+			"c4go_vla := make([][]byte, 4)",
+			"c4go_vla[i] = make([]byte, k)",
+		},
+	} {
+		expr, err := transpileVariableArray(p, cType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := toString(expr)
+		for _, part := range code {
+			if !strings.Contains(src, part) {
+				t.Errorf("cannot find %q in code:\n%s", part, src)
+			}
+		}
+	}
+
+	for _, cType := range []string{"int [4]", "int []", "int (*)[n]", "int [n)]",
+		"int [n ? 1 : 2]", "int [undefined(n)]", "int [1.5 * n]", "int [p->]"} {
+		if _, err := transpileVariableArray(p, cType); err == nil {
+			t.Errorf("type %s is not valid variable length array", cType)
+		}
+	}
+
+	sizeofs := []struct {
+		node ast.Node
+		code string
+		pre  string
+	}{
+		{
+			node: &ast.UnaryExprOrTypeTraitExpr{
+				Function: "sizeof",
+				Type1:    "unsigned long",
+				ChildNodes: []ast.Node{&ast.DeclRefExpr{
+					Name: "a",
+					Type: "double [n][m]",
+				}},
+			},
+			code: "uint32(len(a)) * uint32(len(a[0])) * 8",
+		},
+		{
+			node: &ast.UnaryExprOrTypeTraitExpr{
+				Function: "sizeof",
+				Type1:    "unsigned long",
+				Type2:    "int [n + 1]",
+			},
+			code: "uint32(c4go_vla_size0) * 4",
+			pre:  "c4go_vla_size0 := n + 1",
+		},
+	}
+	for _, tc := range sizeofs {
+		expr, _, preStmts, _, err := transpileToExpr(tc.node, p, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if src := toString(expr); src != tc.code {
+			t.Errorf("not valid sizeof: `%s` != `%s`", src, tc.code)
+		}
+		var pre []string
+		for _, s := range preStmts {
+			pre = append(pre, toString(s))
+		}
+		if src := strings.Join(pre, "\n"); src != tc.pre {
+			t.Errorf("not valid statements before sizeof: `%s` != `%s`", src, tc.pre)
+		}
+	}
+
+	if !types.IsVariableArray("int [n]") || types.IsVariableArray("int [3][4]") {
+		t.Errorf("not valid checking of variable length array")
+	}
+}
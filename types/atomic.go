@@ -0,0 +1,90 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// atomicTypes - types of package "sync/atomic" for Go types of C11
+// atomic types
+var atomicTypes = map[string]string{
+	"int32":  "sync/atomic.Int32",
+	"uint32": "sync/atomic.Uint32",
+	"int64":  "sync/atomic.Int64",
+	"uint64": "sync/atomic.Uint64",
+
+	// characters and shorts are widened to int32
+	"int8":   "github.com/Konstantin8105/c4go/noarch.AtomicInt8",
+	"uint8":  "github.com/Konstantin8105/c4go/noarch.AtomicUint8",
+	"byte":   "github.com/Konstantin8105/c4go/noarch.AtomicUint8",
+	"int16":  "github.com/Konstantin8105/c4go/noarch.AtomicInt16",
+	"uint16": "github.com/Konstantin8105/c4go/noarch.AtomicUint16",
+}
+
+// atomicPointer - generic type of package "noarch" for atomic pointers.
+const atomicPointer = "github.com/Konstantin8105/c4go/noarch.AtomicPointer"
+
+// atomicFlag - C type of `atomic_flag`. Flag is structure with one
+// member `_Value` of type `atomic_bool`, so flag is transpiled as atomic
+// `_Bool`.
+const atomicFlag = "struct atomic_flag"
+
+// GetAtomicBaseType return C type without qualifier `_Atomic`.
+//
+// Examples:
+//
+//	"_Atomic(int)" -> "int"
+//	"atomic_int"   -> "int" // typedef _Atomic int atomic_int;
+//	"atomic_flag"  -> "_Bool"
+func GetAtomicBaseType(p *program.Program, cType string) (base string, ok bool) {
+	cType = util.CleanCType(cType)
+	if cType == atomicFlag || cType == "atomic_flag" {
+		return "_Bool", true
+	}
+	if strings.HasPrefix(cType, "_Atomic(") && strings.HasSuffix(cType, ")") {
+		return strings.TrimSpace(cType[len("_Atomic(") : len(cType)-1]), true
+	}
+	if t, ok := p.GetBaseTypeOfTypedef(cType); ok {
+		return GetAtomicBaseType(p, t)
+	}
+	return "", false
+}
+
+// IsCAtomic - return true, if C type is atomic type
+func IsCAtomic(p *program.Program, cType string) bool {
+	_, ok := GetAtomicBaseType(p, cType)
+	return ok
+}
+
+// IsAtomicFlag return true, if C type is type of structure
+// `atomic_flag` or pointer to that structure.
+func IsAtomicFlag(p *program.Program, cType string) bool {
+	cType = strings.TrimSpace(strings.TrimSuffix(util.CleanCType(cType), "*"))
+	if cType == atomicFlag || cType == "atomic_flag" {
+		return true
+	}
+	if t, ok := p.GetBaseTypeOfTypedef(cType); ok {
+		return IsAtomicFlag(p, t)
+	}
+	return false
+}
+
+// resolveAtomicType return type of package "sync/atomic" for C base type
+// of atomic type. Atomic pointer is generic type of package "noarch".
+func resolveAtomicType(p *program.Program, cBase string) (string, error) {
+	goType, err := ResolveType(p, cBase)
+	if err != nil {
+		return "", err
+	}
+	if IsCPointer(cBase, p) && !strings.HasPrefix(goType, "func") {
+		return p.ImportType(atomicPointer) + "[" + goType + "]", nil
+	}
+	t, ok := atomicTypes[goType]
+	if !ok {
+		return "", fmt.Errorf("atomic type for Go type `%s` is not supported", goType)
+	}
+	return p.ImportType(t), nil
+}
@@ -350,6 +350,11 @@ func CastExpr(p *program.Program, expr goast.Expr, cFromType, cToType string) (
 		return expr, nil
 	}
 
+	// C99 complex types
+	if isGoComplex(fromType) || isGoComplex(toType) {
+		return castComplex(expr, fromType, toType)
+	}
+
 	// Compatible integer types
 	types := []string{
 		// Integer types
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// IsCComplex - return true is C type complex
+func IsCComplex(p *program.Program, cType string) bool {
+	cType = util.CleanCType(cType)
+	if strings.Contains(cType, "_Complex") {
+		return !strings.Contains(cType, "*") && !strings.Contains(cType, "[")
+	}
+	if rt, ok := p.TypedefType[cType]; ok {
+		return IsCComplex(p, rt)
+	}
+	return false
+}
+
+func isGoComplex(goType string) bool {
+	return goType == "complex64" || goType == "complex128"
+}
+
+// complexPartType return Go type of real and imaginary parts of complex type
+func complexPartType(goType string) string {
+	if goType == "complex64" {
+		return "float32"
+	}
+	return "float64"
+}
+
+// castComplex returns casting between Go types, if one of types is complex.
+func castComplex(expr goast.Expr, fromType, toType string) (goast.Expr, error) {
+	numbers := []string{
+		"byte",
+		"int", "int8", "int16", "int32", "int64",
+		"uint8", "uint16", "uint32", "uint64",
+		"float32", "float64",
+	}
+	switch {
+	case isGoComplex(fromType) && isGoComplex(toType):
+		// complex64(z)
+		return util.NewCallExpr(toType, expr), nil
+
+	case isGoComplex(fromType) && toType == "bool":
+		// z != 0
+		return util.NewBinaryExpr(expr, token.NEQ, util.NewIntLit(0),
+			toType, false), nil
+
+	case isGoComplex(fromType) && util.InStrings(toType, numbers):
+		// float64(real(z))
+		return util.NewCallExpr(toType, util.NewCallExpr("real", expr)), nil
+
+	case isGoComplex(toType) && util.InStrings(fromType, numbers):
+		// complex(float64(x), 0)
+		return &goast.CallExpr{
+			Fun: goast.NewIdent("complex"),
+			Args: []goast.Expr{
+				util.NewCallExpr(complexPartType(toType), expr),
+				util.NewIntLit(0),
+			},
+		}, nil
+	}
+	return expr, fmt.Errorf("cannot cast complex types `%s`->`%s`",
+		fromType, toType)
+}
@@ -0,0 +1,303 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/util"
+)
+
+// RecordLayout is memory layout of C struct or union as clang place it
+// on x86_64 machine.
+type RecordLayout struct {
+	// Size of record in bytes with tail padding.
+	Size int
+
+	// Align - alignment of record in bytes.
+	Align int
+
+	// Offsets and sizes of members in bytes. Key is name of field or
+	// bit-field storage.
+	Offsets map[string]int
+	Sizes   map[string]int
+}
+
+// GetRecordLayout return memory layout of C struct or union.
+//
+// Rules of layout:
+//   - field is placed at the first offset multiple of it alignment;
+//   - attribute `packed` of struct or field set alignment of field to 1;
+//   - attribute `aligned` increase alignment of field or struct;
+//   - `#pragma pack(N)` limit alignment of fields by N;
+//   - all fields of union are placed at offset 0;
+//   - sequence of bit-fields is placed right after previous member,
+//     see program.(*Struct).PlaceBitFields;
+//   - size of record is multiple of alignment of record.
+func GetRecordLayout(p *program.Program, s *program.Struct) (
+	l RecordLayout, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot get layout of record `%s`: %v", s.Name, err)
+		}
+	}()
+
+	l.Align = 1
+	l.Offsets = map[string]int{}
+	l.Sizes = map[string]int{}
+
+	var end int
+	for i, name := range s.Members {
+		if run, ok := s.GetBitFieldRun(name); ok {
+			var nextAlign int
+			if i+1 < len(s.Members) {
+				if _, ok := s.GetBitFieldRun(s.Members[i+1]); !ok {
+					_, nextAlign, err = memberLayout(p, s, s.Members[i+1])
+					if err != nil {
+						return
+					}
+				}
+			}
+			end, err = bitFieldsLayout(p, s, run, end, nextAlign, &l)
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		var size, align int
+		size, align, err = memberLayout(p, s, name)
+		if err != nil {
+			return
+		}
+
+		var offset int
+		if s.Type != program.UnionType {
+			offset = alignUp(end, align)
+		}
+		l.Offsets[name] = offset
+		l.Sizes[name] = size
+		if end < offset+size {
+			end = offset + size
+		}
+		if l.Align < align {
+			l.Align = align
+		}
+	}
+
+	if l.Align < s.Align {
+		l.Align = s.Align
+	}
+	l.Size = alignUp(end, l.Align)
+	return
+}
+
+// memberLayout return size and alignment of field of record in bytes.
+func memberLayout(p *program.Program, s *program.Struct, name string) (
+	size, align int, err error) {
+	var cType string
+	switch f := s.Fields[name].(type) {
+	case string:
+		cType = f
+	case *program.Struct:
+		cType = f.Name
+	default:
+		err = fmt.Errorf("cannot find type of field `%s`", name)
+		return
+	}
+
+	size, err = layoutSizeOf(p, cType)
+	if err != nil {
+		return
+	}
+	align, err = AlignOf(p, cType)
+	if err != nil {
+		return
+	}
+
+	if s.Packed || s.PackedFields[name] {
+		align = 1
+	}
+	if a := s.FieldAligns[name]; align < a {
+		align = a
+	}
+	return size, limitAlign(s, align), nil
+}
+
+// bitFieldsLayout place sequence of neighbor bit-fields after byte `start`
+// and return end of sequence in bytes. Storage fields of bit-fields are
+// added in layout of record.
+func bitFieldsLayout(p *program.Program, s *program.Struct, run, start,
+	nextAlign int, l *RecordLayout) (end int, err error) {
+	end, err = s.PlaceBitFields(p, run, start, nextAlign)
+	if err != nil {
+		return
+	}
+	for _, st := range s.GetBitFieldStorages(run) {
+		var size int
+		size, err = SizeOf(p, st.CType)
+		if err != nil {
+			return
+		}
+		l.Offsets[st.Name] = st.Offset
+		l.Sizes[st.Name] = size
+		if end < st.Offset+size {
+			end = st.Offset + size
+		}
+	}
+	// alignment of record is alignment of C types of named bit-fields
+	for _, bf := range s.BitFields {
+		if bf.Run != run {
+			continue
+		}
+		var align int
+		align, err = AlignOf(p, bf.CType)
+		if err != nil {
+			return
+		}
+		if s.Packed || s.PackedFields[bf.Name] {
+			align = 1
+		}
+		if align = limitAlign(s, align); l.Align < align {
+			l.Align = align
+		}
+	}
+	return
+}
+
+// limitAlign limit alignment of field by `#pragma pack`.
+func limitAlign(s *program.Struct, align int) int {
+	if 0 < s.MaxFieldAlign && s.MaxFieldAlign < align {
+		return s.MaxFieldAlign
+	}
+	return align
+}
+
+// AlignOf returns the alignment in bytes of C type. This the same as
+// using the _Alignof operator in C.
+func AlignOf(p *program.Program, cType string) (align int, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cannot determine alignof : |%s|. err = %v", cType, err)
+		}
+	}()
+
+	cType = util.CleanCType(cType)
+	cType = strings.Replace(cType, "unsigned ", "", -1)
+	cType = strings.Replace(cType, "signed ", "", -1)
+
+	pointerSize := 8
+
+	switch {
+	case cType == "FILE *" || cType == "FILE" || cType == "struct _IO_FILE *":
+		return pointerSize, nil
+	case strings.HasPrefix(cType, "enum "):
+		return AlignOf(p, "int")
+	}
+
+	if v, ok := p.GetBaseTypeOfTypedef(cType); ok {
+		return AlignOf(p, v)
+	}
+	if base, ok := GetAtomicBaseType(p, cType); ok {
+		return AlignOf(p, base)
+	}
+	if _, ok := p.EnumTypedefName[cType]; ok {
+		return AlignOf(p, "int")
+	}
+
+	if s, ok := GetRecord(p, cType); ok {
+		var l RecordLayout
+		l, err = GetRecordLayout(p, s)
+		return l.Align, err
+	}
+
+	if arrayType, arraySize := GetArrayTypeAndSize(cType); arraySize > 0 {
+		return AlignOf(p, arrayType)
+	}
+
+	if strings.Contains(cType, "(") || strings.HasSuffix(cType, "*") {
+		return pointerSize, nil
+	}
+
+	if cType == "long double" {
+		return 16, nil
+	}
+
+	size, err := SizeOf(p, cType)
+	if err != nil {
+		return 0, err
+	}
+	if IsCComplex(p, cType) {
+		size /= 2
+	}
+	if size <= 0 {
+		size = 1
+	}
+	return size, nil
+}
+
+// layoutSizeOf is same as SizeOf, but size of function pointer is
+// size of pointer.
+func layoutSizeOf(p *program.Program, cType string) (int, error) {
+	t := util.CleanCType(cType)
+	for {
+		v, ok := p.GetBaseTypeOfTypedef(t)
+		if !ok {
+			break
+		}
+		t = v
+	}
+	if strings.Contains(t, "(*)") {
+		return 8, nil
+	}
+	return SizeOf(p, cType)
+}
+
+// OffsetOf returns the offset in bytes of field in C struct or union with
+// memory layout of C. This the same as using the offsetof macro in C.
+func OffsetOf(p *program.Program, cType, field string) (offset int, err error) {
+	s, ok := GetRecord(p, cType)
+	if !ok {
+		return 0, fmt.Errorf("cannot find record `%s`", cType)
+	}
+	l, err := GetRecordLayout(p, s)
+	if err != nil {
+		return 0, err
+	}
+	offset, ok = l.Offsets[field]
+	if !ok {
+		return 0, fmt.Errorf("cannot find field `%s` in record `%s`", field, cType)
+	}
+	return offset, nil
+}
+
+// GetRecord return definition of struct or union by C type.
+func GetRecord(p *program.Program, cType string) (s *program.Struct, ok bool) {
+	cType = util.CleanCType(cType)
+	if v, ok := p.GetBaseTypeOfTypedef(cType); ok {
+		return GetRecord(p, v)
+	}
+	cType = util.GenerateCorrectType(cType)
+	for _, name := range []string{
+		cType,
+		"struct " + cType,
+		strings.TrimPrefix(cType, "struct "),
+	} {
+		if s, ok = p.Structs[name]; ok {
+			return
+		}
+	}
+	for _, name := range []string{cType, "union " + cType} {
+		if s, ok = p.Unions[name]; ok {
+			return
+		}
+	}
+	return nil, false
+}
+
+func alignUp(offset, align int) int {
+	if align <= 1 {
+		return offset
+	}
+	return (offset + align - 1) / align * align
+}
@@ -0,0 +1,174 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/Konstantin8105/c4go/ast"
+	"github.com/Konstantin8105/c4go/program"
+	"github.com/Konstantin8105/c4go/types"
+)
+
+func TestRecordLayout(t *testing.T) {
+	p := program.NewProgram()
+	p.CLayout = true
+
+	aligned := func(value string) *ast.AlignedAttr {
+		return &ast.AlignedAttr{
+			IsAligned: true,
+			ChildNodes: []ast.Node{&ast.ConstantExpr{
+				Type:       "int",
+				ChildNodes: []ast.Node{&ast.IntegerLiteral{Type: "int", Value: value}},
+			}},
+		}
+	}
+
+	bitField := func(name, cType, width string) *ast.FieldDecl {
+		return &ast.FieldDecl{
+			Name: name,
+			Type: cType,
+			ChildNodes: []ast.Node{&ast.ConstantExpr{
+				Type:       "int",
+				ChildNodes: []ast.Node{&ast.IntegerLiteral{Type: "int", Value: width}},
+			}},
+		}
+	}
+
+	records := []*ast.RecordDecl{
+		// struct rec { char c; int i; double d; short s; };
+		{Kind: "struct", Name: "rec", ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			&ast.FieldDecl{Name: "i", Type: "int"},
+			&ast.FieldDecl{Name: "d", Type: "double"},
+			&ast.FieldDecl{Name: "s", Type: "short"},
+		}},
+		// struct __attribute__((packed)) pk { char c; int i; short s; };
+		{Kind: "struct", Name: "pk", ChildNodes: []ast.Node{
+			&ast.PackedAttr{},
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			&ast.FieldDecl{Name: "i", Type: "int"},
+			&ast.FieldDecl{Name: "s", Type: "short"},
+		}},
+		// struct al { char c; int i __attribute__((aligned(8))); };
+		{Kind: "struct", Name: "al", ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			&ast.FieldDecl{Name: "i", Type: "int", ChildNodes: []ast.Node{aligned("8")}},
+		}},
+		// #pragma pack(2)
+		// struct pp { char c; double d; };
+		{Kind: "struct", Name: "pp", ChildNodes: []ast.Node{
+			&ast.MaxFieldAlignmentAttr{Size: 16},
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			&ast.FieldDecl{Name: "d", Type: "double"},
+		}},
+		// struct __attribute__((aligned(16))) big { char c; };
+		{Kind: "struct", Name: "big", ChildNodes: []ast.Node{
+			aligned("16"),
+			&ast.FieldDecl{Name: "c", Type: "char"},
+		}},
+		// struct nest { char c; struct rec r; };
+		{Kind: "struct", Name: "nest", ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			&ast.FieldDecl{Name: "r", Type: "struct rec"},
+		}},
+		// struct bf1 { char c; int x : 3; };
+		{Kind: "struct", Name: "bf1", ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			bitField("x", "int", "3"),
+		}},
+		// struct bf2 { char c; int x : 20; char d; };
+		{Kind: "struct", Name: "bf2", ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			bitField("x", "int", "20"),
+			&ast.FieldDecl{Name: "d", Type: "char"},
+		}},
+		// struct bf3 { int a : 3; char d; };
+		{Kind: "struct", Name: "bf3", ChildNodes: []ast.Node{
+			bitField("a", "int", "3"),
+			&ast.FieldDecl{Name: "d", Type: "char"},
+		}},
+		// struct bf4 { char c; int : 0; char d; };
+		{Kind: "struct", Name: "bf4", ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			bitField("", "int", "0"),
+			&ast.FieldDecl{Name: "d", Type: "char"},
+		}},
+		// struct __attribute__((packed)) bf5 { char c; int x : 20; char d; };
+		{Kind: "struct", Name: "bf5", ChildNodes: []ast.Node{
+			&ast.PackedAttr{},
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			bitField("x", "int", "20"),
+			&ast.FieldDecl{Name: "d", Type: "char"},
+		}},
+		// #pragma pack(2)
+		// struct bf6 { char c; int x : 28; };
+		{Kind: "struct", Name: "bf6", ChildNodes: []ast.Node{
+			&ast.MaxFieldAlignmentAttr{Size: 16},
+			&ast.FieldDecl{Name: "c", Type: "char"},
+			bitField("x", "int", "28"),
+		}},
+		// struct bf7 { short s; long long x : 20; int y : 30; double d; };
+		{Kind: "struct", Name: "bf7", ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "s", Type: "short"},
+			bitField("x", "long long", "20"),
+			bitField("y", "int", "30"),
+			&ast.FieldDecl{Name: "d", Type: "double"},
+		}},
+		// union un { char c[5]; int i; };
+		{Kind: "union", Name: "un", ChildNodes: []ast.Node{
+			&ast.FieldDecl{Name: "c", Type: "char [5]"},
+			&ast.FieldDecl{Name: "i", Type: "int"},
+		}},
+	}
+	for _, r := range records {
+		s, err := program.NewStruct(p, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Kind == "union" {
+			p.Unions["union "+r.Name] = s
+		} else {
+			p.Structs["struct "+r.Name] = s
+		}
+	}
+
+	tcs := []struct {
+		cType   string
+		size    int
+		align   int
+		offsets map[string]int
+	}{
+		{"struct rec", 24, 8, map[string]int{"c": 0, "i": 4, "d": 8, "s": 16}},
+		{"struct pk", 7, 1, map[string]int{"c": 0, "i": 1, "s": 5}},
+		{"struct al", 16, 8, map[string]int{"c": 0, "i": 8}},
+		{"struct pp", 10, 2, map[string]int{"c": 0, "d": 2}},
+		{"struct big", 16, 16, map[string]int{"c": 0}},
+		{"struct nest", 32, 8, map[string]int{"c": 0, "r": 8}},
+		{"struct bf1", 4, 4, map[string]int{"c": 0, "c4go_bitfield_0": 1}},
+		{"struct bf2", 8, 4, map[string]int{"c": 0, "c4go_bitfield_0": 1, "d": 4}},
+		{"struct bf3", 4, 4, map[string]int{"c4go_bitfield_0": 0, "d": 1}},
+		{"struct bf4", 5, 1, map[string]int{"c": 0, "d": 4}},
+		{"struct bf5", 5, 1, map[string]int{"c": 0, "c4go_bitfield_0": 1, "d": 4}},
+		{"struct bf6", 6, 2, map[string]int{"c": 0, "c4go_bitfield_0": 1}},
+		{"struct bf7", 24, 8, map[string]int{"s": 0, "c4go_bitfield_0": 2, "c4go_bitfield_1": 8, "d": 16}},
+		{"union un", 8, 4, map[string]int{"c": 0, "i": 0}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.cType, func(t *testing.T) {
+			size, err := types.SizeOf(p, tc.cType)
+			if err != nil || size != tc.size {
+				t.Errorf("not valid size: %d != %d. %v", size, tc.size, err)
+			}
+			align, err := types.AlignOf(p, tc.cType)
+			if err != nil || align != tc.align {
+				t.Errorf("not valid align: %d != %d. %v", align, tc.align, err)
+			}
+			for field, offset := range tc.offsets {
+				o, err := types.OffsetOf(p, tc.cType, field)
+				if err != nil || o != offset {
+					t.Errorf("not valid offset of `%s`: %d != %d. %v",
+						field, o, offset, err)
+				}
+			}
+		})
+	}
+}
@@ -172,6 +172,11 @@ func ResolveType(p *program.Program, s string) (resolveResult string, err error)
 		return "* va_list", nil
 	}
 
+	// C11 atomic types
+	if base, ok := GetAtomicBaseType(p, s); ok {
+		return resolveAtomicType(p, base)
+	}
+
 	// The simple resolve types are the types that we know there is an exact Go
 	// equivalent. For example float, int, etc.
 	if v, ok := program.DefinitionType[s]; ok {
@@ -203,6 +208,13 @@ func ResolveType(p *program.Program, s string) (resolveResult string, err error)
 		return ii, nil
 	}
 
+	// Variable length arrays
+	// int [n][m] -> [][]int32
+	if base, sizes, ok := GetVariableArraySizes(s); ok {
+		resolveResult, err = ResolveType(p, base)
+		return strings.Repeat("[]", len(sizes)) + resolveResult, err
+	}
+
 	// For function
 	if util.IsFunction(s) {
 		g, e := resolveFunction(p, s)
@@ -22,10 +22,17 @@ var resolveTestCases = []resolveTestCase{
 	{"div_t", "noarch.DivT"},
 	{"ldiv_t", "noarch.LdivT"},
 	{"lldiv_t", "noarch.LldivT"},
+	{"jmp_buf", "noarch.JmpBuf"},
+	{"struct __jmp_buf_tag [1]", "noarch.JmpBuf"},
+	{"struct __jmp_buf_tag *", "*noarch.JmpBuf"},
 	{"int [2]", "[]int32"},
 	{"int [2][3]", "[][]int32"},
 	{"int [2][3][4]", "[][][]int32"},
 	{"int [2][3][4][5]", "[][][][]int32"},
+	{"int [n]", "[]int32"},
+	{"double [n][m + 1]", "[][]float64"},
+	{"char [strlen(s) + 1]", "[]byte"},
+	{"int [n][4]", "[][]int32"},
 	{"int (*[2])(int, int)", "[2]func(int32,int32)(int32)"},
 	{"int (*(*(*)))(int, int)", "[][]func(int32,int32)(int32)"},
 }
@@ -59,6 +59,11 @@ func SizeOf(p *program.Program, cType string) (size int, err error) {
 		return SizeOf(p, v)
 	}
 
+	// C11 atomic types have the same size
+	if base, ok := GetAtomicBaseType(p, cType); ok {
+		return SizeOf(p, base)
+	}
+
 	// typedef Enum
 	if _, ok := p.EnumTypedefName[cType]; ok {
 		return SizeOf(p, "int")
@@ -77,6 +82,10 @@ func SizeOf(p *program.Program, cType string) (size int, err error) {
 			isStruct = true
 		}
 	}
+	if isStruct && p.CLayout {
+		l, err := GetRecordLayout(p, s)
+		return l.Size, err
+	}
 	if isStruct {
 		totalBytes := 0
 
@@ -87,6 +96,21 @@ func SizeOf(p *program.Program, cType string) (size int, err error) {
 
 		last := 0
 
+		// sequences of bit-fields, which are already calculated
+		runs := map[int]bool{}
+		storagesSize := func(run int) (bytes int, err error) {
+			runs[run] = true
+			for _, st := range s.GetBitFieldStorages(run) {
+				var b int
+				b, err = SizeOf(p, st.CType)
+				if err != nil {
+					return
+				}
+				bytes += b
+			}
+			return
+		}
+
 		for k := 0; k < len(s.FieldNames); k++ {
 			fn := s.FieldNames[k]
 			t := s.Fields[fn]
@@ -96,6 +120,15 @@ func SizeOf(p *program.Program, cType string) (size int, err error) {
 
 			var new_par int = -1
 
+			if bf, ok := s.BitFields[fn]; ok {
+				if runs[bf.Run] {
+					continue
+				}
+				t = nil
+				bytes, err = storagesSize(bf.Run)
+				new_par = 0
+			}
+
 			switch f := t.(type) {
 			case string:
 				bytes, err = SizeOf(p, f)
@@ -124,6 +157,18 @@ func SizeOf(p *program.Program, cType string) (size int, err error) {
 			totalBytes += bytes
 		}
 
+		// bit-fields without names are used only for padding
+		for _, st := range s.Storages {
+			if runs[st.Run] {
+				continue
+			}
+			bytes, err := storagesSize(st.Run)
+			if err != nil {
+				return 0, err
+			}
+			totalBytes += bytes
+		}
+
 		// The size of a struct is rounded up to fit the size of the pointer of
 		// the OS.
 		if totalBytes%pointerSize != 0 {
@@ -149,6 +194,11 @@ func SizeOf(p *program.Program, cType string) (size int, err error) {
 			return 0, fmt.Errorf("error in union")
 		}
 
+		if p.CLayout {
+			l, err := GetRecordLayout(p, s)
+			return l.Size, err
+		}
+
 		for k := 0; k < len(s.FieldNames); k++ {
 			fn := s.FieldNames[k]
 			t := s.Fields[fn]
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GetVariableArraySizes returns the base type and sizes of a variable
+// length array. Sizes are C expressions as they are written in type.
+// If the type is not a variable length array, then ok is false.
+//
+// Examples:
+//
+//	int [n]         -> int,    [n]
+//	double [n][m]   -> double, [n, m]
+//	char [len + 1]  -> char,   [len + 1]
+//	int [n][4]      -> int,    [n, 4]
+//	int [4]         -> not ok
+func GetVariableArraySizes(cType string) (baseType string, sizes []string, ok bool) {
+	s := strings.TrimSpace(cType)
+	for strings.HasSuffix(s, "]") {
+		// find open bracket of last size
+		depth := 0
+		index := -1
+		for i := len(s) - 1; i >= 0; i-- {
+			if s[i] == ']' {
+				depth++
+			} else if s[i] == '[' {
+				depth--
+				if depth == 0 {
+					index = i
+					break
+				}
+			}
+		}
+		if index < 0 {
+			return cType, nil, false
+		}
+		sizes = append([]string{strings.TrimSpace(s[index+1 : len(s)-1])}, sizes...)
+		s = strings.TrimSpace(s[:index])
+	}
+	if s == "" || strings.Contains(s, "(") || strings.Contains(s, "[") {
+		// pointer to array, for example: `int (*)[n]`
+		return cType, nil, false
+	}
+	for _, size := range sizes {
+		if size == "" {
+			// incomplete array type
+			return cType, nil, false
+		}
+		if _, err := strconv.Atoi(size); err != nil {
+			ok = true
+		}
+	}
+	if !ok {
+		return cType, nil, false
+	}
+	return s, sizes, true
+}
+
+// IsVariableArray - return true, if C type is variable length array
+func IsVariableArray(cType string) bool {
+	_, _, ok := GetVariableArraySizes(cType)
+	return ok
+}
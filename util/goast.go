@@ -435,3 +435,34 @@ func ConvertToUnsigned(expr goast.Expr, returnType string) goast.Expr {
 		},
 	}}
 }
+
+// GetDeclNames returns names of top-level Go declaration. Name of method
+// is prefixed by name of receiver type, for example "point.String".
+func GetDeclNames(decl goast.Decl) (names []string) {
+	switch d := decl.(type) {
+	case *goast.FuncDecl:
+		name := d.Name.Name
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			t := d.Recv.List[0].Type
+			if s, ok := t.(*goast.StarExpr); ok {
+				t = s.X
+			}
+			if id, ok := t.(*goast.Ident); ok {
+				name = id.Name + "." + name
+			}
+		}
+		names = append(names, name)
+	case *goast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *goast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *goast.ValueSpec:
+				for _, id := range s.Names {
+					names = append(names, id.Name)
+				}
+			}
+		}
+	}
+	return
+}
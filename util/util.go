@@ -68,6 +68,17 @@ func GetExportedName(field string) string {
 // IsFunction - return true if string is function like "void (*)(void)"
 func IsFunction(s string) bool {
 	s = strings.Replace(s, "(*)", "", -1)
+	// C11 atomic type, like `_Atomic(int)`
+	s = strings.Replace(s, "_Atomic(", "", -1)
+	// size expression of variable length array, like `char [strlen(s) + 1]`
+	for {
+		begin := strings.Index(s, "[")
+		end := strings.Index(s, "]")
+		if begin < 0 || end < begin {
+			break
+		}
+		s = s[:begin] + s[end+1:]
+	}
 	return strings.Contains(s, "(")
 }
 